@@ -22,7 +22,10 @@
 package common
 
 import (
+	"fmt"
 	"minlib/security"
+	"strconv"
+	"strings"
 
 	"gopkg.in/ini.v1"
 )
@@ -33,15 +36,19 @@ import (
 // @Description:
 //
 type MIRConfig struct {
-	GeneralConfig    `ini:"General"`
-	LogConfig        `ini:"Log"`
-	TableConfig      `ini:"Table"`
-	LogicFaceConfig  `ini:"LogicFace"`
-	SecurityConfig   `ini:"Security"`
-	ForwarderConfig  `ini:"Forwarder"`
-	StrategyConfig   `ini:"StrategyConfig"`
-	ManagementConfig `ini:"Management"`
-	PcapConfig       `ini:"Pcap"`
+	GeneralConfig       `ini:"General"`
+	LogConfig           `ini:"Log"`
+	TableConfig         `ini:"Table"`
+	LogicFaceConfig     `ini:"LogicFace"`
+	SecurityConfig      `ini:"Security"`
+	ForwarderConfig     `ini:"Forwarder"`
+	StrategyConfig      `ini:"StrategyConfig"`
+	ManagementConfig    `ini:"Management"`
+	PcapConfig          `ini:"Pcap"`
+	RoutingConfig       `ini:"Routing"`
+	DiscoveryConfig     `ini:"NeighborDiscovery"`
+	PropagationConfig   `ini:"PrefixPropagation"`
+	AuthorizationConfig `ini:"PrefixAuthorization"`
 
 	configPath string // 存储配置文件路径
 }
@@ -59,6 +66,7 @@ func (mirConfig *MIRConfig) Init() {
 	mirConfig.GeneralConfig.IdentifierType = []int{102, 103, 104}
 	mirConfig.GeneralConfig.DefaultRouteConfigPath = "/usr/local/etc/mir/defaultRoute.xml"
 	mirConfig.GeneralConfig.DefaultRouteRetryCount = 3
+	mirConfig.GeneralConfig.RouteStatePath = "/usr/local/etc/mir/routeState.json"
 
 	// Log
 	mirConfig.LogConfig.LogLevel = "INFO"
@@ -70,17 +78,45 @@ func (mirConfig *MIRConfig) Init() {
 	mirConfig.TableConfig.CSSize = 500
 	mirConfig.TableConfig.CSReplaceStrategy = "LRU"
 	mirConfig.TableConfig.CacheUnsolicitedData = false
+	mirConfig.TableConfig.UnsolicitedDataPolicy = ""
+	mirConfig.TableConfig.UnsolicitedDataPolicies = map[string]string{}
+	mirConfig.TableConfig.CSPartitions = []string{}
+	mirConfig.TableConfig.CSMaxAge = 0
+	mirConfig.TableConfig.CSSweepInterval = 1000
+	mirConfig.TableConfig.CSSweepBatchSize = 256
+	mirConfig.TableConfig.RoutePriorities = map[uint64]uint64{}
 
 	// LogicFace
 	mirConfig.LogicFaceConfig.SupportTCP = true
 	mirConfig.LogicFaceConfig.TCPPort = 13899
+	mirConfig.LogicFaceConfig.TCPListenAddr = ""
 	mirConfig.LogicFaceConfig.SupportUDP = true
 	mirConfig.LogicFaceConfig.UDPPort = 13899
+	mirConfig.LogicFaceConfig.UDPListenAddr = ""
 	mirConfig.LogicFaceConfig.SupportUnix = true
 	mirConfig.LogicFaceConfig.UnixPath = "/tmp/mir.sock"
+	mirConfig.LogicFaceConfig.SupportTLS = false
+	mirConfig.LogicFaceConfig.TLSPort = 13900
+	mirConfig.LogicFaceConfig.TLSListenAddr = ""
+	mirConfig.LogicFaceConfig.TLSCertFile = ""
+	mirConfig.LogicFaceConfig.TLSKeyFile = ""
+	mirConfig.LogicFaceConfig.TLSCAFile = ""
+	mirConfig.LogicFaceConfig.TLSClientAuth = "none"
+	mirConfig.LogicFaceConfig.SupportWebSocket = false
+	mirConfig.LogicFaceConfig.WebSocketPort = 13901
+	mirConfig.LogicFaceConfig.WebSocketListenAddr = ""
+	mirConfig.LogicFaceConfig.WebSocketPath = "/mir"
+	mirConfig.LogicFaceConfig.UdpMulticast = []string{}
 
 	mirConfig.LFRecvQueSize = 10000
 	mirConfig.LFSendQueSize = 10000
+	mirConfig.LFSendQueDropPolicy = "tail-drop"
+	mirConfig.LFSendQueDataWeight = 4
+	mirConfig.LFSendQueInterestWeight = 1
+	mirConfig.LFSendQueControlPrefixes = []string{"/min-mir/mgmt", "/localhop/mir-routing", "/localhop/mir-discovery"}
+	mirConfig.LogicFaceConfig.LpReliability = false
+	mirConfig.LogicFaceConfig.LpReliabilityMaxRetx = 3
+	mirConfig.LogicFaceConfig.LogicFaceTags = map[string]map[string]string{}
 
 	// Security
 	mirConfig.SecurityConfig.VerifyPacket = false
@@ -92,11 +128,48 @@ func (mirConfig *MIRConfig) Init() {
 
 	// Forwarder
 	mirConfig.ForwarderConfig.PacketQueueSize = 100
+	mirConfig.ForwarderConfig.LocalOnlyPrefixes = []string{"/localhost", "/min-mir/mgmt/localhost"}
 
 	// Strategy
 	mirConfig.StrategyConfig.RoundRobinStrategyPrefix = "/rrs"
 	mirConfig.StrategyConfig.RoundRobinStrategyRoundTime = 600
 	mirConfig.StrategyConfig.EnableRoundRobinStrategy = false
+	mirConfig.StrategyConfig.TagConstraints = map[string]string{}
+
+	// Routing
+	mirConfig.RoutingConfig.EnableRouting = false
+	mirConfig.RoutingConfig.RouterName = ""
+	mirConfig.RoutingConfig.AdvertisePrefixes = []string{}
+	mirConfig.RoutingConfig.HelloInterval = 5000
+	mirConfig.RoutingConfig.DeadInterval = 20000
+	mirConfig.RoutingConfig.LSARefreshInterval = 600000
+	mirConfig.RoutingConfig.LSALifetime = 1800000
+	mirConfig.RoutingConfig.LinkCost = 10
+	mirConfig.RoutingConfig.Multipath = false
+	mirConfig.RoutingConfig.MaxPaths = 3
+
+	// NeighborDiscovery
+	mirConfig.DiscoveryConfig.EnableDiscovery = false
+	mirConfig.DiscoveryConfig.EnableEtherDiscovery = true
+	mirConfig.DiscoveryConfig.EnableUdpDiscovery = true
+	mirConfig.DiscoveryConfig.UdpMulticastGroup = "224.0.23.170:56363"
+	mirConfig.DiscoveryConfig.DiscoveryInterval = 5000
+	mirConfig.DiscoveryConfig.NeighborDeadInterval = 20000
+
+	// PrefixPropagation
+	mirConfig.PropagationConfig.EnablePropagation = false
+	mirConfig.PropagationConfig.PropagationScopes = []string{}
+	mirConfig.PropagationConfig.UpstreamPrefix = "/"
+	mirConfig.PropagationConfig.PropagationCost = 0
+	mirConfig.PropagationConfig.RefreshInterval = 25000
+	mirConfig.PropagationConfig.RouteLifetime = 60000
+	mirConfig.PropagationConfig.AcceptRemoteRegistration = false
+
+	// PrefixAuthorization
+	mirConfig.AuthorizationConfig.EnableAuthorization = true
+	mirConfig.AuthorizationConfig.ExemptLocalFaces = true
+	mirConfig.AuthorizationConfig.AllowOwnNamespace = true
+	mirConfig.AuthorizationConfig.TrustPolicy = map[string][]string{}
 }
 
 // Save 保存当前配置状态到配置文件当中
@@ -122,6 +195,7 @@ type GeneralConfig struct {
 	IdentifierType          []int  `ini:"IdentifierType"`          // 当前路由器支持的标识类型，102 => GPPkt | 103 => 内容兴趣标识（Interest）| 104 => 内容兴趣标识（Interest）
 	DefaultRouteConfigPath  string `ini:"DefaultRouteConfigPath"`  // 静态路由配置文件路径
 	DefaultRouteRetryCount  int    `ini:"DefaultRouteRetryCount"`  // 静态路由创建重试次数
+	RouteStatePath          string `ini:"RouteStatePath"`          // 路由状态文件路径，保存持久化的 LogicFace 和路由，为空则不保存
 }
 
 type LogConfig struct {
@@ -140,25 +214,71 @@ type TableConfig struct {
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	CSSize               int    `ini:"CSSize"`               // CS缓存大小，包为单位
 	CSReplaceStrategy    string `ini:"CSReplaceStrategy"`    // 缓存替换策略
-	CacheUnsolicitedData bool   `ini:"CacheUnsolicitedData"` // 是否缓存未请求的数据（Unsolicited Data）
+	CacheUnsolicitedData bool   `ini:"CacheUnsolicitedData"` // 是否缓存未请求的数据（Unsolicited Data），UnsolicitedDataPolicy 为空时 true 相当于 admit-all，false 相当于 drop-all
+
+	UnsolicitedDataPolicy   string            `ini:"UnsolicitedDataPolicy"` // 未请求数据的默认接纳策略 drop-all | admit-local | admit-network | admit-all
+	UnsolicitedDataPolicies map[string]string `ini:"-"`                     // 解析得到的前缀 => 未请求数据的接纳策略，位于 [UnsolicitedDataPolicy] 中
+
+	CSPartitions       []string            `ini:"CSPartitions"` // CS 分区名列表，每个分区的具体配置位于 [CSPartition.<分区名>] 中
+	CSPartitionConfigs []CSPartitionConfig `ini:"-"`            // 解析得到的 CS 分区配置
+
+	CSMaxAge         int            `ini:"CSMaxAge"`         // 缓存变旧之后最多还能保留多久（单位为秒），超过之后会被后台清理协程删除，0 表示不清理
+	CSSweepInterval  int            `ini:"CSSweepInterval"`  // CS 后台清理的时间间隔（单位为毫秒），0 表示不开启后台清理
+	CSSweepBatchSize int            `ini:"CSSweepBatchSize"` // CS 后台清理每一轮最多检查的表项数
+	CSMaxAgeConfigs  map[string]int `ini:"-"`                // 解析得到的前缀 => 最大保留时间（单位为秒），位于 [CSMaxAge] 中
+
+	RoutePriorities map[uint64]uint64 `ini:"-"` // 解析得到的路由来源 => 下一跳的管理优先级，位于 [RoutePriority] 中
+}
+
+// CSPartitionConfig 表示一个 CS 分区的配置，与 mirconf.ini 中的 [CSPartition.<分区名>] 一一对应
+//
+// @Description:
+//
+type CSPartitionConfig struct {
+	Name              string   // 分区名
+	Prefixes          []string // 分区绑定的前缀列表，名字匹配这些前缀的数据包缓存到本分区
+	CSSize            int      // 分区缓存大小，包为单位
+	CSReplaceStrategy string   // 分区缓存替换策略
 }
 
 type LogicFaceConfig struct {
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	//// LogicFace
 	////////////////////////////////////////////////////////////////////////////////////////////////
-	SupportTCP                 bool   `ini:"SupportTCP"`                 // 是否开启TCP
-	TCPPort                    int    `ini:"TCPPort"`                    // TCP 端口号
-	SupportUDP                 bool   `ini:"SupportUDP"`                 // 是否开启UDP
-	UDPPort                    int    `ini:"UDPPort"`                    // UDP 端口号
-	SupportUnix                bool   `ini:"SupportUnix"`                // 是否开启Unix
-	UnixPath                   string `ini:"UnixPath"`                   // Unix 套接字路径设置
-	LogicFaceIdleTime          int    `ini:"LogicFaceIdleTime"`          // LogicFace最大闲置时间
-	CleanLogicFaceTableTimeVal int    `ini:"CleanLogicFaceTableTimeVal"` // LogicFaceSystem 清理逻辑接口的时间周期
-	EtherRoutineNumber         int    `ini:"EtherRoutineNumber"`         // 以一个网卡对应的收包协程数
-	UDPReceiveRoutineNumber    int    `ini:"UDPReceiveRoutineNumber"`    //UDP收包协程数
-	LFRecvQueSize              int    `ini:"LFRecvQueSize"`              //	接收队列大小
-	LFSendQueSize              int    `ini:"LFSendQueSize"`              // 发送队列大小
+	SupportTCP                 bool     `ini:"SupportTCP"`                 // 是否开启TCP
+	TCPPort                    int      `ini:"TCPPort"`                    // TCP 端口号
+	TCPListenAddr              string   `ini:"TCPListenAddr"`              // TCP 监听的本地地址，为空时同时监听 IPv4 和 IPv6
+	SupportUDP                 bool     `ini:"SupportUDP"`                 // 是否开启UDP
+	UDPPort                    int      `ini:"UDPPort"`                    // UDP 端口号
+	UDPListenAddr              string   `ini:"UDPListenAddr"`              // UDP 监听的本地地址，为空时同时监听 IPv4 和 IPv6
+	SupportUnix                bool     `ini:"SupportUnix"`                // 是否开启Unix
+	UnixPath                   string   `ini:"UnixPath"`                   // Unix 套接字路径设置
+	SupportTLS                 bool     `ini:"SupportTLS"`                 // 是否开启TLS
+	TLSPort                    int      `ini:"TLSPort"`                    // TLS 端口号
+	TLSListenAddr              string   `ini:"TLSListenAddr"`              // TLS 监听的本地地址，为空时同时监听 IPv4 和 IPv6
+	TLSCertFile                string   `ini:"TLSCertFile"`                // 本机的 X.509 证书文件（PEM）
+	TLSKeyFile                 string   `ini:"TLSKeyFile"`                 // 本机证书对应的私钥文件（PEM）
+	TLSCAFile                  string   `ini:"TLSCAFile"`                  // 用于验证对端证书的 CA 证书文件（PEM）
+	TLSClientAuth              string   `ini:"TLSClientAuth"`              // 对端认证方式 none | cert | identity
+	SupportWebSocket           bool     `ini:"SupportWebSocket"`           // 是否开启WebSocket
+	WebSocketPort              int      `ini:"WebSocketPort"`              // WebSocket 端口号
+	WebSocketListenAddr        string   `ini:"WebSocketListenAddr"`        // WebSocket 监听的本地地址，为空时同时监听 IPv4 和 IPv6
+	WebSocketPath              string   `ini:"WebSocketPath"`              // WebSocket 路径
+	UdpMulticast               []string `ini:"UdpMulticast"`               // UDP 组播 LogicFace 列表，每一项的格式为 <网卡名>@<组播地址>:<端口>
+	LogicFaceIdleTime          int      `ini:"LogicFaceIdleTime"`          // LogicFace最大闲置时间
+	CleanLogicFaceTableTimeVal int      `ini:"CleanLogicFaceTableTimeVal"` // LogicFaceSystem 清理逻辑接口的时间周期
+	EtherRoutineNumber         int      `ini:"EtherRoutineNumber"`         // 以一个网卡对应的收包协程数
+	UDPReceiveRoutineNumber    int      `ini:"UDPReceiveRoutineNumber"`    //UDP收包协程数
+	LFRecvQueSize              int      `ini:"LFRecvQueSize"`              //	接收队列大小
+	LFSendQueSize              int      `ini:"LFSendQueSize"`              // 发送队列大小
+	LFSendQueDropPolicy        string   `ini:"LFSendQueDropPolicy"`        // 发送队列满了之后的丢包策略 tail-drop | drop-oldest-interest
+	LFSendQueDataWeight        int      `ini:"LFSendQueDataWeight"`        // 发送队列中数据包和 Nack 类别的权重
+	LFSendQueInterestWeight    int      `ini:"LFSendQueInterestWeight"`    // 发送队列中兴趣包和推式包类别的权重
+	LFSendQueControlPrefixes   []string `ini:"LFSendQueControlPrefixes"`   // 发送队列中严格优先发送的控制前缀
+	LpReliability              bool     `ini:"LpReliability"`              // 是否在点对点的 UDP 和以太网 LogicFace 上开启链路层可靠传输
+	LpReliabilityMaxRetx       int      `ini:"LpReliabilityMaxRetx"`       // 链路层可靠传输的最大重传次数
+
+	LogicFaceTags map[string]map[string]string `ini:"-"` // 解析得到的地址前缀 => LogicFace 标签，位于 [LogicFaceTag] 中
 }
 
 type SecurityConfig struct {
@@ -177,7 +297,8 @@ type ForwarderConfig struct {
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	//// Forwarder
 	////////////////////////////////////////////////////////////////////////////////////////////////
-	PacketQueueSize int `ini:"PacketQueueSize"` // 包缓冲队列大小
+	PacketQueueSize   int      `ini:"PacketQueueSize"`   // 包缓冲队列大小
+	LocalOnlyPrefixes []string `ini:"LocalOnlyPrefixes"` // 只能通过 local 作用域的 LogicFace（Unix、内部）收发的前缀
 }
 
 type StrategyConfig struct {
@@ -187,6 +308,8 @@ type StrategyConfig struct {
 	RoundRobinStrategyPrefix    string `ini:"RoundRobinStrategyPrefix"`    // 轮询策略生效的前缀（例如：/rrs开头的包全部都会走轮询策略）=> 默认rrs
 	RoundRobinStrategyRoundTime int    `ini:"RoundRobinStrategyRoundTime"` //轮询策略轮换的时间（单位为秒）=> 默认10分钟
 	EnableRoundRobinStrategy    bool   `ini:"EnableRoundRobinStrategy"`    //是否开启轮询策略
+
+	TagConstraints map[string]string `ini:"-"` // 解析得到的前缀 => 下一跳 LogicFace 需要满足的标签表达式，位于 [TagConstraint] 中
 }
 
 type ManagementConfig struct {
@@ -206,6 +329,57 @@ type PcapConfig struct {
 	PcapBufferSize   int   `ini:"PcapBufferSize"`   // libpcap 抓包时的缓冲区大小 4 * 1024 * 1024 => 4194304
 }
 
+type RoutingConfig struct {
+	////////////////////////////////////////////////////////////////////////////////////////////////
+	//// Routing
+	////////////////////////////////////////////////////////////////////////////////////////////////
+	EnableRouting      bool     `ini:"EnableRouting"`      // 是否开启链路状态路由
+	RouterName         string   `ini:"RouterName"`         // 路由器名，为空则使用 DefaultId，必须在 DefaultId 之下
+	AdvertisePrefixes  []string `ini:"AdvertisePrefixes"`  // 本路由器通告的前缀列表
+	HelloInterval      int      `ini:"HelloInterval"`      // 发送 Hello 的时间间隔（单位为毫秒）
+	DeadInterval       int      `ini:"DeadInterval"`       // 超过这么长时间没有收到邻居的 Hello 则认为邻居失效（单位为毫秒）
+	LSARefreshInterval int      `ini:"LSARefreshInterval"` // 重新生成本路由器 LSA 的时间间隔（单位为毫秒）
+	LSALifetime        int      `ini:"LSALifetime"`        // LSA 的生存时间（单位为毫秒），超过之后没有刷新则从 LSDB 中删除
+	LinkCost           int      `ini:"LinkCost"`           // 每条链路的默认开销
+	Multipath          bool     `ini:"Multipath"`          // 是否为每个前缀计算多条路径
+	MaxPaths           int      `ini:"MaxPaths"`           // 开启多路径时每个前缀最多安装的下一跳个数
+}
+
+type DiscoveryConfig struct {
+	////////////////////////////////////////////////////////////////////////////////////////////////
+	//// NeighborDiscovery
+	////////////////////////////////////////////////////////////////////////////////////////////////
+	EnableDiscovery      bool   `ini:"EnableDiscovery"`      // 是否开启邻居发现
+	EnableEtherDiscovery bool   `ini:"EnableEtherDiscovery"` // 是否在以太网组播 LogicFace 上发送 Hello
+	EnableUdpDiscovery   bool   `ini:"EnableUdpDiscovery"`   // 是否在 UDP 组播组中发送 Hello
+	UdpMulticastGroup    string `ini:"UdpMulticastGroup"`    // 邻居发现使用的 UDP 组播地址，格式为 "<ip>:<port>"
+	DiscoveryInterval    int    `ini:"DiscoveryInterval"`    // 发送 Hello 的时间间隔（单位为毫秒）
+	NeighborDeadInterval int    `ini:"NeighborDeadInterval"` // 超过这么长时间没有收到邻居的 Hello 则认为邻居失效，并关闭到邻居的 LogicFace（单位为毫秒）
+}
+
+type PropagationConfig struct {
+	////////////////////////////////////////////////////////////////////////////////////////////////
+	//// PrefixPropagation
+	////////////////////////////////////////////////////////////////////////////////////////////////
+	EnablePropagation        bool     `ini:"EnablePropagation"`        // 是否把本地应用注册的前缀传播到上游路由器
+	PropagationScopes        []string `ini:"PropagationScopes"`        // 传播范围，本地应用注册的前缀被某个范围覆盖时，把这个范围注册到上游路由器
+	UpstreamPrefix           string   `ini:"UpstreamPrefix"`           // 这个前缀的 FIB 表项的下一跳作为上游路由器，默认是默认路由 "/"
+	PropagationCost          int      `ini:"PropagationCost"`          // 在上游路由器注册的路由开销
+	RefreshInterval          int      `ini:"RefreshInterval"`          // 刷新上游路由器上的注册的时间间隔（单位为毫秒）
+	RouteLifetime            int      `ini:"RouteLifetime"`            // 在上游路由器注册的路由的有效期（单位为毫秒），需要大于 RefreshInterval
+	AcceptRemoteRegistration bool     `ini:"AcceptRemoteRegistration"` // 是否接受下游路由器通过 /min-mir/mgmt/localhop 传播过来的前缀
+}
+
+type AuthorizationConfig struct {
+	////////////////////////////////////////////////////////////////////////////////////////////////
+	//// PrefixAuthorization
+	////////////////////////////////////////////////////////////////////////////////////////////////
+	EnableAuthorization bool                `ini:"EnableAuthorization"` // 注册前缀的命令是否需要由证书覆盖该前缀的身份签名
+	ExemptLocalFaces    bool                `ini:"ExemptLocalFaces"`    // 从本地 Unix LogicFace 收到的注册命令是否免于验证
+	AllowOwnNamespace   bool                `ini:"AllowOwnNamespace"`   // 是否允许身份注册以自己的身份名为前缀的命名空间
+	TrustPolicy         map[string][]string `ini:"-"`                   // 解析得到的签名身份前缀 => 允许注册的命名空间，位于 [PrefixAuthorization.TrustPolicy] 中
+}
+
 // ParseConfig
 // 解析配置文件
 //
@@ -227,5 +401,230 @@ func ParseConfig(configPath string) (*MIRConfig, error) {
 	if err = cfg.MapTo(&mirConfig); err != nil {
 		return nil, err
 	}
+	// 加载 CS 分区配置
+	if err = mirConfig.TableConfig.loadCSPartitions(cfg); err != nil {
+		return nil, err
+	}
+	// 加载每个前缀的 CS 最大保留时间
+	if err = mirConfig.TableConfig.loadCSMaxAges(cfg); err != nil {
+		return nil, err
+	}
+	// 加载每个路由来源的管理优先级
+	if err = mirConfig.TableConfig.loadRoutePriorities(cfg); err != nil {
+		return nil, err
+	}
+	// 加载前缀注册的信任策略
+	if err = mirConfig.AuthorizationConfig.loadTrustPolicy(cfg); err != nil {
+		return nil, err
+	}
+	// 加载每个前缀的未请求数据接纳策略
+	if err = mirConfig.TableConfig.loadUnsolicitedDataPolicies(cfg); err != nil {
+		return nil, err
+	}
+	// 加载 LogicFace 标签
+	if err = mirConfig.LogicFaceConfig.loadLogicFaceTags(cfg); err != nil {
+		return nil, err
+	}
+	// 加载每个前缀的下一跳标签约束
+	if err = mirConfig.StrategyConfig.loadTagConstraints(cfg); err != nil {
+		return nil, err
+	}
 	return mirConfig, nil
 }
+
+// loadCSPartitions 根据 CSPartitions 中列出的分区名，从对应的 [CSPartition.<分区名>] 中加载分区配置
+//
+// @Description:
+//  未配置 CSSize 和 CSReplaceStrategy 的分区，分别使用 [Table] 中的 CSSize 和 CSReplaceStrategy 作为默认值
+// @receiver t
+// @param cfg
+// @return error
+//
+func (t *TableConfig) loadCSPartitions(cfg *ini.File) error {
+	t.CSPartitionConfigs = make([]CSPartitionConfig, 0, len(t.CSPartitions))
+	for _, name := range t.CSPartitions {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		section, err := cfg.GetSection("CSPartition." + name)
+		if err != nil {
+			return fmt.Errorf("CS partition %s is declared but section [CSPartition.%s] is missing", name, name)
+		}
+		partitionConfig := CSPartitionConfig{
+			Name:              name,
+			Prefixes:          section.Key("Prefixes").Strings(","),
+			CSSize:            section.Key("CSSize").MustInt(t.CSSize),
+			CSReplaceStrategy: section.Key("CSReplaceStrategy").MustString(t.CSReplaceStrategy),
+		}
+		if len(partitionConfig.Prefixes) == 0 {
+			return fmt.Errorf("CS partition %s must bind at least one prefix", name)
+		}
+		t.CSPartitionConfigs = append(t.CSPartitionConfigs, partitionConfig)
+	}
+	return nil
+}
+
+// loadCSMaxAges 从 [CSMaxAge] 中加载每个前缀的 CS 最大保留时间
+//
+// @Description:
+//  [CSMaxAge] 中每一项的 key 为前缀，value 为最大保留时间（单位为秒），没有配置的前缀使用 [Table] 中的 CSMaxAge
+// @receiver t
+// @param cfg
+// @return error
+//
+func (t *TableConfig) loadCSMaxAges(cfg *ini.File) error {
+	t.CSMaxAgeConfigs = make(map[string]int)
+	section, err := cfg.GetSection("CSMaxAge")
+	if err != nil {
+		// 没有配置 [CSMaxAge]
+		return nil
+	}
+	for _, key := range section.Keys() {
+		maxAge, err := key.Int()
+		if err != nil || maxAge < 0 {
+			return fmt.Errorf("invalid CS max age for prefix %s: %s", key.Name(), key.Value())
+		}
+		t.CSMaxAgeConfigs[key.Name()] = maxAge
+	}
+	return nil
+}
+
+// loadRoutePriorities 从 [RoutePriority] 中加载每个路由来源的管理优先级
+//
+// @Description:
+//  [RoutePriority] 中每一项的 key 为路由来源（数字），value 为该来源的路由对应的下一跳的管理优先级，数值越小越优先，没有配置的来源为 0
+// @receiver t
+// @param cfg
+// @return error
+//
+func (t *TableConfig) loadRoutePriorities(cfg *ini.File) error {
+	t.RoutePriorities = make(map[uint64]uint64)
+	section, err := cfg.GetSection("RoutePriority")
+	if err != nil {
+		// 没有配置 [RoutePriority]
+		return nil
+	}
+	for _, key := range section.Keys() {
+		origin, err := strconv.ParseUint(key.Name(), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid route origin in [RoutePriority]: %s", key.Name())
+		}
+		priority, err := key.Uint64()
+		if err != nil {
+			return fmt.Errorf("invalid route priority for origin %s: %s", key.Name(), key.Value())
+		}
+		t.RoutePriorities[origin] = priority
+	}
+	return nil
+}
+
+// loadUnsolicitedDataPolicies 从 [UnsolicitedDataPolicy] 中加载每个前缀的未请求数据接纳策略
+//
+// @Description:
+//  [UnsolicitedDataPolicy] 中每一项的 key 为前缀，value 为策略名，策略名由 table.ParseUnsolicitedDataPolicy 检查
+// @receiver t
+// @param cfg
+// @return error
+//
+func (t *TableConfig) loadUnsolicitedDataPolicies(cfg *ini.File) error {
+	t.UnsolicitedDataPolicies = make(map[string]string)
+	section, err := cfg.GetSection("UnsolicitedDataPolicy")
+	if err != nil {
+		// 没有配置 [UnsolicitedDataPolicy]
+		return nil
+	}
+	for _, key := range section.Keys() {
+		if strings.TrimSpace(key.Value()) == "" {
+			return fmt.Errorf("unsolicited data policy for prefix %s is empty", key.Name())
+		}
+		t.UnsolicitedDataPolicies[key.Name()] = key.Value()
+	}
+	return nil
+}
+
+// loadTrustPolicy 从 [PrefixAuthorization.TrustPolicy] 中加载前缀注册的信任策略
+//
+// @Description:
+//  [PrefixAuthorization.TrustPolicy] 中每一项的 key 为签名身份的前缀，value 为这些身份允许注册的命名空间，多个命名空间用逗号分隔
+// @receiver a
+// @param cfg
+// @return error
+//
+func (a *AuthorizationConfig) loadTrustPolicy(cfg *ini.File) error {
+	a.TrustPolicy = make(map[string][]string)
+	section, err := cfg.GetSection("PrefixAuthorization.TrustPolicy")
+	if err != nil {
+		// 没有配置 [PrefixAuthorization.TrustPolicy]
+		return nil
+	}
+	for _, key := range section.Keys() {
+		namespaces := key.Strings(",")
+		if len(namespaces) == 0 {
+			return fmt.Errorf("trust policy for identity %s must grant at least one namespace", key.Name())
+		}
+		a.TrustPolicy[key.Name()] = namespaces
+	}
+	return nil
+}
+
+// loadLogicFaceTags 从 [LogicFaceTag] 中加载 LogicFace 标签
+//
+// @Description:
+//  [LogicFaceTag] 中每一项的 key 为地址前缀，value 为 "key=value,key2=value2" 格式的标签，
+//  本地地址或者对端地址以该前缀开头的 LogicFace 在创建时会被打上这些标签，例如 tcp://203.0.113. = role=wan
+// @receiver l
+// @param cfg
+// @return error
+//
+func (l *LogicFaceConfig) loadLogicFaceTags(cfg *ini.File) error {
+	l.LogicFaceTags = make(map[string]map[string]string)
+	section, err := cfg.GetSection("LogicFaceTag")
+	if err != nil {
+		// 没有配置 [LogicFaceTag]
+		return nil
+	}
+	for _, key := range section.Keys() {
+		tags := make(map[string]string)
+		for _, item := range key.Strings(",") {
+			tagKey, tagValue := item, ""
+			if idx := strings.Index(item, "="); idx >= 0 {
+				tagKey, tagValue = strings.TrimSpace(item[:idx]), strings.TrimSpace(item[idx+1:])
+			}
+			if tagKey == "" {
+				return fmt.Errorf("invalid logic face tag for uri prefix %s: %s", key.Name(), key.Value())
+			}
+			tags[tagKey] = tagValue
+		}
+		if len(tags) == 0 {
+			return fmt.Errorf("logic face tag for uri prefix %s must have at least one tag", key.Name())
+		}
+		l.LogicFaceTags[key.Name()] = tags
+	}
+	return nil
+}
+
+// loadTagConstraints 从 [TagConstraint] 中加载每个前缀的下一跳标签约束
+//
+// @Description:
+//  [TagConstraint] 中每一项的 key 为前缀，value 为标签表达式，例如 /internal = role!=wan，
+//  表达式的语法由 lf.ParseTagExpression 检查
+// @receiver s
+// @param cfg
+// @return error
+//
+func (s *StrategyConfig) loadTagConstraints(cfg *ini.File) error {
+	s.TagConstraints = make(map[string]string)
+	section, err := cfg.GetSection("TagConstraint")
+	if err != nil {
+		// 没有配置 [TagConstraint]
+		return nil
+	}
+	for _, key := range section.Keys() {
+		if strings.TrimSpace(key.Value()) == "" {
+			return fmt.Errorf("tag constraint for prefix %s is empty", key.Name())
+		}
+		s.TagConstraints[key.Name()] = key.Value()
+	}
+	return nil
+}
@@ -44,6 +44,7 @@ type Link struct {
 	RemoteUri   string
 	LocalUri    string
 	Persistence int
+	Tags        string // LogicFace 的标签，eg: role=wan,site=bj
 	Routes      Routes
 }
 
@@ -49,26 +49,24 @@ func NewBestRouteStrategy() *BestRouteStrategy {
 }
 
 //
-// 找到所有可用下一跳中开销最小的下一跳
+// 找到所有可用下一跳中优先级最高、开销最小的下一跳
 //
 // @Description:
+//	下一跳按 Priority、Cost、LogicFaceId 排序，选择结果是确定的；最优的下一跳是 ECMP 成员时，
+//	根据包的标识在等价的 ECMP 成员之间分担流量，同一个标识的包总是走同一个下一跳
 // @receiver brs
 // @param ingress
 // @param fibEntry
+// @param identifier	包的标识，用于在 ECMP 成员之间分担流量
 // @return *table.NextHop
 //
-func (brs *BestRouteStrategy) findLowestCostNextHop(ingress *lf.LogicFace, fibEntry *table.FIBEntry) *table.NextHop {
-	// 找到 Cost 最小的下一跳
-	var miniHop *table.NextHop = nil
-	if fibEntry != nil {
-		for _, nextHop := range fibEntry.GetNextHops() {
-			// 找到 Cost 最小，并且排除 Interest 到来的逻辑接口
-			if (miniHop == nil || miniHop.Cost > nextHop.Cost) && nextHop.LogicFace.LogicFaceId != ingress.LogicFaceId {
-				miniHop = nextHop
-			}
-		}
+func (brs *BestRouteStrategy) findLowestCostNextHop(ingress *lf.LogicFace, fibEntry *table.FIBEntry,
+	identifier *component.Identifier) *table.NextHop {
+	if fibEntry == nil {
+		return nil
 	}
-	return miniHop
+	// 排除包到来的逻辑接口，只在满足标签约束的下一跳中选择
+	return table.SelectNextHop(brs.getNextHops(fibEntry, identifier), ingress.LogicFaceId, identifier.ToUri())
 }
 
 func (brs *BestRouteStrategy) AfterReceiveInterest(ingress *lf.LogicFace, interest *packet.Interest, pitEntry *table.PITEntry) {
@@ -87,7 +85,7 @@ func (brs *BestRouteStrategy) AfterReceiveInterest(ingress *lf.LogicFace, intere
 	fibEntry := brs.lookupFibForInterest(interest)
 
 	// 找到开销最小的下一跳
-	miniHop := brs.findLowestCostNextHop(ingress, fibEntry)
+	miniHop := brs.findLowestCostNextHop(ingress, fibEntry, interest.GetName())
 
 	if miniHop == nil {
 		// 如果没有找到下一跳路由信息，直接返回一个原因为 no-route 的 Nack
@@ -135,7 +133,7 @@ func (brs *BestRouteStrategy) AfterReceiveNack(ingress *lf.LogicFace, nack *pack
 
 func (brs *BestRouteStrategy) AfterReceiveGPPkt(ingress *lf.LogicFace, gPPkt *packet.GPPkt) {
 	fibEntry := brs.lookupFibForGPPkt(gPPkt)
-	miniHop := brs.findLowestCostNextHop(ingress, fibEntry)
+	miniHop := brs.findLowestCostNextHop(ingress, fibEntry, gPPkt.DstIdentifier())
 	if miniHop == nil {
 		// 没有路由无法转发
 		common2.LogWarn("No Route")
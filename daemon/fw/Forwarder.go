@@ -38,6 +38,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 // Forwarder MIR 转发器实例
@@ -45,15 +46,21 @@ import (
 // @Description:
 //
 type Forwarder struct {
-	table.PIT                                       // 内嵌一个PIT表
-	table.FIB                                       // 内嵌一个FIB表
-	table.ICS                                       // 内嵌一个CS表
-	table.StrategyTable                             // 内嵌一个策略选择表
-	config              *common.MIRConfig           // 记录配置文件信息
-	pluginManager       *plugin.GlobalPluginManager // 插件管理器
-	packetQueue         *utils2.BlockQueue          // 包队列
-	heapTimer           *utils2.HeapTimer           // 堆定时器，用来处理PIT的超时事件
-	interrupt           chan os.Signal              // 用来接收系统的信号，结束程序
+	table.PIT                                             // 内嵌一个PIT表
+	table.FIB                                             // 内嵌一个FIB表
+	table.ICS                                             // 内嵌一个CS表
+	table.StrategyTable                                   // 内嵌一个策略选择表
+	config              *common.MIRConfig                 // 记录配置文件信息
+	pluginManager       *plugin.GlobalPluginManager       // 插件管理器
+	packetQueue         *utils2.BlockQueue                // 包队列
+	timerWheel          *utils.TimerWheel                 // 时间轮，用来处理PIT的超时事件
+	csSweeper           *table.CSSweeper                  // CS 后台清理器
+	nameTree            *table.NameTree                   // PIT、FIB 和策略表共用的名字树
+	rib                 *table.RIB                        // 路由信息表，计算结果写入 FIB
+	tagConstraints      *table.TagConstraintTable         // 下一跳标签约束表
+	scopeControl        *ScopeControl                     // 作用域控制，只在本机有意义的前缀下的包只能通过 local 的 LogicFace 收发
+	unsolicitedPolicies *table.UnsolicitedDataPolicyTable // 未请求数据接纳策略表
+	interrupt           chan os.Signal                    // 用来接收系统的信号，结束程序
 }
 
 // Init 初始化转发器
@@ -65,20 +72,46 @@ func (f *Forwarder) Init(config *common.MIRConfig, pluginManager *plugin.GlobalP
 	f.config = config
 	f.interrupt = make(chan os.Signal, 1)
 	signal.Notify(f.interrupt, os.Interrupt, os.Kill, syscall.SIGTERM)
-	// 初始化各个表
-	f.PIT.Init()
-	f.FIB.Init()
+	// 初始化各个表，PIT、FIB 和策略表共用同一棵名字树
+	f.nameTree = table.CreateNameTree()
+	f.PIT.InitWithNameTree(f.nameTree)
+	f.FIB.InitWithNameTree(f.nameTree)
+	f.rib = table.CreateRIB(&f.FIB)
+	for origin, priority := range config.TableConfig.RoutePriorities {
+		f.rib.SetOriginPriority(origin, priority)
+	}
 	// 初始化缓存
 	if ucs, err := table.NewUniversalCS(config); err != nil {
 		return err
 	} else {
 		f.ICS = ucs
 	}
-	f.StrategyTable.Init()
+	// 加载未请求数据的接纳策略
+	if unsolicitedPolicies, err := createUnsolicitedDataPolicyTable(config); err != nil {
+		return err
+	} else {
+		f.unsolicitedPolicies = unsolicitedPolicies
+	}
+	f.csSweeper = table.NewCSSweeper(f.ICS, time.Duration(config.TableConfig.CSSweepInterval)*time.Millisecond,
+		config.TableConfig.CSSweepBatchSize)
+	f.StrategyTable.InitWithNameTree(f.nameTree)
+	// 加载下一跳标签约束，表达式有误时拒绝启动，避免包从不允许的 LogicFace 转发出去
+	f.tagConstraints = table.CreateTagConstraintTable()
+	for prefix, expression := range config.StrategyConfig.TagConstraints {
+		if err := f.tagConstraints.SetConstraint(prefix, expression); err != nil {
+			return err
+		}
+	}
+	// 加载只在本机有意义的前缀
+	if scopeControl, err := CreateScopeControl(config.ForwarderConfig.LocalOnlyPrefixes); err != nil {
+		return err
+	} else {
+		f.scopeControl = scopeControl
+	}
 	f.pluginManager = pluginManager
 	f.packetQueue = packetQueue
-	// 初始化一个堆定时器
-	f.heapTimer = utils2.NewHeapTimer()
+	// 初始化时间轮
+	f.timerWheel = utils.NewTimerWheel(1)
 
 	// BestRoute
 	identifier, err := component.CreateIdentifierByString("/")
@@ -96,7 +129,7 @@ func (f *Forwarder) Init(config *common.MIRConfig, pluginManager *plugin.GlobalP
 			return err
 		}
 		roundRobinStrategy := NewRoundRobinStrategy(int64(config.RoundRobinStrategyRoundTime))
-		bestRouteStrategy.SetForwarder(f)
+		roundRobinStrategy.SetForwarder(f)
 		f.StrategyTable.Insert(identifier, "/strategy/round-robin-route", roundRobinStrategy)
 	}
 	return nil
@@ -109,6 +142,12 @@ func (f *Forwarder) Init(config *common.MIRConfig, pluginManager *plugin.GlobalP
 func (f *Forwarder) Start() (string, error) {
 	resMsg := ""
 	resErr := errors.New("")
+	// 启动 CS 后台清理
+	f.csSweeper.Start()
+	defer f.csSweeper.Stop()
+	// 启动 RIB 过期路由清理
+	f.rib.Start()
+	defer f.rib.Stop()
 	utils.ProtectRun(func() {
 		for true {
 			select {
@@ -124,16 +163,17 @@ func (f *Forwarder) Start() (string, error) {
 				break
 			default:
 				// 在处理包之前
-				f.heapTimer.DealEvent()
+				f.timerWheel.DealEvent()
 				// 此处读取包时，不采用阻塞操作，因为要保证超时事件能得到正确的处理
 				if data, err := f.packetQueue.ReadUntil(1); err != nil {
 					// 读取超时了
 				} else {
-					ipd, ok := data.(*lf.IncomingPacketData)
-					if !ok {
-						continue
+					switch item := data.(type) {
+					case *lf.IncomingPacketData:
+						f.OnReceiveMINPacket(item)
+					case *lf.LinkLossReport:
+						f.OnLinkLoss(item)
 					}
-					f.OnReceiveMINPacket(ipd)
 				}
 			}
 		}
@@ -178,6 +218,7 @@ func (f *Forwarder) OnReceiveMINPacket(ipd *lf.IncomingPacketData) {
 				"faceId":     ingress.LogicFaceId,
 				"identifier": identifyWrapper.ToUri(),
 			}, "Create GPPkt by MINPacket failed")
+			ingress.CountDroppedGPPkt()
 			return
 		} else {
 			f.OnIncomingGPPkt(ingress, gPPkt)
@@ -188,11 +229,13 @@ func (f *Forwarder) OnReceiveMINPacket(ipd *lf.IncomingPacketData) {
 				"faceId":     ingress.LogicFaceId,
 				"identifier": identifyWrapper.ToUri(),
 			}, "Create Interest by MINPacket failed")
+			ingress.CountDroppedInterest()
 			return
 		} else {
 			if interest.NackHeader.IsInitial() {
 				nack := packet.NewNackByInterest(interest)
 				// Nack
+				ingress.CountIncomingNack()
 				f.OnIncomingNack(ingress, nack)
 			} else {
 				// Interest
@@ -205,6 +248,7 @@ func (f *Forwarder) OnReceiveMINPacket(ipd *lf.IncomingPacketData) {
 				"faceId":     ingress.LogicFaceId,
 				"identifier": identifyWrapper.ToUri(),
 			}, "Create data by MINPacket failed")
+			ingress.CountDroppedData()
 			return
 		} else {
 			f.OnIncomingData(ingress, data)
@@ -212,6 +256,49 @@ func (f *Forwarder) OnReceiveMINPacket(ipd *lf.IncomingPacketData) {
 	}
 }
 
+// OnLinkLoss 处理链路层可靠传输上报的丢包
+//
+// @Description:
+//  1. 链路层可靠传输在一个包超过最大重传次数仍没有被对端确认时上报丢包；
+//  2. 如果丢失的是兴趣包，并且对应的 PIT 条目中仍然有该 LogicFace 的 out-record 且 Nonce 一致，则删除这个 out-record，
+//     这样下游重传的兴趣包不会因为被认为已经转发过而被抑制，可以重新从该 LogicFace 转发出去；
+//  3. 其它类型的包只记录日志。
+// @receiver f
+// @param report
+//
+func (f *Forwarder) OnLinkLoss(report *lf.LinkLossReport) {
+	egress := report.LogicFace
+	identifyWrapper, err := report.MinPacket.GetIdentifier(0)
+	if err != nil {
+		return
+	}
+	common2.LogDebugWithFields(logrus.Fields{
+		"faceId":     egress.LogicFaceId,
+		"identifier": identifyWrapper.ToUri(),
+	}, "Link loss")
+	if identifyWrapper.GetIdentifierType() != encoding.TlvIdentifierContentInterest {
+		return
+	}
+	interest, err := packet.NewInterestByMINPacket(report.MinPacket)
+	if err != nil || interest.NackHeader.IsInitial() {
+		return
+	}
+	pitEntry, err := f.PIT.Find(interest)
+	if err != nil || pitEntry == nil {
+		return
+	}
+	outRecord, err := pitEntry.GetOutRecord(egress)
+	if err != nil || outRecord == nil || outRecord.LastNonce.GetNonce() != interest.GetNonce() {
+		return
+	}
+	if err := pitEntry.DeleteOutRecord(egress); err != nil {
+		common2.LogWarnWithFields(logrus.Fields{
+			"faceId":   egress.LogicFaceId,
+			"interest": interest.ToUri(),
+		}, "Delete out-record of lost interest failed")
+	}
+}
+
 // OnIncomingInterest 处理一个兴趣包到来 （ Incoming Interest Pipeline）
 //
 // @Description:
@@ -252,6 +339,17 @@ func (f *Forwarder) OnIncomingInterest(ingress *lf.LogicFace, interest *packet.I
 		return
 	}
 
+	// 只在本机有意义的前缀下的兴趣包不能从 non-local 的 LogicFace 流入
+	if !f.scopeControl.CanTransfer(ingress, interest.GetName()) {
+		common2.LogWarnWithFields(logrus.Fields{
+			"faceId":   ingress.LogicFaceId,
+			"interest": interest.ToUri(),
+		}, "Drop local-only interest from non-local LogicFace")
+		ingress.CountDroppedInterest()
+		ingress.CountScopeViolation()
+		return
+	}
+
 	// TTL 减一，并且检查 TTL 是否小于0，小于0则判定为循环兴趣包
 	if interest.TTL.GetTTL() == 0 {
 		f.OnInterestLoop(ingress, interest)
@@ -433,6 +531,16 @@ func (f *Forwarder) OnOutgoingInterest(egress *lf.LogicFace, pitEntry *table.PIT
 		return
 	}
 
+	// 只在本机有意义的前缀下的兴趣包不能从 non-local 的 LogicFace 发出
+	if !f.scopeControl.CanTransfer(egress, interest.GetName()) {
+		common2.LogWarnWithFields(logrus.Fields{
+			"faceId":   egress.LogicFaceId,
+			"interest": interest.ToUri(),
+		}, "Drop local-only interest to non-local LogicFace")
+		egress.CountOutScopeViolation(interest)
+		return
+	}
+
 	// 插入 out-record
 	outRecord := pitEntry.InsertOrUpdateOutRecord(egress, interest)
 	outRecord.ExpireTime = common.GetCurrentTime() + interest.InterestLifeTime.GetInterestLifeTime()
@@ -502,6 +610,7 @@ func (f *Forwarder) OnIncomingData(ingress *lf.LogicFace, data *packet.Data) {
 	if data.TTL.GetTTL() == 0 {
 		//f.OnInterestLoop(ingress, interest)
 		common2.LogDebug(fmt.Sprintf("%s TTL = 0 DROP", data.GetName().ToUri()))
+		ingress.CountDroppedData()
 		return
 	}
 	data.TTL.Minus()
@@ -549,9 +658,11 @@ func (f *Forwarder) OnIncomingData(ingress *lf.LogicFace, data *packet.Data) {
 //
 // @Description:
 //  在 Incoming data 管道处理过程中发现 data 是未经请求的时后会触发 data unsolicited 管道处理逻辑，它的处理过程如下：
-//   1. 根据当前配置的针对未经请求的 data 的处理策略，决定是删除 data 还是将其添加到 ContentStore 。默认情况下，MIR配置了 drop-all 策略，
+//   1. 根据 data 的名字最长前缀匹配到的接纳策略，决定是删除 data 还是将其添加到 ContentStore 。默认情况下，MIR配置了 drop-all 策略，
 //      该策略会丢弃所有未经请求的 data ，因为它们会对转发器造成安全风险。
-//   2. 在某些特殊应用场景下，如果希望MIR将未经请求的 data 存储到 ContentStore，可以在配置文件中修改对应的策略。
+//   2. 在某些特殊应用场景下，如果希望MIR将未经请求的 data 存储到 ContentStore，可以在配置文件中或者通过管理模块给前缀配置
+//      admit-local、admit-network 或者 admit-all 策略。
+//   3. 被丢弃的 data 计入入口 LogicFace 的 DropUnsolicitedDataN。
 // @param ingress
 // @param data
 //
@@ -565,10 +676,50 @@ func (f *Forwarder) OnDataUnsolicited(ingress *lf.LogicFace, data *packet.Data)
 	if f.pluginManager.OnDataUnsolicited(ingress, data) != 0 {
 		return
 	}
-	// 读取配置文件，判断是否缓存未经请求的 data
-	if f.config.TableConfig.CacheUnsolicitedData {
-		f.ICS.Insert(data)
+	// 根据接纳策略判断是否缓存未经请求的 data
+	policy := f.unsolicitedPolicies.FindPolicy(data.GetName())
+	if !policy.Admit(ingress) {
+		common2.LogDebugWithFields(logrus.Fields{
+			"faceId": ingress.LogicFaceId,
+			"data":   data.ToUri(),
+			"policy": policy.String(),
+		}, "Drop unsolicited data")
+		ingress.CountDroppedUnsolicitedData()
+		return
 	}
+	f.ICS.Insert(data)
+}
+
+//
+// @Description: 根据配置创建未请求数据接纳策略表，没有配置默认策略时根据 CacheUnsolicitedData 选择 admit-all 或者 drop-all
+// @param config
+// @return *table.UnsolicitedDataPolicyTable
+// @return error
+//
+func createUnsolicitedDataPolicyTable(config *common.MIRConfig) (*table.UnsolicitedDataPolicyTable, error) {
+	defaultPolicy := table.UnsolicitedDataPolicyDropAll
+	if config.TableConfig.UnsolicitedDataPolicy != "" {
+		policy, err := table.ParseUnsolicitedDataPolicy(config.TableConfig.UnsolicitedDataPolicy)
+		if err != nil {
+			return nil, err
+		}
+		defaultPolicy = policy
+	} else if config.TableConfig.CacheUnsolicitedData {
+		defaultPolicy = table.UnsolicitedDataPolicyAdmitAll
+	}
+	unsolicitedPolicies := table.CreateUnsolicitedDataPolicyTable(defaultPolicy)
+	for prefix, name := range config.TableConfig.UnsolicitedDataPolicies {
+		identifier, err := component.CreateIdentifierByString(prefix)
+		if err != nil {
+			return nil, err
+		}
+		policy, err := table.ParseUnsolicitedDataPolicy(name)
+		if err != nil {
+			return nil, err
+		}
+		unsolicitedPolicies.SetPolicy(identifier, policy)
+	}
+	return unsolicitedPolicies, nil
 }
 
 // OnOutgoingData 处理将一个数据包发出 （ Outgoing data Pipeline ）
@@ -591,6 +742,16 @@ func (f *Forwarder) OnOutgoingData(egress *lf.LogicFace, data *packet.Data) {
 		return
 	}
 
+	// 只在本机有意义的前缀下的数据包不能从 non-local 的 LogicFace 发出
+	if !f.scopeControl.CanTransfer(egress, data.GetName()) {
+		common2.LogWarnWithFields(logrus.Fields{
+			"faceId": egress.LogicFaceId,
+			"data":   data.ToUri(),
+		}, "Drop local-only data to non-local LogicFace")
+		egress.CountOutScopeViolation(data)
+		return
+	}
+
 	egress.SendData(data)
 }
 
@@ -626,6 +787,7 @@ func (f *Forwarder) OnIncomingNack(ingress *lf.LogicFace, nack *packet.Nack) {
 			"nack":   nack.Interest.ToUri(),
 			"reason": nack.GetNackReason(),
 		}, "Have not found match PITEntry for nack")
+		ingress.CountDroppedNack()
 		return
 	}
 
@@ -636,6 +798,7 @@ func (f *Forwarder) OnIncomingNack(ingress *lf.LogicFace, nack *packet.Nack) {
 			"nack":   nack.Interest.ToUri(),
 			"reason": nack.GetNackReason(),
 		}, "Have not found match out-record for nack")
+		ingress.CountDroppedNack()
 		return
 	}
 
@@ -646,6 +809,7 @@ func (f *Forwarder) OnIncomingNack(ingress *lf.LogicFace, nack *packet.Nack) {
 			"nack":   nack.Interest.ToUri(),
 			"reason": nack.GetNackReason(),
 		}, "Founded matched out-record, but Nonce is diff")
+		ingress.CountDroppedNack()
 		return
 	}
 	outRecord.NackHeader = &nack.Interest.NackHeader
@@ -739,6 +903,7 @@ func (f *Forwarder) OnIncomingGPPkt(ingress *lf.LogicFace, gPPkt *packet.GPPkt)
 			"faceId": ingress.LogicFaceId,
 			"gPPkt":  gPPkt.ToUri(),
 		}, "GPPkt TTL < 0")
+		ingress.CountDroppedGPPkt()
 		return
 	}
 	gPPkt.TTL.Minus()
@@ -752,6 +917,7 @@ func (f *Forwarder) OnIncomingGPPkt(ingress *lf.LogicFace, gPPkt *packet.GPPkt)
 			"faceId": ingress.LogicFaceId,
 			"gPPkt":  gPPkt.ToUri(),
 		}, "Not found matched StrategyBase for GPPkt")
+		ingress.CountDroppedGPPkt()
 	}
 }
 
@@ -785,23 +951,41 @@ func (f *Forwarder) OnOutgoingGPPkt(egress *lf.LogicFace, gPPkt *packet.GPPkt) {
 // @param duration			单位 ms
 //
 func (f *Forwarder) SetExpiryTime(pitEntry *table.PITEntry, duration int64) {
-	// TODO: 这边要check一下，是不是调用 SetExpiryTime 的时候之前的定时任务还没有触发，如果已经触发过了，是不是会有问题
+	// 首先取消之前的定时任务，如果之前的定时任务已经触发过了，取消操作不会产生任何影响
+	f.timerWheel.CancelEvent(pitEntry.GetExpiryTimer())
 
-	key := pitEntry.Identifier.ToUri()
-
-	// 首先取消之前的定时任务
-	f.heapTimer.CancelEvent(key)
+	// 表项已经被 OnInterestFinalize 从 PIT 中移除，不需要再设置新的定时任务
+	if pitEntry.IsDeleted() {
+		pitEntry.SetExpiryTimer(nil)
+		return
+	}
 
-	// 接着设置新的定时任务
-	f.heapTimer.AddTimeoutEvent(duration, key, func() {
+	// 接着设置新的定时任务，句柄保存在表项中，不会影响同名的其它表项
+	pitEntry.SetExpiryTimer(f.timerWheel.AddTimeoutEvent(duration, func() {
 		f.OnInterestFinalize(pitEntry)
-	})
+	}))
 
 	if duration == 0 {
-		f.heapTimer.DealEvent()
+		f.timerWheel.DealEvent()
 	}
 }
 
 func (f *Forwarder) GetFIB() *table.FIB {
 	return &f.FIB
 }
+
+func (f *Forwarder) GetRIB() *table.RIB {
+	return f.rib
+}
+
+func (f *Forwarder) GetUnsolicitedDataPolicyTable() *table.UnsolicitedDataPolicyTable {
+	return f.unsolicitedPolicies
+}
+
+func (f *Forwarder) GetCS() table.ICS {
+	return f.ICS
+}
+
+func (f *Forwarder) GetPIT() *table.PIT {
+	return &f.PIT
+}
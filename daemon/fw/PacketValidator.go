@@ -104,6 +104,18 @@ func (p *PacketValidator) ReceiveMINPacket(data *lf.IncomingPacketData) {
 	}
 }
 
+// ReportLinkLoss
+// 收到一个链路层丢包上报
+//
+// @Description:
+//	丢包上报不需要验证签名，直接放入 p.packetQueue，由 Forwarder 在主循环中处理
+// @receiver p
+// @param report
+//
+func (p *PacketValidator) ReportLinkLoss(report *lf.LinkLossReport) {
+	p.packetQueue.Write(report)
+}
+
 // Close
 // 关闭包验证器
 //
@@ -60,7 +60,12 @@ func NewRoundRobinStrategy(roundTime int64) *RoundRobinStrategy {
 //
 func (r *RoundRobinStrategy) AfterReceiveGPPkt(ingress *lf.LogicFace, gPPkt *packet.GPPkt) {
 	fibEntry := r.lookupFibForGPPkt(gPPkt)
-	nextHops := fibEntry.GetNextHops()
+	nextHops := r.getNextHops(fibEntry, gPPkt.DstIdentifier())
+	if len(nextHops) == 0 {
+		// 没有满足标签约束的路由无法转发
+		common2.LogWarn("No Route")
+		return
+	}
 	selectedHop := nextHops[atomic.LoadUint64(&r.currentCount)%uint64(len(nextHops))]
 	if selectedHop == nil {
 		// 没有路由无法转发
@@ -0,0 +1,124 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package fw
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 19:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package fw
+
+import (
+	"minlib/component"
+	"mir-go/daemon/lf"
+	"strings"
+)
+
+// ScopeControl 作用域控制
+//
+// @Description:
+//	只在本机有意义的前缀（例如 /localhost、/min-mir/mgmt/localhost）下的包只能通过 local 作用域的 LogicFace 收发：
+//	1. Incoming Interest 管道丢弃从 non-local LogicFace 收到的这些前缀下的兴趣包；
+//	2. Outgoing Interest 和 Outgoing Data 管道丢弃准备从 non-local LogicFace 发出的这些前缀下的兴趣包和数据包。
+//
+type ScopeControl struct {
+	localOnlyPrefixes [][]string // 只在本机有意义的前缀，每个前缀按组件拆分
+}
+
+// CreateScopeControl 根据只在本机有意义的前缀列表创建作用域控制
+//
+// @Description:
+// @param localOnlyPrefixes
+// @return *ScopeControl
+// @return error
+//
+func CreateScopeControl(localOnlyPrefixes []string) (*ScopeControl, error) {
+	s := &ScopeControl{}
+	for _, prefix := range localOnlyPrefixes {
+		if prefix = strings.TrimSpace(prefix); prefix == "" {
+			continue
+		}
+		identifier, err := component.CreateIdentifierByString(prefix)
+		if err != nil {
+			return nil, err
+		}
+		s.localOnlyPrefixes = append(s.localOnlyPrefixes, identifierToComponents(identifier))
+	}
+	return s, nil
+}
+
+// IsLocalOnly 判断标识是否位于只在本机有意义的前缀下
+//
+// @Description:
+// @receiver s
+// @param identifier
+// @return bool
+//
+func (s *ScopeControl) IsLocalOnly(identifier *component.Identifier) bool {
+	if s == nil || len(s.localOnlyPrefixes) == 0 {
+		return false
+	}
+	components := identifierToComponents(identifier)
+	for _, prefix := range s.localOnlyPrefixes {
+		if isComponentsPrefix(prefix, components) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanTransfer 判断标识为 identifier 的包能否通过 logicFace 收发
+//
+// @Description:
+// @receiver s
+// @param logicFace
+// @param identifier
+// @return bool
+//
+func (s *ScopeControl) CanTransfer(logicFace *lf.LogicFace, identifier *component.Identifier) bool {
+	return logicFace.IsLocal() || !s.IsLocalOnly(identifier)
+}
+
+//
+// @Description: 把标识按组件拆分
+// @param identifier
+// @return []string
+//
+func identifierToComponents(identifier *component.Identifier) []string {
+	components := make([]string, 0, len(identifier.GetComponents()))
+	for _, v := range identifier.GetComponents() {
+		components = append(components, v.ToString())
+	}
+	return components
+}
+
+//
+// @Description: 判断 prefix 是否是 components 的前缀（按组件比较）
+// @param prefix
+// @param components
+// @return bool
+//
+func isComponentsPrefix(prefix []string, components []string) bool {
+	if len(prefix) > len(components) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != components[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package fw
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 19:30 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package fw
+
+import (
+	"minlib/component"
+	"minlib/packet"
+	"mir-go/daemon/lf"
+	"mir-go/daemon/plugin"
+	"testing"
+)
+
+func TestScopeControl(t *testing.T) {
+	scopeControl, err := CreateScopeControl([]string{"/localhost", " /min-mir/mgmt/localhost ", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 零值的 LogicFace 是 non-local 的
+	networkFace := &lf.LogicFace{LogicFaceId: 1}
+	if networkFace.IsLocal() {
+		t.Fatal("zero LogicFace should be non-local")
+	}
+
+	cases := []struct {
+		name      string
+		localOnly bool
+	}{
+		{"/localhost", true},
+		{"/localhost/mir/ping", true},
+		{"/min-mir/mgmt/localhost/face-mgmt/list", true},
+		{"/min-mir/mgmt/localhop/rib-mgmt/register", false},
+		{"/localhostx", false},
+		{"/min/localhost", false},
+	}
+	for _, c := range cases {
+		identifier, err := component.CreateIdentifierByString(c.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := scopeControl.IsLocalOnly(identifier); got != c.localOnly {
+			t.Errorf("IsLocalOnly(%s) = %v, want %v", c.name, got, c.localOnly)
+		}
+		if got := scopeControl.CanTransfer(networkFace, identifier); got == c.localOnly {
+			t.Errorf("CanTransfer(non-local, %s) = %v, want %v", c.name, got, !c.localOnly)
+		}
+	}
+
+	// 没有配置只在本机有意义的前缀时不做限制
+	var empty *ScopeControl
+	identifier, _ := component.CreateIdentifierByString("/localhost")
+	if !empty.CanTransfer(networkFace, identifier) {
+		t.Fatal("nil ScopeControl should not drop any packet")
+	}
+}
+
+// 违反作用域规则被丢弃的包同时计入 ScopeViolationN 和对应方向、对应类型的丢包计数
+func TestScopeControl_Counters(t *testing.T) {
+	scopeControl, err := CreateScopeControl([]string{"/localhost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forwarder := &Forwarder{scopeControl: scopeControl, pluginManager: new(plugin.GlobalPluginManager)}
+	networkFace := &lf.LogicFace{LogicFaceId: 1}
+	identifier, err := component.CreateIdentifierByString("/localhost/mir/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	interest := new(packet.Interest)
+	interest.SetName(identifier)
+	interest.TTL.SetTTL(3)
+	data := new(packet.Data)
+	data.SetName(identifier)
+
+	forwarder.OnIncomingInterest(networkFace, interest)
+	forwarder.OnOutgoingInterest(networkFace, nil, interest)
+	forwarder.OnOutgoingData(networkFace, data)
+
+	counters := networkFace.GetCounters()
+	if counters.ScopeViolationN != 3 {
+		t.Fatalf("ScopeViolationN = %d, want 3", counters.ScopeViolationN)
+	}
+	if counters.DropInterestN != 1 || counters.OutDropInterestN != 1 || counters.OutDropDataN != 1 {
+		t.Fatalf("unexpected drop counters %+v", counters)
+	}
+	if counters.OutInterestN != 0 || counters.OutDataN != 0 {
+		t.Fatalf("dropped packets should not be sent %+v", counters)
+	}
+}
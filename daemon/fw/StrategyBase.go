@@ -302,3 +302,18 @@ func (s *StrategyBase) lookupFibForInterest(interest *packet.Interest) *table.FI
 func (s *StrategyBase) lookupFibForGPPkt(gPPkt *packet.GPPkt) *table.FIBEntry {
 	return s.forwarder.FIB.FindLongestPrefixMatch(gPPkt.DstIdentifier())
 }
+
+//
+// 获取 FIB 条目中满足标签约束的下一跳
+//
+// @Description:
+//  下一跳按 Priority、Cost、LogicFaceId 排序，标签不满足 identifier 在 [TagConstraint] 中最长前缀匹配到的表达式的下一跳会被过滤掉
+// @param fibEntry
+// @param identifier
+//
+func (s *StrategyBase) getNextHops(fibEntry *table.FIBEntry, identifier *component.Identifier) []*table.NextHop {
+	if fibEntry == nil {
+		return nil
+	}
+	return s.forwarder.tagConstraints.FilterNextHops(identifier, fibEntry.GetNextHops())
+}
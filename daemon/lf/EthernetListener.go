@@ -149,6 +149,23 @@ func (e *EthernetListener) monitorDev() {
 	}
 }
 
+// MulticastLogicFaces
+// @Description: 	获取所有已启动网卡上的以太网组播 LogicFace
+// @receiver e
+// @return []*LogicFace
+//
+func (e *EthernetListener) MulticastLogicFaces() []*LogicFace {
+	var logicFaces []*LogicFace
+	e.mInterfaceListeners.Range(func(key, value interface{}) bool {
+		ifListener := value.(*InterfaceListener)
+		if ifListener.logicFace != nil && ifListener.logicFace.state {
+			logicFaces = append(logicFaces, ifListener.logicFace)
+		}
+		return true
+	})
+	return logicFaces
+}
+
 // DeleteLogicFace
 // @Description: 	删除一个logicFace
 // @receiver e
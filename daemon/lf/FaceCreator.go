@@ -22,12 +22,22 @@
 package lf
 
 import (
+	"crypto/tls"
 	"errors"
+	"golang.org/x/net/websocket"
 	common2 "minlib/common"
 	"minlib/logicface"
 	"net"
+	"strings"
+	"time"
 )
 
+// webSocketDialTimeout 主动发起 WebSocket 连接的超时时间
+const webSocketDialTimeout = 10 * time.Second
+
+// logicFaceRedialTimeout 永久性的 LogicFace 每次重连的超时时间
+const logicFaceRedialTimeout = 10 * time.Second
+
 //
 // @Description: 外部接口，为其他模块提供创建各种接口的函数
 //
@@ -61,28 +71,171 @@ func CreateEtherLogicFace(localIfName string, remoteMacAddr net.HardwareAddr) (*
 	return logicFace, nil
 }
 
+// CreateLogicFaceByUri
+// @Description:  给其他模块调用，根据对端地址的协议创建对应类型的LogicFace，静态路由配置文件、管理模块和命令行工具使用同一套地址格式，
+//				eg: tcp://192.168.3.7:13899 | tcp6://[2001:db8::1]:13899 | udp://[fe80::1%eth0]:13899 | tls://r2.example.com:13900
+//				| ws://[2001:db8::1]:13901/mir | ether://34:cf:f6:f8:6a:d8 | unix:///tmp/mir.sock
+// @param faceUri	通过 ParseFaceUri 解析得到的对端地址
+// @param localUri	以太网 LogicFace 使用的网卡名，eg: eth0 | dev://eth0，其它类型忽略
+// @param persistency	持久性
+// @return *LogicFace
+// @return error
+//
+func CreateLogicFaceByUri(faceUri *FaceUri, localUri string, persistency uint64) (*LogicFace, error) {
+	switch faceUri.Protocol() {
+	case FaceUriSchemeTCP:
+		return dialTcpLogicFace(faceUri.Network(), faceUri.Address(), persistency)
+	case FaceUriSchemeTLS:
+		return dialTlsLogicFace(faceUri.Network(), faceUri.Address(), persistency)
+	case FaceUriSchemeWS:
+		return dialWebSocketLogicFace(faceUri, persistency)
+	case FaceUriSchemeUDP:
+		return dialUdpLogicFace(faceUri.Network(), faceUri.Address())
+	case FaceUriSchemeEther:
+		remoteMacAddr, err := net.ParseMAC(faceUri.Host)
+		if err != nil {
+			return nil, err
+		}
+		return CreateEtherLogicFace(strings.TrimPrefix(localUri, FaceUriSchemeDev+"://"), remoteMacAddr)
+	case FaceUriSchemeUnix:
+		return CreateUnixLogicFace(faceUri.Path)
+	}
+	return nil, errors.New("unsupported remote uri " + faceUri.String())
+}
+
 // CreateTcpLogicFace
 // @Description:  给其他模块调用，创建一个TCP类型的LogicFace，传入对方的TCP地址，格式是 "<ip>:<port>"，如"192.168.3.7:13899"。
 //				函数会执行以下操作：
 //				（1） 尝试连接远程TCP地址，如果连接不成功，则返回连接错误信息
 //				（2） 如果连接成功，调用内部函数，创建一个TCP类型的logicFace
 //				（3） 启动该logicFace的接收数据协程
-// @param remoteUri		对方的TCP地址，格式是 "<ip>:<port>"，如"192.168.3.7:13899"
+// @param remoteUri		对方的TCP地址，格式是 "<ip>:<port>"，如"192.168.3.7:13899"、"[2001:db8::1]:13899"
 // @return uint64		logicFaceId
 // @return error		错误信息
 //
 func CreateTcpLogicFace(remoteUri string, persistency uint64) (*LogicFace, error) {
-	conn, err := net.Dial("tcp", remoteUri)
+	return dialTcpLogicFace(FaceUriSchemeTCP, remoteUri, persistency)
+}
+
+//
+// @Description: 连接远程TCP地址，创建一个TCP类型的LogicFace
+// @param network	tcp | tcp4 | tcp6
+// @param remoteAddr	对方的TCP地址，eg: 192.168.3.7:13899 | [2001:db8::1]:13899
+// @param persistency	持久性
+// @return *LogicFace
+// @return error
+//
+func dialTcpLogicFace(network string, remoteAddr string, persistency uint64) (*LogicFace, error) {
+	conn, err := net.Dial(network, remoteAddr)
+	if err != nil {
+		common2.LogWarn(err)
+		return nil, err
+	}
+	logicFace, _ := createTcpLogicFace(conn, persistency, func(linkService *LinkService) (ITransport, error) {
+		conn, err := net.DialTimeout(network, remoteAddr, logicFaceRedialTimeout)
+		if err != nil {
+			return nil, err
+		}
+		var tcpTransport TcpTransport
+		tcpTransport.Init(conn)
+		tcpTransport.linkService = linkService
+		return &tcpTransport, nil
+	})
+	return logicFace, nil
+}
+
+// CreateTlsLogicFace
+// @Description:  给其他模块调用，创建一个TLS类型的LogicFace，传入对方的TLS地址，格式是 "<ip>:<port>"，如"192.168.3.7:13900"。
+//				函数会执行以下操作：
+//				（1） 尝试连接远程地址并完成 TLS 握手，对端证书必须由配置的 CA 签发，如果不成功，则返回错误信息
+//				（2） 如果握手成功，调用内部函数，创建一个TLS类型的logicFace
+//				（3） 启动该logicFace的接收数据协程
+// @param remoteUri		对方的TLS地址，格式是 "<ip>:<port>"，如"192.168.3.7:13900"、"[2001:db8::1]:13900"
+// @param persistency	持久性
+// @return *LogicFace
+// @return error		错误信息
+//
+func CreateTlsLogicFace(remoteUri string, persistency uint64) (*LogicFace, error) {
+	return dialTlsLogicFace(FaceUriSchemeTCP, remoteUri, persistency)
+}
+
+//
+// @Description: 连接远程地址并完成 TLS 握手，创建一个TLS类型的LogicFace
+// @param network	tcp | tcp4 | tcp6
+// @param remoteAddr	对方的TLS地址，eg: 192.168.3.7:13900 | [2001:db8::1]:13900
+// @param persistency	持久性
+// @return *LogicFace
+// @return error
+//
+func dialTlsLogicFace(network string, remoteAddr string, persistency uint64) (*LogicFace, error) {
+	if gLogicFaceSystem.tlsSecurity == nil {
+		return nil, errors.New("TLS is not configured, check SupportTLS, TLSCertFile and TLSKeyFile")
+	}
+	dialer := &net.Dialer{Timeout: tlsHandshakeTimeout}
+	conn, err := tls.DialWithDialer(dialer, network, remoteAddr, gLogicFaceSystem.tlsSecurity.ClientConfig())
 	if err != nil {
 		common2.LogWarn(err)
 		return nil, err
 	}
-	logicFace, _ := createTcpLogicFace(conn, persistency)
+	logicFace, _ := createTlsLogicFace(conn, persistency)
+	return logicFace, nil
+}
+
+// CreateWebSocketLogicFace
+// @Description:  给其他模块调用，创建一个WebSocket类型的LogicFace，传入对方的WebSocket地址，格式是 "<ip>:<port>/<path>"，
+//				如"192.168.3.7:13901/mir"，用于在只允许 HTTP 的网络中与其它路由器互联。
+//				函数会执行以下操作：
+//				（1） 尝试连接远程地址并完成 WebSocket 握手，如果不成功，则返回错误信息
+//				（2） 如果握手成功，调用内部函数，创建一个WebSocket类型的logicFace
+//				（3） 启动该logicFace的接收数据协程
+// @param remoteUri		对方的WebSocket地址，格式是 "<ip>:<port>/<path>"，如"192.168.3.7:13901/mir"
+// @param persistency	持久性
+// @return *LogicFace
+// @return error		错误信息
+//
+func CreateWebSocketLogicFace(remoteUri string, persistency uint64) (*LogicFace, error) {
+	faceUri, err := ParseFaceUri(FaceUriSchemeWS + "://" + remoteUri)
+	if err != nil {
+		common2.LogWarn(err)
+		return nil, err
+	}
+	return dialWebSocketLogicFace(faceUri, persistency)
+}
+
+//
+// @Description: 连接远程地址并完成 WebSocket 握手，创建一个WebSocket类型的LogicFace
+// @param faceUri	对方的WebSocket地址，eg: ws://192.168.3.7:13901/mir | ws://[2001:db8::1]:13901/mir
+// @param persistency	持久性
+// @return *LogicFace
+// @return error
+//
+func dialWebSocketLogicFace(faceUri *FaceUri, persistency uint64) (*LogicFace, error) {
+	// URL 中 IPv6 地址的 zone 需要转义成 %25
+	urlHost := net.JoinHostPort(strings.Replace(faceUri.Host, "%", "%25", 1), faceUri.Port)
+	config, err := websocket.NewConfig(FaceUriSchemeWS+"://"+urlHost+faceUri.Path, "http://"+urlHost)
+	if err != nil {
+		common2.LogWarn(err)
+		return nil, err
+	}
+	rawConn, err := net.DialTimeout(faceUri.Network(), faceUri.Address(), webSocketDialTimeout)
+	if err != nil {
+		common2.LogWarn(err)
+		return nil, err
+	}
+	_ = rawConn.SetDeadline(time.Now().Add(webSocketDialTimeout))
+	conn, err := websocket.NewClient(config, rawConn)
+	if err != nil {
+		common2.LogWarn(err)
+		_ = rawConn.Close()
+		return nil, err
+	}
+	_ = rawConn.SetDeadline(time.Time{})
+	logicFace, _ := createWebSocketLogicFace(conn, rawConn.LocalAddr().String(), faceUri.Address()+faceUri.Path, persistency)
 	return logicFace, nil
 }
 
 // CreateUdpLogicFace
-// @Description:	给其他模块调用，创建一个UDP类型的LogicFace，传入对方的UDP地址，格式是 "<ip>:<port>"，如"192.168.3.7:13899"
+// @Description:	给其他模块调用，创建一个UDP类型的LogicFace，传入对方的UDP地址，格式是 "<ip>:<port>"，如"192.168.3.7:13899"、"[2001:db8::1]:13899"
 //				函数会执行以下操作：
 //				（1） 尝试解析UDP地址，如果解析不成功，则返回连接错误信息
 //				（2） 如果解析UDP地址成功，调用内部函数，创建一个UDP类型的logicFace
@@ -92,21 +245,121 @@ func CreateTcpLogicFace(remoteUri string, persistency uint64) (*LogicFace, error
 // @return error
 //
 func CreateUdpLogicFace(remoteUri string) (*LogicFace, error) {
-	logicFace := gLogicFaceSystem.udpListener.GetLogicFaceByRemoteUri(remoteUri)
+	return dialUdpLogicFace(FaceUriSchemeUDP, remoteUri)
+}
+
+// GetUdpLogicFace
+// @Description:	获取到对方UDP地址的LogicFace，格式和 CreateUdpLogicFace 相同，不存在时返回 nil
+// @param remoteUri
+// @return *LogicFace
+//
+func GetUdpLogicFace(remoteUri string) *LogicFace {
+	udpAddr, err := net.ResolveUDPAddr(FaceUriSchemeUDP, remoteUri)
+	if err != nil {
+		return nil
+	}
+	return gLogicFaceSystem.udpListener.GetLogicFaceByRemoteUri(udpAddr.String())
+}
+
+//
+// @Description: 创建一个发往远程UDP地址的UDP类型的LogicFace，
+//		同一个对端地址只会创建一个LogicFace，对端地址统一使用解析后的 "<ip>:<port>"，与 UdpListener 收包时的源地址一致
+// @param network	udp | udp4 | udp6
+// @param remoteAddr	对方的UDP地址，eg: 192.168.3.7:13899 | [fe80::1%eth0]:13899
+// @return *LogicFace
+// @return error
+//
+func dialUdpLogicFace(network string, remoteAddr string) (*LogicFace, error) {
+	udpAddr, err := net.ResolveUDPAddr(network, remoteAddr)
+	if err != nil {
+		common2.LogWarn(err)
+		return nil, err
+	}
+	logicFace := gLogicFaceSystem.udpListener.GetLogicFaceByRemoteUri(udpAddr.String())
 	if logicFace != nil {
 		return logicFace, nil
 	}
-	udpAddr, err := net.ResolveUDPAddr("udp4", remoteUri)
+	udpConn, err := net.ListenUDP(network, nil)
 	if err != nil {
-		common2.LogWarn(err)
 		return nil, err
 	}
-	udpConn, err := net.ListenUDP("udp", nil)
+	logicFace, _ = createUdpLogicFace(udpConn, udpAddr, func(linkService *LinkService) (ITransport, error) {
+		return redialUdpTransport(network, remoteAddr, linkService)
+	})
+	gLogicFaceSystem.udpListener.AddLogicFace(udpAddr.String(), logicFace)
+	return logicFace, nil
+}
+
+//
+// @Description: 重新解析对端地址并创建一个新的 UDP 句柄，对端地址变化时更新 UdpListener 中的映射，保证对端发来的包仍然交给同一个 LogicFace
+// @param network	udp | udp4 | udp6
+// @param remoteAddr	创建 LogicFace 时使用的对端地址
+// @param linkService
+// @return ITransport
+// @return error
+//
+func redialUdpTransport(network string, remoteAddr string, linkService *LinkService) (ITransport, error) {
+	udpAddr, err := net.ResolveUDPAddr(network, remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	udpConn, err := net.ListenUDP(network, nil)
+	if err != nil {
+		return nil, err
+	}
+	var udpTransport UdpTransport
+	udpTransport.Init(udpConn, udpAddr)
+	udpTransport.linkService = linkService
+	if oldAddr := linkService.getTransport().GetRemoteAddr(); oldAddr != udpAddr.String() {
+		gLogicFaceSystem.udpListener.DeleteLogicFace(oldAddr)
+		gLogicFaceSystem.udpListener.AddLogicFace(udpAddr.String(), linkService.logicFace)
+	}
+	return &udpTransport, nil
+}
+
+// CreateUdpMulticastLogicFace
+// @Description:	给其他模块调用，在指定网卡上创建一个UDP组播类型的LogicFace，组播地址的格式是 "<ip>:<port>"，
+//				如 "224.0.23.171:56364" 或 "[ff02::114]:56364"，IPv4 和 IPv6 都支持。
+//				函数会执行以下操作：
+//				（1） 如果该网卡和组播地址对应的logicFace已经存在，则直接返回已经存在的logicFace
+//				（2） 尝试解析组播地址和网卡，如果解析不成功，则返回错误信息
+//				（3） 在该网卡上加入组播组，调用内部函数，创建一个UDP组播类型的logicFace
+//				从组内任意成员收到的包都通过这一个logicFace交给转发器，发送者记录在 UdpMulticastTransport 的成员表中
+// @param ifName	本地网卡名
+// @param groupUri	组播地址
+// @return *LogicFace
+// @return error
+//
+func CreateUdpMulticastLogicFace(ifName string, groupUri string) (*LogicFace, error) {
+	ifName, groupAddr, err := parseUdpMulticastEntry(ifName + "@" + groupUri)
 	if err != nil {
 		return nil, err
 	}
-	logicFace, _ = createUdpLogicFace(udpConn, udpAddr)
-	gLogicFaceSystem.udpListener.AddLogicFace(remoteUri, logicFace)
+	key := ifName + "@" + groupAddr.String()
+	if value, ok := gLogicFaceSystem.udpMulticastLogicFaces.Load(key); ok {
+		return value.(*LogicFace), nil
+	}
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return nil, err
+	}
+	if ifi.Flags&net.FlagMulticast == 0 {
+		return nil, UdpMulticastError{msg: "interface " + ifName + " does not support multicast"}
+	}
+	conn, err := net.ListenMulticastUDP(udpNetworkOf(groupAddr), ifi, groupAddr)
+	if err != nil {
+		return nil, err
+	}
+	// 扣除 IP 头部和 UDP 头部，接收缓冲区最大为 9000 字节
+	mtu := ifi.MTU - 28
+	if groupAddr.IP.To4() == nil {
+		mtu = ifi.MTU - 48
+	}
+	if mtu > 9000 || mtu <= 0 {
+		mtu = 9000
+	}
+	logicFace, _ := createUdpMulticastLogicFace(conn, ifName, groupAddr, mtu)
+	gLogicFaceSystem.udpMulticastLogicFaces.Store(key, logicFace)
 	return logicFace, nil
 }
 
@@ -115,7 +368,7 @@ func CreateUdpLogicFace(remoteUri string) (*LogicFace, error) {
 //				函数会执行以下操作：
 //				（1） 尝试解析unix地址，如果解析不成功，则返回连接错误信息
 //				（2） 尝试连接远程地址，如果连接不成功，则返回连接错误信息
-//				（3） 如果连接成功，调用内部函数，创建一个unix类型的logicFace
+//				（3） 如果连接成功，调用内部函数，创建一个unix类型的logicFace，永久性的unix类型的logicFace断开后会重新连接该地址
 //				（4） 启动该logicFace的接收数据协程
 // @param remoteUri		传入对方的unix地址，格式是 文件路径，如"/tmp/mirsock"。
 // @return uint64		logicFaceId
@@ -129,9 +382,19 @@ func CreateUnixLogicFace(remoteUri string) (*LogicFace, error) {
 	}
 	conn, err := net.DialUnix("unix", nil, addr)
 	if err != nil {
-		panic("DialUnix failed.")
+		common2.LogWarn(err)
+		return nil, err
 	}
-	logicFace, _ := createUnixLogicFace(conn)
+	logicFace, _ := createUnixLogicFace(conn, func(linkService *LinkService) (ITransport, error) {
+		conn, err := net.DialTimeout("unix", addr.String(), logicFaceRedialTimeout)
+		if err != nil {
+			return nil, err
+		}
+		var unixTransport UnixStreamTransport
+		unixTransport.Init(conn)
+		unixTransport.linkService = linkService
+		return &unixTransport, nil
+	})
 	return logicFace, nil
 }
 
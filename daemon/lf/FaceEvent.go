@@ -0,0 +1,98 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 22:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import "time"
+
+// FaceEventKind LogicFace 生命周期事件的类型
+type FaceEventKind uint8
+
+const (
+	FaceEventCreated    FaceEventKind = iota // LogicFace 被加入 LogicFaceTable
+	FaceEventUp                              // LogicFace 开始收发包
+	FaceEventDown                            // LogicFace 被关闭
+	FaceEventDestroyed                       // LogicFace 被移出 LogicFaceTable
+	FaceEventMtuChanged                      // LogicFace 的 MTU 发生了变化
+)
+
+// String 获取事件类型的名字，eg: created | up | down
+//
+// @Description:
+// @receiver k
+// @return string
+//
+func (k FaceEventKind) String() string {
+	switch k {
+	case FaceEventCreated:
+		return "created"
+	case FaceEventUp:
+		return "up"
+	case FaceEventDown:
+		return "down"
+	case FaceEventDestroyed:
+		return "destroyed"
+	case FaceEventMtuChanged:
+		return "mtu-changed"
+	}
+	return "unknown"
+}
+
+// FaceEvent LogicFace 生命周期事件
+//
+// @Description: 由 LogicFaceTable 统一分配序列号并发布，序列号从 1 开始连续递增，订阅者可以据此发现遗漏的事件。
+//	事件中的 LogicFace 属性是事件发生时的快照
+//
+type FaceEvent struct {
+	Seq           uint64        // 事件序列号
+	Kind          FaceEventKind // 事件类型
+	Timestamp     int64         // 事件发生的时间，ms
+	LogicFaceId   uint64        // 发生事件的 LogicFace
+	LogicFaceType LogicFaceType // LogicFace 类型
+	LocalUri      string        // 本地地址
+	RemoteUri     string        // 对端地址
+	Mtu           uint64        // 事件发生时的 MTU
+}
+
+// FaceEventListener 接收 LogicFace 生命周期事件的回调，在发布事件的协程中按照序列号顺序同步调用，不能阻塞
+type FaceEventListener func(event *FaceEvent)
+
+//
+// @Description: 根据 LogicFace 的当前属性构造一个事件，序列号由 LogicFaceTable 在发布时填写
+// @param face
+// @param kind
+// @return *FaceEvent
+//
+func newFaceEvent(face *LogicFace, kind FaceEventKind) *FaceEvent {
+	event := &FaceEvent{
+		Kind:          kind,
+		Timestamp:     time.Now().UnixNano() / int64(time.Millisecond),
+		LogicFaceId:   face.LogicFaceId,
+		LogicFaceType: face.logicFaceType,
+		Mtu:           face.Mtu,
+	}
+	// 内部测试用的 LogicFace 可能没有绑定 transport
+	if transport := face.getTransport(); transport != nil {
+		event.LocalUri = transport.GetLocalUri()
+		event.RemoteUri = transport.GetRemoteUri()
+	}
+	return event
+}
@@ -0,0 +1,68 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 22:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import "testing"
+
+func TestLogicFaceTable_PublishEvent(t *testing.T) {
+	var table LogicFaceTable
+	table.Init()
+	var evicted []uint64
+	table.OnEvicted = func(logicFaceId uint64) {
+		evicted = append(evicted, logicFaceId)
+	}
+	var events []*FaceEvent
+	table.AddEventListener(func(event *FaceEvent) {
+		events = append(events, event)
+	})
+
+	face := &LogicFace{logicFaceType: LogicFaceTypeUDP, linkService: &LinkService{}, Mtu: 1500, state: true}
+	id := table.AddLogicFace(face)
+	face.onEvent(FaceEventUp)
+	face.updateMTU(1500) // MTU 没有变化时不发布事件
+	face.updateMTU(9000)
+	face.onLogicFaceShutDown()
+	table.RemoveByLogicFaceId(id)
+	table.RemoveByLogicFaceId(id) // 已经删除的 LogicFace 不会再发布事件
+
+	expect := []FaceEventKind{FaceEventCreated, FaceEventUp, FaceEventMtuChanged, FaceEventDown, FaceEventDestroyed}
+	if len(events) != len(expect) {
+		t.Fatalf("expect %d events, got %d", len(expect), len(events))
+	}
+	for i, event := range events {
+		if event.Seq != uint64(i+1) || event.Kind != expect[i] || event.LogicFaceId != id {
+			t.Fatalf("unexpected event %d: seq=%d kind=%s face=%d", i, event.Seq, event.Kind, event.LogicFaceId)
+		}
+	}
+	if events[2].Mtu != 9000 || events[0].LogicFaceType != LogicFaceTypeUDP {
+		t.Fatalf("unexpected event snapshot: %+v", events[2])
+	}
+	if table.GetLastEventSeq() != uint64(len(expect)) || len(evicted) != 1 || evicted[0] != id {
+		t.Fatalf("unexpected last seq %d, evicted %v", table.GetLastEventSeq(), evicted)
+	}
+}
+
+func TestFaceEventKind_String(t *testing.T) {
+	if FaceEventMtuChanged.String() != "mtu-changed" || FaceEventKind(100).String() != "unknown" {
+		t.Fatal("unexpected face event kind name")
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 21:30 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+//
+// @Description: LogicFace 地址支持的协议
+//	tcp | udp 可以加上后缀 4 或 6 限定只使用 IPv4 或 IPv6，不加后缀时根据地址自动选择
+//
+const (
+	FaceUriSchemeTCP   = "tcp"
+	FaceUriSchemeTCP4  = "tcp4"
+	FaceUriSchemeTCP6  = "tcp6"
+	FaceUriSchemeUDP   = "udp"
+	FaceUriSchemeUDP4  = "udp4"
+	FaceUriSchemeUDP6  = "udp6"
+	FaceUriSchemeTLS   = "tls"
+	FaceUriSchemeWS    = "ws"
+	FaceUriSchemeEther = "ether"
+	FaceUriSchemeDev   = "dev"
+	FaceUriSchemeUnix  = "unix"
+)
+
+// FaceUri LogicFace 地址，eg:
+//	tcp://192.168.3.7:13899 | tcp6://[2001:db8::1]:13899 | udp://[fe80::1%eth0]:13899 | tls://r2.example.com:13900
+//	ws://[2001:db8::1]:13901/mir | ether://34:cf:f6:f8:6a:d8 | dev://eth0 | unix:///tmp/mir.sock
+//
+// @Description:
+//	IPv6 地址必须放在方括号中，zone 可以写成 %eth0 或者 URI 中转义后的 %25eth0
+//
+type FaceUri struct {
+	Scheme string // 协议
+	Host   string // 主机名、IP 地址（IPv6 地址不带方括号，可以带 zone，eg: fe80::1%eth0）、MAC 地址或者网卡名
+	Port   string // 端口号，只有基于 IP 的协议有
+	Path   string // unix 套接字路径，或者 WebSocket 的路径
+}
+
+// ParseFaceUri 解析 LogicFace 地址
+//
+// @Description:
+// @param uri
+// @return *FaceUri
+// @return error
+//
+func ParseFaceUri(uri string) (*FaceUri, error) {
+	index := strings.Index(uri, "://")
+	if index <= 0 {
+		return nil, FaceUriError{msg: "expect <scheme>://<address>, got " + uri}
+	}
+	f := &FaceUri{Scheme: strings.ToLower(uri[:index])}
+	rest := uri[index+3:]
+	switch f.Scheme {
+	case FaceUriSchemeEther:
+		macAddr, err := net.ParseMAC(rest)
+		if err != nil {
+			return nil, FaceUriError{msg: "invalid mac address in " + uri}
+		}
+		f.Host = macAddr.String()
+	case FaceUriSchemeDev:
+		if rest == "" || strings.ContainsAny(rest, "/:") {
+			return nil, FaceUriError{msg: "invalid interface name in " + uri}
+		}
+		f.Host = rest
+	case FaceUriSchemeUnix:
+		if rest == "" {
+			return nil, FaceUriError{msg: "missing socket path in " + uri}
+		}
+		f.Path = rest
+	case FaceUriSchemeTCP, FaceUriSchemeTCP4, FaceUriSchemeTCP6, FaceUriSchemeUDP, FaceUriSchemeUDP4,
+		FaceUriSchemeUDP6, FaceUriSchemeTLS, FaceUriSchemeWS:
+		hostPort := rest
+		if f.Scheme == FaceUriSchemeWS {
+			f.Path = "/"
+			// 方括号中的 IPv6 地址不会包含 '/'，第一个 '/' 就是路径的开始
+			if index := strings.Index(rest, "/"); index >= 0 {
+				hostPort, f.Path = rest[:index], rest[index:]
+			}
+		}
+		host, port, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			return nil, FaceUriError{msg: "invalid address in " + uri + ": " + err.Error()}
+		}
+		if err := f.setHostPort(strings.Replace(host, "%25", "%", 1), port); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, FaceUriError{msg: "unsupported scheme " + f.Scheme + " in " + uri}
+	}
+	return f, nil
+}
+
+//
+// @Description: 检查并设置主机和端口，IP 地址的版本必须与协议的后缀 4 或 6 一致
+// @receiver f
+// @param host
+// @param port
+// @return error
+//
+func (f *FaceUri) setHostPort(host string, port string) error {
+	portNum, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || portNum == 0 {
+		return FaceUriError{msg: "invalid port " + port}
+	}
+	if host == "" {
+		return FaceUriError{msg: "missing host"}
+	}
+	ipStr, zone := host, ""
+	if index := strings.Index(host, "%"); index >= 0 {
+		ipStr, zone = host[:index], host[index+1:]
+	}
+	if ip := net.ParseIP(ipStr); ip != nil {
+		isIPv4 := ip.To4() != nil
+		if zone != "" && (isIPv4 || strings.ContainsAny(zone, "/%")) {
+			return FaceUriError{msg: "invalid zone in " + host}
+		}
+		if (strings.HasSuffix(f.Scheme, "4") && !isIPv4) || (strings.HasSuffix(f.Scheme, "6") && isIPv4) {
+			return FaceUriError{msg: "address " + host + " does not match scheme " + f.Scheme}
+		}
+		if isIPv4 {
+			ipStr = ip.To4().String()
+		} else {
+			ipStr = ip.String()
+		}
+		if zone != "" {
+			ipStr += "%" + zone
+		}
+		f.Host, f.Port = ipStr, strconv.FormatUint(portNum, 10)
+		return nil
+	}
+	if zone != "" || strings.Contains(host, ":") || !isValidHostname(host) {
+		return FaceUriError{msg: "invalid host " + host}
+	}
+	f.Host, f.Port = strings.ToLower(host), strconv.FormatUint(portNum, 10)
+	return nil
+}
+
+//
+// @Description: 判断是否是合法的主机名，只允许字母、数字、'-'、'_' 和 '.'
+// @param host
+// @return bool
+//
+func isValidHostname(host string) bool {
+	if len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Protocol 获取去掉 IP 版本后缀之后的协议，eg: tcp6 => tcp
+//
+// @Description:
+// @receiver f
+// @return string	tcp | udp | tls | ws | ether | dev | unix
+//
+func (f *FaceUri) Protocol() string {
+	switch f.Scheme {
+	case FaceUriSchemeTCP4, FaceUriSchemeTCP6:
+		return FaceUriSchemeTCP
+	case FaceUriSchemeUDP4, FaceUriSchemeUDP6:
+		return FaceUriSchemeUDP
+	}
+	return f.Scheme
+}
+
+// Network 获取建立连接时使用的网络类型，可以直接传给 net.Dial，TLS 和 WebSocket 运行在 TCP 之上
+//
+// @Description:
+// @receiver f
+// @return string	tcp | tcp4 | tcp6 | udp | udp4 | udp6 | unix，以太网和网卡地址返回空字符串
+//
+func (f *FaceUri) Network() string {
+	switch f.Scheme {
+	case FaceUriSchemeTLS, FaceUriSchemeWS:
+		return FaceUriSchemeTCP
+	case FaceUriSchemeEther, FaceUriSchemeDev:
+		return ""
+	}
+	return f.Scheme
+}
+
+// Address 获取建立连接时使用的地址，可以直接传给 net.Dial，eg: 192.168.3.7:13899 | [fe80::1%eth0]:13899
+//
+// @Description:
+//	unix 返回套接字路径，以太网返回 MAC 地址，网卡地址返回网卡名
+// @receiver f
+// @return string
+//
+func (f *FaceUri) Address() string {
+	switch f.Scheme {
+	case FaceUriSchemeUnix:
+		return f.Path
+	case FaceUriSchemeEther, FaceUriSchemeDev:
+		return f.Host
+	}
+	return net.JoinHostPort(f.Host, f.Port)
+}
+
+// String 获取规范化的 LogicFace 地址，IPv6 地址放在方括号中，eg: udp://[fe80::1%eth0]:13899
+//
+// @Description:
+// @receiver f
+// @return string
+//
+func (f *FaceUri) String() string {
+	switch f.Scheme {
+	case FaceUriSchemeWS:
+		return f.Scheme + "://" + f.Address() + f.Path
+	case FaceUriSchemeUnix:
+		return f.Scheme + "://" + f.Path
+	}
+	return f.Scheme + "://" + f.Address()
+}
+
+//
+// @Description: 获取监听器使用的网络类型和监听地址
+//	listenAddr 为空时同时监听所有的 IPv4 和 IPv6 地址（双栈）；为 IPv4 地址时只监听 IPv4，eg: 0.0.0.0；
+//	为 IPv6 地址时只监听 IPv6，eg: :: 或 fe80::1%eth0
+// @param network	tcp | udp
+// @param listenAddr	监听的本地地址
+// @param port	端口号
+// @return string	网络类型
+// @return string	监听地址
+//
+func listenNetworkAndAddress(network string, listenAddr string, port int) (string, string) {
+	listenAddr = strings.Trim(strings.TrimSpace(listenAddr), "[]")
+	if listenAddr == "" {
+		return network, ":" + strconv.Itoa(port)
+	}
+	host := listenAddr
+	if index := strings.Index(host, "%"); index >= 0 {
+		host = host[:index]
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() != nil {
+			network += "4"
+		} else {
+			network += "6"
+		}
+	}
+	return network, net.JoinHostPort(listenAddr, strconv.Itoa(port))
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type FaceUriError struct {
+	msg string
+}
+
+func (f FaceUriError) Error() string {
+	return fmt.Sprintf("FaceUriError: %s", f.msg)
+}
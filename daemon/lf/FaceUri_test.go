@@ -0,0 +1,148 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 21:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestParseFaceUri(t *testing.T) {
+	cases := []struct {
+		uri      string
+		protocol string
+		network  string
+		address  string
+		path     string
+		str      string
+	}{
+		{"tcp://192.168.3.7:13899", "tcp", "tcp", "192.168.3.7:13899", "", "tcp://192.168.3.7:13899"},
+		{"TCP4://192.168.3.7:13899", "tcp", "tcp4", "192.168.3.7:13899", "", "tcp4://192.168.3.7:13899"},
+		{"tcp6://[2001:DB8:0::1]:13899", "tcp", "tcp6", "[2001:db8::1]:13899", "", "tcp6://[2001:db8::1]:13899"},
+		{"udp://[fe80::1%eth0]:13899", "udp", "udp", "[fe80::1%eth0]:13899", "", "udp://[fe80::1%eth0]:13899"},
+		{"udp6://[fe80::1%25eth0]:13899", "udp", "udp6", "[fe80::1%eth0]:13899", "", "udp6://[fe80::1%eth0]:13899"},
+		{"udp4://r2.Example.com:13899", "udp", "udp4", "r2.example.com:13899", "", "udp4://r2.example.com:13899"},
+		{"tls://[2001:db8::1]:13900", "tls", "tcp", "[2001:db8::1]:13900", "", "tls://[2001:db8::1]:13900"},
+		{"ws://[2001:db8::1]:13901/mir", "ws", "tcp", "[2001:db8::1]:13901", "/mir", "ws://[2001:db8::1]:13901/mir"},
+		{"ws://localhost:13901", "ws", "tcp", "localhost:13901", "/", "ws://localhost:13901/"},
+		{"ether://34:CF:F6:F8:6A:D8", "ether", "", "34:cf:f6:f8:6a:d8", "", "ether://34:cf:f6:f8:6a:d8"},
+		{"dev://eth0", "dev", "", "eth0", "", "dev://eth0"},
+		{"unix:///tmp/mir.sock", "unix", "unix", "/tmp/mir.sock", "/tmp/mir.sock", "unix:///tmp/mir.sock"},
+	}
+	for _, c := range cases {
+		faceUri, err := ParseFaceUri(c.uri)
+		if err != nil {
+			t.Fatalf("parse %s fail: %v", c.uri, err)
+		}
+		if faceUri.Protocol() != c.protocol || faceUri.Network() != c.network || faceUri.Address() != c.address ||
+			faceUri.Path != c.path || faceUri.String() != c.str {
+			t.Fatalf("parse %s got %+v", c.uri, faceUri)
+		}
+		// 规范化之后的地址可以再次解析，并且结果不变
+		again, err := ParseFaceUri(faceUri.String())
+		if err != nil || *again != *faceUri {
+			t.Fatalf("reparse %s got %+v %v", faceUri.String(), again, err)
+		}
+	}
+
+	for _, uri := range []string{
+		"192.168.3.7:13899",                // 缺少协议
+		"://192.168.3.7:13899",             // 缺少协议
+		"sctp://192.168.3.7:13899",         // 不支持的协议
+		"tcp://192.168.3.7",                // 缺少端口
+		"tcp://192.168.3.7:0",              // 端口为 0
+		"tcp://192.168.3.7:65536",          // 端口越界
+		"tcp://192.168.3.7:http",           // 端口不是数字
+		"tcp://:13899",                     // 缺少主机
+		"tcp://2001:db8::1:13899",          // IPv6 地址没有放在方括号中
+		"tcp4://[2001:db8::1]:13899",       // 地址版本与协议不一致
+		"udp6://192.168.3.7:13899",         // 地址版本与协议不一致
+		"udp://192.168.3.7%eth0:13899",     // IPv4 地址不能带 zone
+		"tcp://bad_host!:13899",            // 非法主机名
+		"tcp://a..b:13899",                 // 非法主机名
+		"ws://[2001:db8::1]/mir",           // 缺少端口
+		"ether://34:cf:f6:f8:6a",           // 非法 MAC 地址
+		"dev://",                           // 缺少网卡名
+		"unix://",                          // 缺少套接字路径
+		"tcp://[fe80::1%eth0%1]:13899",     // 非法 zone
+		"udp://[2001:db8::1]:13899/extra/", // 多余的路径
+	} {
+		if faceUri, err := ParseFaceUri(uri); err == nil {
+			t.Fatalf("uri %q should be rejected, got %+v", uri, faceUri)
+		}
+	}
+}
+
+func TestListenNetworkAndAddress(t *testing.T) {
+	cases := []struct {
+		listenAddr string
+		network    string
+		address    string
+	}{
+		{"", "tcp", ":13899"},
+		{"0.0.0.0", "tcp4", "0.0.0.0:13899"},
+		{"192.168.3.7", "tcp4", "192.168.3.7:13899"},
+		{"::", "tcp6", "[::]:13899"},
+		{"[2001:db8::1]", "tcp6", "[2001:db8::1]:13899"},
+		{"fe80::1%eth0", "tcp6", "[fe80::1%eth0]:13899"},
+		{"localhost", "tcp", "localhost:13899"},
+	}
+	for _, c := range cases {
+		network, address := listenNetworkAndAddress(FaceUriSchemeTCP, c.listenAddr, 13899)
+		if network != c.network || address != c.address {
+			t.Fatalf("listen address %q got %s %s", c.listenAddr, network, address)
+		}
+	}
+
+	// 默认双栈监听，IPv4 和 IPv6 的客户端都可以连接
+	listener, err := net.Listen(listenNetworkAndAddress(FaceUriSchemeTCP, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+	for _, uri := range []string{"tcp4://127.0.0.1:", "tcp6://[::1]:"} {
+		faceUri, err := ParseFaceUri(uri + strconv.Itoa(port))
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := net.Dial(faceUri.Network(), faceUri.Address())
+		if err != nil {
+			if faceUri.Network() == FaceUriSchemeTCP6 {
+				t.Logf("IPv6 is not available: %v", err)
+				continue
+			}
+			t.Fatalf("dial %s fail: %v", faceUri.String(), err)
+		}
+		_ = conn.Close()
+	}
+}
@@ -23,4 +23,5 @@ package lf
 
 type IPacketValidator interface {
 	ReceiveMINPacket(data *IncomingPacketData)
+	ReportLinkLoss(report *LinkLossReport) // 上报链路层可靠传输放弃的包
 }
@@ -37,6 +37,17 @@ type IncomingPacketData struct {
 	MinPacket *packet.MINPacket
 }
 
+// LinkLossReport
+// 链路层可靠传输放弃一个包之后向 Forwarder 上报的丢包信息
+//
+// @Description:
+//	1. LogicFace 表示包是从哪个 LogicFace 发出去的，MinPacket 是超过最大重传次数仍没有被对端确认的包
+//
+type LinkLossReport struct {
+	LogicFace *LogicFace
+	MinPacket *packet.MINPacket
+}
+
 func (ipd *IncomingPacketData) ToFields() logrus.Fields {
 	firstIdentifier, err := ipd.MinPacket.GetIdentifier(0)
 	if err != nil {
@@ -32,6 +32,9 @@ import (
 	"net"
 )
 
+// EtherMulticastAddr 每个网卡上默认创建的以太网组播 LogicFace 的目的MAC地址
+const EtherMulticastAddr = "01:00:5e:00:17:aa"
+
 // InterfaceListener
 // @Description:  etherFaceMap用于保存mac地址和LogicFace的映射关系表。
 //			key 的格式是收到以太网帧的 "<源MAC地址>"
@@ -106,7 +109,7 @@ func (i *InterfaceListener) Close() {
 // @return error 启动失败则返回错误
 //
 func (i *InterfaceListener) Start() error {
-	remoteMacAddr, _ := net.ParseMAC("01:00:5e:00:17:aa")
+	remoteMacAddr, _ := net.ParseMAC(EtherMulticastAddr)
 	var logicFacePtr *LogicFace
 	logicFacePtr, i.pcapHandle = createEtherLogicFace(i.name, i.macAddr, remoteMacAddr, i.mtu)
 	if logicFacePtr == nil {
@@ -26,6 +26,7 @@ import (
 	common2 "minlib/common"
 	"minlib/encoding"
 	"minlib/packet"
+	"sync"
 	"sync/atomic"
 )
 
@@ -39,9 +40,11 @@ var lpPacketId uint64 = 0 // 发送的lpPacket 包ID
 //		在一个发送包的流程中，由logicFace调用linkService的发包函数，再由linkService调用transport的发包函数
 //
 type LinkService struct {
-	transport    ITransport   // 传输通道
-	logicFace    *LogicFace   // LinkService关联的logicFace
-	lpReassemble LpReassemble // 包分片合并器
+	transport     ITransport     // 传输通道
+	transportLock sync.RWMutex   // 保护 transport，永久性的 LogicFace 重连之后会替换 transport
+	logicFace     *LogicFace     // LinkService关联的logicFace
+	lpReassemble  LpReassemble   // 包分片合并器
+	reliability   *LpReliability // 链路层可靠传输，没有开启时为 nil
 
 	mtu              int // MTU大小
 	lpPacketHeadSize int // lpPacket 编码成数组时的头部大小
@@ -93,6 +96,41 @@ func (l *LinkService) updateMTU(mtu int) {
 	l.mtu = mtu
 }
 
+//
+// @Description: 获取当前绑定的 transport
+// @receiver l
+// @return ITransport
+//
+func (l *LinkService) getTransport() ITransport {
+	l.transportLock.RLock()
+	defer l.transportLock.RUnlock()
+	return l.transport
+}
+
+//
+// @Description: 替换绑定的 transport，永久性的 LogicFace 重连成功之后调用。新连接的对端可能已经重启，
+//	所以开启了可靠传输时需要重新协商
+// @receiver l
+// @param transport
+//
+func (l *LinkService) setTransport(transport ITransport) {
+	l.transportLock.Lock()
+	l.transport = transport
+	l.transportLock.Unlock()
+	if l.reliability != nil {
+		l.reliability.Reset()
+	}
+}
+
+//
+// @Description: 通过当前绑定的 transport 发送一个 lpPacket
+// @receiver l
+// @param lpPacket
+//
+func (l *LinkService) sendLpPacket(lpPacket *packet.LpPacket) {
+	l.getTransport().Send(lpPacket)
+}
+
 //
 // @Description: 从lpPacket中提取出MINPacket对象
 // @param lpPacket  LpPacket 对象指针
@@ -119,6 +157,26 @@ func getMINPacketFromLpPacket(lpPacket *packet.LpPacket) (*packet.MINPacket, err
 // @param lpPacket 	lpPacket对象指针
 //
 func (l *LinkService) ReceivePacket(lpPacket *packet.LpPacket) {
+	l.receivePacketFrom(lpPacket, l.getTransport().GetRemoteUri())
+}
+
+//
+// @Description: 收到lpPacket包的处理函数，分片按 reassembleKey 分别重组
+//		多点接入的 LogicFace（例如 UDP 组播）上不同发送者的分片可能使用相同的分片号，需要用发送者的地址区分
+// @receiver l
+// @param lpPacket	lpPacket对象指针
+// @param reassembleKey	分片重组时使用的键，一般为对端的 uri
+//
+func (l *LinkService) receivePacketFrom(lpPacket *packet.LpPacket, reassembleKey string) {
+	// 可靠传输帧先去掉可靠传输头部，本端没有开启可靠传输时直接忽略
+	if isLpReliabilityFrame(lpPacket) {
+		if l.reliability == nil {
+			return
+		}
+		if lpPacket = l.reliability.ReceiveFrame(lpPacket); lpPacket == nil {
+			return
+		}
+	}
 
 	// 未分包，只有一个包
 	if lpPacket.GetFragmentNum() == 1 {
@@ -134,10 +192,11 @@ func (l *LinkService) ReceivePacket(lpPacket *packet.LpPacket) {
 			common2.LogWarn(err)
 			return
 		}
+		atomic.AddUint64(&l.logicFace.logicFaceCounters.InBytesN, uint64(len(lpPacket.GetValue())))
 		l.logicFace.ReceivePacket(minPacket)
 		return
 	}
-	reassembleLpPacket := l.lpReassemble.ReceiveFragment(l.transport.GetRemoteUri(), lpPacket)
+	reassembleLpPacket := l.lpReassemble.ReceiveFragment(reassembleKey, lpPacket)
 	if reassembleLpPacket == nil {
 		return
 	}
@@ -146,6 +205,7 @@ func (l *LinkService) ReceivePacket(lpPacket *packet.LpPacket) {
 		common2.LogWarn(err)
 		return
 	}
+	atomic.AddUint64(&l.logicFace.logicFaceCounters.InBytesN, uint64(len(reassembleLpPacket.GetValue())))
 	l.logicFace.ReceivePacket(minPacket)
 }
 
@@ -159,12 +219,25 @@ func (l *LinkService) ReceivePacket(lpPacket *packet.LpPacket) {
 // @param fragmentSeq	第几块分片，从0开始
 //
 func (l *LinkService) sendFragment(buf []byte, bufLen int, fragmentId, fragmentNum, fragmentSeq uint64) {
-	var lpPacket packet.LpPacket
+	l.sendLpPacket(newLpFragment(buf, bufLen, fragmentId, fragmentNum, fragmentSeq))
+}
+
+//
+// @Description: 构造一个lp包分片
+// @param buf	分片的数据
+// @param bufLen	数据长度
+// @param fragmentId	分片号
+// @param fragmentNum	分片数
+// @param fragmentSeq	第几块分片，从0开始
+// @return *packet.LpPacket
+//
+func newLpFragment(buf []byte, bufLen int, fragmentId, fragmentNum, fragmentSeq uint64) *packet.LpPacket {
+	lpPacket := packet.NewLpPacket()
 	lpPacket.SetId(fragmentId)
 	lpPacket.SetFragmentNum(fragmentNum)
 	lpPacket.SetFragmentSeq(fragmentSeq)
 	lpPacket.SetValue(buf[:bufLen])
-	l.transport.Send(&lpPacket)
+	return lpPacket
 }
 
 //
@@ -176,6 +249,11 @@ func (l *LinkService) sendFragment(buf []byte, bufLen int, fragmentId, fragmentN
 func (l *LinkService) sendByteBuffer(buf []byte, bufLen int) {
 	common2.LogDebug("send to face : ", l.logicFace.LogicFaceId, " ", l.logicFace.GetRemoteUri())
 	fragmentLen := l.mtu - l.lpPacketHeadSize - 10
+	if l.reliability != nil {
+		// 给可靠传输头部预留空间
+		fragmentLen -= lpReliabilityMaxHeadSize
+	}
+	var fragments []*packet.LpPacket
 	startIdx := 0
 	fragmentSeq := 0
 	fragmentNum := bufLen / fragmentLen
@@ -186,11 +264,20 @@ func (l *LinkService) sendByteBuffer(buf []byte, bufLen int) {
 		if fragmentLen > bufLen-startIdx {
 			fragmentLen = bufLen - startIdx
 		}
-		l.sendFragment(buf[startIdx:startIdx+fragmentLen], fragmentLen, lpPacketId, uint64(fragmentNum),
-			uint64(fragmentSeq))
+		if l.reliability != nil {
+			fragments = append(fragments, newLpFragment(buf[startIdx:startIdx+fragmentLen], fragmentLen, lpPacketId,
+				uint64(fragmentNum), uint64(fragmentSeq)))
+		} else {
+			l.sendFragment(buf[startIdx:startIdx+fragmentLen], fragmentLen, lpPacketId, uint64(fragmentNum),
+				uint64(fragmentSeq))
+		}
 		startIdx += fragmentLen
 		fragmentSeq++
 	}
+	if l.reliability != nil {
+		l.reliability.SendFragments(fragments, buf[:bufLen])
+	}
+	atomic.AddUint64(&l.logicFace.logicFaceCounters.OutBytesN, uint64(bufLen))
 	//lpPacketId++
 	atomic.AddUint64(&lpPacketId, 1)
 }
@@ -330,7 +417,7 @@ func (l *LinkService) SendMINPacket(minPacket *packet.MINPacket) {
 //
 func (l *LinkService) SendEncodingAble(pkt encoding.IEncodingAble) {
 	if lpPacket, ok := pkt.(*packet.LpPacket); ok {
-		l.transport.Send(lpPacket)
+		l.sendLpPacket(lpPacket)
 		return
 	}
 	var encoder encoding.Encoder
@@ -352,6 +439,32 @@ func (l *LinkService) SendEncodingAble(pkt encoding.IEncodingAble) {
 	l.sendByteBuffer(buf, bufLen)
 }
 
+//
+// @Description: 开启链路层可靠传输，需要在 LogicFace 开始收发包之前调用
+// @receiver l
+// @param maxRetx	最大重传次数
+//
+func (l *LinkService) enableReliability(maxRetx int) {
+	l.reliability = NewLpReliability(maxRetx, &l.logicFace.logicFaceCounters, l.sendLpPacket, l.onLinkLoss)
+	l.reliability.Start()
+}
+
+//
+// @Description: 链路层可靠传输放弃一个包时调用，把包还原成 MINPacket 之后交给 logicFace 上报
+// @receiver l
+// @param payload	MIN 包的编码
+//
+func (l *LinkService) onLinkLoss(payload []byte) {
+	var lpPacket packet.LpPacket
+	lpPacket.SetValue(payload)
+	minPacket, err := getMINPacketFromLpPacket(&lpPacket)
+	if err != nil {
+		common2.LogWarn(err)
+		return
+	}
+	l.logicFace.onLinkLoss(minPacket)
+}
+
 //
 // @Description:  通过LpPacket验证用户身份
 // @param lpPacket
@@ -28,22 +28,64 @@ import (
 	"minlib/utils"
 	utils2 "mir-go/daemon/utils"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type LogicFaceType uint32
 
-var lock sync.Mutex
-
 //
 // @Description:  LogicFace的类型
 //
 const (
-	LogicFaceTypeTCP   LogicFaceType = 0
-	LogicFaceTypeUDP   LogicFaceType = 1
-	LogicFaceTypeEther LogicFaceType = 2
-	LogicFaceTypeUnix  LogicFaceType = 3
-	LogicFaceTypeInner LogicFaceType = 4
+	LogicFaceTypeTCP          LogicFaceType = 0
+	LogicFaceTypeUDP          LogicFaceType = 1
+	LogicFaceTypeEther        LogicFaceType = 2
+	LogicFaceTypeUnix         LogicFaceType = 3
+	LogicFaceTypeInner        LogicFaceType = 4
+	LogicFaceTypeTLS          LogicFaceType = 5
+	LogicFaceTypeWebSocket    LogicFaceType = 6
+	LogicFaceTypeUdpMulticast LogicFaceType = 7
+)
+
+// String 获取 LogicFace 类型的名字，eg: tcp | udp | ether
+//
+// @Description:
+// @receiver t
+// @return string
+//
+func (t LogicFaceType) String() string {
+	switch t {
+	case LogicFaceTypeTCP:
+		return "tcp"
+	case LogicFaceTypeUDP:
+		return "udp"
+	case LogicFaceTypeEther:
+		return "ether"
+	case LogicFaceTypeUnix:
+		return "unix"
+	case LogicFaceTypeInner:
+		return "inner"
+	case LogicFaceTypeTLS:
+		return "tls"
+	case LogicFaceTypeWebSocket:
+		return "ws"
+	case LogicFaceTypeUdpMulticast:
+		return "udp-multicast"
+	}
+	return "unknown"
+}
+
+// LogicFaceScope LogicFace 的作用域
+type LogicFaceScope uint32
+
+//
+// @Description:  LogicFace的作用域，Unix 和内部 LogicFace 是 local 的，TCP、UDP 和以太网 LogicFace 是 non-local 的，
+//				 只在本机有意义的前缀（例如 /localhost）下的包只能通过 local 的 LogicFace 收发
+//
+const (
+	LogicFaceScopeNonLocal LogicFaceScope = 0
+	LogicFaceScopeLocal    LogicFaceScope = 1
 )
 
 // MaxIdolTimeMs
@@ -95,23 +137,28 @@ func (l *LogicFaceMap) LoadLogicFace(key interface{}) *LogicFace {
 type LogicFace struct {
 	LogicFaceId       uint64 // logicFaceID
 	logicFaceType     LogicFaceType
-	transport         ITransport        // 与logicFace绑定的transport
+	scope             LogicFaceScope    // 作用域
 	linkService       *LinkService      // 与logicFace绑定的linkService
 	logicFaceCounters LogicFaceCounters // logicFace 流量统计对象
 	expireTime        int64             // 超时时间 ms
 	state             bool              //  true 为 up , false 为 down
 	Mtu               uint64            // 最大传输单元 MTU
 	Persistence       uint64            // 持久性, 0 表示没有持久性，会被LogicFaceSystem在一定时间后清理掉
-	//	非 0 时表示有持久性，就算一直没有收发数据，也不会被清理
+	//	非 0 时表示有持久性，就算一直没有收发数据，也不会被清理，取值见 LogicFacePersistency
+	linkDown           int32                    // 1 表示永久性的 LogicFace 连接已经断开，正在后台重连
+	redial             transportRedialer        // 重新建立连接的函数，只有主动发起连接的 TCP、UDP 和 Unix LogicFace 可以重连
 	onShutdownCallback func(logicFaceId uint64) // 传输logic face 关闭时的回调
+	onEventCallback    func(kind FaceEventKind) // logic face 启动、MTU 变化时的回调，由 LogicFaceTable 设置
+	tags               map[string]string        // 标签，例如 role=wan，转发策略可以根据标签约束下一跳
+	tagsLock           sync.RWMutex             // 保护 tags 的读写锁
 
-	sendQue chan encoding.IEncodingAble
+	sendQue *sendQueue
 	recvQue chan *packet.MINPacket
 }
 
 // GetState 获取接口状态
 //
-// @Description:
+// @Description: 返回 true 表示 LogicFace 还没有被关闭，永久性的 LogicFace 重连期间也返回 true，是否可以收发包使用 IsUp 判断
 // @receiver lf
 // @return bool
 //
@@ -119,6 +166,72 @@ func (lf *LogicFace) GetState() bool {
 	return lf.state
 }
 
+// GetLogicFaceType 获取接口类型
+//
+// @Description:
+// @receiver lf
+// @return LogicFaceType
+//
+func (lf *LogicFace) GetLogicFaceType() LogicFaceType {
+	return lf.logicFaceType
+}
+
+// GetScope 获取接口作用域
+//
+// @Description:
+// @receiver lf
+// @return LogicFaceScope
+//
+func (lf *LogicFace) GetScope() LogicFaceScope {
+	return lf.scope
+}
+
+// IsLocal 判断是否是 local 作用域的接口
+//
+// @Description:
+// @receiver lf
+// @return bool
+//
+func (lf *LogicFace) IsLocal() bool {
+	return lf.scope == LogicFaceScopeLocal
+}
+
+// IsMulticast 判断是否是多点接入的组播 LogicFace，组播 LogicFace 发出的包会被链路上所有的路由器收到
+//
+// @Description:
+// @receiver lf
+// @return bool
+//
+func (lf *LogicFace) IsMulticast() bool {
+	if lf.logicFaceType == LogicFaceTypeUdpMulticast {
+		return true
+	}
+	transport := lf.getTransport()
+	return lf.logicFaceType == LogicFaceTypeEther && transport != nil && transport.GetRemoteAddr() == EtherMulticastAddr
+}
+
+//
+// @Description: 获取与 logicFace 绑定的 transport，transport 保存在 linkService 中，永久性的 LogicFace 重连之后会被替换
+// @receiver lf
+// @return ITransport	还没有绑定 linkService 时返回 nil
+//
+func (lf *LogicFace) getTransport() ITransport {
+	if lf.linkService == nil {
+		return nil
+	}
+	return lf.linkService.getTransport()
+}
+
+//
+// @Description: 判断是否可以开启链路层可靠传输，只有点对点的 UDP 和以太网 LogicFace 可以开启，
+//		TCP、TLS、WebSocket 和 Unix 等流式的 LogicFace 由传输层保证可靠，组播 LogicFace 没有唯一的对端可以确认
+// @receiver lf
+// @return bool
+//
+func (lf *LogicFace) supportLpReliability() bool {
+	return (lf.logicFaceType == LogicFaceTypeUDP || lf.logicFaceType == LogicFaceTypeEther) && !lf.IsMulticast()
+}
+
 // Init
 // @Description: 	初始化logicFace
 // @receiver lf
@@ -127,16 +240,30 @@ func (lf *LogicFace) GetState() bool {
 // @param faceType   face类型
 //
 func (lf *LogicFace) Init(transport ITransport, linkService *LinkService, faceType LogicFaceType) {
-	lf.transport = transport
 	lf.linkService = linkService
 	lf.logicFaceType = faceType
+	lf.scope = scopeOfLogicFaceType(faceType)
 	lf.state = true
 	lf.refreshExpireTime()
 	lf.Mtu = uint64(linkService.mtu)
 	lf.Persistence = 0
+	// 根据配置文件中的 [LogicFaceTag] 给 LogicFace 打上标签
+	lf.tags = gLogicFaceSystem.configuredTags(transport.GetLocalUri(), transport.GetRemoteUri())
 
 	lf.recvQue = make(chan *packet.MINPacket, gLogicFaceSystem.config.LFRecvQueSize)
-	lf.sendQue = make(chan encoding.IEncodingAble, gLogicFaceSystem.config.LFSendQueSize)
+	lf.sendQue = newSendQueue(gLogicFaceSystem.sendQueConfig)
+}
+
+//
+// @Description: 根据接口类型获取接口的作用域
+// @param faceType
+// @return LogicFaceScope
+//
+func scopeOfLogicFaceType(faceType LogicFaceType) LogicFaceScope {
+	if faceType == LogicFaceTypeUnix || faceType == LogicFaceTypeInner {
+		return LogicFaceScopeLocal
+	}
+	return LogicFaceScopeNonLocal
 }
 
 // updateMTU 更新MTU
@@ -146,8 +273,12 @@ func (lf *LogicFace) Init(transport ITransport, linkService *LinkService, faceTy
 // @param mtu
 //
 func (lf *LogicFace) updateMTU(mtu int) {
+	changed := lf.Mtu != uint64(mtu)
 	lf.Mtu = uint64(mtu)
 	lf.linkService.mtu = mtu
+	if changed {
+		lf.onEvent(FaceEventMtuChanged)
+	}
 }
 
 //
@@ -174,6 +305,33 @@ func (lf *LogicFace) ReceivePacket(minPacket *packet.MINPacket) {
 	select {
 	case lf.recvQue <- minPacket:
 	default:
+		// 接收队列满了，丢包
+		lf.countByType(minPacket, &lf.logicFaceCounters.DropGPPktN, &lf.logicFaceCounters.DropInterestN,
+			&lf.logicFaceCounters.DropDataN)
+	}
+}
+
+//
+// @Description: 根据 MINPacket 第一个标识的类型，给对应的计数器加一
+// @receiver lf
+// @param minPacket
+// @param gPPktN	推式包计数器
+// @param interestN	兴趣包计数器
+// @param dataN	数据包计数器
+//
+func (lf *LogicFace) countByType(minPacket *packet.MINPacket, gPPktN, interestN, dataN *uint64) {
+	identifier, err := minPacket.GetIdentifier(0)
+	if err != nil {
+		common2.LogWarn(err, "face ", lf.LogicFaceId, " packet has no identifier")
+		return
+	}
+	switch identifier.GetIdentifierType() {
+	case encoding.TlvIdentifierCommon:
+		atomic.AddUint64(gPPktN, 1)
+	case encoding.TlvIdentifierContentInterest:
+		atomic.AddUint64(interestN, 1)
+	case encoding.TlvIdentifierContentData:
+		atomic.AddUint64(dataN, 1)
 	}
 }
 
@@ -189,21 +347,9 @@ func (lf *LogicFace) onReceivePacket(minPacket *packet.MINPacket) {
 		LogicFace: lf,
 		MinPacket: minPacket,
 	})
-	identifier, err := minPacket.GetIdentifier(0)
-	if err != nil {
-		common2.LogWarn(err, "face ", lf.LogicFaceId, " receive packet has no identifier")
-		return
-	}
-	if identifier.GetIdentifierType() == encoding.TlvIdentifierCommon {
-		lf.logicFaceCounters.InGPPktN++
-	} else if identifier.GetIdentifierType() == encoding.TlvIdentifierContentInterest {
-		lock.Lock()
-		lf.logicFaceCounters.InInterestN++
-		lock.Unlock()
-		//common2.LogInfo(lf.logicFaceCounters.InInterestN)
-	} else if identifier.GetIdentifierType() == encoding.TlvIdentifierContentData {
-		lf.logicFaceCounters.InDataN++
-	}
+	// 收到的 Nack 先按兴趣包统计，转发器识别出 Nack 之后通过 CountIncomingNack 修正
+	lf.countByType(minPacket, &lf.logicFaceCounters.InGPPktN, &lf.logicFaceCounters.InInterestN,
+		&lf.logicFaceCounters.InDataN)
 
 	// 更新过期时间
 	lf.refreshExpireTime()
@@ -214,8 +360,14 @@ func (lf *LogicFace) onReceivePacket(minPacket *packet.MINPacket) {
 // @receiver lf
 //
 func (lf *LogicFace) Start() {
+	// 按照配置在点对点的数据报 LogicFace 上开启链路层可靠传输，需要在开始收发包之前开启
+	if gLogicFaceSystem.config.LpReliability && lf.supportLpReliability() {
+		lf.linkService.enableReliability(gLogicFaceSystem.config.LpReliabilityMaxRetx)
+	}
+
 	// 启动收包协程
-	utils2.GoroutineNoPanic(lf.transport.Receive)
+	utils2.GoroutineNoPanic(lf.getTransport().Receive)
+	lf.onEvent(FaceEventUp)
 
 	// 启动收包协程，负责把logic face 收到的包往forwarder的队列送
 	utils2.GoroutineNoPanic(func() {
@@ -233,18 +385,23 @@ func (lf *LogicFace) Start() {
 	// 启动发包协程，负责把forwarder 发往该 logic face 的包转发出去
 	utils2.GoroutineNoPanic(func() {
 		for lf.state {
-			minPacket, ok := <-lf.sendQue
+			minPacket, ok := lf.sendQue.pop()
 			if !ok {
 				common2.LogError("read packet from send que error")
 				lf.Shutdown()
 				break
 			}
+			// 永久性的 LogicFace 重连期间丢弃队列中剩余的包
+			if atomic.LoadInt32(&lf.linkDown) == 1 {
+				continue
+			}
 			lf.linkService.SendEncodingAble(minPacket)
 		}
 	})
 
-	// 如果是持久性的 TCP LogicFace，通过心跳包来保活
-	if lf.Persistence > 0 && lf.logicFaceType == LogicFaceTypeTCP {
+	// 如果是持久性的 TCP、TLS 或 WebSocket LogicFace，通过心跳包来保活
+	if lf.Persistence > 0 && (lf.logicFaceType == LogicFaceTypeTCP || lf.logicFaceType == LogicFaceTypeTLS ||
+		lf.logicFaceType == LogicFaceTypeWebSocket) {
 		// 启动心跳包协程，周期性的往发送队列里面放一个心跳包
 		utils2.GoroutineNoPanic(func() {
 			ticker := time.NewTicker(5 * time.Second)
@@ -256,14 +413,14 @@ func (lf *LogicFace) Start() {
 				}
 
 				// 如果队列堆积较少，则发送心跳包（心跳包是一个特殊类型的 LpPacket）
-				if len(lf.sendQue) < 5 {
+				if lf.sendQue.len() < 5 {
 					heatBeatPkt := packet.NewLpPacket()
 					heatBeatPkt.SetFragmentNum(1)
 					heatBeatPkt.SetFragmentSeq(0)
 					heatBeatPkt.SetHeartBeat(true)
 					common2.LogDebug("Send heart Beat")
 					// 将心跳包加到发送队列当中
-					lf.sendQue <- heatBeatPkt
+					lf.addPkt2SendQue(heatBeatPkt)
 				}
 			}
 		})
@@ -275,15 +432,48 @@ func (lf *LogicFace) Start() {
 // @Description:
 //
 func (lf *LogicFace) refreshExpireTime() {
-	lf.expireTime = getTimestampMS() + logicFaceMaxIdolTimeMs
+	atomic.StoreInt64(&lf.expireTime, getTimestampMS()+logicFaceMaxIdolTimeMs)
 }
 
-func (lf *LogicFace) addPkt2SendQue(pkt encoding.IEncodingAble) {
-	defer send2ChanException()
-	if !lf.state {
-		return
+//
+// @Description: 把一个包放入发送队列，不会阻塞，队列满了之后按照配置的丢包策略丢包并按类型统计
+// @receiver lf
+// @param pkt
+// @return ok	是否成功放入发送队列，LogicFace 已经关闭、正在重连或者发送队列满了时返回 false
+//
+func (lf *LogicFace) addPkt2SendQue(pkt encoding.IEncodingAble) (ok bool) {
+	if !lf.IsUp() {
+		lf.countOutDrop(pkt)
+		return false
+	}
+	ok, evicted := lf.sendQue.push(pkt)
+	if !ok {
+		lf.countOutDrop(pkt)
+	}
+	if evicted != nil {
+		// 被挤出队列的兴趣包在放入时已经计入 OutInterestN，这里修正
+		lf.countOutDrop(evicted)
+		atomic.AddUint64(&lf.logicFaceCounters.OutInterestN, ^uint64(0))
+	}
+	return ok
+}
+
+//
+// @Description: 统计一个交给本接口发送但是被丢弃的包
+// @receiver lf
+// @param pkt
+//
+func (lf *LogicFace) countOutDrop(pkt encoding.IEncodingAble) {
+	switch sendPacketKindOf(pkt) {
+	case sendPacketInterest:
+		atomic.AddUint64(&lf.logicFaceCounters.OutDropInterestN, 1)
+	case sendPacketData:
+		atomic.AddUint64(&lf.logicFaceCounters.OutDropDataN, 1)
+	case sendPacketNack:
+		atomic.AddUint64(&lf.logicFaceCounters.OutDropNackN, 1)
+	case sendPacketGPPkt:
+		atomic.AddUint64(&lf.logicFaceCounters.OutDropGPPktN, 1)
 	}
-	lf.sendQue <- pkt
 }
 
 // SendMINPacket
@@ -292,7 +482,10 @@ func (lf *LogicFace) addPkt2SendQue(pkt encoding.IEncodingAble) {
 // @param packet
 //
 func (lf *LogicFace) SendMINPacket(packet *packet.MINPacket) {
-	lf.addPkt2SendQue(packet)
+	if lf.addPkt2SendQue(packet) {
+		lf.countByType(packet, &lf.logicFaceCounters.OutGPPktN, &lf.logicFaceCounters.OutInterestN,
+			&lf.logicFaceCounters.OutDataN)
+	}
 }
 
 // SendInterest
@@ -301,7 +494,9 @@ func (lf *LogicFace) SendMINPacket(packet *packet.MINPacket) {
 // @param interest
 //
 func (lf *LogicFace) SendInterest(interest *packet.Interest) {
-	lf.addPkt2SendQue(interest)
+	if lf.addPkt2SendQue(interest) {
+		atomic.AddUint64(&lf.logicFaceCounters.OutInterestN, 1)
+	}
 }
 
 // SendData
@@ -310,7 +505,9 @@ func (lf *LogicFace) SendInterest(interest *packet.Interest) {
 // @param data
 //
 func (lf *LogicFace) SendData(data *packet.Data) {
-	lf.addPkt2SendQue(data)
+	if lf.addPkt2SendQue(data) {
+		atomic.AddUint64(&lf.logicFaceCounters.OutDataN, 1)
+	}
 }
 
 // SendNack
@@ -319,7 +516,9 @@ func (lf *LogicFace) SendData(data *packet.Data) {
 // @param nack
 //
 func (lf *LogicFace) SendNack(nack *packet.Nack) {
-	lf.addPkt2SendQue(nack)
+	if lf.addPkt2SendQue(nack) {
+		atomic.AddUint64(&lf.logicFaceCounters.OutNackN, 1)
+	}
 }
 
 // SendGPPkt
@@ -328,7 +527,9 @@ func (lf *LogicFace) SendNack(nack *packet.Nack) {
 // @param gPPkt
 //
 func (lf *LogicFace) SendGPPkt(gPPkt *packet.GPPkt) {
-	lf.addPkt2SendQue(gPPkt)
+	if lf.addPkt2SendQue(gPPkt) {
+		atomic.AddUint64(&lf.logicFaceCounters.OutGPPktN, 1)
+	}
 }
 
 // GetLocalUri
@@ -337,7 +538,7 @@ func (lf *LogicFace) SendGPPkt(gPPkt *packet.GPPkt) {
 // @return string
 //
 func (lf *LogicFace) GetLocalUri() string {
-	return lf.transport.GetLocalUri()
+	return lf.getTransport().GetLocalUri()
 }
 
 // GetRemoteUri
@@ -346,7 +547,7 @@ func (lf *LogicFace) GetLocalUri() string {
 // @return string
 //
 func (lf *LogicFace) GetRemoteUri() string {
-	return lf.transport.GetRemoteUri()
+	return lf.getTransport().GetRemoteUri()
 }
 
 // Shutdown
@@ -359,16 +560,177 @@ func (lf *LogicFace) Shutdown() {
 		return
 	}
 	lf.state = false
-	close(lf.sendQue)
+	lf.sendQue.close()
 	close(lf.recvQue)
-	lf.transport.Close()
+	if lf.linkService.reliability != nil {
+		lf.linkService.reliability.Close()
+	}
+	lf.getTransport().Close()
 
 	common2.LogInfo("logic face : ", lf.LogicFaceId, " is shutdown")
 	lf.onLogicFaceShutDown()
 }
 
 func (lf *LogicFace) GetCounter() uint64 {
-	return lf.logicFaceCounters.InInterestN
+	return atomic.LoadUint64(&lf.logicFaceCounters.InInterestN)
+}
+
+// GetCounters 获取统计信息的快照
+//
+// @Description:
+// @receiver lf
+// @return LogicFaceCounters
+//
+func (lf *LogicFace) GetCounters() LogicFaceCounters {
+	return lf.logicFaceCounters.Snapshot()
+}
+
+// CountIncomingNack 统计一个从本接口流入的 Nack
+//
+// @Description:
+//	Nack 是带有 NackHeader 的兴趣包，在 LogicFace 中先按兴趣包统计，转发器识别出 Nack 之后调用本函数修正
+// @receiver lf
+//
+func (lf *LogicFace) CountIncomingNack() {
+	atomic.AddUint64(&lf.logicFaceCounters.InInterestN, ^uint64(0))
+	atomic.AddUint64(&lf.logicFaceCounters.InNackN, 1)
+}
+
+// CountDroppedGPPkt 统计一个从本接口流入后被丢弃的推式包
+//
+// @Description:
+// @receiver lf
+//
+func (lf *LogicFace) CountDroppedGPPkt() {
+	atomic.AddUint64(&lf.logicFaceCounters.DropGPPktN, 1)
+}
+
+// CountDroppedData 统计一个从本接口流入后被丢弃的数据包
+//
+// @Description:
+// @receiver lf
+//
+func (lf *LogicFace) CountDroppedData() {
+	atomic.AddUint64(&lf.logicFaceCounters.DropDataN, 1)
+}
+
+// CountDroppedNack 统计一个从本接口流入后被丢弃的 Nack
+//
+// @Description:
+// @receiver lf
+//
+func (lf *LogicFace) CountDroppedNack() {
+	atomic.AddUint64(&lf.logicFaceCounters.DropNackN, 1)
+}
+
+// GetExpireTime 获取过期时间，非持久的 LogicFace 超过这个时间没有收发数据会被清理
+//
+// @Description:
+// @receiver lf
+// @return int64	过期时间，单位（毫秒）
+//
+func (lf *LogicFace) GetExpireTime() int64 {
+	return atomic.LoadInt64(&lf.expireTime)
+}
+
+// GetSendQueueDepth 获取发送队列中等待发送的包的个数和发送队列的容量
+//
+// @Description:
+// @receiver lf
+// @return int	等待发送的包的个数
+// @return int	发送队列的容量
+//
+func (lf *LogicFace) GetSendQueueDepth() (int, int) {
+	return lf.sendQue.len(), lf.sendQue.config.capacity
+}
+
+// GetSendQueueStats 获取发送队列中每个调度类别的统计信息，按照 control、data、interest 的顺序排列
+//
+// @Description:
+// @receiver lf
+// @return []SendQueueClassStats
+//
+func (lf *LogicFace) GetSendQueueStats() []SendQueueClassStats {
+	return lf.sendQue.stats()
+}
+
+// GetRecvQueueDepth 获取接收队列中等待交给转发器的包的个数和接收队列的容量
+//
+// @Description:
+// @receiver lf
+// @return int	等待处理的包的个数
+// @return int	接收队列的容量
+//
+func (lf *LogicFace) GetRecvQueueDepth() (int, int) {
+	return len(lf.recvQue), cap(lf.recvQue)
+}
+
+// GetLpReliabilityState 获取链路层可靠传输的状态
+//
+// @Description:
+// @receiver lf
+// @return string	off 表示没有开启，negotiating 表示本端开启但是对端还没有应答，on 表示两端都开启了
+//
+func (lf *LogicFace) GetLpReliabilityState() string {
+	if lf.linkService == nil || lf.linkService.reliability == nil {
+		return "off"
+	}
+	if !lf.linkService.reliability.IsPeerEnabled() {
+		return "negotiating"
+	}
+	return "on"
+}
+
+// CountDroppedInterest 统计一个从本接口流入后被丢弃的兴趣包
+//
+// @Description:
+// @receiver lf
+//
+func (lf *LogicFace) CountDroppedInterest() {
+	atomic.AddUint64(&lf.logicFaceCounters.DropInterestN, 1)
+}
+
+// CountDroppedUnsolicitedData 统计一个从本接口流入后没有被接纳策略接纳而丢弃的未请求数据包，同时计入被丢弃的数据包
+//
+// @Description:
+// @receiver lf
+//
+func (lf *LogicFace) CountDroppedUnsolicitedData() {
+	atomic.AddUint64(&lf.logicFaceCounters.DropUnsolicitedDataN, 1)
+	atomic.AddUint64(&lf.logicFaceCounters.DropDataN, 1)
+}
+
+// CountScopeViolation 统计一个从本接口流入后因为违反作用域规则被丢弃的包，调用方还需要计入对应类型的被丢弃的包
+//
+// @Description:
+// @receiver lf
+//
+func (lf *LogicFace) CountScopeViolation() {
+	atomic.AddUint64(&lf.logicFaceCounters.ScopeViolationN, 1)
+}
+
+// CountOutScopeViolation 统计一个准备从本接口流出但是因为违反作用域规则被丢弃的包，同时计入 OutDrop* 中对应类型的计数
+//
+// @Description:
+// @receiver lf
+// @param pkt
+//
+func (lf *LogicFace) CountOutScopeViolation(pkt encoding.IEncodingAble) {
+	atomic.AddUint64(&lf.logicFaceCounters.ScopeViolationN, 1)
+	lf.countOutDrop(pkt)
+}
+
+//
+// @Description: 链路层可靠传输放弃一个从本接口发出的包时调用，把丢包上报给转发器
+// @receiver lf
+// @param minPacket
+//
+func (lf *LogicFace) onLinkLoss(minPacket *packet.MINPacket) {
+	common2.LogDebug("link loss on logicFace : ", lf.LogicFaceId, " ", lf.GetRemoteUri())
+	gLogicFaceSystem.packetValidator.ReportLinkLoss(&LinkLossReport{
+		LogicFace: lf,
+		MinPacket: minPacket,
+	})
 }
 
 // SetPersistence
@@ -380,6 +742,52 @@ func (lf *LogicFace) SetPersistence(persistence uint64) {
 	lf.Persistence = persistence
 }
 
+// SetTags 给 LogicFace 打上标签，已有的同名标签会被覆盖
+//
+// @Description:
+// @receiver lf
+// @param tags
+//
+func (lf *LogicFace) SetTags(tags map[string]string) {
+	lf.tagsLock.Lock()
+	defer lf.tagsLock.Unlock()
+	if lf.tags == nil {
+		lf.tags = make(map[string]string, len(tags))
+	}
+	for key, value := range tags {
+		lf.tags[key] = value
+	}
+}
+
+// GetTags 获取 LogicFace 所有标签的拷贝
+//
+// @Description:
+// @receiver lf
+// @return map[string]string
+//
+func (lf *LogicFace) GetTags() map[string]string {
+	lf.tagsLock.RLock()
+	defer lf.tagsLock.RUnlock()
+	tags := make(map[string]string, len(lf.tags))
+	for key, value := range lf.tags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// MatchTags 判断 LogicFace 的标签是否满足标签表达式
+//
+// @Description:
+// @receiver lf
+// @param expression
+// @return bool
+//
+func (lf *LogicFace) MatchTags(expression *TagExpression) bool {
+	lf.tagsLock.RLock()
+	defer lf.tagsLock.RUnlock()
+	return expression.Match(lf.tags)
+}
+
 func (lf *LogicFace) onLogicFaceShutDown() {
 	lf.state = false
 	if lf.onShutdownCallback != nil {
@@ -390,3 +798,18 @@ func (lf *LogicFace) onLogicFaceShutDown() {
 func (lf *LogicFace) SetOnShutdownCallback(callback func(logicFaceId uint64)) {
 	lf.onShutdownCallback = callback
 }
+
+//
+// @Description: 设置 logic face 启动、MTU 变化时的回调
+// @receiver lf
+// @param callback
+//
+func (lf *LogicFace) setOnEventCallback(callback func(kind FaceEventKind)) {
+	lf.onEventCallback = callback
+}
+
+func (lf *LogicFace) onEvent(kind FaceEventKind) {
+	if lf.onEventCallback != nil {
+		lf.onEventCallback(kind)
+	}
+}
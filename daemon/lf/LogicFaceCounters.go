@@ -21,8 +21,11 @@
 //
 package lf
 
+import "sync/atomic"
+
 // LogicFaceCounters
 // @Description: 统计信息对象，其关键成员有以下几个,用于统计一个logicFace的流量信息
+//		所有计数器都只能通过 sync/atomic 读写，读取全部计数器时使用 Snapshot
 //
 type LogicFaceCounters struct {
 	InGPPktN      uint64 // 从本接口流入的普通推式包的个数
@@ -37,6 +40,49 @@ type LogicFaceCounters struct {
 	InNackN       uint64 // 从本接口流入的Nack包的个数
 	OutNackN      uint64 // 从本接口流出的Nack包的个数
 	DropNackN     uint64 // 从本接口流入后被丢弃的Nack包的个数
-	InBytesN      uint64 // 从本接口流入的数据字节数
-	OutBytesN     uint64 // 从本接口流出的数据字节数
+	InBytesN      uint64 // 从本接口流入的数据字节数（MIN 包编码后的字节数，不包括 lpPacket 头部）
+	OutBytesN     uint64 // 从本接口流出的数据字节数（MIN 包编码后的字节数，不包括 lpPacket 头部）
+
+	ScopeViolationN      uint64 // 违反作用域规则被丢弃的包的个数，包括从本接口流入的包和准备从本接口流出的包
+	DropUnsolicitedDataN uint64 // 从本接口流入后没有被接纳策略接纳而丢弃的未请求数据包的个数
+	LpRetransmitN        uint64 // 链路层可靠传输重传的分片个数
+	LpLossN              uint64 // 链路层可靠传输超过最大重传次数后放弃的包的个数
+
+	OutDropInterestN uint64 // 交给本接口发送但是因为发送队列满了、LogicFace 不可用或者违反作用域规则被丢弃的兴趣包的个数，不计入 OutInterestN
+	OutDropDataN     uint64 // 交给本接口发送但是因为发送队列满了、LogicFace 不可用或者违反作用域规则被丢弃的数据包的个数，不计入 OutDataN
+	OutDropNackN     uint64 // 交给本接口发送但是因为发送队列满了、LogicFace 不可用或者违反作用域规则被丢弃的Nack包的个数，不计入 OutNackN
+	OutDropGPPktN    uint64 // 交给本接口发送但是因为发送队列满了、LogicFace 不可用或者违反作用域规则被丢弃的普通推式包的个数，不计入 OutGPPktN
+}
+
+// Snapshot 获取所有计数器的一份快照
+//
+// @Description:
+// @receiver c
+// @return LogicFaceCounters
+//
+func (c *LogicFaceCounters) Snapshot() LogicFaceCounters {
+	return LogicFaceCounters{
+		InGPPktN:             atomic.LoadUint64(&c.InGPPktN),
+		OutGPPktN:            atomic.LoadUint64(&c.OutGPPktN),
+		DropGPPktN:           atomic.LoadUint64(&c.DropGPPktN),
+		InInterestN:          atomic.LoadUint64(&c.InInterestN),
+		OutInterestN:         atomic.LoadUint64(&c.OutInterestN),
+		DropInterestN:        atomic.LoadUint64(&c.DropInterestN),
+		InDataN:              atomic.LoadUint64(&c.InDataN),
+		OutDataN:             atomic.LoadUint64(&c.OutDataN),
+		DropDataN:            atomic.LoadUint64(&c.DropDataN),
+		InNackN:              atomic.LoadUint64(&c.InNackN),
+		OutNackN:             atomic.LoadUint64(&c.OutNackN),
+		DropNackN:            atomic.LoadUint64(&c.DropNackN),
+		InBytesN:             atomic.LoadUint64(&c.InBytesN),
+		OutBytesN:            atomic.LoadUint64(&c.OutBytesN),
+		ScopeViolationN:      atomic.LoadUint64(&c.ScopeViolationN),
+		DropUnsolicitedDataN: atomic.LoadUint64(&c.DropUnsolicitedDataN),
+		LpRetransmitN:        atomic.LoadUint64(&c.LpRetransmitN),
+		LpLossN:              atomic.LoadUint64(&c.LpLossN),
+		OutDropInterestN:     atomic.LoadUint64(&c.OutDropInterestN),
+		OutDropDataN:         atomic.LoadUint64(&c.OutDropDataN),
+		OutDropNackN:         atomic.LoadUint64(&c.OutDropNackN),
+		OutDropGPPktN:        atomic.LoadUint64(&c.OutDropGPPktN),
+	}
 }
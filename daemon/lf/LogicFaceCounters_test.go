@@ -0,0 +1,75 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 22:10 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLogicFaceCounters_Snapshot(t *testing.T) {
+	var counters LogicFaceCounters
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				atomic.AddUint64(&counters.InInterestN, 1)
+				atomic.AddUint64(&counters.OutDataN, 2)
+				atomic.AddUint64(&counters.InBytesN, 100)
+				_ = counters.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+
+	snapshot := counters.Snapshot()
+	if snapshot.InInterestN != 8000 || snapshot.OutDataN != 16000 || snapshot.InBytesN != 800000 {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+	// 快照是一份拷贝，之后的修改不会影响快照
+	atomic.AddUint64(&counters.InInterestN, 1)
+	if snapshot.InInterestN != 8000 {
+		t.Fatal("snapshot should not change")
+	}
+}
+
+func TestLogicFaceType_String(t *testing.T) {
+	cases := map[LogicFaceType]string{
+		LogicFaceTypeTCP:          "tcp",
+		LogicFaceTypeUDP:          "udp",
+		LogicFaceTypeEther:        "ether",
+		LogicFaceTypeUnix:         "unix",
+		LogicFaceTypeInner:        "inner",
+		LogicFaceTypeTLS:          "tls",
+		LogicFaceTypeWebSocket:    "ws",
+		LogicFaceTypeUdpMulticast: "udp-multicast",
+		LogicFaceType(100):        "unknown",
+	}
+	for faceType, name := range cases {
+		if faceType.String() != name {
+			t.Fatalf("type %d got %s, expect %s", faceType, faceType.String(), name)
+		}
+	}
+}
@@ -22,7 +22,9 @@
 package lf
 
 import (
+	"crypto/tls"
 	"github.com/google/gopacket/pcap"
+	"golang.org/x/net/websocket"
 	"minlib/logicface"
 	"minlib/packet"
 	"net"
@@ -61,10 +63,12 @@ func createEtherLogicFace(ifName string, localMacAddr, remoteMacAddr net.Hardwar
 //
 // @Description: 创建一个TCP类型的LogicFace
 // @param conn	TCP连接句柄
+// @param persistency	持久性
+// @param redial	重新建立连接的函数，被动接受的连接为 nil
 // @return *LogicFace	LogicFace指针
 // @return uint64		    LogicFace ID号
 //
-func createTcpLogicFace(conn net.Conn, persistency uint64) (*LogicFace, uint64) {
+func createTcpLogicFace(conn net.Conn, persistency uint64, redial transportRedialer) (*LogicFace, uint64) {
 	var tcpTransport TcpTransport
 	var linkService LinkService
 	var logicFace0 LogicFace
@@ -79,6 +83,93 @@ func createTcpLogicFace(conn net.Conn, persistency uint64) (*LogicFace, uint64)
 
 	logicFace0.Init(&tcpTransport, &linkService, LogicFaceTypeTCP)
 	logicFace0.Persistence = persistency
+	logicFace0.redial = redial
+	logicFaceId := gLogicFaceSystem.logicFaceTable.AddLogicFace(&logicFace0)
+	logicFace0.Start() // 启动处理收包和发包的协程
+	return &logicFace0, logicFaceId
+}
+
+//
+// @Description: 创建一个TLS类型的LogicFace
+// @param conn	已经完成握手的TLS连接句柄
+// @param persistency	持久性
+// @return *LogicFace	LogicFace指针
+// @return uint64		    LogicFace ID号
+//
+func createTlsLogicFace(conn *tls.Conn, persistency uint64) (*LogicFace, uint64) {
+	var tlsTransport TlsTransport
+	var linkService LinkService
+	var logicFace0 LogicFace
+
+	tlsTransport.Init(conn)
+	linkService.Init(9000)
+
+	linkService.transport = &tlsTransport
+	linkService.logicFace = &logicFace0
+
+	tlsTransport.linkService = &linkService
+
+	logicFace0.Init(&tlsTransport, &linkService, LogicFaceTypeTLS)
+	logicFace0.Persistence = persistency
+	logicFaceId := gLogicFaceSystem.logicFaceTable.AddLogicFace(&logicFace0)
+	logicFace0.Start() // 启动处理收包和发包的协程
+	return &logicFace0, logicFaceId
+}
+
+//
+// @Description: 创建一个WebSocket类型的LogicFace
+// @param conn	已经完成握手的WebSocket连接句柄
+// @param localAddr	本地地址
+// @param remoteAddr	对端地址
+// @param persistency	持久性
+// @return *LogicFace	LogicFace指针
+// @return uint64		    LogicFace ID号
+//
+func createWebSocketLogicFace(conn *websocket.Conn, localAddr string, remoteAddr string, persistency uint64) (*LogicFace, uint64) {
+	var webSocketTransport WebSocketTransport
+	var linkService LinkService
+	var logicFace0 LogicFace
+
+	webSocketTransport.Init(conn, localAddr, remoteAddr)
+	linkService.Init(9000)
+
+	linkService.transport = &webSocketTransport
+	linkService.logicFace = &logicFace0
+
+	webSocketTransport.linkService = &linkService
+
+	logicFace0.Init(&webSocketTransport, &linkService, LogicFaceTypeWebSocket)
+	logicFace0.Persistence = persistency
+	logicFaceId := gLogicFaceSystem.logicFaceTable.AddLogicFace(&logicFace0)
+	logicFace0.Start() // 启动处理收包和发包的协程
+	return &logicFace0, logicFaceId
+}
+
+//
+// @Description: 创建一个UDP组播类型的LogicFace
+//				UDP组播类型的LogicFace 和以太网组播 LogicFace 一样默认都是带有 Persistence 属性的
+// @param conn	通过 net.ListenMulticastUDP 创建的 UDP 句柄
+// @param ifName	网卡名
+// @param groupAddr	组播地址
+// @param mtu
+// @return *LogicFace	LogicFace指针
+// @return uint64		    LogicFace ID号
+//
+func createUdpMulticastLogicFace(conn *net.UDPConn, ifName string, groupAddr *net.UDPAddr, mtu int) (*LogicFace, uint64) {
+	var udpMulticastTransport UdpMulticastTransport
+	var linkService LinkService
+	var logicFace0 LogicFace
+
+	udpMulticastTransport.Init(conn, ifName, groupAddr)
+	linkService.Init(mtu)
+
+	linkService.transport = &udpMulticastTransport
+	linkService.logicFace = &logicFace0
+
+	udpMulticastTransport.linkService = &linkService
+
+	logicFace0.Init(&udpMulticastTransport, &linkService, LogicFaceTypeUdpMulticast)
+	logicFace0.SetPersistence(1) // 设置该Face是一直不会被因为没收发数据而被清理
 	logicFaceId := gLogicFaceSystem.logicFaceTable.AddLogicFace(&logicFace0)
 	logicFace0.Start() // 启动处理收包和发包的协程
 	return &logicFace0, logicFaceId
@@ -88,10 +179,11 @@ func createTcpLogicFace(conn net.Conn, persistency uint64) (*LogicFace, uint64)
 // @Description: 创建一个unix socket类型的LogicFace
 //				UnixSocket类型 的LogicFace 默认都是带有 Persistence 属性的
 // @param conn	unix socket 连接句柄
+// @param redial	重新建立连接的函数，被动接受的连接为 nil
 // @return *LogicFace	LogicFace指针
 // @return uint64		    LogicFace ID号
 //
-func createUnixLogicFace(conn net.Conn) (*LogicFace, uint64) {
+func createUnixLogicFace(conn net.Conn, redial transportRedialer) (*LogicFace, uint64) {
 	var unixTransport UnixStreamTransport
 	var linkService LinkService
 	var logicFace0 LogicFace
@@ -106,6 +198,7 @@ func createUnixLogicFace(conn net.Conn) (*LogicFace, uint64) {
 
 	logicFace0.Init(&unixTransport, &linkService, LogicFaceTypeUnix)
 	logicFace0.SetPersistence(1)
+	logicFace0.redial = redial
 	logicFaceId := gLogicFaceSystem.logicFaceTable.AddLogicFace(&logicFace0)
 	logicFace0.Start() // 启动处理收包和发包的协程
 	return &logicFace0, logicFaceId
@@ -115,10 +208,11 @@ func createUnixLogicFace(conn net.Conn) (*LogicFace, uint64) {
 // @Description: 创建一个Udp类型的LogicFace，UDP类型的logicFace都是只能用来发包
 // @param conn	Udp句柄
 // @param remoteAddr	对端udp地址
+// @param redial	重新创建UDP句柄的函数
 // @return *LogicFace	LogicFace 指针
 // @return uint64		LogicFace ID号
 //
-func createUdpLogicFace(conn *net.UDPConn, remoteAddr *net.UDPAddr) (*LogicFace, uint64) {
+func createUdpLogicFace(conn *net.UDPConn, remoteAddr *net.UDPAddr, redial transportRedialer) (*LogicFace, uint64) {
 	var udpTransport UdpTransport
 	var linkService LinkService
 	var logicFace0 LogicFace
@@ -132,6 +226,7 @@ func createUdpLogicFace(conn *net.UDPConn, remoteAddr *net.UDPAddr) (*LogicFace,
 	udpTransport.linkService = &linkService
 
 	logicFace0.Init(&udpTransport, &linkService, LogicFaceTypeUDP)
+	logicFace0.redial = redial
 	logicFaceId := gLogicFaceSystem.logicFaceTable.AddLogicFace(&logicFace0)
 	logicFace0.Start() // 启动处理收包和发包的协程
 	return &logicFace0, logicFaceId
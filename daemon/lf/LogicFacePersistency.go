@@ -0,0 +1,174 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 23:00 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"fmt"
+	common2 "minlib/common"
+	"mir-go/daemon/utils"
+	"sync/atomic"
+	"time"
+)
+
+// LogicFacePersistency LogicFace 的持久性，保存在 LogicFace.Persistence 中
+type LogicFacePersistency uint64
+
+const (
+	LogicFacePersistencyOnDemand   LogicFacePersistency = 0 // 长时间没有收发数据会被清理，连接断开后销毁
+	LogicFacePersistencyPersistent LogicFacePersistency = 1 // 不会因为长时间没有收发数据被清理，连接断开后销毁
+	LogicFacePersistencyPermanent  LogicFacePersistency = 2 // 在 persistent 的基础上，连接断开后保留 LogicFaceId 和下一跳并在后台重连
+)
+
+// 永久性的 LogicFace 重连的退避时间，每次失败之后加倍，直到最大值
+var (
+	logicFaceReconnectInitialBackoff = time.Second
+	logicFaceReconnectMaxBackoff     = 60 * time.Second
+)
+
+// transportRedialer 重新建立连接并创建一个绑定到 linkService 的 transport
+type transportRedialer func(linkService *LinkService) (ITransport, error)
+
+// String 获取持久性的名字，eg: on-demand | persistent | permanent
+//
+// @Description: 旧版本中任意非 0 的值都表示有持久性，无法识别的值按照 persistent 处理
+// @receiver p
+// @return string
+//
+func (p LogicFacePersistency) String() string {
+	switch p {
+	case LogicFacePersistencyOnDemand:
+		return "on-demand"
+	case LogicFacePersistencyPermanent:
+		return "permanent"
+	}
+	return "persistent"
+}
+
+// ParseLogicFacePersistency 根据名字解析持久性
+//
+// @Description:
+// @param name	on-demand | persistent | permanent，persist 是 persistent 的旧写法
+// @return LogicFacePersistency
+// @return error
+//
+func ParseLogicFacePersistency(name string) (LogicFacePersistency, error) {
+	switch name {
+	case "on-demand":
+		return LogicFacePersistencyOnDemand, nil
+	case "persistent", "persist":
+		return LogicFacePersistencyPersistent, nil
+	case "permanent":
+		return LogicFacePersistencyPermanent, nil
+	}
+	return LogicFacePersistencyOnDemand, LogicFacePersistencyError{msg: fmt.Sprintf("unknown persistency %q", name)}
+}
+
+// IsPermanent 判断是否是永久性的 LogicFace
+//
+// @Description:
+// @receiver lf
+// @return bool
+//
+func (lf *LogicFace) IsPermanent() bool {
+	return LogicFacePersistency(lf.Persistence) == LogicFacePersistencyPermanent
+}
+
+// IsUp 判断 LogicFace 当前是否可以收发包，永久性的 LogicFace 在重连期间是 down 状态，但是仍然保留在 LogicFaceTable 中
+//
+// @Description:
+// @receiver lf
+// @return bool
+//
+func (lf *LogicFace) IsUp() bool {
+	return lf.state && atomic.LoadInt32(&lf.linkDown) == 0
+}
+
+//
+// @Description: transport 收发出错时调用，永久性并且可以重连的 LogicFace 在后台重连，其它 LogicFace 直接关闭
+// @receiver lf
+// @param failed	出错的 transport，重连之前的旧 transport 上报的错误会被忽略
+//
+func (lf *LogicFace) onTransportFailure(failed *Transport) {
+	if !lf.IsPermanent() || lf.redial == nil {
+		lf.Shutdown()
+		return
+	}
+	// 收包协程和发包协程可能同时发现连接断开，只有当前 transport 的第一次上报会触发重连
+	transport := lf.getTransport()
+	if current, ok := transport.(interface{ base() *Transport }); !ok || current.base() != failed {
+		return
+	}
+	if !lf.state || !atomic.CompareAndSwapInt32(&lf.linkDown, 0, 1) {
+		return
+	}
+	transport.Close()
+	common2.LogWarn("logic face : ", lf.LogicFaceId, " lost connection to ", transport.GetRemoteUri(), ", reconnecting")
+	lf.onEvent(FaceEventDown)
+	utils.GoroutineNoPanic(lf.reconnect)
+}
+
+//
+// @Description: 按照指数退避不断尝试重新建立连接，成功之后替换 transport 并恢复收发包，LogicFace 被关闭时退出
+// @receiver lf
+//
+func (lf *LogicFace) reconnect() {
+	backoff := logicFaceReconnectInitialBackoff
+	for lf.state {
+		time.Sleep(backoff)
+		if !lf.state {
+			return
+		}
+		transport, err := lf.redial(lf.linkService)
+		if err != nil {
+			backoff *= 2
+			if backoff > logicFaceReconnectMaxBackoff {
+				backoff = logicFaceReconnectMaxBackoff
+			}
+			common2.LogDebug("logic face : ", lf.LogicFaceId, " reconnect failed: ", err, ", retry in ", backoff)
+			continue
+		}
+		lf.linkService.setTransport(transport)
+		// 重连过程中 LogicFace 被关闭了，Shutdown 关闭的可能是旧的 transport
+		if !lf.state {
+			transport.Close()
+			return
+		}
+		atomic.StoreInt32(&lf.linkDown, 0)
+		lf.refreshExpireTime()
+		utils.GoroutineNoPanic(transport.Receive)
+		common2.LogInfo("logic face : ", lf.LogicFaceId, " reconnected to ", transport.GetRemoteUri())
+		lf.onEvent(FaceEventUp)
+		return
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type LogicFacePersistencyError struct {
+	msg string
+}
+
+func (l LogicFacePersistencyError) Error() string {
+	return fmt.Sprintf("LogicFacePersistencyError: %s", l.msg)
+}
@@ -0,0 +1,164 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 23:10 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"minlib/packet"
+	"mir-go/daemon/common"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 丢弃所有收到的包的 IPacketValidator
+type discardPacketValidator struct{}
+
+func (discardPacketValidator) ReceiveMINPacket(data *IncomingPacketData) {}
+
+func (discardPacketValidator) ReportLinkLoss(report *LinkLossReport) {}
+
+// 在 listener 上不断接受连接，直到 listener 被关闭
+func acceptConns(listener net.Listener, conns chan<- net.Conn) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conns <- conn
+	}
+}
+
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for " + what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestParseLogicFacePersistency(t *testing.T) {
+	for name, expect := range map[string]LogicFacePersistency{
+		"on-demand":  LogicFacePersistencyOnDemand,
+		"persist":    LogicFacePersistencyPersistent,
+		"persistent": LogicFacePersistencyPersistent,
+		"permanent":  LogicFacePersistencyPermanent,
+	} {
+		if persistency, err := ParseLogicFacePersistency(name); err != nil || persistency != expect {
+			t.Fatalf("parse %s: got %v, %v", name, persistency, err)
+		}
+	}
+	if _, err := ParseLogicFacePersistency("forever"); err == nil {
+		t.Fatal("unknown persistency should be rejected")
+	}
+	// 旧版本中任意非 0 的值都表示有持久性
+	if LogicFacePersistency(5).String() != "persistent" || LogicFacePersistencyPermanent.String() != "permanent" {
+		t.Fatal("unexpected persistency name")
+	}
+}
+
+func TestLogicFace_PermanentReconnect(t *testing.T) {
+	var config common.MIRConfig
+	config.Init()
+	var faceSystem LogicFaceSystem
+	faceSystem.Init(discardPacketValidator{}, &config)
+
+	initialBackoff, maxBackoff := logicFaceReconnectInitialBackoff, logicFaceReconnectMaxBackoff
+	logicFaceReconnectInitialBackoff, logicFaceReconnectMaxBackoff = 20*time.Millisecond, 100*time.Millisecond
+	defer func() {
+		logicFaceReconnectInitialBackoff, logicFaceReconnectMaxBackoff = initialBackoff, maxBackoff
+	}()
+
+	var lock sync.Mutex
+	var events []FaceEventKind
+	faceSystem.logicFaceTable.AddEventListener(func(event *FaceEvent) {
+		lock.Lock()
+		defer lock.Unlock()
+		events = append(events, event.Kind)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	conns := make(chan net.Conn, 4)
+	go acceptConns(listener, conns)
+
+	faceUri, err := ParseFaceUri("tcp://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logicFace, err := CreateLogicFaceByUri(faceUri, "", uint64(LogicFacePersistencyPermanent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logicFace.Shutdown()
+	logicFaceId := logicFace.LogicFaceId
+	conn := <-conns
+
+	// 关闭监听和已经建立的连接，LogicFace 保留在表中并进入重连状态
+	_ = listener.Close()
+	_ = conn.Close()
+	waitUntil(t, "logic face down", func() bool { return !logicFace.IsUp() })
+	if !logicFace.GetState() || faceSystem.logicFaceTable.GetLogicFacePtrById(logicFaceId) != logicFace {
+		t.Fatal("permanent logic face should be kept while reconnecting")
+	}
+	logicFace.SendInterest(new(packet.Interest))
+	if counters := logicFace.GetCounters(); counters.OutDropInterestN != 1 || counters.OutInterestN != 0 {
+		t.Fatalf("packets should be dropped and counted while reconnecting, got %+v", counters)
+	}
+
+	// 让重连失败几次之后在同一个地址上重新启动监听
+	time.Sleep(200 * time.Millisecond)
+	listener, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go acceptConns(listener, conns)
+
+	waitUntil(t, "logic face up", logicFace.IsUp)
+	select {
+	case conn = <-conns:
+		defer conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener should accept the reconnection")
+	}
+	if logicFace.LogicFaceId != logicFaceId || logicFace.GetRemoteUri() != "tcp://"+addr {
+		t.Fatalf("unexpected logic face %d %s after reconnect", logicFace.LogicFaceId, logicFace.GetRemoteUri())
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	expect := []FaceEventKind{FaceEventCreated, FaceEventUp, FaceEventDown, FaceEventUp}
+	if len(events) != len(expect) {
+		t.Fatalf("unexpected events %v", events)
+	}
+	for i := range expect {
+		if events[i] != expect[i] {
+			t.Fatalf("unexpected events %v", events)
+		}
+	}
+}
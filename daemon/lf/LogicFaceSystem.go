@@ -18,45 +18,92 @@
 // @Version: 1.0.0
 // @Date: 2021/3/14 下午10:04
 // @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
-//
 package lf
 
 import (
 	common2 "minlib/common"
 	"mir-go/daemon/common"
 	"mir-go/daemon/utils"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
-//
 // @Description: 全局logicFace系统
-//
 var gLogicFaceSystem *LogicFaceSystem
 
 // LogicFaceSystem
 // @Description: 启动所有类型的Face监听
 // 整个 LogicFaceSystem 的工作原理，
-//
 type LogicFaceSystem struct {
-	ethernetListener      EthernetListener
-	tcpListener           TcpListener
-	udpListener           UdpListener
-	unixListener          UnixStreamListener
-	logicFaceTable        *LogicFaceTable
-	packetValidator       IPacketValidator
-	config                *common.MIRConfig
-	cleanLogicFaceTimeVal int
+	ethernetListener       EthernetListener
+	tcpListener            TcpListener
+	tlsListener            TlsListener
+	webSocketListener      WebSocketListener
+	udpListener            UdpListener
+	unixListener           UnixStreamListener
+	logicFaceTable         *LogicFaceTable
+	packetValidator        IPacketValidator
+	config                 *common.MIRConfig
+	cleanLogicFaceTimeVal  int
+	tagRules               []*logicFaceTagRule // 配置文件中的 [LogicFaceTag]，按地址前缀从短到长排序
+	tlsSecurity            *TlsSecurity        // TLS LogicFace 使用的证书和对端认证配置，没有开启 TLS 时为 nil
+	udpMulticastLogicFaces sync.Map            // "<网卡名>@<组播地址>" => UDP 组播 LogicFace
+	sendQueConfig          *sendQueueConfig    // LogicFace 发送队列的容量、丢包策略和调度配置
+}
+
+// @Description: 配置文件 [LogicFaceTag] 中的一条规则，本地地址或者对端地址以 uriPrefix 开头的 LogicFace 会被打上 tags
+type logicFaceTagRule struct {
+	uriPrefix string
+	tags      map[string]string
 }
 
 func (l *LogicFaceSystem) LogicFaceTable() *LogicFaceTable {
 	return l.logicFaceTable
 }
 
+// SetTlsIdentityVerifier 设置 TLS 对端证书中 MIR 身份的验证函数，TLSClientAuth 为 identity 时使用
+//
+// @Description:
+// @receiver l
+// @param identityVerifier
+func (l *LogicFaceSystem) SetTlsIdentityVerifier(identityVerifier TlsIdentityVerifier) {
+	if l.tlsSecurity != nil {
+		l.tlsSecurity.SetIdentityVerifier(identityVerifier)
+	}
+}
+
+// EtherMulticastLogicFaces 获取所有网卡上的以太网组播 LogicFace，用于邻居发现
+//
+// @Description:
+// @receiver l
+// @return []*LogicFace
+func (l *LogicFaceSystem) EtherMulticastLogicFaces() []*LogicFace {
+	return l.ethernetListener.MulticastLogicFaces()
+}
+
+// UdpMulticastLogicFaces 获取所有配置的 UDP 组播 LogicFace
+//
+// @Description:
+// @receiver l
+// @return []*LogicFace
+func (l *LogicFaceSystem) UdpMulticastLogicFaces() []*LogicFace {
+	var logicFaces []*LogicFace
+	l.udpMulticastLogicFaces.Range(func(key, value interface{}) bool {
+		logicFace := value.(*LogicFace)
+		if logicFace.state {
+			logicFaces = append(logicFaces, logicFace)
+		}
+		return true
+	})
+	return logicFaces
+}
+
 // Init
 // @Description: 初始化LogicFaceSystem对象
 // @receiver l
 // @param table
-//
 func (l *LogicFaceSystem) Init(packetValidator IPacketValidator, config *common.MIRConfig) {
 	var logicFaceTable LogicFaceTable
 	logicFaceTable.Init()
@@ -67,8 +114,33 @@ func (l *LogicFaceSystem) Init(packetValidator IPacketValidator, config *common.
 	l.tcpListener.Init(config)
 	l.udpListener.Init(config)
 	l.unixListener.Init(config)
+	l.webSocketListener.Init(config)
+	if config.SupportTLS {
+		tlsSecurity, err := LoadTlsSecurity(config.TLSCertFile, config.TLSKeyFile, config.TLSCAFile, config.TLSClientAuth)
+		if err != nil {
+			common2.LogFatal(err)
+		}
+		l.tlsSecurity = tlsSecurity
+		l.tlsListener.Init(config, tlsSecurity)
+	}
+
+	sendQueConfig, err := newSendQueueConfig(config)
+	if err != nil {
+		common2.LogFatal(err)
+	}
+	l.sendQueConfig = sendQueConfig
 
 	l.cleanLogicFaceTimeVal = config.CleanLogicFaceTableTimeVal
+	for uriPrefix, tags := range config.LogicFaceConfig.LogicFaceTags {
+		l.tagRules = append(l.tagRules, &logicFaceTagRule{uriPrefix: uriPrefix, tags: tags})
+	}
+	// 更具体（更长）的地址前缀后应用，同名标签以更具体的规则为准
+	sort.Slice(l.tagRules, func(i, j int) bool {
+		if len(l.tagRules[i].uriPrefix) != len(l.tagRules[j].uriPrefix) {
+			return len(l.tagRules[i].uriPrefix) < len(l.tagRules[j].uriPrefix)
+		}
+		return l.tagRules[i].uriPrefix < l.tagRules[j].uriPrefix
+	})
 
 	gLogicFaceSystem = l
 	logicFaceMaxIdolTimeMs = int64(config.LogicFaceIdleTime)
@@ -76,10 +148,11 @@ func (l *LogicFaceSystem) Init(packetValidator IPacketValidator, config *common.
 
 // Start
 // @Description: 启动所有类型的Face监听,启用logicFace的清理协程
-//		清理协程的工作机制是：每隔300秒扫描一篇logicFaceTable中的Face，如果logicFace在状态等于false，或者logicFace的超时时间已经过期，
-//		则清理logicFace。
-// @receiver l
 //
+//	清理协程的工作机制是：每隔300秒扫描一篇logicFaceTable中的Face，如果logicFace在状态等于false，或者logicFace的超时时间已经过期，
+//	则清理logicFace。
+//
+// @receiver l
 func (l *LogicFaceSystem) Start() {
 	l.ethernetListener.Start()
 	if l.config.SupportTCP {
@@ -91,29 +164,74 @@ func (l *LogicFaceSystem) Start() {
 	if l.config.SupportUnix {
 		l.unixListener.Start()
 	}
+	if l.config.SupportTLS {
+		l.tlsListener.Start()
+	}
+	if l.config.SupportWebSocket {
+		l.webSocketListener.Start()
+	}
+	l.startUdpMulticastLogicFaces()
 	utils.GoroutineNoPanic(l.faceCleaner)
 }
 
+// @Description: 根据配置文件中的 UdpMulticast 在对应的网卡上创建 UDP 组播 LogicFace，
+//
+//	网卡不存在或者没有启动时只打印警告，不影响其它 LogicFace
+//
+// @receiver l
+func (l *LogicFaceSystem) startUdpMulticastLogicFaces() {
+	for _, entry := range l.config.UdpMulticast {
+		ifName, groupAddr, err := parseUdpMulticastEntry(entry)
+		if err != nil {
+			common2.LogWarn(err)
+			continue
+		}
+		if _, err := CreateUdpMulticastLogicFace(ifName, groupAddr.String()); err != nil {
+			common2.LogWarn("create udp multicast logic face on ", entry, " fail: ", err)
+		}
+	}
+}
+
+// @Description: 根据配置文件中的 [LogicFaceTag] 计算本地地址为 localUri、对端地址为 remoteUri 的 LogicFace 的标签
+// @receiver l
+// @param localUri
+// @param remoteUri
+// @return map[string]string
+func (l *LogicFaceSystem) configuredTags(localUri string, remoteUri string) map[string]string {
+	tags := make(map[string]string)
+	if l == nil {
+		return tags
+	}
+	for _, rule := range l.tagRules {
+		if strings.HasPrefix(localUri, rule.uriPrefix) || strings.HasPrefix(remoteUri, rule.uriPrefix) {
+			for key, value := range rule.tags {
+				tags[key] = value
+			}
+		}
+	}
+	return tags
+}
+
 func (l *LogicFaceSystem) destroyFace(logicFaceId uint64, logicFace *LogicFace) {
 	if logicFace.logicFaceType == LogicFaceTypeUDP {
-		l.udpListener.DeleteLogicFace(logicFace.transport.GetRemoteAddr())
+		l.udpListener.DeleteLogicFace(logicFace.getTransport().GetRemoteAddr())
 	} else if logicFace.logicFaceType == LogicFaceTypeEther {
-		l.ethernetListener.DeleteLogicFace(logicFace.transport.GetLocalAddr(), logicFace.transport.GetRemoteAddr())
+		l.ethernetListener.DeleteLogicFace(logicFace.getTransport().GetLocalAddr(), logicFace.getTransport().GetRemoteAddr())
+	} else if logicFace.logicFaceType == LogicFaceTypeUdpMulticast {
+		l.udpMulticastLogicFaces.Delete(logicFace.getTransport().GetLocalAddr() + "@" + logicFace.getTransport().GetRemoteAddr())
 	}
 	l.logicFaceTable.RemoveByLogicFaceId(logicFaceId)
 }
 
-//
 // @Description: 	遍历faceTable，清除过期或失效的logicFace
 // @receiver l
-//
 func (l *LogicFaceSystem) doFaceClean() {
 	curTime := getTimestampMS()
 	l.logicFaceTable.Range(func(k uint64, v *LogicFace) bool {
 		if v.state == false {
 			common2.LogInfo("1. remove LogicFace id = ", v.LogicFaceId)
 			l.destroyFace(k, v)
-		} else if v.expireTime < curTime && v.Persistence == 0 { // logicFace已经超时
+		} else if v.GetExpireTime() < curTime && v.Persistence == 0 { // logicFace已经超时
 			common2.LogInfo("2. remove LogicFace id = ", v.LogicFaceId)
 			v.Shutdown()        // 调用shutdown关闭logicFace
 			l.destroyFace(k, v) // 将logicFace从全局logicFaceTable中删除
@@ -122,10 +240,8 @@ func (l *LogicFaceSystem) doFaceClean() {
 	})
 }
 
-//
 // @Description:  由协程调用，每300秒执行一个清表操作
 // @receiver l
-//
 func (l *LogicFaceSystem) faceCleaner() {
 	for true {
 		l.doFaceClean()
@@ -134,10 +250,8 @@ func (l *LogicFaceSystem) faceCleaner() {
 	}
 }
 
-//
 // @Description: 	获取当前unix时间， 单位是 ms
 // @return int64
-//
 func getTimestampMS() int64 {
 	curTime := time.Now().UnixNano() / 1000000
 	return curTime
@@ -23,6 +23,7 @@ package lf
 
 import (
 	"minlib/utils"
+	"sync"
 )
 
 // LogicFaceTable
@@ -34,6 +35,9 @@ type LogicFaceTable struct {
 	lastId          utils.ThreadFreeUint64 // 下一个分配的 LogicFace Id
 	version         utils.ThreadFreeUint64 // 版本
 	OnEvicted       func(uint64)
+	eventLock       sync.Mutex          // 保证事件按照序列号的顺序发布
+	eventSeq        uint64              // 最近一次发布的事件的序列号
+	eventListeners  []FaceEventListener // LogicFace 生命周期事件的订阅者
 }
 
 // Init 初始化 LogicFace Table
@@ -77,7 +81,12 @@ func (l *LogicFaceTable) AddLogicFace(logicFacePtr *LogicFace) uint64 {
 	l.mLogicFaceTable.StoreLogicFace(logicFacePtr.LogicFaceId, logicFacePtr)
 	l.mSize.AddAndGet(1)
 	l.version.AddAndGet(1)
+	l.publishEvent(logicFacePtr, FaceEventCreated)
+	logicFacePtr.setOnEventCallback(func(kind FaceEventKind) {
+		l.publishEvent(logicFacePtr, kind)
+	})
 	logicFacePtr.SetOnShutdownCallback(func(logicFaceId uint64) {
+		l.publishEvent(logicFacePtr, FaceEventDown)
 		l.OnEvicted(logicFaceId)
 	})
 	return logicFacePtr.LogicFaceId
@@ -99,9 +108,13 @@ func (l *LogicFaceTable) GetLogicFacePtrById(logicFaceId uint64) *LogicFace {
 // @param logicFaceId logicFace号
 //
 func (l *LogicFaceTable) RemoveByLogicFaceId(logicFaceId uint64) {
+	logicFacePtr := l.mLogicFaceTable.LoadLogicFace(logicFaceId)
 	l.mLogicFaceTable.Delete(logicFaceId)
 	l.mSize.SubtractAndGet(1)
 	l.version.AddAndGet(1)
+	if logicFacePtr != nil {
+		l.publishEvent(logicFacePtr, FaceEventDestroyed)
+	}
 }
 
 // Range 遍历 LogicFace Table
@@ -128,3 +141,44 @@ func (l *LogicFaceTable) GetAllFaceList() []*LogicFace {
 	})
 	return faceList
 }
+
+// AddEventListener 订阅 LogicFace 生命周期事件
+//
+// @Description: 订阅之后发生的事件会按照序列号的顺序回调 listener，之前的事件不会补发
+// @receiver l
+// @param listener
+//
+func (l *LogicFaceTable) AddEventListener(listener FaceEventListener) {
+	l.eventLock.Lock()
+	defer l.eventLock.Unlock()
+	l.eventListeners = append(l.eventListeners, listener)
+}
+
+// GetLastEventSeq 获取最近一次发布的事件的序列号，还没有发布过事件时返回 0
+//
+// @Description:
+// @receiver l
+// @return uint64
+//
+func (l *LogicFaceTable) GetLastEventSeq() uint64 {
+	l.eventLock.Lock()
+	defer l.eventLock.Unlock()
+	return l.eventSeq
+}
+
+//
+// @Description: 为事件分配序列号并通知所有订阅者，分配序列号和回调在同一把锁内完成，保证订阅者看到的序列号是连续递增的
+// @receiver l
+// @param logicFacePtr
+// @param kind
+//
+func (l *LogicFaceTable) publishEvent(logicFacePtr *LogicFace, kind FaceEventKind) {
+	event := newFaceEvent(logicFacePtr, kind)
+	l.eventLock.Lock()
+	defer l.eventLock.Unlock()
+	l.eventSeq++
+	event.Seq = l.eventSeq
+	for _, listener := range l.eventListeners {
+		listener(event)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 18:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// LogicFace 标签的文本格式为 "key=value,key2=value2"，只有 key 没有 value 的标签表示 value 为空，例如 "wan"。
+// 在 face-mgmt/add 命令中，标签以查询串的形式附在对端地址后面，例如 "tcp://203.0.113.1:13899?role=wan&site=bj"。
+
+// ParseTags 解析 "key=value,key2=value2" 格式的标签
+//
+// @Description:
+// @param str
+// @return map[string]string
+// @return error
+//
+func ParseTags(str string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, item := range strings.Split(str, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		key, value := item, ""
+		if idx := strings.Index(item, "="); idx >= 0 {
+			key, value = strings.TrimSpace(item[:idx]), strings.TrimSpace(item[idx+1:])
+		}
+		if !isValidTagToken(key) || (value != "" && !isValidTagToken(value)) {
+			return nil, LogicFaceTagError{msg: "invalid tag: " + item}
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// FormatTags 把标签格式化为 "key=value,key2=value2"，按 key 排序
+//
+// @Description:
+// @param tags
+// @return string
+//
+func FormatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if tags[key] == "" {
+			items = append(items, key)
+		} else {
+			items = append(items, key+"="+tags[key])
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// SplitUriTags 把带标签查询串的地址拆分为地址和标签
+//
+// @Description:
+//	例如 "tcp://203.0.113.1:13899?role=wan&site=bj" => "tcp://203.0.113.1:13899", {role: wan, site: bj}
+// @param uri
+// @return string
+// @return map[string]string
+// @return error
+//
+func SplitUriTags(uri string) (string, map[string]string, error) {
+	idx := strings.Index(uri, "?")
+	if idx < 0 {
+		return uri, map[string]string{}, nil
+	}
+	values, err := url.ParseQuery(uri[idx+1:])
+	if err != nil {
+		return "", nil, LogicFaceTagError{msg: "invalid tags in uri " + uri + ": " + err.Error()}
+	}
+	tags := make(map[string]string)
+	for key, value := range values {
+		if !isValidTagToken(key) || len(value) != 1 || (value[0] != "" && !isValidTagToken(value[0])) {
+			return "", nil, LogicFaceTagError{msg: "invalid tag " + key + " in uri " + uri}
+		}
+		tags[key] = value[0]
+	}
+	return uri[:idx], tags, nil
+}
+
+// AppendUriTags 把标签以查询串的形式附在地址后面，是 SplitUriTags 的逆操作
+//
+// @Description:
+// @param uri
+// @param tags
+// @return string
+//
+func AppendUriTags(uri string, tags map[string]string) string {
+	if len(tags) == 0 {
+		return uri
+	}
+	values := url.Values{}
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+	return uri + "?" + values.Encode()
+}
+
+//
+// @Description: 标签的 key 和 value 只能由字母、数字和 "-_.:/" 组成
+// @param token
+// @return bool
+//
+func isValidTagToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, c := range token {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:/", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 标签表达式
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+//
+// @Description: 标签表达式中单个条件的类型
+//
+type tagOperator int
+
+const (
+	tagOperatorExists    tagOperator = iota // key		  有这个标签
+	tagOperatorNotExists                    // !key		  没有这个标签
+	tagOperatorIn                           // key=v1|v2  标签的值是 v1 或 v2
+	tagOperatorNotIn                        // key!=v1|v2 没有这个标签，或者标签的值既不是 v1 也不是 v2
+)
+
+//
+// @Description: 标签表达式中的单个条件
+//
+type tagTerm struct {
+	key      string
+	operator tagOperator
+	values   []string
+}
+
+//
+// @Description: 判断标签是否满足条件
+// @receiver t
+// @param tags
+// @return bool
+//
+func (t *tagTerm) match(tags map[string]string) bool {
+	value, ok := tags[t.key]
+	switch t.operator {
+	case tagOperatorExists:
+		return ok
+	case tagOperatorNotExists:
+		return !ok
+	case tagOperatorIn:
+		return ok && containsString(t.values, value)
+	case tagOperatorNotIn:
+		return !ok || !containsString(t.values, value)
+	}
+	return false
+}
+
+// TagExpression 标签表达式，用来约束某个前缀的包可以从哪些 LogicFace 转发出去
+//
+// @Description:
+//	表达式由逗号分隔的若干条件组成，所有条件都满足时才匹配，条件有四种形式：
+//	1. key			有这个标签，例如 "lan"
+//	2. !key			没有这个标签，例如 "!wan"
+//	3. key=v1|v2	标签的值是 v1 或 v2，例如 "role=lan|inner"
+//	4. key!=v1|v2	没有这个标签，或者标签的值既不是 v1 也不是 v2，例如 "role!=wan"
+//
+type TagExpression struct {
+	expression string
+	terms      []*tagTerm
+}
+
+// ParseTagExpression 解析标签表达式
+//
+// @Description:
+// @param expression
+// @return *TagExpression
+// @return error
+//
+func ParseTagExpression(expression string) (*TagExpression, error) {
+	tagExpression := &TagExpression{expression: strings.TrimSpace(expression)}
+	for _, item := range strings.Split(expression, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		term := &tagTerm{}
+		if idx := strings.Index(item, "!="); idx >= 0 {
+			term.key, term.operator = strings.TrimSpace(item[:idx]), tagOperatorNotIn
+			term.values = strings.Split(item[idx+2:], "|")
+		} else if idx := strings.Index(item, "="); idx >= 0 {
+			term.key, term.operator = strings.TrimSpace(item[:idx]), tagOperatorIn
+			term.values = strings.Split(item[idx+1:], "|")
+		} else if strings.HasPrefix(item, "!") {
+			term.key, term.operator = strings.TrimSpace(item[1:]), tagOperatorNotExists
+		} else {
+			term.key, term.operator = item, tagOperatorExists
+		}
+		if !isValidTagToken(term.key) {
+			return nil, LogicFaceTagError{msg: "invalid tag expression: " + item}
+		}
+		for i := range term.values {
+			if term.values[i] = strings.TrimSpace(term.values[i]); !isValidTagToken(term.values[i]) {
+				return nil, LogicFaceTagError{msg: "invalid tag expression: " + item}
+			}
+		}
+		tagExpression.terms = append(tagExpression.terms, term)
+	}
+	if len(tagExpression.terms) == 0 {
+		return nil, LogicFaceTagError{msg: "empty tag expression"}
+	}
+	return tagExpression, nil
+}
+
+// Match 判断标签是否满足表达式
+//
+// @Description:
+// @receiver e
+// @param tags
+// @return bool
+//
+func (e *TagExpression) Match(tags map[string]string) bool {
+	for _, term := range e.terms {
+		if !term.match(tags) {
+			return false
+		}
+	}
+	return true
+}
+
+// String 返回表达式的文本
+//
+// @Description:
+// @receiver e
+// @return string
+//
+func (e *TagExpression) String() string {
+	return e.expression
+}
+
+//
+// @Description: 判断 values 中是否包含 value
+// @param values
+// @param value
+// @return bool
+//
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type LogicFaceTagError struct {
+	msg string
+}
+
+func (l LogicFaceTagError) Error() string {
+	return fmt.Sprintf("LogicFaceTagError: %s", l.msg)
+}
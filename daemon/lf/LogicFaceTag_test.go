@@ -0,0 +1,108 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 19:00 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tags, err := ParseTags(" role=wan, site = bj ,lte ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"role": "wan", "site": "bj", "lte": ""}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatalf("ParseTags = %v, want %v", tags, want)
+	}
+	if got := FormatTags(tags); got != "lte,role=wan,site=bj" {
+		t.Fatalf("FormatTags = %s", got)
+	}
+	if _, err := ParseTags("role=w an"); err == nil {
+		t.Fatal("tag with space should be rejected")
+	}
+	if _, err := ParseTags("=wan"); err == nil {
+		t.Fatal("tag without key should be rejected")
+	}
+}
+
+func TestSplitUriTags(t *testing.T) {
+	tags := map[string]string{"role": "wan", "site": "bj"}
+	uri := AppendUriTags("tcp://203.0.113.1:13899", tags)
+	if uri != "tcp://203.0.113.1:13899?role=wan&site=bj" {
+		t.Fatalf("AppendUriTags = %s", uri)
+	}
+	remoteUri, parsed, err := SplitUriTags(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remoteUri != "tcp://203.0.113.1:13899" || !reflect.DeepEqual(parsed, tags) {
+		t.Fatalf("SplitUriTags = %s %v", remoteUri, parsed)
+	}
+	if remoteUri, parsed, err = SplitUriTags("ether://34:cf:f6:f8:6a:d8"); err != nil ||
+		remoteUri != "ether://34:cf:f6:f8:6a:d8" || len(parsed) != 0 {
+		t.Fatalf("SplitUriTags without tags = %s %v %v", remoteUri, parsed, err)
+	}
+	if _, _, err = SplitUriTags("udp://192.168.3.7:13899?role=wan&role=lan"); err == nil {
+		t.Fatal("duplicated tag should be rejected")
+	}
+}
+
+func TestTagExpression(t *testing.T) {
+	wan := map[string]string{"role": "wan", "site": "bj"}
+	lan := map[string]string{"role": "lan"}
+	none := map[string]string{}
+
+	cases := []struct {
+		expression string
+		tags       map[string]string
+		want       bool
+	}{
+		{"role!=wan", wan, false},
+		{"role!=wan", lan, true},
+		{"role!=wan", none, true},
+		{"role=lan|inner", lan, true},
+		{"role=lan|inner", wan, false},
+		{"role=lan|inner", none, false},
+		{"site", wan, true},
+		{"site", lan, false},
+		{"!site", lan, true},
+		{"role=wan, site=bj", wan, true},
+		{"role=wan, site=sz", wan, false},
+	}
+	for _, c := range cases {
+		expression, err := ParseTagExpression(c.expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := expression.Match(c.tags); got != c.want {
+			t.Errorf("%s.Match(%v) = %v, want %v", c.expression, c.tags, got, c.want)
+		}
+	}
+
+	for _, invalid := range []string{"", " , ", "role=", "!", "role!=wan|", "ro le=wan"} {
+		if _, err := ParseTagExpression(invalid); err == nil {
+			t.Errorf("invalid expression %q should be rejected", invalid)
+		}
+	}
+}
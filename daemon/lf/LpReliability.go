@@ -0,0 +1,549 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 21:50 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"minlib/packet"
+	utils2 "mir-go/daemon/utils"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//
+// @Description: 链路层可靠传输使用的常量
+//	1. 可靠传输帧的 lpPacket Id 最高位为 1，Value 的开头是可靠传输头部，后面才是分片数据；
+//	2. 可靠传输头部的格式为：标志位（1字节）| 序列号（8字节，有 lpReliabilityFlagSeq 标志时才有）| 确认个数（1字节）| 确认的序列号（每个8字节）
+//
+const (
+	lpReliabilityIdFlag         uint64 = 1 << 63 // lpPacket Id 中表示这是一个可靠传输帧的标志位
+	lpReliabilityFlagSeq        byte   = 1 << 0  // 帧中带有序列号，需要对端确认
+	lpReliabilityFlagCapability byte   = 1 << 1  // 能力探测，表示本端开启了可靠传输
+	lpReliabilityFlagCapAck     byte   = 1 << 2  // 对能力探测的应答
+	lpReliabilityMaxAcks               = 16      // 一个帧中最多携带的确认个数
+	lpReliabilityMaxHeadSize           = 1 + 8 + 1 + lpReliabilityMaxAcks*8
+
+	lpReliabilityTickInterval  = 20 * time.Millisecond  // 检查重传和发送独立确认的周期
+	lpReliabilityInitialRto    = time.Second            // 还没有 RTT 样本时的 RTO
+	lpReliabilityMinRto        = 200 * time.Millisecond // RTO 下限
+	lpReliabilityMaxRto        = 4 * time.Second        // RTO 上限
+	lpReliabilityProbeInterval = time.Second            // 能力探测的间隔
+	lpReliabilityMaxProbes     = 5                      // 对端一直不应答时最多发送的能力探测次数
+	lpReliabilityDedupeTime    = 10 * time.Second       // 收到的序列号保留这么长时间，用于丢弃重复的重传帧
+)
+
+//
+// @Description: 一个 MIN 包的所有分片共享的发送记录，用于在任意一个分片丢失时只上报一次丢包
+//
+type lpReliabilityRecord struct {
+	payload []byte   // MIN 包的编码
+	seqs    []uint64 // 所有分片的序列号
+	lost    bool     // 是否已经上报过丢包
+}
+
+//
+// @Description: 一个等待对端确认的帧
+//
+type lpReliabilityFrame struct {
+	lpPacket *packet.LpPacket     // 不带可靠传输头部的原始分片
+	seq      uint64               // 序列号
+	sentTime time.Time            // 最近一次发送的时间
+	retxN    int                  // 已经重传的次数
+	record   *lpReliabilityRecord // 所属 MIN 包的发送记录
+}
+
+// LpReliability 逐跳的链路层可靠传输
+//
+// @Description:
+//	1. 每个分片带上序列号发送，对端收到后通过捎带在反向数据帧中的确认或者独立的确认帧应答；
+//	2. 超过 RTO 没有被确认的分片会被重传，RTO 按照 RFC 6298 根据 RTT 样本估计，重传的帧不产生 RTT 样本（Karn 算法）；
+//	3. 重传超过 maxRetx 次仍没有被确认时，放弃该分片所属 MIN 包的所有分片，并通过 reportLoss 上报一次丢包；
+//	4. 只有两端都开启时才生效：本端开启后周期性的发送能力探测，收到对端的探测或应答后才开始给分片加上序列号，
+//	   在此之前分片按照原来的方式发送；本端没有开启时，收到的可靠传输帧会被 LinkService 直接忽略。
+//
+type LpReliability struct {
+	maxRetx     int                             // 最大重传次数
+	counters    *LogicFaceCounters              // 所属 LogicFace 的统计信息，记录重传和丢包的个数
+	send        func(lpPacket *packet.LpPacket) // 把一个 lpPacket 交给 transport 发送
+	reportLoss  func(payload []byte)            // 一个 MIN 包的分片超过最大重传次数时调用，参数为 MIN 包的编码
+	lock        sync.Mutex                      // 保护下面的所有字段
+	peerEnable  bool                            // 对端是否开启了可靠传输
+	probeN      int                             // 已经发送的能力探测次数
+	lastProbe   time.Time                       // 最近一次发送能力探测的时间
+	nextSeq     uint64                          // 下一个分片的序列号
+	unacked     map[uint64]*lpReliabilityFrame  // 序列号 => 等待确认的帧
+	acks        []uint64                        // 等待发给对端的确认
+	firstAck    time.Time                       // acks 中最早的确认产生的时间
+	recentSeqs  map[uint64]time.Time            // 最近收到的序列号 => 收到的时间，用于去重
+	srtt        time.Duration                   // 平滑 RTT
+	rttVar      time.Duration                   // RTT 偏差
+	rto         time.Duration                   // 当前的重传超时时间
+	hasRtt      bool                            // 是否已经有 RTT 样本
+	lastBackoff time.Time                       // 最近一次 RTO 加倍的时间
+	stop        chan struct{}                   // 关闭定时协程
+	closeOnce   sync.Once                       // 保证 stop 只被关闭一次
+}
+
+// NewLpReliability 创建一个链路层可靠传输对象
+//
+// @Description:
+// @param maxRetx	最大重传次数
+// @param counters	所属 LogicFace 的统计信息
+// @param send	发送一个 lpPacket 的函数
+// @param reportLoss	上报丢包的函数
+// @return *LpReliability
+//
+func NewLpReliability(maxRetx int, counters *LogicFaceCounters, send func(lpPacket *packet.LpPacket),
+	reportLoss func(payload []byte)) *LpReliability {
+	return &LpReliability{
+		maxRetx:    maxRetx,
+		counters:   counters,
+		send:       send,
+		reportLoss: reportLoss,
+		unacked:    make(map[uint64]*lpReliabilityFrame),
+		recentSeqs: make(map[uint64]time.Time),
+		rto:        lpReliabilityInitialRto,
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start 启动定时协程，负责能力探测、重传和发送独立的确认
+//
+// @Description:
+// @receiver r
+//
+func (r *LpReliability) Start() {
+	utils2.GoroutineNoPanic(func() {
+		ticker := time.NewTicker(lpReliabilityTickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case now := <-ticker.C:
+				r.onTick(now)
+			}
+		}
+	})
+}
+
+// Close 停止定时协程，丢弃所有等待确认的帧
+//
+// @Description:
+// @receiver r
+//
+func (r *LpReliability) Close() {
+	r.closeOnce.Do(func() {
+		close(r.stop)
+	})
+	r.lock.Lock()
+	r.unacked = make(map[uint64]*lpReliabilityFrame)
+	r.lock.Unlock()
+}
+
+// Reset 重新和对端协商，LogicFace 更换 transport 之后调用
+//
+// @Description:
+//	新连接的对端可能已经重启，所以清除对端开启的标志并重新发送能力探测，同时丢弃之前收到的序列号；
+//	等待确认的帧保留，重连前后是同一个对端时仍然可以被确认
+// @receiver r
+//
+func (r *LpReliability) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.peerEnable = false
+	r.probeN = 0
+	r.lastProbe = time.Time{}
+	r.forgetPeerSeqs()
+}
+
+// IsPeerEnabled 判断对端是否也开启了可靠传输，即可靠传输是否已经生效
+//
+// @Description:
+// @receiver r
+// @return bool
+//
+func (r *LpReliability) IsPeerEnabled() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.peerEnable
+}
+
+// GetRto 获取当前的重传超时时间
+//
+// @Description:
+// @receiver r
+// @return time.Duration
+//
+func (r *LpReliability) GetRto() time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.rto
+}
+
+// SendFragments 发送一个 MIN 包的所有分片
+//
+// @Description:
+//	对端还没有开启可靠传输时按照原来的方式直接发送，否则给每个分片加上序列号，并捎带上等待发送的确认
+// @receiver r
+// @param fragments	MIN 包的所有分片
+// @param payload	MIN 包的编码，丢包时用于上报
+//
+func (r *LpReliability) SendFragments(fragments []*packet.LpPacket, payload []byte) {
+	r.lock.Lock()
+	if !r.peerEnable {
+		r.lock.Unlock()
+		for _, fragment := range fragments {
+			r.send(fragment)
+		}
+		return
+	}
+	now := time.Now()
+	record := &lpReliabilityRecord{payload: append([]byte(nil), payload...)}
+	frames := make([]*packet.LpPacket, 0, len(fragments))
+	for _, fragment := range fragments {
+		frame := &lpReliabilityFrame{lpPacket: fragment, seq: r.nextSeq, sentTime: now, record: record}
+		r.nextSeq++
+		r.unacked[frame.seq] = frame
+		record.seqs = append(record.seqs, frame.seq)
+		frames = append(frames, r.encodeFrame(fragment, lpReliabilityFlagSeq, frame.seq, r.takeAcks()))
+	}
+	r.lock.Unlock()
+	for _, frame := range frames {
+		r.send(frame)
+	}
+}
+
+// ReceiveFrame 处理收到的一个可靠传输帧
+//
+// @Description:
+//	处理帧中的能力探测和确认，带有序列号的帧会被确认；返回去掉可靠传输头部之后的分片，
+//	如果这是一个纯控制帧或者重复的帧，则返回 nil
+// @receiver r
+// @param lpPacket
+// @return *packet.LpPacket
+//
+func (r *LpReliability) ReceiveFrame(lpPacket *packet.LpPacket) *packet.LpPacket {
+	flags, seq, acks, payload, err := decodeLpReliabilityHead(lpPacket.GetValue())
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	var reply *packet.LpPacket
+	r.lock.Lock()
+	if flags&lpReliabilityFlagCapability != 0 {
+		// 已经开启的对端再次发送能力探测，说明对端重新开始了协商（eg: 重启），之前收到的序列号不再有效
+		if r.peerEnable {
+			r.forgetPeerSeqs()
+		}
+		reply = r.encodeFrame(nil, lpReliabilityFlagCapAck, 0, nil)
+	}
+	if flags&(lpReliabilityFlagCapability|lpReliabilityFlagCapAck) != 0 {
+		r.peerEnable = true
+	}
+	for _, ack := range acks {
+		r.onAck(ack, now)
+	}
+	duplicate := false
+	if flags&lpReliabilityFlagSeq != 0 {
+		// 重复的帧也要确认，因为可能是之前的确认丢了
+		if len(r.acks) == 0 {
+			r.firstAck = now
+		}
+		r.acks = append(r.acks, seq)
+		_, duplicate = r.recentSeqs[seq]
+		r.recentSeqs[seq] = now
+	}
+	r.lock.Unlock()
+	if reply != nil {
+		r.send(reply)
+	}
+	if flags&lpReliabilityFlagSeq == 0 || duplicate {
+		return nil
+	}
+	fragment := packet.NewLpPacket()
+	fragment.SetId(lpPacket.GetId() &^ lpReliabilityIdFlag)
+	fragment.SetFragmentNum(lpPacket.GetFragmentNum())
+	fragment.SetFragmentSeq(lpPacket.GetFragmentSeq())
+	fragment.SetValue(payload)
+	return fragment
+}
+
+//
+// @Description: 丢弃对端之前的序列号，包括等待发给对端的确认和用于去重的序列号，由调用者加锁
+// @receiver r
+//
+func (r *LpReliability) forgetPeerSeqs() {
+	r.acks = nil
+	r.recentSeqs = make(map[uint64]time.Time)
+}
+
+//
+// @Description: 处理对端对一个序列号的确认，由调用者加锁
+// @receiver r
+// @param seq
+// @param now
+//
+func (r *LpReliability) onAck(seq uint64, now time.Time) {
+	frame, ok := r.unacked[seq]
+	if !ok {
+		return
+	}
+	delete(r.unacked, seq)
+	// Karn 算法：重传过的帧无法确定确认对应的是哪一次发送，不产生 RTT 样本
+	if frame.retxN == 0 {
+		r.updateRto(now.Sub(frame.sentTime))
+	}
+}
+
+//
+// @Description: 根据一个 RTT 样本更新 RTO（RFC 6298），由调用者加锁
+// @receiver r
+// @param rtt
+//
+func (r *LpReliability) updateRto(rtt time.Duration) {
+	if !r.hasRtt {
+		r.srtt = rtt
+		r.rttVar = rtt / 2
+		r.hasRtt = true
+	} else {
+		diff := r.srtt - rtt
+		if diff < 0 {
+			diff = -diff
+		}
+		r.rttVar = (3*r.rttVar + diff) / 4
+		r.srtt = (7*r.srtt + rtt) / 8
+	}
+	variance := 4 * r.rttVar
+	if variance < lpReliabilityTickInterval {
+		variance = lpReliabilityTickInterval
+	}
+	r.rto = clampLpReliabilityRto(r.srtt + variance)
+}
+
+//
+// @Description: 把 RTO 限制在 [lpReliabilityMinRto, lpReliabilityMaxRto] 之间
+// @param rto
+// @return time.Duration
+//
+func clampLpReliabilityRto(rto time.Duration) time.Duration {
+	if rto < lpReliabilityMinRto {
+		return lpReliabilityMinRto
+	}
+	if rto > lpReliabilityMaxRto {
+		return lpReliabilityMaxRto
+	}
+	return rto
+}
+
+//
+// @Description: 定时处理：发送能力探测、重传超时的帧、放弃超过最大重传次数的 MIN 包、发送独立的确认、清理过期的序列号
+// @receiver r
+// @param now
+//
+func (r *LpReliability) onTick(now time.Time) {
+	var frames []*packet.LpPacket
+	var lostPayloads [][]byte
+	r.lock.Lock()
+	if !r.peerEnable && r.probeN < lpReliabilityMaxProbes && now.Sub(r.lastProbe) >= lpReliabilityProbeInterval {
+		r.probeN++
+		r.lastProbe = now
+		frames = append(frames, r.encodeFrame(nil, lpReliabilityFlagCapability, 0, nil))
+	}
+
+	timeout := false
+	for seq, frame := range r.unacked {
+		if now.Sub(frame.sentTime) < r.rto {
+			continue
+		}
+		if frame.retxN >= r.maxRetx {
+			// 放弃该分片所属 MIN 包的所有分片，并且只上报一次丢包
+			for _, sibling := range frame.record.seqs {
+				delete(r.unacked, sibling)
+			}
+			if !frame.record.lost {
+				frame.record.lost = true
+				lostPayloads = append(lostPayloads, frame.record.payload)
+			}
+			continue
+		}
+		// 同一轮发送的帧超时只让 RTO 加倍一次，避免大量分片同时在途时 RTO 被连续加倍
+		if frame.sentTime.After(r.lastBackoff) {
+			timeout = true
+		}
+		frame.retxN++
+		frame.sentTime = now
+		atomic.AddUint64(&r.counters.LpRetransmitN, 1)
+		frames = append(frames, r.encodeFrame(frame.lpPacket, lpReliabilityFlagSeq, seq, r.takeAcks()))
+	}
+	// 发生超时后 RTO 加倍（指数退避），直到收到新的 RTT 样本
+	if timeout {
+		r.rto = clampLpReliabilityRto(2 * r.rto)
+		r.lastBackoff = now
+	}
+
+	if len(r.acks) > 0 && now.Sub(r.firstAck) >= lpReliabilityTickInterval {
+		for len(r.acks) > 0 {
+			frames = append(frames, r.encodeFrame(nil, 0, 0, r.takeAcks()))
+		}
+	}
+
+	for seq, recvTime := range r.recentSeqs {
+		if now.Sub(recvTime) > lpReliabilityDedupeTime {
+			delete(r.recentSeqs, seq)
+		}
+	}
+	r.lock.Unlock()
+
+	for _, frame := range frames {
+		r.send(frame)
+	}
+	for _, payload := range lostPayloads {
+		atomic.AddUint64(&r.counters.LpLossN, 1)
+		r.reportLoss(payload)
+	}
+}
+
+//
+// @Description: 取出最多 lpReliabilityMaxAcks 个等待发送的确认，由调用者加锁
+// @receiver r
+// @return []uint64
+//
+func (r *LpReliability) takeAcks() []uint64 {
+	n := len(r.acks)
+	if n > lpReliabilityMaxAcks {
+		n = lpReliabilityMaxAcks
+	}
+	acks := r.acks[:n:n]
+	r.acks = r.acks[n:]
+	if len(r.acks) == 0 {
+		r.acks = nil
+	}
+	return acks
+}
+
+//
+// @Description: 构造一个可靠传输帧
+// @receiver r
+// @param fragment	要携带的分片，为 nil 时构造一个纯控制帧
+// @param flags	标志位
+// @param seq	序列号，只有 flags 中有 lpReliabilityFlagSeq 时才会编码
+// @param acks	捎带的确认
+// @return *packet.LpPacket
+//
+func (r *LpReliability) encodeFrame(fragment *packet.LpPacket, flags byte, seq uint64, acks []uint64) *packet.LpPacket {
+	var payload []byte
+	frame := packet.NewLpPacket()
+	frame.SetId(lpReliabilityIdFlag)
+	frame.SetFragmentNum(1)
+	frame.SetFragmentSeq(0)
+	if fragment != nil {
+		payload = fragment.GetValue()
+		frame.SetId(fragment.GetId() | lpReliabilityIdFlag)
+		frame.SetFragmentNum(fragment.GetFragmentNum())
+		frame.SetFragmentSeq(fragment.GetFragmentSeq())
+	}
+	frame.SetValue(encodeLpReliabilityHead(flags, seq, acks, payload))
+	return frame
+}
+
+//
+// @Description: 判断一个 lpPacket 是否是可靠传输帧
+// @param lpPacket
+// @return bool
+//
+func isLpReliabilityFrame(lpPacket *packet.LpPacket) bool {
+	return lpPacket.GetId()&lpReliabilityIdFlag != 0
+}
+
+//
+// @Description: 编码可靠传输头部，并在后面接上分片数据
+// @param flags
+// @param seq
+// @param acks
+// @param payload
+// @return []byte
+//
+func encodeLpReliabilityHead(flags byte, seq uint64, acks []uint64, payload []byte) []byte {
+	var num [8]byte
+	buf := make([]byte, 0, lpReliabilityMaxHeadSize+len(payload))
+	buf = append(buf, flags)
+	if flags&lpReliabilityFlagSeq != 0 {
+		binary.BigEndian.PutUint64(num[:], seq)
+		buf = append(buf, num[:]...)
+	}
+	buf = append(buf, byte(len(acks)))
+	for _, ack := range acks {
+		binary.BigEndian.PutUint64(num[:], ack)
+		buf = append(buf, num[:]...)
+	}
+	return append(buf, payload...)
+}
+
+//
+// @Description: 解码可靠传输头部
+// @param buf
+// @return byte	标志位
+// @return uint64	序列号
+// @return []uint64	确认
+// @return []byte	分片数据
+// @return error
+//
+func decodeLpReliabilityHead(buf []byte) (byte, uint64, []uint64, []byte, error) {
+	if len(buf) < 2 {
+		return 0, 0, nil, nil, LpReliabilityError{msg: "frame is too short"}
+	}
+	flags := buf[0]
+	buf = buf[1:]
+	var seq uint64
+	if flags&lpReliabilityFlagSeq != 0 {
+		if len(buf) < 9 {
+			return 0, 0, nil, nil, LpReliabilityError{msg: "frame is too short"}
+		}
+		seq = binary.BigEndian.Uint64(buf)
+		buf = buf[8:]
+	}
+	ackN := int(buf[0])
+	buf = buf[1:]
+	if len(buf) < ackN*8 {
+		return 0, 0, nil, nil, LpReliabilityError{msg: "frame is too short"}
+	}
+	acks := make([]uint64, ackN)
+	for i := range acks {
+		acks[i] = binary.BigEndian.Uint64(buf[i*8:])
+	}
+	return flags, seq, acks, buf[ackN*8:], nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type LpReliabilityError struct {
+	msg string
+}
+
+func (l LpReliabilityError) Error() string {
+	return fmt.Sprintf("LpReliabilityError: %s", l.msg)
+}
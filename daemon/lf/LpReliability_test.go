@@ -0,0 +1,310 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 22:00 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"minlib/packet"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 内存中的有损链路，把两个 LpReliability 连接起来，每个方向按照 lossRate 随机丢包
+type lossyLink struct {
+	lock     sync.Mutex
+	rand     *rand.Rand
+	lossRate float64
+	dropAll  bool // 丢弃 a 发往 b 的所有帧
+
+	a, b         *LpReliability
+	aCnt, bCnt   LogicFaceCounters
+	received     map[string]int // b 收到的分片 "<分片号>/<第几块分片>" => 次数
+	losses       [][]byte       // a 上报的丢包
+	plainFrameN  int            // b 收到的不带可靠传输头部的帧数
+	deliveredToB int
+}
+
+func newLossyLink(lossRate float64, maxRetx int) *lossyLink {
+	link := &lossyLink{rand: rand.New(rand.NewSource(1)), lossRate: lossRate, received: make(map[string]int)}
+	link.a = NewLpReliability(maxRetx, &link.aCnt, link.sendToB, func(payload []byte) {
+		link.lock.Lock()
+		link.losses = append(link.losses, payload)
+		link.lock.Unlock()
+	})
+	link.b = NewLpReliability(maxRetx, &link.bCnt, link.sendToA, func(payload []byte) {})
+	return link
+}
+
+func (l *lossyLink) drop(toB bool) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if toB && l.dropAll {
+		return true
+	}
+	return l.rand.Float64() < l.lossRate
+}
+
+func (l *lossyLink) sendToB(lpPacket *packet.LpPacket) {
+	if l.drop(true) {
+		return
+	}
+	fragment := lpPacket
+	if isLpReliabilityFrame(lpPacket) {
+		if fragment = l.b.ReceiveFrame(lpPacket); fragment == nil {
+			return
+		}
+	} else {
+		l.lock.Lock()
+		l.plainFrameN++
+		l.lock.Unlock()
+	}
+	l.lock.Lock()
+	l.received[fmt.Sprintf("%d/%d", fragment.GetId(), fragment.GetFragmentSeq())]++
+	l.deliveredToB++
+	l.lock.Unlock()
+}
+
+func (l *lossyLink) sendToA(lpPacket *packet.LpPacket) {
+	if l.drop(false) {
+		return
+	}
+	if isLpReliabilityFrame(lpPacket) {
+		l.a.ReceiveFrame(lpPacket)
+	}
+}
+
+// 把一个 MIN 包切成 3 个分片
+func makeFragments(id uint64, payload []byte) []*packet.LpPacket {
+	var fragments []*packet.LpPacket
+	size := (len(payload) + 2) / 3
+	for i := 0; i < 3; i++ {
+		end := (i + 1) * size
+		if end > len(payload) {
+			end = len(payload)
+		}
+		fragments = append(fragments, newLpFragment(payload[i*size:end], end-i*size, id, 3, uint64(i)))
+	}
+	return fragments
+}
+
+func TestLpReliabilityHead(t *testing.T) {
+	buf := encodeLpReliabilityHead(lpReliabilityFlagSeq|lpReliabilityFlagCapAck, 1<<40+7, []uint64{1, 2, 1 << 62},
+		[]byte("fragment"))
+	flags, seq, acks, payload, err := decodeLpReliabilityHead(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags != lpReliabilityFlagSeq|lpReliabilityFlagCapAck || seq != 1<<40+7 || len(acks) != 3 || acks[2] != 1<<62 ||
+		string(payload) != "fragment" {
+		t.Fatalf("unexpected head: %d %d %v %s", flags, seq, acks, payload)
+	}
+
+	// 独立的确认帧没有序列号
+	flags, seq, acks, payload, err = decodeLpReliabilityHead(encodeLpReliabilityHead(0, 100, []uint64{5}, nil))
+	if err != nil || flags != 0 || seq != 0 || len(acks) != 1 || acks[0] != 5 || len(payload) != 0 {
+		t.Fatalf("unexpected ack frame: %d %d %v %v", flags, seq, acks, err)
+	}
+
+	for _, bad := range [][]byte{nil, {lpReliabilityFlagSeq, 0, 0}, {0, 2, 0, 0, 0, 0, 0, 0, 0, 1}} {
+		if _, _, _, _, err := decodeLpReliabilityHead(bad); err == nil {
+			t.Fatalf("head %v should be rejected", bad)
+		}
+	}
+}
+
+func TestLpReliability_UpdateRto(t *testing.T) {
+	r := NewLpReliability(3, &LogicFaceCounters{}, func(lpPacket *packet.LpPacket) {}, func(payload []byte) {})
+	if r.GetRto() != lpReliabilityInitialRto {
+		t.Fatalf("unexpected initial rto %v", r.GetRto())
+	}
+	r.updateRto(100 * time.Millisecond)
+	// srtt = 100ms, rttVar = 50ms, rto = srtt + 4 * rttVar
+	if r.GetRto() != 300*time.Millisecond {
+		t.Fatalf("unexpected rto %v", r.GetRto())
+	}
+	r.updateRto(100 * time.Millisecond)
+	// rttVar = 3/4 * 50ms, rto = 100ms + 4 * 37.5ms
+	if r.GetRto() != 250*time.Millisecond {
+		t.Fatalf("unexpected rto %v", r.GetRto())
+	}
+	for i := 0; i < 20; i++ {
+		r.updateRto(time.Millisecond)
+	}
+	if r.GetRto() != lpReliabilityMinRto {
+		t.Fatalf("rto should be clamped to %v, got %v", lpReliabilityMinRto, r.GetRto())
+	}
+	r.updateRto(time.Minute)
+	if r.GetRto() != lpReliabilityMaxRto {
+		t.Fatalf("rto should be clamped to %v, got %v", lpReliabilityMaxRto, r.GetRto())
+	}
+}
+
+func TestLpReliability_Negotiation(t *testing.T) {
+	link := newLossyLink(0, 3)
+
+	// 协商完成之前分片按照原来的方式发送
+	link.a.SendFragments(makeFragments(1, []byte("before negotiation")), []byte("before negotiation"))
+	if link.plainFrameN != 3 || len(link.a.unacked) != 0 {
+		t.Fatalf("fragments should be sent without reliability, plain=%d unacked=%d", link.plainFrameN,
+			len(link.a.unacked))
+	}
+
+	// a 发出能力探测，b 应答之后两端都认为对端开启了可靠传输
+	link.a.onTick(time.Now())
+	if !link.a.IsPeerEnabled() || !link.b.IsPeerEnabled() {
+		t.Fatal("negotiation should be finished")
+	}
+
+	// 没有开启可靠传输的一端收到探测后不会应答，探测次数有上限
+	var probeN int
+	r := NewLpReliability(3, &LogicFaceCounters{}, func(lpPacket *packet.LpPacket) {
+		probeN++
+	}, func(payload []byte) {})
+	now := time.Now()
+	for i := 0; i < 2*lpReliabilityMaxProbes; i++ {
+		r.onTick(now.Add(time.Duration(i) * lpReliabilityProbeInterval))
+	}
+	if probeN != lpReliabilityMaxProbes || r.IsPeerEnabled() {
+		t.Fatalf("expect %d probes, got %d", lpReliabilityMaxProbes, probeN)
+	}
+}
+
+// 对端重启之后序列号从头开始，重新协商时要丢弃之前收到的序列号，否则新的分片会被当作重复的帧丢弃
+func TestLpReliability_Renegotiation(t *testing.T) {
+	link := newLossyLink(0, 3)
+	link.a.onTick(time.Now())
+	link.a.SendFragments(makeFragments(1, []byte("before restart")), []byte("before restart"))
+
+	// a 重启之后重新发送能力探测，已经开启的 b 收到之后丢弃之前的序列号
+	link.a = NewLpReliability(3, &link.aCnt, link.sendToB, func(payload []byte) {})
+	link.a.onTick(time.Now())
+	if len(link.b.recentSeqs) != 0 {
+		t.Fatalf("recent seqs should be cleared after the peer probes again, got %d", len(link.b.recentSeqs))
+	}
+	link.a.SendFragments(makeFragments(1, []byte("after restart")), []byte("after restart"))
+	for key, n := range link.received {
+		if n != 2 {
+			t.Errorf("fragment %s should be received twice, got %d", key, n)
+		}
+	}
+
+	// 更换 transport 之后重新协商
+	link.b.Reset()
+	if link.b.IsPeerEnabled() || len(link.b.recentSeqs) != 0 {
+		t.Fatal("negotiation should be reset")
+	}
+	link.b.onTick(time.Now())
+	if !link.b.IsPeerEnabled() {
+		t.Fatal("negotiation should be finished again")
+	}
+}
+
+func TestLpReliability_LossyLink(t *testing.T) {
+	link := newLossyLink(0, 10)
+	link.a.Start()
+	link.b.Start()
+	defer link.a.Close()
+	defer link.b.Close()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !link.a.IsPeerEnabled() || !link.b.IsPeerEnabled() {
+		if time.Now().After(deadline) {
+			t.Fatal("negotiation timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 协商完成之后每个方向丢弃 20% 的帧，包括数据帧和确认帧
+	link.lock.Lock()
+	link.lossRate = 0.2
+	link.lock.Unlock()
+	const packetN = 20
+	for i := 0; i < packetN; i++ {
+		payload := bytes.Repeat([]byte{byte(i)}, 30)
+		link.a.SendFragments(makeFragments(uint64(i), payload), payload)
+	}
+
+	deadline = time.Now().Add(20 * time.Second)
+	for {
+		link.a.lock.Lock()
+		unackedN := len(link.a.unacked)
+		link.a.lock.Unlock()
+		if unackedN == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d fragments are still unacked", unackedN)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	link.lock.Lock()
+	defer link.lock.Unlock()
+	// 每个分片都只交付一次，重复的重传帧被丢弃
+	if len(link.received) != 3*packetN || link.deliveredToB != 3*packetN {
+		t.Fatalf("expect %d fragments, got %d distinct and %d delivered", 3*packetN, len(link.received),
+			link.deliveredToB)
+	}
+	if len(link.losses) != 0 || atomic.LoadUint64(&link.aCnt.LpLossN) != 0 {
+		t.Fatalf("unexpected losses: %d", len(link.losses))
+	}
+	if atomic.LoadUint64(&link.aCnt.LpRetransmitN) == 0 {
+		t.Fatal("fragments should be retransmitted on a lossy link")
+	}
+}
+
+func TestLpReliability_ReportLoss(t *testing.T) {
+	link := newLossyLink(0, 2)
+	now := time.Now()
+	link.a.onTick(now)
+	if !link.a.IsPeerEnabled() {
+		t.Fatal("negotiation should be finished")
+	}
+
+	link.dropAll = true
+	payload := []byte("lost min packet")
+	link.a.SendFragments(makeFragments(1, payload), payload)
+	now = time.Now()
+
+	// 每次超时之后 RTO 加倍，超过最大重传次数后放弃所有分片，只上报一次丢包
+	rto := link.a.GetRto()
+	for i := 0; i < 3; i++ {
+		now = now.Add(rto)
+		link.a.onTick(now)
+		rto = clampLpReliabilityRto(2 * rto)
+	}
+	if len(link.losses) != 1 || !bytes.Equal(link.losses[0], payload) {
+		t.Fatalf("expect one loss report, got %d", len(link.losses))
+	}
+	if link.aCnt.LpLossN != 1 || link.aCnt.LpRetransmitN != 3*2 || len(link.a.unacked) != 0 {
+		t.Fatalf("unexpected counters: loss=%d retransmit=%d unacked=%d", link.aCnt.LpLossN,
+			link.aCnt.LpRetransmitN, len(link.a.unacked))
+	}
+	link.a.onTick(now.Add(lpReliabilityMaxRto))
+	if len(link.losses) != 1 {
+		t.Fatal("loss should be reported only once")
+	}
+}
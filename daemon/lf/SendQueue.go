@@ -0,0 +1,489 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 23:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"fmt"
+	"minlib/component"
+	"minlib/encoding"
+	"minlib/packet"
+	"mir-go/daemon/common"
+	"strings"
+	"sync"
+)
+
+// SendQueueDropPolicy LogicFace 发送队列满了之后的丢包策略
+type SendQueueDropPolicy uint8
+
+const (
+	SendQueueDropTail           SendQueueDropPolicy = 0 // 丢弃新到达的包
+	SendQueueDropOldestInterest SendQueueDropPolicy = 1 // 优先丢弃队列中最早的兴趣包，队列中没有兴趣包时丢弃新到达的包
+)
+
+// String 获取丢包策略的名字，eg: tail-drop | drop-oldest-interest
+//
+// @Description:
+// @receiver p
+// @return string
+//
+func (p SendQueueDropPolicy) String() string {
+	switch p {
+	case SendQueueDropTail:
+		return "tail-drop"
+	case SendQueueDropOldestInterest:
+		return "drop-oldest-interest"
+	}
+	return "unknown"
+}
+
+// ParseSendQueueDropPolicy 根据名字解析丢包策略
+//
+// @Description:
+// @param name	tail-drop | drop-oldest-interest
+// @return SendQueueDropPolicy
+// @return error
+//
+func ParseSendQueueDropPolicy(name string) (SendQueueDropPolicy, error) {
+	switch name {
+	case "tail-drop":
+		return SendQueueDropTail, nil
+	case "drop-oldest-interest":
+		return SendQueueDropOldestInterest, nil
+	}
+	return SendQueueDropTail, SendQueueError{msg: fmt.Sprintf("unknown drop policy %q", name)}
+}
+
+// SendQueueClass LogicFace 发送队列中包的调度类别
+type SendQueueClass uint8
+
+const (
+	SendQueueClassControl  SendQueueClass = 0 // 心跳包和管理、路由等控制前缀下的包，严格优先发送
+	SendQueueClassData     SendQueueClass = 1 // 数据包和 Nack，与兴趣包类别按照权重分享发送机会
+	SendQueueClassInterest SendQueueClass = 2 // 兴趣包和推式包，与数据包类别按照权重分享发送机会
+	sendQueueClassNum                     = 3
+)
+
+// String 获取调度类别的名字，eg: control | data | interest
+//
+// @Description:
+// @receiver c
+// @return string
+//
+func (c SendQueueClass) String() string {
+	switch c {
+	case SendQueueClassControl:
+		return "control"
+	case SendQueueClassData:
+		return "data"
+	case SendQueueClassInterest:
+		return "interest"
+	}
+	return "unknown"
+}
+
+// SendQueueClassStats 发送队列中一个调度类别的统计信息
+//
+// @Description:
+//
+type SendQueueClassStats struct {
+	Class    string // 调度类别 control | data | interest
+	Weight   int    // 权重，严格优先的 control 类别为 0
+	Len      int    // 当前排队等待发送的包的个数
+	EnqueueN uint64 // 放入队列的包的个数
+	DequeueN uint64 // 从队列中取出交给 linkService 发送的包的个数
+	DropN    uint64 // 队列满了被丢弃的包的个数，包括被挤出队列的兴趣包
+}
+
+//
+// @Description: 发送队列中包的类型，用于按照类型统计丢包
+//
+type sendPacketKind uint8
+
+const (
+	sendPacketOther    sendPacketKind = iota // 心跳包等链路层控制包
+	sendPacketInterest                       // 兴趣包
+	sendPacketData                           // 数据包
+	sendPacketNack                           // Nack
+	sendPacketGPPkt                          // 推式包
+)
+
+//
+// @Description: 获取发送队列中一个包的类型，MINPacket 按照第一个标识的类型判断，Nack 形式的 MINPacket 按兴趣包处理
+// @param pkt
+// @return sendPacketKind
+//
+func sendPacketKindOf(pkt encoding.IEncodingAble) sendPacketKind {
+	switch p := pkt.(type) {
+	case *packet.Interest:
+		return sendPacketInterest
+	case *packet.Data:
+		return sendPacketData
+	case *packet.Nack:
+		return sendPacketNack
+	case *packet.GPPkt:
+		return sendPacketGPPkt
+	case *packet.MINPacket:
+		identifier, err := p.GetIdentifier(0)
+		if err != nil {
+			return sendPacketOther
+		}
+		switch identifier.GetIdentifierType() {
+		case encoding.TlvIdentifierCommon:
+			return sendPacketGPPkt
+		case encoding.TlvIdentifierContentInterest:
+			return sendPacketInterest
+		case encoding.TlvIdentifierContentData:
+			return sendPacketData
+		}
+	}
+	return sendPacketOther
+}
+
+//
+// @Description: 发送队列的配置，由 LogicFaceSystem 根据配置文件创建，所有 LogicFace 共用
+//
+type sendQueueConfig struct {
+	capacity        int                    // 队列容量，所有调度类别共用
+	policy          SendQueueDropPolicy    // 队列满了之后的丢包策略
+	weights         [sendQueueClassNum]int // 数据包和兴趣包类别的权重，control 类别严格优先，不使用权重
+	controlPrefixes []string               // 控制前缀，这些前缀下的包进入 control 类别
+}
+
+//
+// @Description: 根据配置文件创建发送队列的配置
+// @param config
+// @return *sendQueueConfig
+// @return error	丢包策略无法识别或者权重小于 1 时返回错误
+//
+func newSendQueueConfig(config *common.MIRConfig) (*sendQueueConfig, error) {
+	policy, err := ParseSendQueueDropPolicy(config.LFSendQueDropPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if config.LFSendQueDataWeight < 1 || config.LFSendQueInterestWeight < 1 {
+		return nil, SendQueueError{msg: fmt.Sprintf("class weights should be positive, got data=%d interest=%d",
+			config.LFSendQueDataWeight, config.LFSendQueInterestWeight)}
+	}
+	sendQueConfig := &sendQueueConfig{capacity: config.LFSendQueSize, policy: policy}
+	sendQueConfig.weights[SendQueueClassData] = config.LFSendQueDataWeight
+	sendQueConfig.weights[SendQueueClassInterest] = config.LFSendQueInterestWeight
+	for _, prefix := range config.LFSendQueControlPrefixes {
+		if prefix = strings.TrimRight(strings.TrimSpace(prefix), "/"); prefix != "" {
+			sendQueConfig.controlPrefixes = append(sendQueConfig.controlPrefixes, prefix)
+		}
+	}
+	return sendQueConfig, nil
+}
+
+//
+// @Description: 判断标识是否位于控制前缀下，前缀只在组件边界上匹配，例如 /min-mir/mgmt 不匹配 /min-mir/mgmtx
+// @receiver c
+// @param uri
+// @return bool
+//
+func (c *sendQueueConfig) isControl(uri string) bool {
+	for _, prefix := range c.controlPrefixes {
+		if uri == prefix || strings.HasPrefix(uri, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+//
+// @Description: 获取一个包的调度类别，心跳包等链路层控制包和控制前缀下的包进入 control 类别，
+//		其余的数据包和 Nack 进入 data 类别，兴趣包和推式包进入 interest 类别
+// @receiver c
+// @param pkt
+// @return SendQueueClass
+//
+func (c *sendQueueConfig) classOf(pkt encoding.IEncodingAble) SendQueueClass {
+	kind := sendPacketKindOf(pkt)
+	if kind == sendPacketOther {
+		return SendQueueClassControl
+	}
+	if len(c.controlPrefixes) > 0 && c.isControl(sendPacketUri(pkt)) {
+		return SendQueueClassControl
+	}
+	if kind == sendPacketData || kind == sendPacketNack {
+		return SendQueueClassData
+	}
+	return SendQueueClassInterest
+}
+
+//
+// @Description: 获取包的标识，用于匹配控制前缀，推式包使用目的标识，eg: 路由协议的 hello 和 LSA
+// @param pkt
+// @return string
+//
+func sendPacketUri(pkt encoding.IEncodingAble) string {
+	switch p := pkt.(type) {
+	case *packet.Interest:
+		return identifierUri(p.GetName())
+	case *packet.Data:
+		return identifierUri(p.GetName())
+	case *packet.Nack:
+		return identifierUri(p.Interest.GetName())
+	case *packet.GPPkt:
+		return identifierUri(p.DstIdentifier())
+	case *packet.MINPacket:
+		identifier, err := p.GetIdentifier(0)
+		if err != nil {
+			return ""
+		}
+		if identifier.GetIdentifierType() == encoding.TlvIdentifierCommon {
+			if gPPkt, err := packet.NewGPPktByMINPacket(p); err == nil {
+				return identifierUri(gPPkt.DstIdentifier())
+			}
+			return ""
+		}
+		return identifier.ToUri()
+	}
+	return ""
+}
+
+func identifierUri(identifier *component.Identifier) string {
+	if identifier == nil {
+		return ""
+	}
+	return identifier.ToUri()
+}
+
+//
+// @Description: 发送队列中一个调度类别的包和统计信息
+//
+type sendQueueClass struct {
+	items    []encoding.IEncodingAble // 等待发送的包，按照放入的顺序排列
+	current  int                      // 平滑加权轮询的当前值
+	enqueueN uint64
+	dequeueN uint64
+	dropN    uint64
+}
+
+//
+// @Description: LogicFace 的发送队列，转发器等多个协程往队列中放包，LogicFace 的发包协程从队列中取包。
+//		放包永远不会阻塞，队列满了之后按照丢包策略丢弃新到达的包或者队列中最早的兴趣包，
+//		这样一个发送缓慢的对端只会导致自己的 LogicFace 丢包，不会阻塞转发器处理其它 LogicFace 的包。
+//		队列中的包分为 control、data、interest 三个调度类别，所有类别共用队列容量：
+//		control 类别严格优先，其余两个类别按照权重进行平滑加权轮询，近似按包个数的加权公平队列
+//
+type sendQueue struct {
+	lock    sync.Mutex
+	config  *sendQueueConfig
+	classes [sendQueueClassNum]sendQueueClass
+	size    int           // 所有类别中等待发送的包的个数
+	notify  chan struct{} // 有新的包放入或者队列被关闭时通知发包协程
+	closed  bool
+}
+
+//
+// @Description: 创建一个发送队列
+// @param config
+// @return *sendQueue
+//
+func newSendQueue(config *sendQueueConfig) *sendQueue {
+	return &sendQueue{
+		config: config,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+//
+// @Description: 往队列中放入一个包，不会阻塞
+// @receiver q
+// @param pkt
+// @return ok	是否成功放入队列，队列满了并且没有可以丢弃的兴趣包，或者队列已经关闭时返回 false
+// @return evicted	为了放入 pkt 被丢弃的队列中最早的兴趣包，没有丢弃时为 nil
+//
+func (q *sendQueue) push(pkt encoding.IEncodingAble) (ok bool, evicted encoding.IEncodingAble) {
+	class := q.config.classOf(pkt)
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return false, nil
+	}
+	if q.size >= q.config.capacity {
+		if q.config.policy == SendQueueDropOldestInterest {
+			evicted = q.removeOldestInterest()
+		}
+		if evicted == nil {
+			q.classes[class].dropN++
+			return false, nil
+		}
+	}
+	q.classes[class].items = append(q.classes[class].items, pkt)
+	q.classes[class].enqueueN++
+	q.size++
+
+	// notify 由 close 在持有锁的情况下关闭，这里也必须在持有锁的情况下通知，否则与 LogicFace 关闭并发时会往已关闭的 chan 发送
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return true, evicted
+}
+
+//
+// @Description: 从 interest 类别中移除最早的兴趣包，需要持有锁
+// @receiver q
+// @return encoding.IEncodingAble	被移除的兴趣包，队列中没有兴趣包时返回 nil
+//
+func (q *sendQueue) removeOldestInterest() encoding.IEncodingAble {
+	class := &q.classes[SendQueueClassInterest]
+	for i, item := range class.items {
+		if sendPacketKindOf(item) == sendPacketInterest {
+			copy(class.items[i:], class.items[i+1:])
+			class.items[len(class.items)-1] = nil
+			class.items = class.items[:len(class.items)-1]
+			class.dropN++
+			q.size--
+			return item
+		}
+	}
+	return nil
+}
+
+//
+// @Description: 选择下一个发送的调度类别，需要持有锁。control 类别不为空时总是先发送 control 类别，
+//		否则在不为空的类别中进行平滑加权轮询：每个类别的当前值加上自己的权重，选出当前值最大的类别，
+//		再把它的当前值减去所有参与轮询的类别的权重之和
+// @receiver q
+// @return SendQueueClass
+// @return bool	所有类别都为空时返回 false
+//
+func (q *sendQueue) nextClass() (SendQueueClass, bool) {
+	if len(q.classes[SendQueueClassControl].items) > 0 {
+		return SendQueueClassControl, true
+	}
+	var selected SendQueueClass
+	found := false
+	totalWeight := 0
+	for class := SendQueueClassData; class < sendQueueClassNum; class++ {
+		if len(q.classes[class].items) == 0 {
+			continue
+		}
+		q.classes[class].current += q.config.weights[class]
+		totalWeight += q.config.weights[class]
+		if !found || q.classes[class].current > q.classes[selected].current {
+			selected, found = class, true
+		}
+	}
+	if found {
+		q.classes[selected].current -= totalWeight
+	}
+	return selected, found
+}
+
+//
+// @Description: 按照调度规则从队列中取出一个包，队列为空时阻塞等待，只能由一个发包协程调用
+// @receiver q
+// @return encoding.IEncodingAble
+// @return bool	队列已经关闭时返回 false
+//
+func (q *sendQueue) pop() (encoding.IEncodingAble, bool) {
+	for {
+		q.lock.Lock()
+		if q.closed {
+			q.lock.Unlock()
+			return nil, false
+		}
+		if selected, ok := q.nextClass(); ok {
+			class := &q.classes[selected]
+			pkt := class.items[0]
+			class.items[0] = nil
+			class.items = class.items[1:]
+			if len(class.items) == 0 {
+				// 类别变空之后重新开始轮询，避免积累的当前值影响之后的调度
+				class.current = 0
+			}
+			class.dequeueN++
+			q.size--
+			q.lock.Unlock()
+			return pkt, true
+		}
+		q.lock.Unlock()
+		<-q.notify
+	}
+}
+
+//
+// @Description: 获取队列中等待发送的包的个数
+// @receiver q
+// @return int
+//
+func (q *sendQueue) len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.size
+}
+
+//
+// @Description: 获取每个调度类别的统计信息
+// @receiver q
+// @return []SendQueueClassStats
+//
+func (q *sendQueue) stats() []SendQueueClassStats {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	stats := make([]SendQueueClassStats, 0, sendQueueClassNum)
+	for class := SendQueueClassControl; class < sendQueueClassNum; class++ {
+		stats = append(stats, SendQueueClassStats{
+			Class:    class.String(),
+			Weight:   q.config.weights[class],
+			Len:      len(q.classes[class].items),
+			EnqueueN: q.classes[class].enqueueN,
+			DequeueN: q.classes[class].dequeueN,
+			DropN:    q.classes[class].dropN,
+		})
+	}
+	return stats
+}
+
+//
+// @Description: 关闭队列，丢弃队列中剩余的包并唤醒发包协程，关闭之后放入的包都会被丢弃
+// @receiver q
+//
+func (q *sendQueue) close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	for class := range q.classes {
+		q.classes[class].items = nil
+	}
+	q.size = 0
+	close(q.notify)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type SendQueueError struct {
+	msg string
+}
+
+func (s SendQueueError) Error() string {
+	return fmt.Sprintf("SendQueueError: %s", s.msg)
+}
@@ -0,0 +1,317 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 23:30 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"minlib/component"
+	"minlib/packet"
+	"mir-go/daemon/common"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// release 不为 nil 时 Send 一直阻塞到 release 被关闭，模拟一个不再读取数据的对端
+type blockingTransport struct {
+	Transport
+	release chan struct{}
+	sentN   int64
+}
+
+func (b *blockingTransport) Send(lpPacket *packet.LpPacket) {
+	if b.release != nil {
+		<-b.release
+	}
+	atomic.AddInt64(&b.sentN, 1)
+}
+
+func (b *blockingTransport) Receive() {}
+
+func (b *blockingTransport) Close() {}
+
+func newBlockingLogicFace(transport *blockingTransport) *LogicFace {
+	var linkService LinkService
+	var logicFace LogicFace
+	linkService.Init(9000)
+	linkService.transport = transport
+	linkService.logicFace = &logicFace
+	transport.linkService = &linkService
+	logicFace.Init(transport, &linkService, LogicFaceTypeInner)
+	gLogicFaceSystem.logicFaceTable.AddLogicFace(&logicFace)
+	logicFace.Start()
+	return &logicFace
+}
+
+func newTestSendQueue(capacity int, policy SendQueueDropPolicy, controlPrefixes ...string) *sendQueue {
+	config := &sendQueueConfig{capacity: capacity, policy: policy, controlPrefixes: controlPrefixes}
+	config.weights[SendQueueClassData] = 2
+	config.weights[SendQueueClassInterest] = 1
+	return newSendQueue(config)
+}
+
+func newTestIdentifier(t *testing.T, name string) *component.Identifier {
+	identifier, err := component.CreateIdentifierByString(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return identifier
+}
+
+func newTestData(t *testing.T, name string) *packet.Data {
+	data := new(packet.Data)
+	data.SetName(newTestIdentifier(t, name))
+	return data
+}
+
+func newTestInterest(name string) *packet.Interest {
+	interest := new(packet.Interest)
+	interest.SetNameByString(name)
+	interest.SetCanBePrefix(true)
+	interest.SetNonce(1234)
+	return interest
+}
+
+func TestSendQueue_DropPolicy(t *testing.T) {
+	interest, data, nack := new(packet.Interest), new(packet.Data), new(packet.Nack)
+
+	tailDrop := newTestSendQueue(2, SendQueueDropTail)
+	tailDrop.push(interest)
+	tailDrop.push(data)
+	if ok, evicted := tailDrop.push(nack); ok || evicted != nil {
+		t.Fatal("tail drop should drop the new packet")
+	}
+
+	dropInterest := newTestSendQueue(2, SendQueueDropOldestInterest)
+	dropInterest.push(interest)
+	dropInterest.push(data)
+	if ok, evicted := dropInterest.push(nack); !ok || evicted != interest {
+		t.Fatal("the oldest interest should be evicted")
+	}
+	// 队列中没有兴趣包可以丢弃时丢弃新到达的包
+	if ok, evicted := dropInterest.push(new(packet.Interest)); ok || evicted != nil {
+		t.Fatal("new packet should be dropped when there is no interest in queue")
+	}
+	if pkt, _ := dropInterest.pop(); pkt != data {
+		t.Fatal("packets should be sent in order")
+	}
+	if pkt, _ := dropInterest.pop(); pkt != nack {
+		t.Fatal("packets should be sent in order")
+	}
+
+	dropInterest.close()
+	if _, ok := dropInterest.pop(); ok {
+		t.Fatal("pop should fail after close")
+	}
+	if ok, _ := dropInterest.push(data); ok {
+		t.Fatal("push should fail after close")
+	}
+
+	if _, err := ParseSendQueueDropPolicy("random-drop"); err == nil {
+		t.Fatal("unknown drop policy should be rejected")
+	}
+}
+
+func TestSendQueue_Schedule(t *testing.T) {
+	queue := newTestSendQueue(100, SendQueueDropTail, "/min-mir/mgmt")
+	for i := 0; i < 4; i++ {
+		queue.push(newTestInterest("/min/pkusz"))
+		queue.push(newTestData(t, "/min/pkusz"))
+	}
+	command := newTestInterest("/min-mir/mgmt/localhop/rib-mgmt/register")
+	heartBeat := packet.NewLpPacket()
+	queue.push(command)
+	queue.push(heartBeat)
+	// 前缀只在组件边界上匹配
+	queue.push(newTestInterest("/min-mir/mgmtx"))
+
+	// control 类别严格优先，之后数据包和兴趣包按照 2:1 的权重平滑轮询
+	if pkt, _ := queue.pop(); pkt != command {
+		t.Fatal("management packets should be sent first")
+	}
+	if pkt, _ := queue.pop(); pkt != heartBeat {
+		t.Fatal("heart beats should be sent before data and interests")
+	}
+	expect := []sendPacketKind{sendPacketData, sendPacketInterest, sendPacketData, sendPacketData, sendPacketInterest,
+		sendPacketData, sendPacketInterest, sendPacketInterest, sendPacketInterest}
+	for i, kind := range expect {
+		pkt, _ := queue.pop()
+		if sendPacketKindOf(pkt) != kind {
+			t.Fatalf("unexpected packet kind %d at %d", sendPacketKindOf(pkt), i)
+		}
+	}
+
+	stats := queue.stats()
+	if len(stats) != sendQueueClassNum || stats[0].Class != "control" || stats[0].EnqueueN != 2 ||
+		stats[0].DequeueN != 2 || stats[1].Weight != 2 || stats[1].DequeueN != 4 || stats[2].EnqueueN != 5 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// 路由协议的推式包按照目的标识匹配控制前缀，排在已经在队列中的兴趣包前面
+	routing := newTestSendQueue(100, SendQueueDropTail, "/localhop/mir-routing")
+	for i := 0; i < 4; i++ {
+		routing.push(newTestInterest("/min/pkusz"))
+	}
+	hello := new(packet.GPPkt)
+	hello.SetSrcIdentifier(newTestIdentifier(t, "/pku/r1"))
+	hello.SetDstIdentifier(newTestIdentifier(t, "/localhop/mir-routing/hello"))
+	hello.SetTTL(1)
+	routing.push(hello)
+	if pkt, _ := routing.pop(); pkt != hello {
+		t.Fatal("routing packets should be sent before queued interests")
+	}
+
+	// 队列容量由所有类别共用，丢包计入新到达的包所在的类别
+	small := newTestSendQueue(1, SendQueueDropTail)
+	small.push(newTestData(t, "/min/pkusz"))
+	small.push(newTestInterest("/min/pkusz"))
+	if stats = small.stats(); stats[SendQueueClassData].Len != 1 || stats[SendQueueClassInterest].DropN != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+// LogicFace 关闭时可能还有协程在往发送队列放包，不能因为往已关闭的 chan 发送而崩溃
+func TestSendQueue_CloseWhilePushing(t *testing.T) {
+	for round := 0; round < 100; round++ {
+		queue := newTestSendQueue(8, SendQueueDropOldestInterest)
+		popped := make(chan struct{})
+		go func() {
+			defer close(popped)
+			for {
+				if _, ok := queue.pop(); !ok {
+					return
+				}
+			}
+		}()
+		start := make(chan struct{})
+		done := make(chan struct{})
+		for i := 0; i < 4; i++ {
+			go func() {
+				defer func() { done <- struct{}{} }()
+				interest := newTestInterest("/min/pkusz")
+				<-start
+				for j := 0; j < 2000; j++ {
+					queue.push(interest)
+				}
+			}()
+		}
+		close(start)
+		// 等放包的协程都开始之后再关闭队列
+		for queue.stats()[SendQueueClassInterest].EnqueueN < 16 {
+			runtime.Gosched()
+		}
+		queue.close()
+		for i := 0; i < 4; i++ {
+			<-done
+		}
+		<-popped
+		if ok, _ := queue.push(newTestInterest("/min/pkusz")); ok || queue.len() != 0 {
+			t.Fatal("closed queue should drop all packets")
+		}
+	}
+}
+
+// 关闭 LogicFace 的同时有多个协程在发包
+func TestLogicFace_ShutdownWhileSending(t *testing.T) {
+	var config common.MIRConfig
+	config.Init()
+	config.LFSendQueSize = 8
+	var faceSystem LogicFaceSystem
+	faceSystem.Init(discardPacketValidator{}, &config)
+
+	logicFace := newBlockingLogicFace(&blockingTransport{})
+	interest := newTestInterest("/min/pkusz")
+	start := make(chan struct{})
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			<-start
+			for j := 0; j < 1000; j++ {
+				logicFace.SendInterest(interest)
+			}
+		}()
+	}
+	close(start)
+	logicFace.Shutdown()
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+	if logicFace.GetState() {
+		t.Fatal("logic face should be shut down")
+	}
+}
+
+// 一个对端停止读取数据的 LogicFace 不能阻塞转发器往其它 LogicFace 发包
+func TestLogicFace_StalledTransport(t *testing.T) {
+	var config common.MIRConfig
+	config.Init()
+	config.LFSendQueSize = 16
+	config.LFSendQueDropPolicy = "drop-oldest-interest"
+	var faceSystem LogicFaceSystem
+	faceSystem.Init(discardPacketValidator{}, &config)
+
+	stalledTransport := &blockingTransport{release: make(chan struct{})}
+	stalled := newBlockingLogicFace(stalledTransport)
+	defer close(stalledTransport.release)
+	defer stalled.Shutdown()
+	healthyTransport := &blockingTransport{}
+	healthy := newBlockingLogicFace(healthyTransport)
+	defer healthy.Shutdown()
+
+	interest := newTestInterest("/min/pkusz")
+	data := newTestData(t, "/min/pkusz")
+
+	// 模拟转发器的处理协程，交替往两个 LogicFace 发包，每个包都要在对端收到之后才处理下一个
+	const packetNum = 100
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= packetNum; i++ {
+			stalled.SendInterest(interest)
+			healthy.SendInterest(interest)
+			for atomic.LoadInt64(&healthyTransport.sentN) < int64(i) {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		stalled.SendData(data)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("forwarder is blocked by the stalled logic face")
+	}
+
+	queueLen, queueCap := stalled.GetSendQueueDepth()
+	counters := stalled.GetCounters()
+	if queueLen != queueCap || counters.OutDropInterestN == 0 ||
+		counters.OutInterestN+counters.OutDropInterestN != packetNum {
+		t.Fatalf("unexpected stalled logic face queue %d/%d, counters %+v", queueLen, queueCap, counters)
+	}
+	// 队列满了之后数据包挤掉最早的兴趣包
+	if counters.OutDataN != 1 || counters.OutDropDataN != 0 {
+		t.Fatalf("data should evict the oldest interest, counters %+v", counters)
+	}
+	if counters = healthy.GetCounters(); counters.OutInterestN != packetNum || counters.OutDropInterestN != 0 {
+		t.Fatalf("unexpected healthy logic face counters %+v", counters)
+	}
+}
@@ -67,7 +67,7 @@ func (t *StreamTransport) Send(lpPacket *packet.LpPacket) {
 		if err != nil {
 			common2.LogError(err, "send to stream transport error:",
 				err, ". remote uri: ", t.remoteUri, ", local uri: ", t.localUri)
-			t.linkService.logicFace.Shutdown()
+			t.linkService.logicFace.onTransportFailure(&t.Transport)
 			return
 		}
 		writeLen += writeRet
@@ -160,9 +160,9 @@ func (t *StreamTransport) onReceive() error {
 
 // Receive
 // @Description:  用协程调用，不断地从流式通道中读出数据
-//			（1） 从流式通道中读出数据，如果读出错，则关闭face
+//			（1） 从流式通道中读出数据，如果读出错，则关闭face，永久性的 face 会在后台重连
 //			（2） 如果读到数据，则调用onReceive尝试处理接收到的数据
-//			（3） 如果数据处理出错， 则关闭face
+//			（3） 如果数据处理出错， 则关闭face，永久性的 face 会在后台重连
 // @receiver t
 //
 func (t *StreamTransport) Receive() {
@@ -171,7 +171,7 @@ func (t *StreamTransport) Receive() {
 		if err != nil {
 			common2.LogError("recv from stream transport error,the err is:",
 				err, ". remote uri: ", t.remoteUri, ", local uri: ", t.localUri)
-			t.linkService.logicFace.Shutdown()
+			t.linkService.logicFace.onTransportFailure(&t.Transport)
 			break
 		}
 		t.recvLen += uint64(recvRet)
@@ -179,7 +179,7 @@ func (t *StreamTransport) Receive() {
 		if err != nil {
 			common2.LogError("recv from stream transport error: ", err, ". remote uri: ", t.remoteUri,
 				", local uri: ", t.localUri)
-			t.linkService.logicFace.Shutdown()
+			t.linkService.logicFace.onTransportFailure(&t.Transport)
 			break
 		}
 	}
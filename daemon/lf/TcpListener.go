@@ -26,7 +26,6 @@ import (
 	"mir-go/daemon/common"
 	"mir-go/daemon/utils"
 	"net"
-	"strconv"
 )
 
 // TcpListener
@@ -34,9 +33,10 @@ import (
 //			并启动一个TCP-Transport类型的LogicFace
 //
 type TcpListener struct {
-	TcpPort  uint16       // TCP端口号
-	listener net.Listener // TCP监听句柄
-	config   *common.MIRConfig
+	TcpPort    uint16       // TCP端口号
+	ListenAddr string       // 监听的本地地址，为空时同时监听 IPv4 和 IPv6
+	listener   net.Listener // TCP监听句柄
+	config     *common.MIRConfig
 }
 
 // Init
@@ -46,6 +46,7 @@ type TcpListener struct {
 //
 func (t *TcpListener) Init(config *common.MIRConfig) {
 	t.TcpPort = uint16(config.TCPPort)
+	t.ListenAddr = config.TCPListenAddr
 	t.config = config
 }
 
@@ -55,7 +56,7 @@ func (t *TcpListener) Init(config *common.MIRConfig) {
 // @param conn	新TCP连接句柄
 //
 func (t *TcpListener) tryCreateTcpLogicFace(conn net.Conn) {
-	createTcpLogicFace(conn, 0)
+	createTcpLogicFace(conn, 0, nil)
 }
 
 //
@@ -77,7 +78,7 @@ func (t *TcpListener) accept() {
 // @receiver t
 //
 func (t *TcpListener) Start() {
-	listener, err := net.Listen("tcp", "0.0.0.0:"+strconv.Itoa(int(t.TcpPort)))
+	listener, err := net.Listen(listenNetworkAndAddress(FaceUriSchemeTCP, t.ListenAddr, int(t.TcpPort)))
 	if err != nil {
 		common2.LogFatal(err)
 		return
@@ -0,0 +1,187 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 22:10 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"minlib/component"
+	"minlib/encoding"
+	"minlib/packet"
+	"minlib/security"
+	"time"
+)
+
+// TlsIdentityBindingOid 证书中 MIR 身份绑定扩展的 OID
+//
+// @Description:
+//	KeyChain 中的身份密钥不能直接用于 TLS 握手，所以 TLS 证书使用单独生成的密钥，再由 MIR 身份对证书的公钥签名，
+//	把二者绑定起来。扩展的值是一个以身份名命名的 Data 包，Payload 为证书的 SubjectPublicKeyInfo，
+//	使用该身份的私钥签名。
+//
+var TlsIdentityBindingOid = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 59420, 1, 1}
+
+// IssueTlsIdentityCertificate 为 KeyChain 中的 MIR 身份签发 identity 模式使用的 TLS 证书
+//
+// @Description:
+//	生成一个新的 ECDSA 密钥，证书的 CommonName 为身份名，由 caCert 签发，并带有身份对证书公钥的签名。
+//	身份必须是 KeyChain 的当前身份（已经用密码解锁），否则无法签名。
+// @param keyChain
+// @param identityName	MIR 身份名，例如 /pku/r1
+// @param caCert	签发证书的 CA，需要与对端的 TLSCAFile 一致
+// @param caKey	CA 的私钥
+// @param validity	证书有效期
+// @return []byte	PEM 格式的证书
+// @return []byte	PEM 格式的私钥
+// @return error
+//
+func IssueTlsIdentityCertificate(keyChain *security.KeyChain, identityName string, caCert *x509.Certificate,
+	caKey crypto.Signer, validity time.Duration) ([]byte, []byte, error) {
+	if currentIdentity := keyChain.GetCurrentIdentity(); currentIdentity == nil || currentIdentity.Name != identityName {
+		return nil, nil, TlsSecurityError{msg: "identity " + identityName + " is not the current identity of KeyChain"}
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKeyInfo, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	binding, err := signTlsIdentityBinding(keyChain, identityName, publicKeyInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:    serial,
+		Subject:         pkix.Name{CommonName: identityName},
+		NotBefore:       time.Now().Add(-time.Minute),
+		NotAfter:        time.Now().Add(validity),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		ExtraExtensions: []pkix.Extension{{Id: TlsIdentityBindingOid, Value: binding}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), nil
+}
+
+// NewKeyChainTlsIdentityVerifier 创建一个使用 KeyChain 验证证书中 MIR 身份的 TlsIdentityVerifier
+//
+// @Description:
+//	要求证书带有 IssueTlsIdentityCertificate 生成的身份绑定扩展，扩展中的 Data 包以证书的身份名命名，
+//	Payload 与证书的公钥相同，由该身份签名，并且该身份的证书存在于 KeyChain 中（本地创建或者导入了证书）。
+// @param keyChain
+// @return TlsIdentityVerifier
+//
+func NewKeyChainTlsIdentityVerifier(keyChain *security.KeyChain) TlsIdentityVerifier {
+	return func(identityName string, cert *x509.Certificate) error {
+		var binding []byte
+		for _, extension := range cert.Extensions {
+			if extension.Id.Equal(TlsIdentityBindingOid) {
+				binding = extension.Value
+			}
+		}
+		if binding == nil {
+			return TlsSecurityError{msg: "certificate of " + identityName + " is not bound to a MIR identity"}
+		}
+		block, err := encoding.CreateBlockByBuffer(binding, true)
+		if err != nil {
+			return err
+		}
+		var minPacket packet.MINPacket
+		if err := minPacket.WireDecode(block); err != nil {
+			return err
+		}
+		data, err := packet.NewDataByMINPacket(&minPacket)
+		if err != nil {
+			return err
+		}
+		if data.GetName().ToUri() != identityName || !bytes.Equal(data.Payload.GetValue(), cert.RawSubjectPublicKeyInfo) {
+			return TlsSecurityError{msg: "identity binding does not match the certificate of " + identityName}
+		}
+		sig, err := data.GetSignature(0)
+		if err != nil || sig == nil || sig.SigInfo == nil || sig.SigInfo.KeyLocator == nil ||
+			sig.SigInfo.KeyLocator.ToUri() != identityName {
+			return TlsSecurityError{msg: "identity binding of " + identityName + " is not signed by itself"}
+		}
+		if keyChain.GetIdentityByName(identityName) == nil {
+			return TlsSecurityError{msg: "the certificate of " + identityName + " is not in KeyChain"}
+		}
+		if err := keyChain.Verify(&minPacket); err != nil {
+			return TlsSecurityError{msg: "verify identity binding of " + identityName + " failed: " + err.Error()}
+		}
+		return nil
+	}
+}
+
+//
+// @Description: 使用 KeyChain 的当前身份对证书公钥签名，返回编码后的 Data 包
+// @param keyChain
+// @param identityName
+// @param publicKeyInfo	DER 编码的 SubjectPublicKeyInfo
+// @return []byte
+// @return error
+//
+func signTlsIdentityBinding(keyChain *security.KeyChain, identityName string, publicKeyInfo []byte) ([]byte, error) {
+	identifier, err := component.CreateIdentifierByString(identityName)
+	if err != nil {
+		return nil, err
+	}
+	data := packet.NewDataByName(identifier)
+	data.Payload.SetValue(publicKeyInfo)
+	if err := keyChain.SignData(data); err != nil {
+		return nil, err
+	}
+	var encoder encoding.Encoder
+	if err := encoder.EncoderReset(encoding.MaxPacketSize, 0); err != nil {
+		return nil, err
+	}
+	bufLen, err := data.WireEncode(&encoder)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := encoder.GetBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf[:bufLen], nil
+}
@@ -0,0 +1,105 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 20:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"crypto/tls"
+	common2 "minlib/common"
+	"mir-go/daemon/common"
+	"mir-go/daemon/utils"
+	"net"
+	"time"
+)
+
+// tlsHandshakeTimeout TLS 握手的超时时间
+const tlsHandshakeTimeout = 10 * time.Second
+
+// TlsListener
+// @Description:  TLS端口监听器，用于接收远程mir的TLS连接请求，握手和对端认证成功后为新连接创建
+//			并启动一个TLS-Transport类型的LogicFace
+//
+type TlsListener struct {
+	TlsPort    uint16       // TLS端口号
+	ListenAddr string       // 监听的本地地址，为空时同时监听 IPv4 和 IPv6
+	listener   net.Listener // TCP监听句柄
+	security   *TlsSecurity // 证书和对端认证配置
+}
+
+// Init
+// @Description: 	初始化TLS监听器
+// @receiver t
+// @param config
+// @param security
+//
+func (t *TlsListener) Init(config *common.MIRConfig, security *TlsSecurity) {
+	t.TlsPort = uint16(config.TLSPort)
+	t.ListenAddr = config.TLSListenAddr
+	t.security = security
+}
+
+//
+// @Description: 完成 TLS 握手和对端认证，成功后创建一个TLS类型的logicFace，失败时关闭连接
+// @receiver t
+// @param conn	新TLS连接句柄
+//
+func (t *TlsListener) tryCreateTlsLogicFace(conn *tls.Conn) {
+	_ = conn.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+	if err := conn.Handshake(); err != nil {
+		common2.LogWarn("tls handshake with ", conn.RemoteAddr().String(), " fail: ", err)
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	createTlsLogicFace(conn, 0)
+}
+
+//
+// @Description: 接收TLS连接，每个连接在单独的协程中握手，避免慢速的对端阻塞其它连接
+// @receiver t
+//
+func (t *TlsListener) accept() {
+	for true {
+		newConnect, err := t.listener.Accept()
+		if err != nil {
+			common2.LogFatal(err)
+		}
+		conn := newConnect.(*tls.Conn)
+		utils.GoroutineNoPanic(func() {
+			t.tryCreateTlsLogicFace(conn)
+		})
+	}
+}
+
+// Start
+// @Description:  启动监听协程
+// @receiver t
+//
+func (t *TlsListener) Start() {
+	network, address := listenNetworkAndAddress(FaceUriSchemeTCP, t.ListenAddr, int(t.TlsPort))
+	listener, err := tls.Listen(network, address, t.security.ServerConfig())
+	if err != nil {
+		common2.LogFatal(err)
+		return
+	}
+	t.listener = listener
+	utils.GoroutineNoPanic(t.accept)
+}
@@ -0,0 +1,224 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 20:00 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+//
+// @Description: TLS 对端认证方式
+//	1. none：作为服务端时不要求对端提供证书；
+//	2. cert：双向认证，对端证书必须由配置的 CA 签发；
+//	3. identity：在 cert 的基础上，对端证书的 CommonName 必须是 KeyChain 中存在的 MIR 身份名，例如 /pku/r2，
+//	   并且证书带有该身份对证书公钥的签名（使用 IssueTlsIdentityCertificate 或者 mirgen -issueTLS 签发）。
+//	主动发起的 tls:// 连接总是验证服务端的证书，identity 模式下同样要求服务端证书对应一个 MIR 身份。
+//
+const (
+	TlsClientAuthNone     = "none"
+	TlsClientAuthCert     = "cert"
+	TlsClientAuthIdentity = "identity"
+)
+
+// TlsIdentityVerifier 验证证书中的 MIR 身份是否可信，不可信时返回原因，参见 NewKeyChainTlsIdentityVerifier
+type TlsIdentityVerifier func(identityName string, cert *x509.Certificate) error
+
+// TlsSecurity TLS LogicFace 使用的证书和对端认证配置
+//
+// @Description:
+//	TLS 监听器和主动发起的 tls:// 连接共用同一份证书；对端证书的验证不检查主机名，
+//	而是检查证书链是否由配置的 CA 签发，因为站点之间的链路一般直接使用 IP 地址连接。
+//
+type TlsSecurity struct {
+	certificate      tls.Certificate     // 本机证书和私钥
+	roots            *x509.CertPool      // 用于验证对端证书的 CA
+	clientAuth       string              // 对端认证方式
+	identityVerifier TlsIdentityVerifier // MIR 身份验证函数，identity 模式下使用
+}
+
+// LoadTlsSecurity 从 PEM 文件中加载证书、私钥和 CA，创建 TLS 安全配置
+//
+// @Description:
+// @param certFile	本机证书文件
+// @param keyFile	本机私钥文件
+// @param caFile	CA 证书文件，为空时使用系统的 CA，cert 和 identity 模式下必须配置
+// @param clientAuth	对端认证方式 none | cert | identity
+// @return *TlsSecurity
+// @return error
+//
+func LoadTlsSecurity(certFile string, keyFile string, caFile string, clientAuth string) (*TlsSecurity, error) {
+	clientAuth = strings.ToLower(strings.TrimSpace(clientAuth))
+	if clientAuth == "" {
+		clientAuth = TlsClientAuthNone
+	}
+	if clientAuth != TlsClientAuthNone && clientAuth != TlsClientAuthCert && clientAuth != TlsClientAuthIdentity {
+		return nil, TlsSecurityError{msg: "unknown TLS client auth mode: " + clientAuth}
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, TlsSecurityError{msg: "TLSCertFile and TLSKeyFile must be configured"}
+	}
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, TlsSecurityError{msg: "load certificate fail: " + err.Error()}
+	}
+	t := &TlsSecurity{certificate: certificate, clientAuth: clientAuth}
+	if caFile != "" {
+		caPem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, TlsSecurityError{msg: "read CA file fail: " + err.Error()}
+		}
+		t.roots = x509.NewCertPool()
+		if !t.roots.AppendCertsFromPEM(caPem) {
+			return nil, TlsSecurityError{msg: "no certificate found in CA file " + caFile}
+		}
+	} else if clientAuth != TlsClientAuthNone {
+		return nil, TlsSecurityError{msg: "TLSCAFile must be configured when TLSClientAuth is " + clientAuth}
+	}
+	return t, nil
+}
+
+// SetIdentityVerifier 设置 MIR 身份验证函数
+//
+// @Description:
+// @receiver t
+// @param identityVerifier
+//
+func (t *TlsSecurity) SetIdentityVerifier(identityVerifier TlsIdentityVerifier) {
+	t.identityVerifier = identityVerifier
+}
+
+// ServerConfig 获取 TLS 监听器使用的配置
+//
+// @Description:
+// @receiver t
+// @return *tls.Config
+//
+func (t *TlsSecurity) ServerConfig() *tls.Config {
+	config := &tls.Config{
+		Certificates: []tls.Certificate{t.certificate},
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   tls.NoClientCert,
+	}
+	if t.clientAuth != TlsClientAuthNone {
+		// 证书链由 verifyPeerCertificate 验证，以便同时检查 MIR 身份
+		config.ClientAuth = tls.RequireAnyClientCert
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			_, err := t.verifyPeerCertificate(rawCerts)
+			return err
+		}
+	}
+	return config
+}
+
+// ClientConfig 获取主动发起 tls:// 连接时使用的配置
+//
+// @Description:
+// @receiver t
+// @return *tls.Config
+//
+func (t *TlsSecurity) ClientConfig() *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{t.certificate},
+		MinVersion:   tls.VersionTLS12,
+		// 不检查主机名，证书链由 verifyPeerCertificate 验证
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			_, err := t.verifyPeerCertificate(rawCerts)
+			return err
+		},
+	}
+}
+
+//
+// @Description: 验证对端证书链，identity 模式下还要求对端证书对应一个可信的 MIR 身份
+// @receiver t
+// @param rawCerts	对端发送的证书链，第一个是对端自己的证书
+// @return string	对端证书中的 MIR 身份名，没有时为空字符串
+// @return error
+//
+func (t *TlsSecurity) verifyPeerCertificate(rawCerts [][]byte) (string, error) {
+	if len(rawCerts) == 0 {
+		return "", TlsSecurityError{msg: "peer did not provide a certificate"}
+	}
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return "", TlsSecurityError{msg: "parse peer certificate fail: " + err.Error()}
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         t.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return "", TlsSecurityError{msg: "verify peer certificate fail: " + err.Error()}
+	}
+
+	identityName := PeerIdentityOfCertificate(certs[0])
+	if t.clientAuth == TlsClientAuthIdentity {
+		if identityName == "" {
+			return "", TlsSecurityError{msg: "peer certificate does not carry a MIR identity: " + certs[0].Subject.CommonName}
+		}
+		if t.identityVerifier == nil {
+			return "", TlsSecurityError{msg: "untrusted MIR identity: " + identityName}
+		}
+		if err := t.identityVerifier(identityName, certs[0]); err != nil {
+			return "", TlsSecurityError{msg: "untrusted MIR identity: " + err.Error()}
+		}
+	}
+	return identityName, nil
+}
+
+// PeerIdentityOfCertificate 获取证书对应的 MIR 身份名，证书的 CommonName 是以 '/' 开头的身份名时有效，否则返回空字符串
+//
+// @Description:
+// @param cert
+// @return string
+//
+func PeerIdentityOfCertificate(cert *x509.Certificate) string {
+	if cert == nil || !strings.HasPrefix(cert.Subject.CommonName, "/") {
+		return ""
+	}
+	return cert.Subject.CommonName
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type TlsSecurityError struct {
+	msg string
+}
+
+func (t TlsSecurityError) Error() string {
+	return fmt.Sprintf("TlsSecurityError: %s", t.msg)
+}
@@ -0,0 +1,293 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 20:30 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+package lf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"minlib/security"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificate 测试用的证书和私钥
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// @Description: 生成一个证书，parent 为 nil 时生成自签名的 CA 证书
+// @param t
+// @param commonName
+// @param parent
+// @param extensions	证书的额外扩展
+// @return *testCertificate
+func newTestCertificate(t *testing.T, commonName string, parent *testCertificate, extensions ...pkix.Extension) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:    serial,
+		Subject:         pkix.Name{CommonName: commonName},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		ExtraExtensions: extensions,
+	}
+	signerCert, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCertificate{cert: cert, key: key}
+}
+
+// @Description: 把证书和私钥写入 PEM 文件
+// @receiver c
+// @param t
+// @param dir
+// @param name
+// @return string	证书文件路径
+// @return string	私钥文件路径
+func (c *testCertificate) writePem(t *testing.T, dir string, name string) (string, string) {
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	keyDer, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// @Description: 使用 KeyChain 中的身份签发 identity 模式的证书，并写入 PEM 文件
+// @param t
+// @param keyChain
+// @param identityName
+// @param ca
+// @param dir
+// @param name
+// @return string	证书文件路径
+// @return string	私钥文件路径
+func issueTestIdentityCertificate(t *testing.T, keyChain *security.KeyChain, identityName string, ca *testCertificate,
+	dir string, name string) (string, string) {
+	identity := keyChain.GetIdentityByName(identityName)
+	if identity == nil {
+		var err error
+		if identity, err = keyChain.CreateIdentityByName(identityName, "mir-test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := keyChain.SetCurrentIdentity(identity, "mir-test"); err != nil {
+		t.Fatal(err)
+	}
+	certPem, keyPem, err := IssueTlsIdentityCertificate(keyChain, identityName, ca.cert, ca.key, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	if err := ioutil.WriteFile(certFile, certPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// @Description: 在本地回环地址上完成一次 TLS 握手，返回客户端连接以及客户端和服务端的握手结果
+// @param t
+// @param serverConfig
+// @param clientConfig
+// @return *tls.Conn	客户端连接
+// @return error	客户端握手结果
+// @return error	服务端握手结果
+func tlsHandshake(t *testing.T, serverConfig *tls.Config, clientConfig *tls.Config) (*tls.Conn, error, error) {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		err = conn.(*tls.Conn).Handshake()
+		_ = conn.Close()
+		serverErr <- err
+	}()
+	client, clientErr := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if clientErr == nil {
+		// TLS 1.3 中服务端在客户端握手结束之后才验证客户端证书
+		clientErr = client.Handshake()
+	}
+	return client, clientErr, <-serverErr
+}
+
+func TestTlsSecurity(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCertificate(t, "MIR Test CA", nil)
+	rogueCa := newTestCertificate(t, "Rogue CA", nil)
+	caFile, _ := ca.writePem(t, dir, "ca")
+	// r1 和 r2 的身份都在 KeyChain 中，r3 的身份只在另一个 KeyChain 中
+	keyChain := new(security.KeyChain)
+	if err := keyChain.InitialKeyChainByPath(filepath.Join(dir, "identity.db")); err != nil {
+		t.Fatal(err)
+	}
+	otherKeyChain := new(security.KeyChain)
+	if err := otherKeyChain.InitialKeyChainByPath(filepath.Join(dir, "other-identity.db")); err != nil {
+		t.Fatal(err)
+	}
+	r1CertFile, r1KeyFile := issueTestIdentityCertificate(t, keyChain, "/pku/r1", ca, dir, "r1")
+	r2CertFile, r2KeyFile := issueTestIdentityCertificate(t, keyChain, "/pku/r2", ca, dir, "r2")
+	unknownCertFile, unknownKeyFile := issueTestIdentityCertificate(t, otherKeyChain, "/pku/r3", ca, dir, "unknown")
+	// 当前身份不是 /pku/r1 时不能为 /pku/r1 签发证书
+	if _, _, err := IssueTlsIdentityCertificate(keyChain, "/pku/r1", ca.cert, ca.key, time.Hour); err == nil {
+		t.Fatal("only the current identity can issue its certificate")
+	}
+	// CA 签发的 /pku/r2 证书，复制了 /pku/r2 的身份绑定，但是公钥不是绑定的公钥
+	r2Pair, err := tls.LoadX509KeyPair(r2CertFile, r2KeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2Cert, err := x509.ParseCertificate(r2Pair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r2Binding pkix.Extension
+	for _, extension := range r2Cert.Extensions {
+		if extension.Id.Equal(TlsIdentityBindingOid) {
+			r2Binding = extension
+		}
+	}
+	impostorCertFile, impostorKeyFile := newTestCertificate(t, "/pku/r2", ca, r2Binding).writePem(t, dir, "impostor")
+	// CA 签发、CommonName 是已知身份，但是没有身份绑定的证书
+	unboundCertFile, unboundKeyFile := newTestCertificate(t, "/pku/r2", ca).writePem(t, dir, "unbound")
+	hostCertFile, hostKeyFile := newTestCertificate(t, "r3.example.com", ca).writePem(t, dir, "r3")
+	rogueCertFile, rogueKeyFile := newTestCertificate(t, "/pku/r2", rogueCa).writePem(t, dir, "rogue")
+
+	// 配置检查
+	if _, err := LoadTlsSecurity(r1CertFile, r1KeyFile, caFile, "whatever"); err == nil {
+		t.Fatal("unknown client auth mode should be rejected")
+	}
+	if _, err := LoadTlsSecurity(r1CertFile, r1KeyFile, "", TlsClientAuthCert); err == nil {
+		t.Fatal("cert client auth without CA should be rejected")
+	}
+	if _, err := LoadTlsSecurity("", "", caFile, TlsClientAuthNone); err == nil {
+		t.Fatal("missing certificate should be rejected")
+	}
+
+	// 与 MIRStarter 使用同样的验证函数
+	verifier := NewKeyChainTlsIdentityVerifier(keyChain)
+	load := func(certFile string, keyFile string, clientAuth string) *TlsSecurity {
+		security, err := LoadTlsSecurity(certFile, keyFile, caFile, clientAuth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		security.SetIdentityVerifier(verifier)
+		return security
+	}
+	server := load(r1CertFile, r1KeyFile, TlsClientAuthIdentity)
+
+	// 双方都是 CA 签发的已知身份，握手成功，并且能从连接中拿到对端的 MIR 身份
+	client, clientErr, serverErr := tlsHandshake(t, server.ServerConfig(), load(r2CertFile, r2KeyFile, TlsClientAuthIdentity).ClientConfig())
+	if clientErr != nil || serverErr != nil {
+		t.Fatalf("handshake between known identities failed: %v %v", clientErr, serverErr)
+	}
+	var transport TlsTransport
+	transport.Init(client)
+	if transport.GetPeerIdentity() != "/pku/r1" || !strings.HasPrefix(transport.GetRemoteUri(), "tls://127.0.0.1:") {
+		t.Fatalf("unexpected transport: %s %s", transport.GetPeerIdentity(), transport.GetRemoteUri())
+	}
+	_ = client.Close()
+
+	// CA 签发但不对应 MIR 身份的证书在 identity 模式下被拒绝，在 cert 模式下可以通过
+	if _, _, serverErr = tlsHandshake(t, server.ServerConfig(), load(hostCertFile, hostKeyFile, TlsClientAuthIdentity).ClientConfig()); serverErr == nil {
+		t.Fatal("certificate without MIR identity should be rejected in identity mode")
+	}
+	// 身份名已知但没有身份绑定、或者公钥与身份绑定不匹配的证书在 identity 模式下被拒绝
+	if _, _, serverErr = tlsHandshake(t, server.ServerConfig(), load(unboundCertFile, unboundKeyFile, TlsClientAuthIdentity).ClientConfig()); serverErr == nil {
+		t.Fatal("certificate without identity binding should be rejected in identity mode")
+	}
+	if _, _, serverErr = tlsHandshake(t, server.ServerConfig(), load(impostorCertFile, impostorKeyFile, TlsClientAuthIdentity).ClientConfig()); serverErr == nil {
+		t.Fatal("certificate whose key does not match the identity binding should be rejected in identity mode")
+	}
+	// 身份绑定有效，但是身份的证书不在 KeyChain 中
+	if _, _, serverErr = tlsHandshake(t, server.ServerConfig(), load(unknownCertFile, unknownKeyFile, TlsClientAuthIdentity).ClientConfig()); serverErr == nil {
+		t.Fatal("identity not in KeyChain should be rejected in identity mode")
+	}
+	certServer := load(r1CertFile, r1KeyFile, TlsClientAuthCert)
+	if _, clientErr, serverErr = tlsHandshake(t, certServer.ServerConfig(), load(hostCertFile, hostKeyFile, TlsClientAuthCert).ClientConfig()); clientErr != nil || serverErr != nil {
+		t.Fatalf("CA signed certificate should be accepted in cert mode: %v %v", clientErr, serverErr)
+	}
+
+	// 其它 CA 签发的证书即使 CommonName 是已知身份也被拒绝
+	rogue, err := LoadTlsSecurity(rogueCertFile, rogueKeyFile, "", TlsClientAuthNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rogue.roots = x509.NewCertPool()
+	rogue.roots.AddCert(ca.cert)
+	if _, _, serverErr = tlsHandshake(t, certServer.ServerConfig(), rogue.ClientConfig()); serverErr == nil {
+		t.Fatal("certificate signed by another CA should be rejected")
+	}
+
+	// 客户端总是验证服务端的证书
+	if _, clientErr, _ = tlsHandshake(t, load(rogueCertFile, rogueKeyFile, TlsClientAuthNone).ServerConfig(),
+		load(r2CertFile, r2KeyFile, TlsClientAuthNone).ClientConfig()); clientErr == nil {
+		t.Fatal("server certificate signed by another CA should be rejected by client")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 20:10 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"crypto/tls"
+)
+
+// TlsTransport
+// @Description: 基于 TLS 的 TCP 流式通道，收发包的逻辑与 TcpTransport 相同
+//
+type TlsTransport struct {
+	StreamTransport
+	peerIdentity string // 对端证书中的 MIR 身份名，没有时为空字符串
+}
+
+// Init
+// @Description:  初始化 TlsTransport，conn 必须已经完成握手
+// @receiver t
+// @param conn
+//
+func (t *TlsTransport) Init(conn *tls.Conn) {
+	t.conn = conn
+	t.localAddr = conn.LocalAddr().String()
+	t.localUri = "tls://" + t.localAddr
+	t.remoteAddr = conn.RemoteAddr().String()
+	t.remoteUri = "tls://" + t.remoteAddr
+	t.recvBuf = make([]byte, 1024*1024*4)
+	t.recvLen = 0
+	if peerCertificates := conn.ConnectionState().PeerCertificates; len(peerCertificates) > 0 {
+		t.peerIdentity = PeerIdentityOfCertificate(peerCertificates[0])
+	}
+}
+
+// GetPeerIdentity 获取对端证书中的 MIR 身份名
+//
+// @Description:
+// @receiver t
+// @return string
+//
+func (t *TlsTransport) GetPeerIdentity() string {
+	return t.peerIdentity
+}
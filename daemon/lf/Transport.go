@@ -122,3 +122,12 @@ func (t *Transport) GetRemoteAddr() string {
 func (t *Transport) GetLocalAddr() string {
 	return t.localAddr
 }
+
+//
+// @Description: 获取各种 transport 共用的 Transport 部分，用于判断出错的 transport 是否是 LogicFace 当前绑定的 transport
+// @receiver t
+// @return *Transport
+//
+func (t *Transport) base() *Transport {
+	return t
+}
@@ -28,7 +28,6 @@ import (
 	"mir-go/daemon/common"
 	"mir-go/daemon/utils"
 	"net"
-	"strconv"
 )
 
 // UdpPacket
@@ -52,6 +51,7 @@ type UdpPacket struct {
 //
 type UdpListener struct {
 	udpPort        uint16
+	listenAddr     string // 监听的本地地址，为空时同时监听 IPv4 和 IPv6
 	conn           *net.UDPConn
 	udpAddrFaceMap LogicFaceMap
 	//udpAddrFaceMapLock sync.Mutex // udpAddrFaceMap 的互斥锁
@@ -62,6 +62,7 @@ type UdpListener struct {
 
 func (u *UdpListener) Init(config *common.MIRConfig) {
 	u.udpPort = uint16(config.UDPPort)
+	u.listenAddr = config.UDPListenAddr
 	u.recvBuf = make([]byte, 9000)
 	u.receiveRoutineNum = config.UDPReceiveRoutineNumber
 	u.config = config
@@ -73,7 +74,7 @@ func (u *UdpListener) Init(config *common.MIRConfig) {
 // @param conn	新udp 句柄
 //
 func (u *UdpListener) createUdpLogicFace(conn *net.UDPConn) {
-	createUdpLogicFace(conn, nil)
+	createUdpLogicFace(conn, nil, nil)
 }
 
 // Start
@@ -81,12 +82,13 @@ func (u *UdpListener) createUdpLogicFace(conn *net.UDPConn) {
 // @receiver t
 //
 func (u *UdpListener) Start() {
-	udpAddr, err := net.ResolveUDPAddr("udp4", "0.0.0.0:"+strconv.Itoa(int(u.udpPort)))
+	network, address := listenNetworkAndAddress(FaceUriSchemeUDP, u.listenAddr, int(u.udpPort))
+	udpAddr, err := net.ResolveUDPAddr(network, address)
 	if err != nil {
 		common2.LogFatal(err)
 		return
 	}
-	conn, err := net.ListenUDP("udp", udpAddr)
+	conn, err := net.ListenUDP(network, udpAddr)
 	if err != nil {
 		common2.LogFatal(err)
 	}
@@ -174,7 +176,7 @@ func (u *UdpListener) doReceive() {
 }
 
 func (u *UdpListener) DeleteLogicFace(remoteAddr string) {
-	u.DeleteLogicFace(remoteAddr)
+	u.udpAddrFaceMap.Delete(remoteAddr)
 }
 
 func (u *UdpListener) AddLogicFace(remoteAddr string, logicFace *LogicFace) {
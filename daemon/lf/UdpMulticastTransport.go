@@ -0,0 +1,219 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 21:10 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"fmt"
+	common2 "minlib/common"
+	"minlib/packet"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UdpMulticastMember UDP 组播 LogicFace 上收到过包的组成员（发送者）
+//
+// @Description:
+//
+type UdpMulticastMember struct {
+	Addr      string // 发送者地址，eg: 192.168.1.2:56364
+	FirstSeen int64  // 第一次收到该发送者的包的时间，单位（毫秒）
+	LastSeen  int64  // 最后一次收到该发送者的包的时间，单位（毫秒）
+	InPacketN uint64 // 收到该发送者的包的数量
+}
+
+// UdpMulticastTransport
+// @Description:  UDP 组播通信隧道，是以太网组播 LogicFace 在不允许使用 pcap 的网络中的替代
+//	1. 发出的包发往组播地址，会被链路上所有加入该组的路由器收到；
+//	2. 从任意成员收到的包都通过同一个多点接入的 LogicFace 交给转发器，发送者记录在成员表中；
+//	3. 分片按发送者分别重组，不同成员的分片不会混在一起。
+//
+type UdpMulticastTransport struct {
+	Transport
+	conn      *net.UDPConn                   // 加入组播组的 UDP 句柄，同时用于收包和发包
+	groupAddr *net.UDPAddr                   // 组播地址
+	ifName    string                         // 网卡名
+	members   map[string]*UdpMulticastMember // 发送者地址 => 成员信息
+	lock      sync.Mutex                     // members 的互斥锁
+}
+
+// Init
+// @Description: 初始化 UdpMulticastTransport
+// @receiver u
+// @param conn	通过 net.ListenMulticastUDP 创建的 UDP 句柄
+// @param ifName	网卡名
+// @param groupAddr	组播地址
+//
+func (u *UdpMulticastTransport) Init(conn *net.UDPConn, ifName string, groupAddr *net.UDPAddr) {
+	u.conn = conn
+	u.ifName = ifName
+	u.groupAddr = groupAddr
+	u.members = make(map[string]*UdpMulticastMember)
+	u.localAddr = ifName
+	u.localUri = "dev://" + ifName
+	u.remoteAddr = groupAddr.String()
+	u.remoteUri = "udp://" + u.remoteAddr
+}
+
+// Close
+// @Description: 关闭函数
+// @receiver u
+//
+func (u *UdpMulticastTransport) Close() {
+	err := u.conn.Close()
+	if err != nil {
+		common2.LogWarn(err)
+	}
+}
+
+// Send
+// @Description: 往组播地址发送一个UDP包，UDP包里装着一个lpPacket
+// @receiver u
+// @param lpPacket
+//
+func (u *UdpMulticastTransport) Send(lpPacket *packet.LpPacket) {
+	encodeBufLen, encodeBuf := encodeLpPacket2ByteArray(lpPacket)
+	if encodeBufLen <= 0 {
+		return
+	}
+	if _, err := u.conn.WriteToUDP(encodeBuf[:encodeBufLen], u.groupAddr); err != nil {
+		common2.LogWarn(err)
+	}
+}
+
+// Receive
+// @Description: 用协程调用，不断地从组播句柄中读出UDP包，记录发送者之后交给 linkService 处理
+//		net.ListenMulticastUDP 已经关闭了组播回环，不会收到本机发出的包
+// @receiver u
+//
+func (u *UdpMulticastTransport) Receive() {
+	recvBuf := make([]byte, 9000)
+	for true {
+		recvLen, senderAddr, err := u.conn.ReadFromUDP(recvBuf)
+		if err != nil {
+			common2.LogError("recv from udp multicast transport error,the err is:",
+				err, ". remote uri: ", u.remoteUri, ", local uri: ", u.localUri)
+			u.linkService.logicFace.Shutdown()
+			break
+		}
+		u.recordSender(senderAddr.String(), getTimestampMS())
+		lpPacket, err := parseByteArray2LpPacket(recvBuf[:recvLen])
+		if err != nil || lpPacket == nil {
+			common2.LogWarn("parse lpPacket from udp multicast error. sender: ", senderAddr.String())
+			continue
+		}
+		u.linkService.receivePacketFrom(lpPacket, "udp://"+senderAddr.String())
+	}
+}
+
+//
+// @Description: 在成员表中记录一个发送者
+// @receiver u
+// @param senderAddr
+// @param now	当前时间，单位（毫秒）
+//
+func (u *UdpMulticastTransport) recordSender(senderAddr string, now int64) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	member, ok := u.members[senderAddr]
+	if !ok {
+		member = &UdpMulticastMember{Addr: senderAddr, FirstSeen: now}
+		u.members[senderAddr] = member
+	}
+	member.LastSeen = now
+	member.InPacketN++
+}
+
+// GetMembers 获取收到过包的所有组成员，按地址排序
+//
+// @Description:
+// @receiver u
+// @return []UdpMulticastMember
+//
+func (u *UdpMulticastTransport) GetMembers() []UdpMulticastMember {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	members := make([]UdpMulticastMember, 0, len(u.members))
+	for _, member := range u.members {
+		members = append(members, *member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Addr < members[j].Addr
+	})
+	return members
+}
+
+//
+// @Description: 解析配置文件中的一项 UDP 组播 LogicFace 配置，格式为 "<网卡名>@<组播地址>:<端口>"，
+//		eg: eth0@224.0.23.171:56364 | eth0@[ff02::114]:56364，IPv6 链路本地组播地址的 zone 默认为网卡名
+// @param entry
+// @return string	网卡名
+// @return *net.UDPAddr	组播地址
+// @return error
+//
+func parseUdpMulticastEntry(entry string) (string, *net.UDPAddr, error) {
+	items := strings.SplitN(strings.TrimSpace(entry), "@", 2)
+	if len(items) != 2 || items[0] == "" || items[1] == "" {
+		return "", nil, UdpMulticastError{msg: "expect <ifName>@<group>:<port>, got " + entry}
+	}
+	ifName := items[0]
+	groupAddr, err := net.ResolveUDPAddr("udp", items[1])
+	if err != nil {
+		return "", nil, UdpMulticastError{msg: "parse group address of " + entry + " fail: " + err.Error()}
+	}
+	if groupAddr.IP == nil || !groupAddr.IP.IsMulticast() {
+		return "", nil, UdpMulticastError{msg: items[1] + " is not a multicast address"}
+	}
+	if groupAddr.Port == 0 {
+		return "", nil, UdpMulticastError{msg: "missing port in " + entry}
+	}
+	if groupAddr.IP.To4() == nil && groupAddr.Zone == "" &&
+		(groupAddr.IP.IsLinkLocalMulticast() || groupAddr.IP.IsInterfaceLocalMulticast()) {
+		groupAddr.Zone = ifName
+	}
+	return ifName, groupAddr, nil
+}
+
+//
+// @Description: 根据组播地址获取对应的网络类型 udp4 或 udp6
+// @param groupAddr
+// @return string
+//
+func udpNetworkOf(groupAddr *net.UDPAddr) string {
+	if groupAddr.IP.To4() != nil {
+		return "udp4"
+	}
+	return "udp6"
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type UdpMulticastError struct {
+	msg string
+}
+
+func (u UdpMulticastError) Error() string {
+	return fmt.Sprintf("UdpMulticastError: %s", u.msg)
+}
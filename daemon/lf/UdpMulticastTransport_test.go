@@ -0,0 +1,104 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 21:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"testing"
+)
+
+func TestParseUdpMulticastEntry(t *testing.T) {
+	cases := []struct {
+		entry   string
+		ifName  string
+		group   string
+		network string
+	}{
+		{"eth0@224.0.23.171:56364", "eth0", "224.0.23.171:56364", "udp4"},
+		{" eth1@239.1.2.3:6363 ", "eth1", "239.1.2.3:6363", "udp4"},
+		// 链路本地的 IPv6 组播地址默认使用网卡名作为 zone
+		{"eth0@[ff02::114]:56364", "eth0", "[ff02::114%eth0]:56364", "udp6"},
+		{"eth0@[ff02::114%eth1]:56364", "eth0", "[ff02::114%eth1]:56364", "udp6"},
+		{"eth0@[ff0e::114]:56364", "eth0", "[ff0e::114]:56364", "udp6"},
+	}
+	for _, c := range cases {
+		ifName, groupAddr, err := parseUdpMulticastEntry(c.entry)
+		if err != nil {
+			t.Fatalf("parse %s fail: %v", c.entry, err)
+		}
+		if ifName != c.ifName || groupAddr.String() != c.group || udpNetworkOf(groupAddr) != c.network {
+			t.Fatalf("parse %s got %s %s %s", c.entry, ifName, groupAddr.String(), udpNetworkOf(groupAddr))
+		}
+	}
+
+	for _, entry := range []string{
+		"",
+		"224.0.23.171:56364",      // 缺少网卡名
+		"@224.0.23.171:56364",     // 缺少网卡名
+		"eth0@",                   // 缺少组播地址
+		"eth0@224.0.23.171",       // 缺少端口
+		"eth0@224.0.23.171:0",     // 端口为 0
+		"eth0@192.168.1.2:56364",  // 单播地址
+		"eth0@[fe80::1]:56364",    // 单播地址
+		"eth0@not-an-addr:56364",  // 无法解析
+		"eth0@224.0.23.171:65536", // 端口越界
+	} {
+		if _, _, err := parseUdpMulticastEntry(entry); err == nil {
+			t.Fatalf("entry %q should be rejected", entry)
+		}
+	}
+}
+
+func TestUdpMulticastTransport_Members(t *testing.T) {
+	var transport UdpMulticastTransport
+	_, groupAddr, err := parseUdpMulticastEntry("eth0@224.0.23.171:56364")
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport.Init(nil, "eth0", groupAddr)
+	if transport.GetLocalUri() != "dev://eth0" || transport.GetRemoteUri() != "udp://224.0.23.171:56364" {
+		t.Fatalf("unexpected uri: %s %s", transport.GetLocalUri(), transport.GetRemoteUri())
+	}
+	if len(transport.GetMembers()) != 0 {
+		t.Fatal("member table should be empty")
+	}
+
+	transport.recordSender("192.168.1.3:56364", 100)
+	transport.recordSender("192.168.1.2:56364", 200)
+	transport.recordSender("192.168.1.3:56364", 300)
+
+	members := transport.GetMembers()
+	if len(members) != 2 {
+		t.Fatalf("expect 2 members, got %d", len(members))
+	}
+	if members[0] != (UdpMulticastMember{Addr: "192.168.1.2:56364", FirstSeen: 200, LastSeen: 200, InPacketN: 1}) {
+		t.Fatalf("unexpected member: %+v", members[0])
+	}
+	if members[1] != (UdpMulticastMember{Addr: "192.168.1.3:56364", FirstSeen: 100, LastSeen: 300, InPacketN: 2}) {
+		t.Fatalf("unexpected member: %+v", members[1])
+	}
+
+	// 返回的是成员信息的拷贝
+	members[0].InPacketN = 100
+	if transport.GetMembers()[0].InPacketN != 1 {
+		t.Fatal("GetMembers should return copies")
+	}
+}
@@ -82,6 +82,10 @@ func (u *UdpTransport) Send(lpPacket *packet.LpPacket) {
 	_, err := u.conn.WriteToUDP(encodeBuf, u.remoteUdpAddr)
 	if err != nil {
 		common2.LogWarn(err)
+		// 永久性的 UDP LogicFace 发送出错时重新解析对端地址并创建新的 UDP 句柄，其它 UDP LogicFace 忽略发送错误
+		if u.linkService.logicFace.IsPermanent() {
+			u.linkService.logicFace.onTransportFailure(&u.Transport)
+		}
 	}
 }
 
@@ -47,7 +47,7 @@ func (u *UnixStreamListener) Init(config *common.MIRConfig) {
 // @param conn	新unix scoket连接句柄
 //
 func (u *UnixStreamListener) createTcpLogicFace(conn net.Conn) {
-	createUnixLogicFace(conn)
+	createUnixLogicFace(conn, nil)
 }
 
 //
@@ -0,0 +1,94 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 20:50 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"golang.org/x/net/websocket"
+	common2 "minlib/common"
+	"mir-go/daemon/common"
+	"mir-go/daemon/utils"
+	"net"
+	"net/http"
+)
+
+// WebSocketListener
+// @Description:  WebSocket 监听器，在配置的端口和路径上接收浏览器应用和代理后面的客户端的 WebSocket 连接，
+//			为新连接创建并启动一个 WebSocket-Transport 类型的 LogicFace
+//
+type WebSocketListener struct {
+	WebSocketPort uint16       // WebSocket 端口号
+	WebSocketPath string       // WebSocket 路径，eg: /mir
+	ListenAddr    string       // 监听的本地地址，为空时同时监听 IPv4 和 IPv6
+	listener      net.Listener // TCP监听句柄
+	server        *http.Server // HTTP 服务，负责 WebSocket 握手
+}
+
+// Init
+// @Description: 	初始化 WebSocket 监听器
+// @receiver w
+// @param config
+//
+func (w *WebSocketListener) Init(config *common.MIRConfig) {
+	w.WebSocketPort = uint16(config.WebSocketPort)
+	w.WebSocketPath = config.WebSocketPath
+	w.ListenAddr = config.WebSocketListenAddr
+	if w.WebSocketPath == "" || w.WebSocketPath[0] != '/' {
+		w.WebSocketPath = "/" + w.WebSocketPath
+	}
+}
+
+//
+// @Description: 为握手成功的 WebSocket 连接创建一个 WebSocket 类型的 LogicFace，并等待 LogicFace 关闭
+// @receiver w
+// @param conn	新 WebSocket 连接句柄
+//
+func (w *WebSocketListener) tryCreateWebSocketLogicFace(conn *websocket.Conn) {
+	localAddr := ""
+	if addr, ok := conn.Request().Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		localAddr = addr.String()
+	}
+	logicFace, _ := createWebSocketLogicFace(conn, localAddr, conn.Request().RemoteAddr, 0)
+	// 处理函数返回之后连接会被关闭
+	logicFace.getTransport().(*WebSocketTransport).waitClosed()
+}
+
+// Start
+// @Description:  启动监听协程
+// @receiver w
+//
+func (w *WebSocketListener) Start() {
+	listener, err := net.Listen(listenNetworkAndAddress(FaceUriSchemeTCP, w.ListenAddr, int(w.WebSocketPort)))
+	if err != nil {
+		common2.LogFatal(err)
+		return
+	}
+	w.listener = listener
+	mux := http.NewServeMux()
+	// 不检查 Origin，浏览器应用可以来自任意站点
+	mux.Handle(w.WebSocketPath, websocket.Server{Handler: w.tryCreateWebSocketLogicFace})
+	w.server = &http.Server{Handler: mux}
+	utils.GoroutineNoPanic(func() {
+		if err := w.server.Serve(w.listener); err != nil && err != http.ErrServerClosed {
+			common2.LogFatal(err)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 20:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"golang.org/x/net/websocket"
+	common2 "minlib/common"
+	"minlib/packet"
+	"sync"
+)
+
+// webSocketMaxMessageSize 一个 WebSocket 消息的最大长度，与流式通道的接收缓冲区大小相同
+const webSocketMaxMessageSize = 1024 * 1024 * 4
+
+// WebSocketTransport
+// @Description:  WebSocket 通信隧道，每个二进制消息装载一个完整的 LpPacket，不需要像流式通道一样处理粘包
+//
+type WebSocketTransport struct {
+	Transport
+	conn      *websocket.Conn // WebSocket 连接句柄
+	closed    chan struct{}   // 连接关闭时关闭该通道
+	closeOnce sync.Once
+}
+
+// Init
+// @Description:  初始化 WebSocketTransport
+// @receiver w
+// @param conn	WebSocket 连接句柄
+// @param localAddr	本地地址，eg: 192.168.3.7:13901
+// @param remoteAddr	对端地址，主动发起的连接包含路径，eg: 192.168.3.8:13901/mir
+//
+func (w *WebSocketTransport) Init(conn *websocket.Conn, localAddr string, remoteAddr string) {
+	conn.PayloadType = websocket.BinaryFrame
+	conn.MaxPayloadBytes = webSocketMaxMessageSize
+	w.conn = conn
+	w.closed = make(chan struct{})
+	w.localAddr = localAddr
+	w.localUri = "ws://" + localAddr
+	w.remoteAddr = remoteAddr
+	w.remoteUri = "ws://" + remoteAddr
+}
+
+// Close
+// @Description: 关闭 WebSocket 连接
+// @receiver w
+//
+func (w *WebSocketTransport) Close() {
+	w.closeOnce.Do(func() {
+		if err := w.conn.Close(); err != nil {
+			common2.LogWarn(err)
+		}
+		close(w.closed)
+	})
+}
+
+//
+// @Description: 等待连接关闭，WebSocket 监听器的处理函数返回之后连接会被关闭，所以处理函数需要等待 LogicFace 关闭
+// @receiver w
+//
+func (w *WebSocketTransport) waitClosed() {
+	<-w.closed
+}
+
+// Send
+// @Description: 将lpPacket对象编码成字节数组后，作为一个二进制消息发送出去
+// @receiver w
+// @param lpPacket
+//
+func (w *WebSocketTransport) Send(lpPacket *packet.LpPacket) {
+	encodeBufLen, encodeBuf := encodeLpPacket2ByteArray(lpPacket)
+	if encodeBufLen <= 0 {
+		return
+	}
+	if err := websocket.Message.Send(w.conn, encodeBuf[:encodeBufLen]); err != nil {
+		common2.LogError("send to websocket transport error:",
+			err, ". remote uri: ", w.remoteUri, ", local uri: ", w.localUri)
+		w.linkService.logicFace.Shutdown()
+	}
+}
+
+// Receive
+// @Description:  用协程调用，不断地从 WebSocket 连接中读出消息
+//			（1） 读出一个完整的消息，如果读出错，则关闭face
+//			（2） 把消息解析成一个 LpPacket 交给 linkService 处理，解析失败的消息直接丢弃
+// @receiver w
+//
+func (w *WebSocketTransport) Receive() {
+	for true {
+		var message []byte
+		if err := websocket.Message.Receive(w.conn, &message); err != nil {
+			common2.LogError("recv from websocket transport error,the err is:",
+				err, ". remote uri: ", w.remoteUri, ", local uri: ", w.localUri)
+			w.linkService.logicFace.Shutdown()
+			break
+		}
+		lpPacket, err := parseByteArray2LpPacket(message)
+		if err != nil || lpPacket == nil {
+			common2.LogWarn("parse lpPacket from websocket message error. remote uri: ", w.remoteUri)
+			continue
+		}
+		w.linkService.ReceivePacket(lpPacket)
+	}
+}
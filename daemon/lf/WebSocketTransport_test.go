@@ -0,0 +1,89 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package lf
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 21:00 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package lf
+
+import (
+	"golang.org/x/net/websocket"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebSocketTransport(t *testing.T) {
+	serverTransports := make(chan *WebSocketTransport, 1)
+	handlerDone := make(chan struct{})
+	server := httptest.NewServer(websocket.Server{Handler: func(conn *websocket.Conn) {
+		var transport WebSocketTransport
+		transport.Init(conn, "127.0.0.1:13901", conn.Request().RemoteAddr)
+		serverTransports <- &transport
+		transport.waitClosed()
+		close(handlerDone)
+	}})
+	defer server.Close()
+
+	remoteAddr := strings.TrimPrefix(server.URL, "http://") + "/mir"
+	conn, err := websocket.Dial("ws://"+remoteAddr, "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var clientTransport WebSocketTransport
+	clientTransport.Init(conn, "127.0.0.1:40000", remoteAddr)
+	if clientTransport.GetRemoteUri() != "ws://"+remoteAddr || conn.PayloadType != websocket.BinaryFrame {
+		t.Fatalf("unexpected client transport: %s %d", clientTransport.GetRemoteUri(), conn.PayloadType)
+	}
+
+	serverTransport := <-serverTransports
+	if !strings.HasPrefix(serverTransport.GetRemoteUri(), "ws://127.0.0.1:") {
+		t.Fatalf("unexpected server transport remote uri: %s", serverTransport.GetRemoteUri())
+	}
+
+	// 每个二进制消息被完整地接收一次，不会像流式通道一样粘包
+	for _, message := range [][]byte{{1, 2, 3}, {4}} {
+		if err := websocket.Message.Send(conn, message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []int{3, 1} {
+		var message []byte
+		if err := websocket.Message.Receive(serverTransport.conn, &message); err != nil {
+			t.Fatal(err)
+		}
+		if len(message) != want {
+			t.Fatalf("unexpected message length %d, want %d", len(message), want)
+		}
+	}
+
+	// 关闭之后监听器的处理函数返回，对端读出错
+	serverTransport.Close()
+	serverTransport.Close()
+	select {
+	case <-handlerDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler does not return after transport closed")
+	}
+	var message []byte
+	if err := websocket.Message.Receive(conn, &message); err == nil {
+		t.Fatal("receive from closed websocket should fail")
+	}
+	clientTransport.Close()
+}
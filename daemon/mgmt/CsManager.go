@@ -26,6 +26,7 @@ import (
 	"minlib/component"
 	"minlib/mgmt"
 	"minlib/packet"
+	common2 "mir-go/daemon/common"
 	"mir-go/daemon/lf"
 	"mir-go/daemon/table"
 )
@@ -36,10 +37,11 @@ import (
 // @Description:CS管理模块结构体
 //
 type CsManager struct {
-	cs             *table.CS // CS表
-	logicFaceTable *lf.LogicFaceTable
-	enableServe    bool // 是否可以展示信息
-	enableAdd      bool // 是否可以添加缓存
+	cs                      table.ICS // CS表
+	logicFaceTable          *lf.LogicFaceTable
+	unsolicitedDataPolicies *table.UnsolicitedDataPolicyTable // 未请求数据接纳策略表
+	enableServe             bool                              // 是否可以展示信息
+	enableAdd               bool                              // 是否可以添加缓存
 }
 
 // CreateCsManager
@@ -50,7 +52,6 @@ type CsManager struct {
 //
 func CreateCsManager() *CsManager {
 	return &CsManager{
-		cs:          new(table.CS),
 		enableServe: true,
 		enableAdd:   true,
 	}
@@ -81,6 +82,36 @@ func (c *CsManager) Init(dispatcher *Dispatcher, logicFaceTable *lf.LogicFaceTab
 	if err != nil {
 		common.LogError("cs add list-command fail,the err is:", err)
 	}
+
+	// /cs-mgmt/unsolicited-set => 给前缀绑定未请求数据的接纳策略
+	identifier, _ = component.CreateIdentifierByString("/cs-mgmt/unsolicited-set")
+	err = dispatcher.AddControlCommand(identifier, dispatcher.authorization, func(parameters *component.ControlParameters) bool {
+		return parameters.ControlParameterPrefix.IsInitial() && parameters.ControlParameterCommonString.IsInitial()
+	}, c.setUnsolicitedDataPolicy)
+	if err != nil {
+		common.LogError("cs add unsolicited-set-command fail,the err is:", err)
+	}
+	// /cs-mgmt/unsolicited-unset => 删除前缀绑定的未请求数据接纳策略
+	identifier, _ = component.CreateIdentifierByString("/cs-mgmt/unsolicited-unset")
+	err = dispatcher.AddControlCommand(identifier, dispatcher.authorization, func(parameters *component.ControlParameters) bool {
+		return parameters.ControlParameterPrefix.IsInitial()
+	}, c.unsetUnsolicitedDataPolicy)
+	if err != nil {
+		common.LogError("cs add unsolicited-unset-command fail,the err is:", err)
+	}
+	// /cs-mgmt/unsolicited-list => 展示默认策略以及所有前缀绑定的接纳策略
+	identifier, _ = component.CreateIdentifierByString("/cs-mgmt/unsolicited-list")
+	err = dispatcher.AddStatusDataset(
+		identifier,
+		dispatcher.authorization,
+		func(parameters *component.ControlParameters) bool {
+			return true
+		},
+		c.listUnsolicitedDataPolicies,
+	)
+	if err != nil {
+		common.LogError("cs add unsolicited-list-command fail,the err is:", err)
+	}
 }
 
 // TODO:后续进行实现，配置CS表读写权限等
@@ -96,51 +127,116 @@ func (c *CsManager) changeConfig(topPrefix *component.Identifier, interest *pack
 	return nil
 }
 
+// CSInfo CS 状态信息，包括配置信息、条目数量以及每个分区的命中统计
+//
+// @Description:
+//
+type CSInfo struct {
+	EnableServe bool                       // 是否可以展示信息
+	EnableAdd   bool                       // 是否可以添加缓存
+	Size        int                        // 已缓存的数据包数量
+	Partitions  []*table.CSPartitionStatus // 各个分区的状态信息，第一项为默认分区
+}
+
 //
 // 获取CS管理模块的服务信息
 //
-// @Description:获取CS管理模块的服务信息，分片发送给客户端，信息包括配置信息、条目数量、命中缓存次数等
+// @Description:获取CS管理模块的服务信息，分片发送给客户端，信息包括配置信息、条目数量、每个分区的容量和命中缓存次数等
 // @receiver c
 //
 func (c *CsManager) serveInfo(topPrefix *component.Identifier, interest *packet.Interest,
 	parameters *component.ControlParameters,
 	context *StatusDatasetContext) {
-	//var response *mgmt.ControlResponse
-	//if c.enableServe {
-	//	var CSInfo = struct {
-	//		enableServe bool
-	//		enableAdd   bool
-	//		size        uint64
-	//		hits        uint64
-	//		misses      uint64
-	//	}{
-	//		enableServe: c.enableServe,
-	//		enableAdd:   c.enableAdd,
-	//		size:        c.cs.Size(),
-	//		hits:        c.cs.Hits,
-	//		misses:      c.cs.Misses,
-	//	}
-	//	data, err := json.Marshal(CSInfo)
-	//	if err != nil {
-	//		response = MakeControlResponse(400, "mashal CSInfo fail , the err is:"+err.Error(), "")
-	//		context.responseSender(response, interest)
-	//	}
-	//	context.data = data
-	//	dataList := context.Append()
-	//	if dataList == nil {
-	//		response = MakeControlResponse(400, "slice data packet err!", "")
-	//		context.responseSender(response, interest)
-	//		return
-	//	} else {
-	//		for _, data := range dataList {
-	//			// 包编码放在dataSender中
-	//			context.dataSender(data)
-	//		}
-	//	}
-	//} else {
-	//	response = MakeControlResponse(400, "have no Permission to get CsInfo!", "")
-	//	context.responseSender(response, interest)
-	//}
+	if !c.enableServe {
+		context.Reject(MakeControlResponse(400, "have no Permission to get CsInfo!", ""))
+		return
+	}
+	if c.cs == nil {
+		context.Reject(MakeControlResponse(400, "CS is not bound to CS management module!", ""))
+		return
+	}
+	context.Append(&CSInfo{
+		EnableServe: c.enableServe,
+		EnableAdd:   c.enableAdd,
+		Size:        c.cs.Size(),
+		Partitions:  c.cs.GetPartitionStatus(),
+	})
+
+	// CS 的统计信息时刻在变化，使用当前时间作为版本号
+	_ = context.Done(common2.GetCurrentTime())
+}
+
+// UnsolicitedDataPolicyInfo 未请求数据接纳策略信息
+//
+// @Description:
+//
+type UnsolicitedDataPolicyInfo struct {
+	DefaultPolicy string                              // 没有匹配到任何前缀时使用的默认策略
+	Policies      []*table.UnsolicitedDataPolicyEntry // 前缀绑定的接纳策略
+}
+
+//
+// @Description: 给前缀绑定未请求数据的接纳策略，策略名为 drop-all、admit-local、admit-network 或者 admit-all
+// @receiver c
+// @param topPrefix
+// @param interest
+// @param parameters
+// @return *mgmt.ControlResponse
+//
+func (c *CsManager) setUnsolicitedDataPolicy(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters) *mgmt.ControlResponse {
+	if c.unsolicitedDataPolicies == nil {
+		return MakeControlResponse(400, "unsolicited data policy table is not bound to CS management module!", "")
+	}
+	policy, err := table.ParseUnsolicitedDataPolicy(parameters.ControlParameterCommonString.Value())
+	if err != nil {
+		return MakeControlResponse(400, err.Error(), "")
+	}
+	prefix := parameters.ControlParameterPrefix.Prefix()
+	c.unsolicitedDataPolicies.SetPolicy(prefix, policy)
+	common.LogInfo("set unsolicited data policy", prefix.ToUri(), "=>", policy.String())
+	return MakeControlResponse(200, "", "")
+}
+
+//
+// @Description: 删除前缀绑定的未请求数据接纳策略，删除后该前缀使用更短前缀的策略或者默认策略
+// @receiver c
+// @param topPrefix
+// @param interest
+// @param parameters
+// @return *mgmt.ControlResponse
+//
+func (c *CsManager) unsetUnsolicitedDataPolicy(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters) *mgmt.ControlResponse {
+	if c.unsolicitedDataPolicies == nil {
+		return MakeControlResponse(400, "unsolicited data policy table is not bound to CS management module!", "")
+	}
+	prefix := parameters.ControlParameterPrefix.Prefix()
+	if err := c.unsolicitedDataPolicies.UnsetPolicy(prefix); err != nil {
+		return MakeControlResponse(400, err.Error(), "")
+	}
+	common.LogInfo("unset unsolicited data policy of", prefix.ToUri())
+	return MakeControlResponse(200, "", "")
+}
+
+//
+// @Description: 获取默认策略以及所有前缀绑定的未请求数据接纳策略
+// @receiver c
+//
+func (c *CsManager) listUnsolicitedDataPolicies(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters,
+	context *StatusDatasetContext) {
+	if c.unsolicitedDataPolicies == nil {
+		context.Reject(MakeControlResponse(400, "unsolicited data policy table is not bound to CS management module!", ""))
+		return
+	}
+	context.Append(&UnsolicitedDataPolicyInfo{
+		DefaultPolicy: c.unsolicitedDataPolicies.GetDefaultPolicy().String(),
+		Policies:      c.unsolicitedDataPolicies.List(),
+	})
+
+	// 策略可以在运行时修改，使用当前时间作为版本号
+	_ = context.Done(common2.GetCurrentTime())
 }
 
 // ValidateParameters
@@ -62,14 +62,15 @@ type Module struct {
 //   			读写锁，对网络包进行签名和验签、签名元数据、缓存
 //
 type Dispatcher struct {
-	FaceClient    *logicface.LogicFace             // 内部face，用来和转发器进行通信
-	topPrefixList map[string]*component.Identifier // 已经注册的顶级域前缀 map实现 方便取 存储前缀如:/min-mir/mgmt/localhost
-	module        map[string]*Module               // 行为模块
-	topLock       *sync.RWMutex                    // 顶级域map读写锁
-	moduleLock    *sync.RWMutex                    // 行为模块map读写锁
-	keyChain      *security.KeyChain               // 网络包签名和验签 发送数据包的时候使用
-	SignInfo      *component.SignatureInfo         // 表示签名的元数据
-	Cache         *Cache                           // 存储数据包分片缓存
+	FaceClient       *logicface.LogicFace             // 内部face，用来和转发器进行通信
+	topPrefixList    map[string]*component.Identifier // 已经注册的顶级域前缀 map实现 方便取 存储前缀如:/min-mir/mgmt/localhost
+	module           map[string]*Module               // 行为模块
+	topLock          *sync.RWMutex                    // 顶级域map读写锁
+	moduleLock       *sync.RWMutex                    // 行为模块map读写锁
+	keyChain         *security.KeyChain               // 网络包签名和验签 发送数据包的时候使用
+	SignInfo         *component.SignatureInfo         // 表示签名的元数据
+	Cache            *Cache                           // 存储数据包分片缓存
+	prefixAuthorizer *PrefixAuthorizer                // 前缀注册的授权验证，为 nil 时不验证
 }
 
 // CreateDispatcher
@@ -204,9 +205,9 @@ func (d *Dispatcher) Start() {
 						module.sdHandler(topPrefix, interest, parameters, context)
 					}
 				})
-			}, func(errorType int) {
+			}, func(errorType int, reason string) {
 				// Reject => 权限验证失败，返回错误
-				d.sendControlResponse(MakeControlResponse(errorType, "Authorization Failed!", ""), interest)
+				d.sendControlResponse(MakeControlResponse(errorType, reason, ""), interest)
 			})
 		}
 	})
@@ -224,18 +225,81 @@ func (d *Dispatcher) authorization(topPrefix *component.Identifier, interest *pa
 	reject AuthorizationReject) {
 	if _, ok := d.topPrefixList[topPrefix.ToUri()]; !ok {
 		// 顶级域不存在
-		reject(0)
+		reject(0, "Authorization Failed!")
 		return
 	}
 	// 没有权限
 	if topPrefix.ToUri() == "" {
-		reject(1)
+		reject(1, "Authorization Failed!")
+		return
+	}
+	// 相邻路由器通过 LocalhopTopPrefix 只能执行前缀传播使用的命令
+	if topPrefix.ToUri() == LocalhopTopPrefix && !isLocalhopCommand(interest.GetName()) {
+		reject(1, "Authorization Failed!")
 		return
 	}
 	accept()
 	return
 }
 
+//
+// 注册前缀的授权验证函数
+//
+// @Description:在 authorization 的基础上，要求命令兴趣包由证书覆盖要注册的前缀的身份签名，用于 fib add/del、前缀监听注册和 rib register/unregister
+//
+func (d *Dispatcher) prefixAuthorization(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters,
+	accept AuthorizationAccept,
+	reject AuthorizationReject) {
+	d.authorization(topPrefix, interest, parameters, func() {
+		// 没有携带前缀的命令会在参数验证时被拒绝
+		if d.prefixAuthorizer == nil || parameters == nil || !parameters.ControlParameterPrefix.IsInitial() {
+			accept()
+			return
+		}
+		prefix := parameters.Prefix()
+		if err := d.prefixAuthorizer.Authorize(interest, prefix); err != nil {
+			common.LogWarnWithFields(logrus.Fields{
+				"command":     interest.GetName().ToUri(),
+				"prefix":      prefix.ToUri(),
+				"logicFaceId": interest.IncomingLogicFaceId.GetIncomingLogicFaceId(),
+			}, "Reject prefix registration: ", err)
+			reject(ControlResponseCodeForbidden, err.Error())
+			return
+		}
+		accept()
+	}, reject)
+}
+
+// SetPrefixAuthorizer 设置前缀注册的授权验证，为 nil 时不验证
+//
+// @Description:
+// @receiver d
+// @param prefixAuthorizer
+//
+func (d *Dispatcher) SetPrefixAuthorizer(prefixAuthorizer *PrefixAuthorizer) {
+	d.prefixAuthorizer = prefixAuthorizer
+}
+
+// localhopCommands 允许通过 LocalhopTopPrefix 执行的命令
+var localhopCommands = map[string]bool{
+	"/rib-mgmt/register":   true,
+	"/rib-mgmt/unregister": true,
+}
+
+//
+// @Description: 判断命令是否允许通过 LocalhopTopPrefix 执行
+// @param prefix	命令兴趣包的名字
+// @return bool
+//
+func isLocalhopCommand(prefix *component.Identifier) bool {
+	relPrefix, err := prefix.GetSubIdentifier(4, 2)
+	if err != nil {
+		return false
+	}
+	return localhopCommands[relPrefix.ToUri()]
+}
+
 // AddTopPrefix
 // 添加顶级域函数
 //
@@ -0,0 +1,138 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 22:30 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"mir-go/daemon/lf"
+	"sync"
+)
+
+// 管理模块默认缓存的 LogicFace 事件个数，订阅者落后超过这个数量的事件会被丢弃
+const defaultFaceEventBufferSize = 1024
+
+// FaceEventInfo LogicFace 生命周期事件
+//
+// @Description:
+//
+type FaceEventInfo struct {
+	Seq           uint64 // 事件序列号，从 1 开始连续递增
+	Kind          string // 事件类型 created | up | down | destroyed | mtu-changed
+	Timestamp     int64  // 事件发生的时间，单位（毫秒）
+	LogicFaceId   uint64
+	LogicFaceType string // 类型，eg: tcp | udp | ether
+	LocalUri      string
+	RemoteUri     string
+	Mtu           uint64 // 事件发生时的 MTU
+}
+
+// FaceEventBatch 一次拉取到的 LogicFace 事件
+//
+// @Description: 订阅者记录收到的最后一个序列号 since，下一次拉取 since 之后的事件：
+//	1. FirstSeq > since + 1 时，序列号在 (since, FirstSeq) 之间的事件已经被丢弃；
+//	2. LastSeq < since 时，说明路由器重启过，序列号重新从 1 开始。
+//
+type FaceEventBatch struct {
+	FirstSeq uint64           // 缓存中最早的事件的序列号，缓存为空时为 0
+	LastSeq  uint64           // 最近一次发布的事件的序列号
+	Events   []*FaceEventInfo // 序列号大于 since 的事件，按照序列号递增排列
+}
+
+// FaceEventStream 缓存最近发生的 LogicFace 事件
+//
+// @Description: 使用环形缓冲区保存最近 capacity 个事件，供订阅者按照序列号拉取
+//
+type FaceEventStream struct {
+	lock     sync.RWMutex
+	capacity int
+	events   []*FaceEventInfo // 环形缓冲区
+	head     int              // 最早的事件在 events 中的下标
+	lastSeq  uint64
+}
+
+// NewFaceEventStream 创建一个 FaceEventStream
+//
+// @Description:
+// @param capacity	最多缓存的事件个数
+// @return *FaceEventStream
+//
+func NewFaceEventStream(capacity int) *FaceEventStream {
+	if capacity <= 0 {
+		capacity = defaultFaceEventBufferSize
+	}
+	return &FaceEventStream{
+		capacity: capacity,
+		events:   make([]*FaceEventInfo, 0, capacity),
+	}
+}
+
+// Push 缓存一个事件，缓存满时丢弃最早的事件
+//
+// @Description: 可以直接作为 lf.FaceEventListener 订阅 LogicFaceTable 的事件
+// @receiver s
+// @param event
+//
+func (s *FaceEventStream) Push(event *lf.FaceEvent) {
+	info := &FaceEventInfo{
+		Seq:           event.Seq,
+		Kind:          event.Kind.String(),
+		Timestamp:     event.Timestamp,
+		LogicFaceId:   event.LogicFaceId,
+		LogicFaceType: event.LogicFaceType.String(),
+		LocalUri:      event.LocalUri,
+		RemoteUri:     event.RemoteUri,
+		Mtu:           event.Mtu,
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lastSeq = event.Seq
+	if len(s.events) < s.capacity {
+		s.events = append(s.events, info)
+		return
+	}
+	s.events[s.head] = info
+	s.head = (s.head + 1) % s.capacity
+}
+
+// Since 获取序列号大于 since 的所有缓存的事件
+//
+// @Description:
+// @receiver s
+// @param since
+// @return *FaceEventBatch
+//
+func (s *FaceEventStream) Since(since uint64) *FaceEventBatch {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	batch := &FaceEventBatch{LastSeq: s.lastSeq, Events: []*FaceEventInfo{}}
+	if len(s.events) == 0 {
+		return batch
+	}
+	batch.FirstSeq = s.events[s.head].Seq
+	for i := 0; i < len(s.events); i++ {
+		event := s.events[(s.head+i)%len(s.events)]
+		if event.Seq > since {
+			batch.Events = append(batch.Events, event)
+		}
+	}
+	return batch
+}
@@ -0,0 +1,61 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 22:50 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"mir-go/daemon/lf"
+	"testing"
+)
+
+func TestFaceEventStream_Since(t *testing.T) {
+	stream := NewFaceEventStream(4)
+	batch := stream.Since(0)
+	if batch.FirstSeq != 0 || batch.LastSeq != 0 || len(batch.Events) != 0 {
+		t.Fatalf("unexpected empty batch: %+v", batch)
+	}
+
+	for seq := uint64(1); seq <= 3; seq++ {
+		stream.Push(&lf.FaceEvent{Seq: seq, Kind: lf.FaceEventUp, LogicFaceId: seq, LogicFaceType: lf.LogicFaceTypeTCP})
+	}
+	batch = stream.Since(1)
+	if batch.FirstSeq != 1 || batch.LastSeq != 3 || len(batch.Events) != 2 || batch.Events[0].Seq != 2 ||
+		batch.Events[0].Kind != "up" || batch.Events[0].LogicFaceType != "tcp" {
+		t.Fatalf("unexpected batch: %+v", batch)
+	}
+
+	// 缓存满之后丢弃最早的事件，订阅者可以通过 FirstSeq 发现遗漏的事件
+	for seq := uint64(4); seq <= 7; seq++ {
+		stream.Push(&lf.FaceEvent{Seq: seq, Kind: lf.FaceEventDown, LogicFaceId: seq})
+	}
+	batch = stream.Since(2)
+	if batch.FirstSeq != 4 || batch.LastSeq != 7 || len(batch.Events) != 4 {
+		t.Fatalf("unexpected batch after wrap: %+v", batch)
+	}
+	for i, event := range batch.Events {
+		if event.Seq != uint64(4+i) {
+			t.Fatalf("events should be ordered by seq, got %d at %d", event.Seq, i)
+		}
+	}
+	if batch = stream.Since(7); len(batch.Events) != 0 || batch.LastSeq != 7 {
+		t.Fatalf("unexpected batch without new events: %+v", batch)
+	}
+}
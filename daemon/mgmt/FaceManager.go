@@ -26,10 +26,9 @@ import (
 	"minlib/component"
 	"minlib/mgmt"
 	"minlib/packet"
+	common2 "mir-go/daemon/common"
 	"mir-go/daemon/lf"
-	"net"
 	"strconv"
-	"strings"
 )
 
 type FaceInfo struct {
@@ -37,6 +36,28 @@ type FaceInfo struct {
 	RemoteUri   string
 	LocalUri    string
 	Mtu         uint64
+	Tags        map[string]string
+}
+
+// FaceStatus LogicFace 的详细状态，包括统计信息、状态、持久性、类型、过期时间和队列深度
+//
+// @Description:
+//
+type FaceStatus struct {
+	FaceInfo
+	LogicFaceType string               // 类型，eg: tcp | udp | ether
+	State         string               // 状态 up | down
+	Persistency   uint64               // 持久性，0 on-demand | 1 persistent | 2 permanent，见 lf.LogicFacePersistency
+	Local         bool                 // 作用域是否是 local
+	ExpireTime    int64                // 过期时间，单位（毫秒），只对没有持久性的 LogicFace 有意义
+	SendQueueLen  int                  // 发送队列中等待发送的包的个数
+	SendQueueCap  int                  // 发送队列的容量
+	RecvQueueLen  int                  // 接收队列中等待交给转发器的包的个数
+	RecvQueueCap  int                  // 接收队列的容量
+	LpReliability string               // 链路层可靠传输的状态 off | negotiating | on
+	Counters      lf.LogicFaceCounters // 统计信息
+
+	SendQueueClasses []lf.SendQueueClassStats // 发送队列中每个调度类别的统计信息，按照 control、data、interest 的顺序排列
 }
 
 // FaceManager face管理模块结构体
@@ -45,6 +66,7 @@ type FaceInfo struct {
 //
 type FaceManager struct {
 	logicFaceTable *lf.LogicFaceTable
+	events         *FaceEventStream // 最近发生的 LogicFace 生命周期事件
 }
 
 // CreateFaceManager
@@ -54,6 +76,7 @@ type FaceManager struct {
 //
 func CreateFaceManager() *FaceManager {
 	faceManager := new(FaceManager)
+	faceManager.events = NewFaceEventStream(defaultFaceEventBufferSize)
 	return faceManager
 }
 
@@ -65,6 +88,7 @@ func CreateFaceManager() *FaceManager {
 //
 func (f *FaceManager) Init(dispatcher *Dispatcher, logicFaceTable *lf.LogicFaceTable) {
 	f.logicFaceTable = logicFaceTable
+	logicFaceTable.AddEventListener(f.events.Push)
 
 	// /face-mgmt/add => 添加一个逻辑接口
 	//identifier, _ := component.CreateIdentifierByString("/" + mgmt.ManagementModuleFaceMgmt + "/" + mgmt.LogicFaceManagementActionAdd)
@@ -104,83 +128,65 @@ func (f *FaceManager) Init(dispatcher *Dispatcher, logicFaceTable *lf.LogicFaceT
 	if err != nil {
 		common.LogError("Face add list-command fail,the err is:", err)
 	}
+
+	// /face-mgmt/status => 获取所有逻辑接口的详细状态，指定 LogicFaceId 时只获取一个逻辑接口的状态
+	identifier, _ = component.CreateIdentifierByString("/face-mgmt/status")
+	err = dispatcher.AddStatusDataset(identifier, dispatcher.authorization, func(parameters *component.ControlParameters) bool {
+		return true
+	}, f.logicFaceStatus)
+	if err != nil {
+		common.LogError("Face add status-command fail,the err is:", err)
+	}
+
+	// /face-mgmt/events => 获取序列号大于 Count 的 LogicFace 事件，订阅者通过不断拉取实现对事件流的订阅
+	identifier, _ = component.CreateIdentifierByString("/face-mgmt/events")
+	err = dispatcher.AddStatusDataset(identifier, dispatcher.authorization, func(parameters *component.ControlParameters) bool {
+		return true
+	}, f.logicFaceEvents)
+	if err != nil {
+		common.LogError("Face add events-command fail,the err is:", err)
+	}
 }
 
 //
 // 创建连接face函数
 //
-// @Description:创建连接face函数，有Ether、TCP、UDP、UNIX四种
+// @Description:创建连接face函数，有Ether、TCP、TLS、WebSocket、UDP、UNIX六种
 // @receiver f
 // @Return:*mgmt.ControlResponse返回创建结果
 //
 func (f *FaceManager) addLogicFace(topPrefix *component.Identifier, interest *packet.Interest,
 	parameters *component.ControlParameters) *mgmt.ControlResponse {
 
-	// 提取参数
-	uriScheme := parameters.ControlParameterUriScheme.UriScheme()
+	// 提取参数，LogicFace 的类型由对端地址的协议决定，UriScheme 只为兼容旧版本的命令行工具保留
 	uri := parameters.ControlParameterUri.Uri()
 	localUri := parameters.ControlParameterLocalUri.LocalUri()
 	persistency := parameters.ControlParameterLogicFacePersistency.Persistency()
 
-	// 判断Uri格式是否正确
-	uriItems := strings.Split(uri, "://")
-	if len(uriItems) != 2 {
-		return MakeControlResponse(400, "Remote uri is wrong, expect one '://' item, "+uri, "")
+	// 标签以查询串的形式附在对端地址后面，eg: tcp://203.0.113.1:13899?role=wan
+	uri, tags, err := lf.SplitUriTags(uri)
+	if err != nil {
+		return MakeControlResponse(400, err.Error(), "")
 	}
 
-	// 根据不同的 Uri scheme，创建不同的逻辑接口
-	switch uriScheme {
-	case component.ControlParameterUriSchemeEther:
-		remoteMacAddr, err := net.ParseMAC(uriItems[1])
+	// 判断Uri格式是否正确，eg: tcp://192.168.3.7:13899 | udp6://[2001:db8::1]:13899 | tls://r2.example.com:13900
+	faceUri, err := lf.ParseFaceUri(uri)
+	if err != nil {
+		return MakeControlResponse(400, "Remote uri is wrong, "+err.Error(), "")
+	}
+
+	// 根据不同的协议，创建不同的逻辑接口
+	logicFace, err := lf.CreateLogicFaceByUri(faceUri, localUri, persistency)
+	if err != nil || logicFace == nil {
+		msg := ""
 		if err != nil {
-			return MakeControlResponse(400, "parse remote address fail,the err is:"+err.Error(), "")
-		}
-		logicFace, err := lf.CreateEtherLogicFace(localUri, remoteMacAddr)
-		if err != nil || logicFace == nil {
-			msg := ""
-			if err != nil {
-				msg = err.Error()
-			}
-			return MakeControlResponse(400, "Create EtherLogicFace fail, the err is:"+msg, "")
-		}
-		logicFace.SetPersistence(persistency)
-		return MakeControlResponse(200, "", strconv.FormatUint(logicFace.LogicFaceId, 10))
-	case component.ControlParameterUriSchemeTCP:
-		logicFace, err := lf.CreateTcpLogicFace(uriItems[1], persistency)
-		if err != nil || logicFace == nil {
-			msg := ""
-			if err != nil {
-				msg = err.Error()
-			}
-			return MakeControlResponse(400, "Create TcpLogicFace failed, the err is:"+msg, "")
-		}
-		logicFace.SetPersistence(persistency)
-		return MakeControlResponse(200, "", strconv.FormatUint(logicFace.LogicFaceId, 10))
-	case component.ControlParameterUriSchemeUDP:
-		logicFace, err := lf.CreateUdpLogicFace(uriItems[1])
-		if err != nil || logicFace == nil {
-			msg := ""
-			if err != nil {
-				msg = err.Error()
-			}
-			return MakeControlResponse(400, "Create UdpLogicFace failed, the err is:"+msg, "")
-		}
-		logicFace.SetPersistence(persistency)
-		return MakeControlResponse(200, "", strconv.FormatUint(logicFace.LogicFaceId, 10))
-	case component.ControlParameterUriSchemeUnix:
-		logicFace, err := lf.CreateUnixLogicFace(uriItems[1])
-		if err != nil || logicFace == nil {
-			msg := ""
-			if err != nil {
-				msg = err.Error()
-			}
-			return MakeControlResponse(400, "Create UnixLogicFace failed, the err is:"+msg, "")
+			msg = err.Error()
 		}
-		logicFace.SetPersistence(persistency)
-		return MakeControlResponse(200, "", strconv.FormatUint(logicFace.LogicFaceId, 10))
-	default:
-		return MakeControlResponse(400, "Unsupported protocol", "")
+		return MakeControlResponse(400, "Create "+faceUri.Protocol()+" LogicFace failed, the err is:"+msg, "")
 	}
+	logicFace.SetPersistence(persistency)
+	logicFace.SetTags(tags)
+	return MakeControlResponse(200, "", strconv.FormatUint(logicFace.LogicFaceId, 10))
 }
 
 //
@@ -221,6 +227,7 @@ func (f *FaceManager) listLogicFace(topPrefix *component.Identifier, interest *p
 				RemoteUri:   face.GetRemoteUri(),
 				LocalUri:    face.GetLocalUri(),
 				Mtu:         face.Mtu,
+				Tags:        face.GetTags(),
 			}
 			context.Append(faceInfo)
 		}
@@ -233,3 +240,84 @@ func (f *FaceManager) listLogicFace(topPrefix *component.Identifier, interest *p
 	// 2. 对 LogicFace 表的数据进行分片和并缓存到管理模块的缓存当中
 	_ = context.Done(currentVersion)
 }
+
+//
+// 获取逻辑接口的详细状态并分片发送给客户端
+//
+// @Description:获取逻辑接口的详细状态并分片发送给客户端，参数中带有 LogicFaceId 时只获取该逻辑接口的状态
+// @receiver f
+//
+func (f *FaceManager) logicFaceStatus(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters,
+	context *StatusDatasetContext) {
+	if parameters.ControlParameterLogicFaceId.IsInitial() {
+		logicFace := f.logicFaceTable.GetLogicFacePtrById(parameters.ControlParameterLogicFaceId.LogicFaceId())
+		if logicFace == nil {
+			context.Reject(MakeControlResponse(400, "The logicFace is not existed", ""))
+			return
+		}
+		context.Append(makeFaceStatus(logicFace))
+	} else {
+		for _, face := range f.logicFaceTable.GetAllFaceList() {
+			context.Append(makeFaceStatus(face))
+		}
+	}
+	// 统计信息一直在变化，使用当前时间作为版本号
+	_ = context.Done(common2.GetCurrentTime())
+}
+
+//
+// 获取最近发生的 LogicFace 事件并分片发送给客户端
+//
+// @Description:获取序列号大于 Count 参数的 LogicFace 事件，没有 Count 参数时获取所有缓存的事件
+// @receiver f
+//
+func (f *FaceManager) logicFaceEvents(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters,
+	context *StatusDatasetContext) {
+	var since uint64
+	if parameters.ControlParameterCount.IsInitial() {
+		since = parameters.ControlParameterCount.Count()
+	}
+	batch := f.events.Since(since)
+	context.Append(batch)
+	// 使用最近一次发布的事件的序列号作为版本号，没有新事件时可以直接命中缓存
+	_ = context.Done(batch.LastSeq)
+}
+
+//
+// @Description: 根据 LogicFace 构造它的详细状态
+// @param face
+// @return *FaceStatus
+//
+func makeFaceStatus(face *lf.LogicFace) *FaceStatus {
+	// 永久性的 LogicFace 重连期间显示为 down
+	state := "down"
+	if face.IsUp() {
+		state = "up"
+	}
+	sendQueueLen, sendQueueCap := face.GetSendQueueDepth()
+	recvQueueLen, recvQueueCap := face.GetRecvQueueDepth()
+	return &FaceStatus{
+		FaceInfo: FaceInfo{
+			LogicFaceId: face.LogicFaceId,
+			RemoteUri:   face.GetRemoteUri(),
+			LocalUri:    face.GetLocalUri(),
+			Mtu:         face.Mtu,
+			Tags:        face.GetTags(),
+		},
+		LogicFaceType: face.GetLogicFaceType().String(),
+		State:         state,
+		Persistency:   face.Persistence,
+		Local:         face.IsLocal(),
+		ExpireTime:    face.GetExpireTime(),
+		SendQueueLen:  sendQueueLen,
+		SendQueueCap:  sendQueueCap,
+		RecvQueueLen:  recvQueueLen,
+		RecvQueueCap:  recvQueueCap,
+		LpReliability: face.GetLpReliabilityState(),
+		Counters:      face.GetCounters(),
+
+		SendQueueClasses: face.GetSendQueueStats(),
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"minlib/component"
 	"minlib/mgmt"
 	"minlib/packet"
+	common2 "mir-go/daemon/common"
 	"mir-go/daemon/lf"
 	"mir-go/daemon/table"
 	"strconv"
@@ -40,6 +41,8 @@ type FibInfo struct {
 type NextHopInfo struct {
 	LogicFaceId uint64
 	Cost        uint64
+	Priority    uint64 // 管理优先级，数值越小越优先
+	ECMP        bool   // 是否是等价多路径成员
 }
 
 // FibManager
@@ -48,8 +51,10 @@ type NextHopInfo struct {
 // @Description:fib管理模块结构体
 //
 type FibManager struct {
-	fib            *table.FIB //fib表
-	logicFaceTable *lf.LogicFaceTable
+	fib              *table.FIB        //fib表
+	rib              *table.RIB        // RIB，不为空时下一跳先写入 RIB，再由 RIB 计算出 FIB
+	prefixPropagator *PrefixPropagator // 前缀传播服务，不为空时把本地应用注册的前缀传播到上游路由器
+	logicFaceTable   *lf.LogicFaceTable
 }
 
 // CreateFibManager
@@ -75,7 +80,7 @@ func (f *FibManager) Init(dispatcher *Dispatcher, logicFaceTable *lf.LogicFaceTa
 	identifier, _ := component.CreateIdentifierByStringArray(mgmt.ManagementModuleFibMgmt, mgmt.FibManagementActionAdd)
 	//identifier, _ := component.CreateIdentifierByString("/" + mgmt.ManagementModuleFibMgmt + "/" + mgmt.FibManagementActionAdd)
 	// 绑定控制函数，加入参数验证
-	err := dispatcher.AddControlCommand(identifier, dispatcher.authorization, func(parameters *component.ControlParameters) bool {
+	err := dispatcher.AddControlCommand(identifier, dispatcher.prefixAuthorization, func(parameters *component.ControlParameters) bool {
 		if parameters.ControlParameterPrefix.IsInitial() &&
 			parameters.ControlParameterLogicFaceId.IsInitial() &&
 			parameters.ControlParameterCost.IsInitial() {
@@ -86,10 +91,10 @@ func (f *FibManager) Init(dispatcher *Dispatcher, logicFaceTable *lf.LogicFaceTa
 	if err != nil {
 		common.LogError("add add-command fail,the err is:", err)
 	}
-	// /fib-mgmt/del => 删除一个转发表项
+	// /fib-mgmt/del => 删除一个转发表项，和添加一样需要证书覆盖前缀的身份签名，防止删除别人的路由
 	identifier, _ = component.CreateIdentifierByStringArray(mgmt.ManagementModuleFibMgmt, mgmt.FibManagementActionDel)
 	//identifier, _ = component.CreateIdentifierByString("/" + mgmt.ManagementModuleFibMgmt + "/" + mgmt.FibManagementActionDel)
-	err = dispatcher.AddControlCommand(identifier, dispatcher.authorization, func(parameters *component.ControlParameters) bool {
+	err = dispatcher.AddControlCommand(identifier, dispatcher.prefixAuthorization, func(parameters *component.ControlParameters) bool {
 		if parameters.ControlParameterPrefix.IsInitial() &&
 			parameters.ControlParameterLogicFaceId.IsInitial() {
 			return true
@@ -111,12 +116,21 @@ func (f *FibManager) Init(dispatcher *Dispatcher, logicFaceTable *lf.LogicFaceTa
 
 	// /fib-mgmt/register => 注册一个前缀监听
 	identifier, _ = component.CreateIdentifierByString("/" + mgmt.ManagementModuleFibMgmt + "/" + mgmt.FibManagementActionRegister)
-	err = dispatcher.AddControlCommand(identifier, dispatcher.authorization, func(parameters *component.ControlParameters) bool {
+	err = dispatcher.AddControlCommand(identifier, dispatcher.prefixAuthorization, func(parameters *component.ControlParameters) bool {
 		return parameters.ControlParameterPrefix.IsInitial()
 	}, f.RegisterPrefix)
 	if err != nil {
 		common.LogError("add register-command fail,the err is:", err)
 	}
+
+	// /fib-mgmt/export => 导出持久化的 LogicFace 和路由
+	identifier, _ = component.CreateIdentifierByString("/" + mgmt.ManagementModuleFibMgmt + "/export")
+	err = dispatcher.AddStatusDataset(identifier, dispatcher.authorization, func(parameters *component.ControlParameters) bool {
+		return true
+	}, f.ExportRoutes)
+	if err != nil {
+		common.LogError("add export-command fail,the err is:", err)
+	}
 }
 
 // AddNextHop
@@ -127,7 +141,16 @@ func (f *FibManager) Init(dispatcher *Dispatcher, logicFaceTable *lf.LogicFaceTa
 //
 func (f *FibManager) AddNextHop(topPrefix *component.Identifier, interest *packet.Interest,
 	parameters *component.ControlParameters) *mgmt.ControlResponse {
+	return f.addNextHop(parameters, table.RouteOriginStatic)
+}
 
+//
+// 添加来源为 origin 的下一跳
+//
+// @Description:绑定了 RIB 时以 origin 为来源写入 RIB，否则直接写入 fib 表
+// @receiver f
+//
+func (f *FibManager) addNextHop(parameters *component.ControlParameters, origin uint64) *mgmt.ControlResponse {
 	// 提取参数
 	prefix := parameters.ControlParameterPrefix.Prefix()
 	logicFaceId := parameters.ControlParameterLogicFaceId.LogicFaceId()
@@ -160,7 +183,22 @@ func (f *FibManager) AddNextHop(topPrefix *component.Identifier, interest *packe
 		}, "change read only prefix")
 		return MakeControlResponse(400, "read only,the prefix can't be changed", "")
 	}
-	f.fib.AddOrUpdate(prefix, face, cost)
+	if f.rib != nil {
+		flags := table.RouteFlagChildInherit
+		// mirc fib add 添加的静态路由需要在重启之后恢复
+		if origin == table.RouteOriginStatic {
+			flags |= table.RouteFlagPersistent
+		}
+		// mirc fib add --ecmp 把下一跳标记为等价多路径成员
+		if parameters.ControlParameterFlags.IsInitial() {
+			flags |= parameters.ControlParameterFlags.Flags() & table.RouteFlagECMP
+		}
+		if err := f.rib.Register(prefix, face, origin, cost, flags, 0); err != nil {
+			return MakeControlResponse(400, err.Error(), "")
+		}
+	} else {
+		f.fib.AddOrUpdate(prefix, face, cost)
+	}
 	common.LogInfo("Add next hop success:", prefix.ToUri(), "->", logicFaceId)
 	return MakeControlResponse(200, "add next hop success", "")
 }
@@ -186,6 +224,19 @@ func (f *FibManager) RemoveNextHop(topPrefix *component.Identifier, interest *pa
 		return MakeControlResponse(400, "the face is not found", "")
 
 	}
+	if f.rib != nil {
+		// 只删除 mirc fib add 添加的静态路由和应用通过 fib register 注册的路由，其它来源的路由保持不变
+		if err := f.rib.Unregister(prefix, logicFaceId, table.RouteOriginStatic); err != nil {
+			if f.rib.Unregister(prefix, logicFaceId, table.RouteOriginApp) != nil {
+				return MakeControlResponse(400, err.Error(), "")
+			}
+			if f.prefixPropagator != nil {
+				f.prefixPropagator.OnAppUnregister(prefix, logicFaceId)
+			}
+		}
+		common.LogInfo("Remove next hop success:", prefix.ToUri(), "->", logicFaceId)
+		return MakeControlResponse(200, "remove next hop success", "")
+	}
 	fibEntry := f.fib.FindExactMatch(prefix)
 	if fibEntry == nil {
 		common.LogDebugWithFields(logrus.Fields{
@@ -211,6 +262,10 @@ func (f *FibManager) RemoveNextHop(topPrefix *component.Identifier, interest *pa
 			return MakeControlResponse(400, err.Error(), "")
 		}
 	}
+	// 没有 RIB 时无法区分下一跳的来源，应用没有注册过这个前缀时 OnAppUnregister 不做任何事
+	if f.prefixPropagator != nil {
+		f.prefixPropagator.OnAppUnregister(prefix, logicFaceId)
+	}
 	common.LogInfo("Remove next hop success:", prefix.ToUri(), "->", logicFaceId)
 	// 返回成功
 	return MakeControlResponse(200, "remove next hop success", "")
@@ -229,7 +284,12 @@ func (f *FibManager) ListEntries(topPrefix *component.Identifier, interest *pack
 	for _, fibEntry := range fibEntryList {
 		var nextHopInfo []NextHopInfo
 		for _, nextHop := range fibEntry.GetNextHops() {
-			nextHopInfo = append(nextHopInfo, NextHopInfo{LogicFaceId: nextHop.LogicFace.LogicFaceId, Cost: nextHop.Cost})
+			nextHopInfo = append(nextHopInfo, NextHopInfo{
+				LogicFaceId: nextHop.LogicFace.LogicFaceId,
+				Cost:        nextHop.Cost,
+				Priority:    nextHop.Priority,
+				ECMP:        nextHop.ECMP,
+			})
 		}
 		fibInfo := FibInfo{
 			Identifier:   fibEntry.GetIdentifier().ToUri(),
@@ -243,6 +303,27 @@ func (f *FibManager) ListEntries(topPrefix *component.Identifier, interest *pack
 	_ = context.Done(currentVersion)
 }
 
+// ExportRoutes 导出持久化的 LogicFace 和路由，格式和路由状态文件相同
+//
+// @Description:
+// @receiver f
+// @param topPrefix
+// @param interest
+// @param parameters
+// @param context
+//
+func (f *FibManager) ExportRoutes(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters,
+	context *StatusDatasetContext) {
+	if f.rib == nil {
+		context.Reject(MakeControlResponse(400, "RIB is not bound to FIB management module!", ""))
+		return
+	}
+	context.Append(BuildRouteSnapshot(f.rib, f.logicFaceTable))
+	// 快照同时依赖 LogicFace 表和 RIB，使用当前时间作为版本号
+	_ = context.Done(common2.GetCurrentTime())
+}
+
 // NextHopCleaner
 // 从fib表项中清除所有以指定id为下一跳的nextHop
 //
@@ -251,6 +332,12 @@ func (f *FibManager) ListEntries(topPrefix *component.Identifier, interest *pack
 // @receiver f
 //
 func (f *FibManager) NextHopCleaner(logicFaceId uint64) {
+	if f.rib != nil {
+		f.rib.RemoveRoutesByFace(logicFaceId)
+	}
+	if f.prefixPropagator != nil {
+		f.prefixPropagator.OnFaceClosed(logicFaceId)
+	}
 	fibEntryList := f.fib.GetAllEntry()
 	for _, fibEntry := range fibEntryList {
 		fibEntry.RWlock.Lock()
@@ -275,7 +362,12 @@ func (f *FibManager) NextHopCleaner(logicFaceId uint64) {
 func (f *FibManager) RegisterPrefix(topPrefix *component.Identifier, interest *packet.Interest,
 	parameters *component.ControlParameters) *mgmt.ControlResponse {
 	parameters.SetLogicFaceId(interest.IncomingLogicFaceId.GetIncomingLogicFaceId())
-	return f.AddNextHop(topPrefix, interest, parameters)
+	response := f.addNextHop(parameters, table.RouteOriginApp)
+	if response.Code == mgmt.ControlResponseCodeSuccess && f.prefixPropagator != nil {
+		f.prefixPropagator.OnAppRegister(parameters.ControlParameterPrefix.Prefix(),
+			parameters.ControlParameterLogicFaceId.LogicFaceId())
+	}
+	return response
 }
 
 func (f *FibManager) GetFib() *table.FIB {
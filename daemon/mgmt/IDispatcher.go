@@ -42,8 +42,10 @@ type AuthorizationAccept func()
 // 授权拒绝回调
 //
 // @Description:
+// @param errorType	错误类型，作为 ControlResponse 的状态码
+// @param reason	拒绝的原因，作为 ControlResponse 的状态信息
 //
-type AuthorizationReject func(errorType int)
+type AuthorizationReject func(errorType int, reason string)
 
 // Authorization
 // 一个回调函数，用于对收到的控制命令进行授权验证
@@ -24,6 +24,7 @@ package mgmt
 
 import (
 	"mir-go/daemon/lf"
+	"mir-go/daemon/routing"
 	"mir-go/daemon/table"
 )
 
@@ -32,12 +33,18 @@ type ManagementSystem struct {
 	fibManager      *FibManager
 	faceManager     *FaceManager
 	identityManager *IdentityManager
+	neighborManager *NeighborManager
+	pitManager      *PitManager
+	ribManager      *RibManager
 }
 
 func (m *ManagementSystem) Init(dispatcher *Dispatcher, logicFaceTable *lf.LogicFaceTable) {
 	m.fibManager.Init(dispatcher, logicFaceTable)
 	m.faceManager.Init(dispatcher, logicFaceTable)
 	m.csManager.Init(dispatcher, logicFaceTable)
+	m.pitManager.Init(dispatcher)
+	m.neighborManager.Init(dispatcher)
+	m.ribManager.Init(dispatcher, logicFaceTable)
 	m.identityManager = CreateIdentityManager(dispatcher.keyChain)
 	m.identityManager.Init(dispatcher)
 }
@@ -46,14 +53,43 @@ func (m *ManagementSystem) SetFIB(fib *table.FIB) {
 	m.fibManager.fib = fib
 }
 
+func (m *ManagementSystem) SetCS(cs table.ICS) {
+	m.csManager.cs = cs
+}
+
+func (m *ManagementSystem) SetUnsolicitedDataPolicyTable(unsolicitedDataPolicies *table.UnsolicitedDataPolicyTable) {
+	m.csManager.unsolicitedDataPolicies = unsolicitedDataPolicies
+}
+
+func (m *ManagementSystem) SetRIB(rib *table.RIB) {
+	m.ribManager.rib = rib
+	m.fibManager.rib = rib
+}
+
+func (m *ManagementSystem) SetPIT(pit *table.PIT) {
+	m.pitManager.pit = pit
+}
+
+func (m *ManagementSystem) SetNeighborDiscovery(neighborDiscovery *routing.NeighborDiscovery) {
+	m.neighborManager.neighborDiscovery = neighborDiscovery
+}
+
+func (m *ManagementSystem) SetPrefixPropagator(prefixPropagator *PrefixPropagator) {
+	m.fibManager.prefixPropagator = prefixPropagator
+	m.ribManager.prefixPropagator = prefixPropagator
+}
+
 func (m *ManagementSystem) BindFibCleaner(l *lf.LogicFaceTable) {
 	l.OnEvicted = m.fibManager.NextHopCleaner
 }
 
 func CreateMgmtSystem() *ManagementSystem {
 	return &ManagementSystem{
-		csManager:   CreateCsManager(),
-		faceManager: CreateFaceManager(),
-		fibManager:  CreateFibManager(),
+		csManager:       CreateCsManager(),
+		faceManager:     CreateFaceManager(),
+		fibManager:      CreateFibManager(),
+		neighborManager: CreateNeighborManager(),
+		pitManager:      CreatePitManager(),
+		ribManager:      CreateRibManager(),
 	}
 }
@@ -0,0 +1,84 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"minlib/common"
+	"minlib/component"
+	"minlib/packet"
+	common2 "mir-go/daemon/common"
+	"mir-go/daemon/routing"
+)
+
+// NeighborManager 邻居管理模块，提供邻居发现得到的邻居表数据集
+//
+// @Description:
+//
+type NeighborManager struct {
+	neighborDiscovery *routing.NeighborDiscovery // 邻居发现模块，没有开启邻居发现时为 nil
+}
+
+// CreateNeighborManager 创建邻居管理模块
+//
+// @Description:
+// @return *NeighborManager
+//
+func CreateNeighborManager() *NeighborManager {
+	return &NeighborManager{}
+}
+
+// Init 注册邻居管理模块的数据集 list
+//
+// @Description:
+// @receiver n
+// @param dispatcher
+//
+func (n *NeighborManager) Init(dispatcher *Dispatcher) {
+	identifier, _ := component.CreateIdentifierByString("/neighbor-mgmt/list")
+	if err := dispatcher.AddStatusDataset(identifier, dispatcher.authorization, func(parameters *component.ControlParameters) bool {
+		return true
+	}, n.ListNeighbors); err != nil {
+		common.LogError("neighbor add list-command fail,the err is:", err)
+	}
+}
+
+// ListNeighbors 获取邻居表，包括每个邻居的发现方式、LogicFace 和存活时间
+//
+// @Description:
+// @receiver n
+// @param topPrefix
+// @param interest
+// @param parameters
+// @param context
+//
+func (n *NeighborManager) ListNeighbors(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters,
+	context *StatusDatasetContext) {
+	if n.neighborDiscovery == nil {
+		context.Reject(MakeControlResponse(400, "Neighbor discovery is not enabled!", ""))
+		return
+	}
+	for _, neighbor := range n.neighborDiscovery.GetNeighborTable().List() {
+		context.Append(neighbor)
+	}
+	_ = context.Done(common2.GetCurrentTime())
+}
@@ -0,0 +1,142 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 15:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"minlib/common"
+	"minlib/component"
+	"minlib/packet"
+	common2 "mir-go/daemon/common"
+	"mir-go/daemon/table"
+)
+
+// defaultPITListLimit 没有指定数量上限时，pit-mgmt/list 最多返回的表项数
+const defaultPITListLimit = 1000
+
+// PITInfo pit-mgmt/list 返回的 PIT 表项列表
+//
+// @Description:
+//
+type PITInfo struct {
+	Total   uint64                  // 匹配前缀的表项总数，可能大于 Entries 的长度
+	Entries []*table.PITEntryStatus // 表项的状态信息，按标识排序
+}
+
+// PitManager PIT 管理模块，只提供只读的数据集，用于排查兴趣包一直得不到满足的问题
+//
+// @Description:
+//
+type PitManager struct {
+	pit *table.PIT // PIT 表
+}
+
+// CreatePitManager 创建 PIT 管理模块
+//
+// @Description:
+// @return *PitManager
+//
+func CreatePitManager() *PitManager {
+	return &PitManager{}
+}
+
+// Init 注册 PIT 管理模块的数据集 list 和 count
+//
+// @Description:
+//  两个数据集都支持用 Prefix 参数过滤表项，list 还支持用 Count 参数限制返回的表项数
+// @receiver p
+// @param dispatcher
+//
+func (p *PitManager) Init(dispatcher *Dispatcher) {
+	validateParameters := func(parameters *component.ControlParameters) bool {
+		return true
+	}
+	identifier, _ := component.CreateIdentifierByString("/pit-mgmt/list")
+	if err := dispatcher.AddStatusDataset(identifier, dispatcher.authorization, validateParameters, p.ListEntries); err != nil {
+		common.LogError("pit add list-command fail,the err is:", err)
+	}
+	identifier, _ = component.CreateIdentifierByString("/pit-mgmt/count")
+	if err := dispatcher.AddStatusDataset(identifier, dispatcher.authorization, validateParameters, p.CountEntries); err != nil {
+		common.LogError("pit add count-command fail,the err is:", err)
+	}
+}
+
+// ListEntries 获取 PIT 表项列表，包括每个表项的流入、流出记录
+//
+// @Description:
+// @receiver p
+// @param topPrefix
+// @param interest
+// @param parameters
+// @param context
+//
+func (p *PitManager) ListEntries(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters,
+	context *StatusDatasetContext) {
+	if p.pit == nil {
+		context.Reject(MakeControlResponse(400, "PIT is not bound to PIT management module!", ""))
+		return
+	}
+	limit := defaultPITListLimit
+	if parameters != nil && parameters.ControlParameterCount.IsInitial() {
+		limit = int(parameters.ControlParameterCount.Count())
+	}
+	entries, total := p.pit.ListStatus(getPrefixFilter(parameters), limit)
+	context.Append(&PITInfo{
+		Total:   total,
+		Entries: entries,
+	})
+
+	// PIT 时刻在变化，使用当前时间作为版本号
+	_ = context.Done(common2.GetCurrentTime())
+}
+
+// CountEntries 获取 PIT 的统计信息
+//
+// @Description:
+// @receiver p
+// @param topPrefix
+// @param interest
+// @param parameters
+// @param context
+//
+func (p *PitManager) CountEntries(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters,
+	context *StatusDatasetContext) {
+	if p.pit == nil {
+		context.Reject(MakeControlResponse(400, "PIT is not bound to PIT management module!", ""))
+		return
+	}
+	context.Append(p.pit.GetSummary(getPrefixFilter(parameters)))
+	_ = context.Done(common2.GetCurrentTime())
+}
+
+//
+// @Description: 从命令参数中取出用于过滤表项的前缀，没有指定时返回 nil
+// @param parameters
+// @return *component.Identifier
+//
+func getPrefixFilter(parameters *component.ControlParameters) *component.Identifier {
+	if parameters == nil || !parameters.ControlParameterPrefix.IsInitial() {
+		return nil
+	}
+	return parameters.ControlParameterPrefix.Prefix()
+}
@@ -0,0 +1,129 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 23:50 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"minlib/component"
+	"minlib/mgmt"
+	"minlib/packet"
+	"mir-go/daemon/table"
+	"testing"
+)
+
+// 创建一个 PitManager，PIT 中有 /min/a 下的 5 个表项和 /min/b 下的 1000 个表项
+func newTestPitManager(t *testing.T) *PitManager {
+	pit := table.CreatePIT()
+	insert := func(name string) {
+		interest := new(packet.Interest)
+		interest.SetName(newTestIdentifier(t, name))
+		interest.SetNonce(1234)
+		pit.Insert(interest)
+	}
+	for i := 0; i < 5; i++ {
+		insert(fmt.Sprintf("/min/a/%d", i))
+	}
+	for i := 0; i < 1000; i++ {
+		insert(fmt.Sprintf("/min/b/%04d", i))
+	}
+	pitManager := CreatePitManager()
+	pitManager.pit = pit
+	return pitManager
+}
+
+// 执行一个数据集命令，把所有分片拼接起来之后反序列化到 result 中
+func runPitDataset(t *testing.T, handler func(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters, context *StatusDatasetContext),
+	parameters *component.ControlParameters, result interface{}) {
+	interest := new(packet.Interest)
+	interest.SetName(newTestIdentifier(t, "/min-mir/mgmt/localhost/pit-mgmt/list"))
+	var payload []byte
+	var response *mgmt.ControlResponse
+	context := CreateSDC(interest, nil, func(controlResponse *mgmt.ControlResponse, interest *packet.Interest) {
+		response = controlResponse
+	}, func(data *packet.Data) {
+		payload = append(payload, data.Payload.GetValue()...)
+	})
+	handler(nil, interest, parameters, context)
+	if response == nil || response.Code != mgmt.ControlResponseCodeSuccess {
+		t.Fatalf("unexpected response %+v", response)
+	}
+	if err := json.Unmarshal(payload, result); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPitManager_ListEntries(t *testing.T) {
+	pitManager := newTestPitManager(t)
+
+	// 没有指定 Count 时最多返回 defaultPITListLimit 个表项
+	var infos []*PITInfo
+	runPitDataset(t, pitManager.ListEntries, &component.ControlParameters{}, &infos)
+	if len(infos) != 1 || infos[0].Total != 1005 || len(infos[0].Entries) != defaultPITListLimit {
+		t.Fatalf("unexpected list result %d/%d", len(infos[0].Entries), infos[0].Total)
+	}
+	if infos[0].Entries[0].Name != newTestIdentifier(t, "/min/a/0").ToUri() {
+		t.Errorf("entries should be sorted by name, got %s first", infos[0].Entries[0].Name)
+	}
+
+	// Count 限制返回的表项数
+	parameters := &component.ControlParameters{}
+	parameters.SetCount(10)
+	infos = nil
+	runPitDataset(t, pitManager.ListEntries, parameters, &infos)
+	if infos[0].Total != 1005 || len(infos[0].Entries) != 10 {
+		t.Errorf("unexpected list result with count %d/%d", len(infos[0].Entries), infos[0].Total)
+	}
+
+	// Prefix 过滤表项
+	parameters = &component.ControlParameters{}
+	parameters.SetPrefix(newTestIdentifier(t, "/min/a"))
+	infos = nil
+	runPitDataset(t, pitManager.ListEntries, parameters, &infos)
+	if infos[0].Total != 5 || len(infos[0].Entries) != 5 {
+		t.Fatalf("unexpected list result with prefix %d/%d", len(infos[0].Entries), infos[0].Total)
+	}
+	for i, entry := range infos[0].Entries {
+		if entry.Name != newTestIdentifier(t, fmt.Sprintf("/min/a/%d", i)).ToUri() {
+			t.Errorf("unexpected entry %s at %d", entry.Name, i)
+		}
+	}
+}
+
+func TestPitManager_CountEntries(t *testing.T) {
+	pitManager := newTestPitManager(t)
+
+	var summaries []*table.PITSummary
+	runPitDataset(t, pitManager.CountEntries, nil, &summaries)
+	if len(summaries) != 1 || summaries[0].Entries != 1005 {
+		t.Fatalf("unexpected summary %+v", summaries)
+	}
+
+	parameters := &component.ControlParameters{}
+	parameters.SetPrefix(newTestIdentifier(t, "/min/a"))
+	summaries = nil
+	runPitDataset(t, pitManager.CountEntries, parameters, &summaries)
+	if len(summaries) != 1 || summaries[0].Entries != 5 {
+		t.Fatalf("unexpected summary with prefix %+v", summaries)
+	}
+}
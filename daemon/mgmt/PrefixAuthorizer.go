@@ -0,0 +1,211 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 18:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"fmt"
+	"minlib/component"
+	"minlib/packet"
+	"minlib/security"
+	common2 "mir-go/daemon/common"
+	"mir-go/daemon/lf"
+	"sort"
+	"strings"
+)
+
+// ControlResponseCodeForbidden 注册前缀的命令没有通过授权验证时返回的状态码
+const ControlResponseCodeForbidden = 403
+
+// TrustRule 信任策略中的一条规则
+//
+// @Description:
+//  签名身份以 Signer 为前缀时，可以注册被 Namespaces 中任意一个命名空间覆盖的前缀
+//
+type TrustRule struct {
+	Signer     string   // 签名身份的前缀
+	Namespaces []string // 允许注册的命名空间
+}
+
+// PrefixAuthorizer 前缀注册的授权验证
+//
+// @Description:
+//	fib add、前缀监听注册和 rib register 都可以把任意前缀指向某个 LogicFace，fib del 和 rib unregister 可以删除别人的路由，
+//	为了防止命名空间被劫持，开启之后这些命令需要满足：
+//	1. 命令兴趣包带有签名，签名身份存在于 KeyChain 中（本地创建或者通过 identity-mgmt/importCert 导入了证书），并且签名验证通过；
+//	2. 签名身份的证书覆盖要注册的前缀：身份名是前缀的前缀（AllowOwnNamespace），或者信任策略中有规则授权该身份注册这个命名空间。
+//	从本地 Unix LogicFace 和内部 LogicFace 收到的命令可以配置为免于验证。
+//
+type PrefixAuthorizer struct {
+	keyChain          *security.KeyChain
+	logicFaceTable    *lf.LogicFaceTable
+	exemptLocalFaces  bool
+	allowOwnNamespace bool
+	trustPolicy       []*TrustRule // 按 Signer 排序，保证日志和匹配结果稳定
+}
+
+// CreatePrefixAuthorizer 根据配置创建前缀注册的授权验证
+//
+// @Description:
+// @param config
+// @param keyChain
+// @param logicFaceTable
+// @return *PrefixAuthorizer
+//
+func CreatePrefixAuthorizer(config *common2.MIRConfig, keyChain *security.KeyChain,
+	logicFaceTable *lf.LogicFaceTable) *PrefixAuthorizer {
+	p := &PrefixAuthorizer{
+		keyChain:          keyChain,
+		logicFaceTable:    logicFaceTable,
+		exemptLocalFaces:  config.AuthorizationConfig.ExemptLocalFaces,
+		allowOwnNamespace: config.AuthorizationConfig.AllowOwnNamespace,
+	}
+	for signer, namespaces := range config.AuthorizationConfig.TrustPolicy {
+		p.AddTrustRule(signer, namespaces...)
+	}
+	return p
+}
+
+// AddTrustRule 在信任策略中添加一条规则
+//
+// @Description:
+// @receiver p
+// @param signer		签名身份的前缀
+// @param namespaces	允许注册的命名空间
+//
+func (p *PrefixAuthorizer) AddTrustRule(signer string, namespaces ...string) {
+	rule := &TrustRule{Signer: strings.TrimSpace(signer)}
+	for _, namespace := range namespaces {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			rule.Namespaces = append(rule.Namespaces, namespace)
+		}
+	}
+	p.trustPolicy = append(p.trustPolicy, rule)
+	sort.Slice(p.trustPolicy, func(i, j int) bool {
+		return p.trustPolicy[i].Signer < p.trustPolicy[j].Signer
+	})
+}
+
+// Authorize 验证注册 prefix 的命令兴趣包是否有权限
+//
+// @Description:
+// @receiver p
+// @param interest	注册前缀的命令兴趣包
+// @param prefix	要注册的前缀
+// @return error	没有权限时返回原因
+//
+func (p *PrefixAuthorizer) Authorize(interest *packet.Interest, prefix *component.Identifier) error {
+	if p.exemptLocalFaces && p.isLocalLogicFace(interest.IncomingLogicFaceId.GetIncomingLogicFaceId()) {
+		return nil
+	}
+	signerName, err := getSignerName(interest)
+	if err != nil {
+		return err
+	}
+	if p.keyChain.GetIdentityByName(signerName) == nil {
+		return PrefixAuthorizerError{msg: "the certificate of signer " + signerName + " is not in KeyChain"}
+	}
+	if err := p.keyChain.VerifyInterest(interest); err != nil {
+		return PrefixAuthorizerError{msg: "verify signature of " + signerName + " failed: " + err.Error()}
+	}
+	if !p.IsCovered(signerName, prefix.ToUri()) {
+		return PrefixAuthorizerError{msg: signerName + " is not allowed to register " + prefix.ToUri()}
+	}
+	return nil
+}
+
+// IsCovered 判断签名身份 signerName 是否有权注册前缀 prefix
+//
+// @Description:
+// @receiver p
+// @param signerName
+// @param prefix
+// @return bool
+//
+func (p *PrefixAuthorizer) IsCovered(signerName string, prefix string) bool {
+	if p.allowOwnNamespace && uriCovers(signerName, prefix) {
+		return true
+	}
+	for _, rule := range p.trustPolicy {
+		if !uriCovers(rule.Signer, signerName) {
+			continue
+		}
+		for _, namespace := range rule.Namespaces {
+			if uriCovers(namespace, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//
+// @Description: 判断 LogicFace 是否是本地的 Unix LogicFace 或者内部 LogicFace
+// @receiver p
+// @param logicFaceId
+// @return bool
+//
+func (p *PrefixAuthorizer) isLocalLogicFace(logicFaceId uint64) bool {
+	if p.logicFaceTable == nil {
+		return false
+	}
+	logicFace := p.logicFaceTable.GetLogicFacePtrById(logicFaceId)
+	if logicFace == nil {
+		return false
+	}
+	return logicFace.IsLocal()
+}
+
+//
+// @Description: 获取命令兴趣包的签名身份名，兴趣包没有签名时返回错误
+// @param interest
+// @return string
+// @return error
+//
+func getSignerName(interest *packet.Interest) (string, error) {
+	signature, err := interest.GetSignature(0)
+	if err != nil || signature == nil || signature.SigInfo == nil || signature.SigInfo.KeyLocator == nil {
+		return "", PrefixAuthorizerError{msg: "command interest is not signed"}
+	}
+	return signature.SigInfo.KeyLocator.ToUri(), nil
+}
+
+//
+// @Description: 判断 scope 是否覆盖 uri，即 scope 是 uri 本身或者 uri 的前缀
+// @param scope
+// @param uri
+// @return bool
+//
+func uriCovers(scope string, uri string) bool {
+	return scope == "/" || uri == scope || strings.HasPrefix(uri, scope+"/")
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type PrefixAuthorizerError struct {
+	msg string
+}
+
+func (p PrefixAuthorizerError) Error() string {
+	return fmt.Sprintf("PrefixAuthorizerError: %s", p.msg)
+}
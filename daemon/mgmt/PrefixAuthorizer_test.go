@@ -0,0 +1,215 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 18:30 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"minlib/component"
+	"minlib/packet"
+	"minlib/security"
+	"minlib/utils"
+	"mir-go/daemon/common"
+	"mir-go/daemon/fw"
+	"mir-go/daemon/lf"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrefixAuthorizer_IsCovered(t *testing.T) {
+	p := &PrefixAuthorizer{allowOwnNamespace: true}
+	p.AddTrustRule("/edu/pkusz/admin", "/edu/pkusz", " /video ")
+	p.AddTrustRule("/mir/router", "/")
+
+	cases := []struct {
+		signer string
+		prefix string
+		want   bool
+	}{
+		// 身份自己的命名空间
+		{"/edu/pkusz/alice", "/edu/pkusz/alice", true},
+		{"/edu/pkusz/alice", "/edu/pkusz/alice/video", true},
+		{"/edu/pkusz/alice", "/edu/pkusz/alicex", false},
+		{"/edu/pkusz/alice", "/edu/pkusz", false},
+		// 信任策略授权的命名空间，签名身份以规则中的 Signer 为前缀即可
+		{"/edu/pkusz/admin", "/edu/pkusz/bob", true},
+		{"/edu/pkusz/admin/ops", "/video/live", true},
+		{"/edu/pkusz/admin", "/edu/other", false},
+		{"/mir/router/0", "/anything", true},
+		{"/mir/routerx", "/anything", false},
+	}
+	for _, c := range cases {
+		if got := p.IsCovered(c.signer, c.prefix); got != c.want {
+			t.Errorf("IsCovered(%s, %s) = %v, want %v", c.signer, c.prefix, got, c.want)
+		}
+	}
+
+	// 关闭 AllowOwnNamespace 之后只能注册信任策略授权的命名空间
+	p.allowOwnNamespace = false
+	if p.IsCovered("/edu/pkusz/alice", "/edu/pkusz/alice/video") {
+		t.Error("own namespace should not be covered when AllowOwnNamespace is off")
+	}
+}
+
+// prefixAuthorizationTester 通过 Dispatcher 的 prefixAuthorization 验证命令，记录返回的状态码和拒绝的原因
+type prefixAuthorizationTester struct {
+	dispatcher *Dispatcher
+	authorizer *PrefixAuthorizer
+	topPrefix  *component.Identifier
+	keyChain   *security.KeyChain
+	udpFace    *lf.LogicFace // 非本地的 LogicFace
+	unixFace   *lf.LogicFace // 本地 Unix LogicFace
+	reason     string        // 最近一次拒绝的原因
+}
+
+func newPrefixAuthorizationTester(t *testing.T) *prefixAuthorizationTester {
+	config := &common.MIRConfig{}
+	config.Init()
+	var faceSystem lf.LogicFaceSystem
+	packetValidator := &fw.PacketValidator{}
+	packetValidator.Init(100, false, utils.NewBlockQueue(100))
+	faceSystem.Init(packetValidator, config)
+
+	keyChain := new(security.KeyChain)
+	if err := keyChain.InitialKeyChainByPath(filepath.Join(t.TempDir(), "identity.db")); err != nil {
+		t.Fatal(err)
+	}
+	tester := &prefixAuthorizationTester{
+		dispatcher: CreateDispatcher(config, keyChain),
+		authorizer: CreatePrefixAuthorizer(config, keyChain, faceSystem.LogicFaceTable()),
+		topPrefix:  newTestIdentifier(t, "/min-mir/mgmt/localhost"),
+		keyChain:   keyChain,
+	}
+	tester.dispatcher.topPrefixList[tester.topPrefix.ToUri()] = tester.topPrefix
+	tester.dispatcher.SetPrefixAuthorizer(tester.authorizer)
+
+	udpFace, err := lf.CreateUdpLogicFace("127.0.0.1:13999")
+	if err != nil || udpFace == nil {
+		t.Fatalf("create udp logic face failed: %v", err)
+	}
+	tester.udpFace = udpFace
+	unixPath := filepath.Join(t.TempDir(), "mir.sock")
+	listener, err := net.Listen("unix", unixPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conns := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			conns <- conn
+		}
+	}()
+	if tester.unixFace, err = lf.CreateUnixLogicFace(unixPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		tester.udpFace.Shutdown()
+		tester.unixFace.Shutdown()
+		_ = listener.Close()
+		select {
+		case conn := <-conns:
+			_ = conn.Close()
+		default:
+		}
+	})
+	return tester
+}
+
+// 创建一个身份并设为当前身份，之后的命令都由这个身份签名
+func (p *prefixAuthorizationTester) useIdentity(t *testing.T, name string) {
+	identity, err := p.keyChain.CreateIdentityByName(name, "mir-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.keyChain.SetCurrentIdentity(identity, "mir-test"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 构造一条注册 prefix 的命令，sign 为 true 时使用当前身份签名，返回 prefixAuthorization 的结果，200 表示通过
+func (p *prefixAuthorizationTester) authorize(t *testing.T, prefix string, sign bool, logicFace *lf.LogicFace) int {
+	parameters := &component.ControlParameters{}
+	parameters.SetPrefix(newTestIdentifier(t, prefix))
+	interest := new(packet.Interest)
+	interest.SetName(newTestIdentifier(t, "/min-mir/mgmt/localhost/rib-mgmt/register"))
+	if sign {
+		if err := p.keyChain.SignInterest(interest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	interest.IncomingLogicFaceId.SetIncomingLogicFaceId(logicFace.LogicFaceId)
+	code := 0
+	p.dispatcher.prefixAuthorization(p.topPrefix, interest, parameters, func() {
+		code = 200
+	}, func(errorType int, reason string) {
+		code = errorType
+		p.reason = reason
+	})
+	return code
+}
+
+func TestPrefixAuthorizer_Authorize(t *testing.T) {
+	tester := newPrefixAuthorizationTester(t)
+	tester.authorizer.AddTrustRule("/edu/pkusz/admin", "/edu/pkusz")
+
+	// 没有签名的命令
+	if code := tester.authorize(t, "/edu/pkusz/alice", false, tester.udpFace); code != ControlResponseCodeForbidden {
+		t.Errorf("unsigned command should be rejected with 403, got %d", code)
+	}
+
+	// 签名身份既不覆盖前缀，也没有被信任策略授权
+	tester.useIdentity(t, "/edu/pkusz/mallory")
+	if code := tester.authorize(t, "/edu/pkusz/alice", true, tester.udpFace); code != ControlResponseCodeForbidden {
+		t.Errorf("signer outside the policy should be rejected with 403, got %d", code)
+	}
+	// 拒绝的原因会放到 ControlResponse 中
+	if !strings.Contains(tester.reason, "/edu/pkusz/mallory is not allowed to register /edu/pkusz/alice") {
+		t.Errorf("reject reason should carry the authorize error, got %q", tester.reason)
+	}
+	if code := tester.authorize(t, "/edu/pkusz/mallory/video", true, tester.udpFace); code != 200 {
+		t.Errorf("signer should be able to register its own namespace, got %d", code)
+	}
+
+	// 信任策略授权的身份
+	tester.useIdentity(t, "/edu/pkusz/admin/ops")
+	if code := tester.authorize(t, "/edu/pkusz/alice", true, tester.udpFace); code != 200 {
+		t.Errorf("signer authorized by trust policy should be accepted, got %d", code)
+	}
+}
+
+func TestPrefixAuthorizer_ExemptLocalFaces(t *testing.T) {
+	tester := newPrefixAuthorizationTester(t)
+
+	// 从本地 Unix LogicFace 收到的命令免于验证，从其它 LogicFace 收到的不行
+	if code := tester.authorize(t, "/edu/pkusz/alice", false, tester.unixFace); code != 200 {
+		t.Errorf("command from local unix face should be exempted, got %d", code)
+	}
+	if code := tester.authorize(t, "/edu/pkusz/alice", false, tester.udpFace); code != ControlResponseCodeForbidden {
+		t.Errorf("command from udp face should be rejected with 403, got %d", code)
+	}
+
+	// 关闭 ExemptLocalFaces 之后本地命令同样需要签名
+	tester.authorizer.exemptLocalFaces = false
+	if code := tester.authorize(t, "/edu/pkusz/alice", false, tester.unixFace); code != ControlResponseCodeForbidden {
+		t.Errorf("command from local unix face should be verified when exemption is off, got %d", code)
+	}
+}
@@ -0,0 +1,490 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:50 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"fmt"
+	"minlib/common"
+	"minlib/component"
+	"minlib/mgmt"
+	"minlib/packet"
+	"minlib/security"
+	common2 "mir-go/daemon/common"
+	"mir-go/daemon/lf"
+	"mir-go/daemon/plugin"
+	"mir-go/daemon/table"
+	"mir-go/daemon/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LocalhopTopPrefix 相邻路由器之间发送管理命令使用的顶级前缀，只允许执行 rib-mgmt 的 register 和 unregister 命令
+const LocalhopTopPrefix = "/min-mir/mgmt/localhop"
+
+// pendingCommandTimeout 发往上游路由器的命令超过这么长时间（ms）没有收到回复，则不再等待
+const pendingCommandTimeout = 10000
+
+// PrefixPropagator 前缀传播服务
+//
+// @Description:
+//	1. 本地应用注册的前缀被某个传播范围（PropagationScopes）覆盖时，使用当前身份签名一个 rib-mgmt/register 命令，
+//	   通过上游 LogicFace（UpstreamPrefix 对应的 FIB 表项的下一跳，默认是默认路由）把这个范围注册到上游路由器；
+//	2. 上游路由器以 prefix(129) 来源、RouteLifetime 有效期写入 RIB，本服务每隔 RefreshInterval 刷新一次；
+//	3. 覆盖某个范围的最后一个应用注册被删除或者应用的 LogicFace 关闭之后，从上游路由器撤销这个范围。
+//	所有注册和撤销命令都放到一个队列中，由一个 worker 协程按顺序执行，保证同一个范围的注册和撤销不会乱序。
+//
+//	命令通过一对内部 LogicFace 发出，本服务作为插件拦截内部 LogicFace 发出的命令兴趣包，直接从上游 LogicFace 转发出去，
+//	并把上游路由器的回复交还给内部 LogicFace，所以本地的 LocalhopTopPrefix 不会截获发往上游的命令。
+//
+type PrefixPropagator struct {
+	plugin.BasePlugin
+	scopes          []*component.Identifier     // 传播范围，按长度从长到短排序
+	upstreamPrefix  *component.Identifier       // 这个前缀的 FIB 表项的下一跳作为上游 LogicFace
+	cost            uint64                      // 在上游路由器注册的路由开销
+	refreshInterval time.Duration               // 刷新注册的时间间隔
+	routeLifetime   uint64                      // 在上游路由器注册的路由的有效期（ms）
+	fib             *table.FIB                  // 用于查找上游 LogicFace
+	serverFace      *lf.LogicFace               // 内部 LogicFace 中转发器使用的一端
+	controller      *mgmt.MIRController         // 通过内部 LogicFace 发送签名命令
+	lock            sync.Mutex                  // 保护 propagated、pendingCommands 和 tasks
+	propagated      map[string]*propagatedScope // 范围 => 覆盖它的应用注册
+	pendingCommands map[string]uint64           // 已经发往上游、还没有收到回复的命令名 => 发出的时间（ms）
+	tasks           []*propagationTask          // 等待 worker 执行的注册和撤销命令
+	notify          chan struct{}               // 有新的命令放入 tasks 时通知 worker
+	stopChan        chan struct{}
+	// 执行一个 rib-mgmt 命令，默认通过内部 LogicFace 发往上游路由器
+	commandExecutor func(action string, parameters *component.ControlParameters) error
+}
+
+// propagationTask 一个等待发往上游路由器的注册或撤销命令
+type propagationTask struct {
+	scope    *component.Identifier
+	withdraw bool // true 表示撤销，false 表示注册或者刷新
+}
+
+// propagatedScope 一个已经传播到上游路由器的范围
+type propagatedScope struct {
+	scope   *component.Identifier
+	sources map[uint64]map[string]bool // LogicFaceId => 这个 LogicFace 上被范围覆盖的应用前缀
+}
+
+// CreatePrefixPropagator 根据配置创建前缀传播服务，需要在 LogicFaceSystem 初始化之后调用
+//
+// @Description:
+// @param config
+// @param fib
+// @param keyChain	用于给注册命令签名
+// @return *PrefixPropagator
+// @return error
+//
+func CreatePrefixPropagator(config *common2.MIRConfig, fib *table.FIB, keyChain *security.KeyChain) (*PrefixPropagator, error) {
+	propagationConfig := config.PropagationConfig
+	if propagationConfig.RouteLifetime <= propagationConfig.RefreshInterval {
+		return nil, PrefixPropagatorError{msg: "RouteLifetime must be greater than RefreshInterval"}
+	}
+	upstreamPrefix, err := component.CreateIdentifierByString(propagationConfig.UpstreamPrefix)
+	if err != nil {
+		return nil, err
+	}
+	p := &PrefixPropagator{
+		upstreamPrefix:  upstreamPrefix,
+		cost:            uint64(propagationConfig.PropagationCost),
+		refreshInterval: time.Duration(propagationConfig.RefreshInterval) * time.Millisecond,
+		routeLifetime:   uint64(propagationConfig.RouteLifetime),
+		fib:             fib,
+		propagated:      make(map[string]*propagatedScope),
+		pendingCommands: make(map[string]uint64),
+		notify:          make(chan struct{}, 1),
+		stopChan:        make(chan struct{}),
+	}
+	p.commandExecutor = p.executeCommand
+	for _, scope := range propagationConfig.PropagationScopes {
+		identifier, err := component.CreateIdentifierByString(strings.TrimSpace(scope))
+		if err != nil {
+			return nil, err
+		}
+		p.scopes = append(p.scopes, identifier)
+	}
+	// 优先匹配更长的范围
+	sort.Slice(p.scopes, func(i, j int) bool {
+		return p.scopes[i].Size() > p.scopes[j].Size()
+	})
+
+	serverFace, clientFace := lf.CreateInnerLogicFacePair()
+	p.serverFace = serverFace
+	p.controller = mgmt.CreateMIRController(func() (mgmt.IMgmtLogicFace, error) {
+		return clientFace, nil
+	}, true, keyChain)
+	return p, nil
+}
+
+// Start 启动执行命令的 worker 和周期性刷新注册的协程
+//
+// @Description:
+// @receiver p
+//
+func (p *PrefixPropagator) Start() {
+	utils.GoroutineNoPanic(p.runWorker)
+	utils.GoroutineNoPanic(func() {
+		ticker := time.NewTicker(p.refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stopChan:
+				return
+			case <-ticker.C:
+			}
+			p.refresh()
+		}
+	})
+}
+
+// Stop 停止刷新，已经传播的范围会在上游路由器上自然过期
+//
+// @Description:
+// @receiver p
+//
+func (p *PrefixPropagator) Stop() {
+	close(p.stopChan)
+}
+
+// OnAppRegister 本地应用注册了一个前缀，前缀被某个范围覆盖时把范围传播到上游路由器
+//
+// @Description:
+// @receiver p
+// @param prefix
+// @param logicFaceId	应用所在的 LogicFace
+//
+func (p *PrefixPropagator) OnAppRegister(prefix *component.Identifier, logicFaceId uint64) {
+	scope := p.matchScope(prefix)
+	if scope == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	entry, ok := p.propagated[scope.ToUri()]
+	if !ok {
+		entry = &propagatedScope{scope: scope, sources: make(map[uint64]map[string]bool)}
+		p.propagated[scope.ToUri()] = entry
+		p.addTask(scope, false)
+	}
+	if entry.sources[logicFaceId] == nil {
+		entry.sources[logicFaceId] = make(map[string]bool)
+	}
+	entry.sources[logicFaceId][prefix.ToUri()] = true
+}
+
+// OnAppUnregister 本地应用删除了一个前缀注册，范围不再被任何注册覆盖时从上游路由器撤销
+//
+// @Description:
+// @receiver p
+// @param prefix
+// @param logicFaceId
+//
+func (p *PrefixPropagator) OnAppUnregister(prefix *component.Identifier, logicFaceId uint64) {
+	scope := p.matchScope(prefix)
+	if scope == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	entry, ok := p.propagated[scope.ToUri()]
+	if !ok {
+		return
+	}
+	delete(entry.sources[logicFaceId], prefix.ToUri())
+	if len(entry.sources[logicFaceId]) == 0 {
+		delete(entry.sources, logicFaceId)
+	}
+	if p.removeIfUnused(entry) {
+		p.addTask(scope, true)
+	}
+}
+
+// OnFaceClosed 应用的 LogicFace 关闭之后，撤销不再被任何注册覆盖的范围
+//
+// @Description:
+// @receiver p
+// @param logicFaceId
+//
+func (p *PrefixPropagator) OnFaceClosed(logicFaceId uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, entry := range p.propagated {
+		if _, ok := entry.sources[logicFaceId]; !ok {
+			continue
+		}
+		delete(entry.sources, logicFaceId)
+		if p.removeIfUnused(entry) {
+			p.addTask(entry.scope, true)
+		}
+	}
+}
+
+// OnIncomingInterest 把内部 LogicFace 发出的命令兴趣包直接转发给上游路由器
+//
+// @Description:
+// @receiver p
+// @param ingress
+// @param interest
+// @return int
+//
+func (p *PrefixPropagator) OnIncomingInterest(ingress *lf.LogicFace, interest *packet.Interest) int {
+	if ingress != p.serverFace {
+		return 0
+	}
+	upstreamFaces := p.getUpstreamFaces()
+	if len(upstreamFaces) == 0 {
+		common.LogWarn("no upstream logic face to propagate prefix, drop ", interest.GetName().ToUri())
+		return -1
+	}
+	p.lock.Lock()
+	p.pendingCommands[interest.GetName().ToUri()] = common2.GetCurrentTime()
+	p.lock.Unlock()
+	for _, logicFace := range upstreamFaces {
+		logicFace.SendInterest(interest)
+	}
+	return -1
+}
+
+// OnIncomingData 把上游路由器对注册命令的回复交还给内部 LogicFace
+//
+// @Description:
+// @receiver p
+// @param ingress
+// @param data
+// @return int
+//
+func (p *PrefixPropagator) OnIncomingData(ingress *lf.LogicFace, data *packet.Data) int {
+	name := data.GetName().ToUri()
+	p.lock.Lock()
+	_, ok := p.pendingCommands[name]
+	delete(p.pendingCommands, name)
+	p.lock.Unlock()
+	if !ok {
+		return 0
+	}
+	p.serverFace.SendData(data)
+	return -1
+}
+
+//
+// @Description: 刷新所有已经传播的范围，并清理超时没有收到回复的命令
+// @receiver p
+//
+func (p *PrefixPropagator) refresh() {
+	now := common2.GetCurrentTime()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, entry := range p.propagated {
+		p.addTask(entry.scope, false)
+	}
+	for name, sendTime := range p.pendingCommands {
+		if sendTime+pendingCommandTimeout <= now {
+			delete(p.pendingCommands, name)
+		}
+	}
+}
+
+//
+// @Description: 把一个注册或撤销命令放入队列并通知 worker，需要持有 p.lock。
+//	队列中这个范围最后一个命令和新命令相同时不再重复放入，上游路由器响应很慢时刷新不会在队列中堆积
+// @receiver p
+// @param scope
+// @param withdraw
+//
+func (p *PrefixPropagator) addTask(scope *component.Identifier, withdraw bool) {
+	for i := len(p.tasks) - 1; i >= 0; i-- {
+		if p.tasks[i].scope.ToUri() != scope.ToUri() {
+			continue
+		}
+		if p.tasks[i].withdraw == withdraw {
+			return
+		}
+		break
+	}
+	p.tasks = append(p.tasks, &propagationTask{scope: scope, withdraw: withdraw})
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+//
+// @Description: 从队列中取出下一个命令，队列为空时返回 nil
+// @receiver p
+// @return *propagationTask
+//
+func (p *PrefixPropagator) nextTask() *propagationTask {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if len(p.tasks) == 0 {
+		return nil
+	}
+	task := p.tasks[0]
+	p.tasks[0] = nil
+	p.tasks = p.tasks[1:]
+	return task
+}
+
+//
+// @Description: worker 协程，按照放入的顺序逐个执行队列中的命令，直到 Stop 被调用
+// @receiver p
+//
+func (p *PrefixPropagator) runWorker() {
+	for {
+		select {
+		case <-p.stopChan:
+			return
+		case <-p.notify:
+		}
+		for task := p.nextTask(); task != nil; task = p.nextTask() {
+			if task.withdraw {
+				p.withdraw(task.scope)
+			} else {
+				p.register(task.scope)
+			}
+		}
+	}
+}
+
+//
+// @Description: 在上游路由器上注册一个范围
+// @receiver p
+// @param scope
+//
+func (p *PrefixPropagator) register(scope *component.Identifier) {
+	parameters := &component.ControlParameters{}
+	parameters.SetPrefix(scope)
+	parameters.SetCost(p.cost)
+	parameters.SetOrigin(table.RouteOriginPrefix)
+	parameters.SetExpirationPeriod(p.routeLifetime)
+	if err := p.commandExecutor("register", parameters); err != nil {
+		common.LogWarn("propagate ", scope.ToUri(), " to upstream failed: ", err)
+		return
+	}
+	common.LogDebug("propagate ", scope.ToUri(), " to upstream success")
+}
+
+//
+// @Description: 从上游路由器上撤销一个范围
+// @receiver p
+// @param scope
+//
+func (p *PrefixPropagator) withdraw(scope *component.Identifier) {
+	parameters := &component.ControlParameters{}
+	parameters.SetPrefix(scope)
+	parameters.SetOrigin(table.RouteOriginPrefix)
+	if err := p.commandExecutor("unregister", parameters); err != nil {
+		common.LogWarn("withdraw ", scope.ToUri(), " from upstream failed: ", err)
+		return
+	}
+	common.LogInfo("withdraw ", scope.ToUri(), " from upstream success")
+}
+
+//
+// @Description: 通过内部 LogicFace 发送一个签名的 rib-mgmt 命令，并等待上游路由器的回复，只在 worker 协程中调用
+// @receiver p
+// @param action
+// @param parameters
+// @return error
+//
+func (p *PrefixPropagator) executeCommand(action string, parameters *component.ControlParameters) error {
+	commandExecutor, err := p.controller.PrepareCommandExecutor(
+		mgmt.CreateControlCommand(LocalhopTopPrefix, "rib-mgmt", action, parameters))
+	if err != nil {
+		return err
+	}
+	// 内部 LogicFace 需要一直复用，不能在命令执行完之后关闭
+	commandExecutor.SetAutoShutdown(false)
+	response, err := commandExecutor.Start()
+	if err != nil {
+		return err
+	}
+	if response.Code != mgmt.ControlResponseCodeSuccess {
+		return PrefixPropagatorError{msg: response.Msg}
+	}
+	return nil
+}
+
+//
+// @Description: 找到覆盖 prefix 的最长的传播范围，没有时返回 nil
+// @receiver p
+// @param prefix
+// @return *component.Identifier
+//
+func (p *PrefixPropagator) matchScope(prefix *component.Identifier) *component.Identifier {
+	uri := prefix.ToUri()
+	for _, scope := range p.scopes {
+		if uriCovers(scope.ToUri(), uri) {
+			return scope
+		}
+	}
+	return nil
+}
+
+//
+// @Description: 范围不再被任何注册覆盖时删除它，需要持有 p.lock
+// @receiver p
+// @param entry
+// @return bool	是否删除了，删除之后需要从上游路由器撤销
+//
+func (p *PrefixPropagator) removeIfUnused(entry *propagatedScope) bool {
+	if len(entry.sources) > 0 {
+		return false
+	}
+	delete(p.propagated, entry.scope.ToUri())
+	return true
+}
+
+//
+// @Description: 获取上游 LogicFace，即 UpstreamPrefix 对应的 FIB 表项的所有下一跳
+// @receiver p
+// @return []*lf.LogicFace
+//
+func (p *PrefixPropagator) getUpstreamFaces() []*lf.LogicFace {
+	fibEntry := p.fib.FindExactMatch(p.upstreamPrefix)
+	if fibEntry == nil {
+		return nil
+	}
+	var logicFaces []*lf.LogicFace
+	for _, nextHop := range fibEntry.GetNextHops() {
+		if nextHop.LogicFace != nil && nextHop.LogicFace.IsUp() {
+			logicFaces = append(logicFaces, nextHop.LogicFace)
+		}
+	}
+	return logicFaces
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type PrefixPropagatorError struct {
+	msg string
+}
+
+func (p PrefixPropagatorError) Error() string {
+	return fmt.Sprintf("PrefixPropagatorError: %s", p.msg)
+}
@@ -0,0 +1,184 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 23:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"minlib/component"
+	"minlib/packet"
+	"minlib/utils"
+	"mir-go/daemon/common"
+	"mir-go/daemon/fw"
+	"mir-go/daemon/lf"
+	"mir-go/daemon/table"
+	"testing"
+	"time"
+)
+
+// 创建一个前缀传播服务，发往上游路由器的命令以 "<action> <prefix>" 的形式记录到返回的 chan 中
+func newTestPrefixPropagator(t *testing.T, refreshInterval int, scopes ...string) (*PrefixPropagator, chan string) {
+	config := &common.MIRConfig{}
+	config.Init()
+	var faceSystem lf.LogicFaceSystem
+	packetValidator := &fw.PacketValidator{}
+	packetValidator.Init(100, false, utils.NewBlockQueue(100))
+	faceSystem.Init(packetValidator, config)
+
+	config.PropagationConfig.PropagationScopes = scopes
+	config.PropagationConfig.RefreshInterval = refreshInterval
+	config.PropagationConfig.RouteLifetime = refreshInterval * 4
+	p, err := CreatePrefixPropagator(config, table.CreateFIB(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commands := make(chan string, 100)
+	p.commandExecutor = func(action string, parameters *component.ControlParameters) error {
+		commands <- action + " " + parameters.Prefix().ToUri()
+		return nil
+	}
+	return p, commands
+}
+
+func newTestIdentifier(t *testing.T, name string) *component.Identifier {
+	identifier, err := component.CreateIdentifierByString(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return identifier
+}
+
+func expectCommands(t *testing.T, commands chan string, expect ...string) {
+	for _, command := range expect {
+		select {
+		case got := <-commands:
+			if got != command {
+				t.Fatalf("expect command %s, got %s", command, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for command %s", command)
+		}
+	}
+}
+
+func expectNoCommand(t *testing.T, commands chan string) {
+	select {
+	case got := <-commands:
+		t.Fatalf("unexpected command %s", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestPrefixPropagator_Register(t *testing.T) {
+	p, commands := newTestPrefixPropagator(t, 60000, "/pku", "/pku/video")
+	p.Start()
+	defer p.Stop()
+
+	// 前缀被多个范围覆盖时传播最长的范围，同一个范围只注册一次
+	p.OnAppRegister(newTestIdentifier(t, "/pku/video/live"), 1)
+	p.OnAppRegister(newTestIdentifier(t, "/pku/app"), 1)
+	p.OnAppRegister(newTestIdentifier(t, "/pku/app2"), 2)
+	expectCommands(t, commands, "register /pku/video", "register /pku")
+	// 没有被任何范围覆盖的前缀不传播
+	p.OnAppRegister(newTestIdentifier(t, "/pkusz/app"), 1)
+	expectNoCommand(t, commands)
+}
+
+func TestPrefixPropagator_Refresh(t *testing.T) {
+	p, commands := newTestPrefixPropagator(t, 50, "/pku")
+	p.OnAppRegister(newTestIdentifier(t, "/pku/app"), 1)
+	// worker 还没有启动时多次刷新，队列中同一个范围的注册命令不会堆积
+	p.refresh()
+	p.refresh()
+	if len(p.tasks) != 1 {
+		t.Fatalf("refresh should not pile up register commands, got %d tasks", len(p.tasks))
+	}
+	p.Start()
+	defer p.Stop()
+	// 之后每隔 RefreshInterval 重新注册一次
+	expectCommands(t, commands, "register /pku", "register /pku", "register /pku")
+}
+
+func TestPrefixPropagator_Withdraw(t *testing.T) {
+	p, commands := newTestPrefixPropagator(t, 60000, "/pku")
+	p.OnAppRegister(newTestIdentifier(t, "/pku/app1"), 1)
+	p.OnAppRegister(newTestIdentifier(t, "/pku/app2"), 2)
+	p.OnAppRegister(newTestIdentifier(t, "/pku/app3"), 2)
+	p.Start()
+	defer p.Stop()
+	expectCommands(t, commands, "register /pku")
+
+	// 范围还被其它注册覆盖时不撤销
+	p.OnAppUnregister(newTestIdentifier(t, "/pku/app1"), 1)
+	p.OnAppUnregister(newTestIdentifier(t, "/pku/app2"), 2)
+	expectNoCommand(t, commands)
+
+	// 最后一个应用的 LogicFace 关闭之后撤销
+	p.OnFaceClosed(2)
+	expectCommands(t, commands, "unregister /pku")
+	p.OnFaceClosed(2)
+	p.OnAppUnregister(newTestIdentifier(t, "/pku/app3"), 2)
+	expectNoCommand(t, commands)
+}
+
+// 注册和撤销命令由同一个 worker 按顺序执行，不会乱序
+func TestPrefixPropagator_CommandOrder(t *testing.T) {
+	p, commands := newTestPrefixPropagator(t, 60000, "/pku")
+	prefix := newTestIdentifier(t, "/pku/app")
+	p.OnAppRegister(prefix, 1)
+	p.OnAppUnregister(prefix, 1)
+	p.OnAppRegister(prefix, 1)
+	p.OnFaceClosed(1)
+	p.Start()
+	defer p.Stop()
+	expectCommands(t, commands, "register /pku", "unregister /pku", "register /pku", "unregister /pku")
+	expectNoCommand(t, commands)
+}
+
+// 相邻路由器通过 LocalhopTopPrefix 只能执行 rib-mgmt 的 register 和 unregister
+func TestIsLocalhopCommand(t *testing.T) {
+	config := &common.MIRConfig{}
+	config.Init()
+	dispatcher := CreateDispatcher(config, nil)
+	topPrefix := newTestIdentifier(t, LocalhopTopPrefix)
+	dispatcher.topPrefixList[topPrefix.ToUri()] = topPrefix
+	for name, want := range map[string]bool{
+		LocalhopTopPrefix + "/rib-mgmt/register/params":   true,
+		LocalhopTopPrefix + "/rib-mgmt/unregister/params": true,
+		LocalhopTopPrefix + "/rib-mgmt/list/params":       false,
+		LocalhopTopPrefix + "/fib-mgmt/add/params":        false,
+		LocalhopTopPrefix + "/face-mgmt/destroy/params":   false,
+		LocalhopTopPrefix + "/rib-mgmt":                   false,
+	} {
+		identifier := newTestIdentifier(t, name)
+		if got := isLocalhopCommand(identifier); got != want {
+			t.Errorf("isLocalhopCommand(%s) = %v, want %v", name, got, want)
+		}
+		interest := new(packet.Interest)
+		interest.SetName(identifier)
+		accepted := false
+		dispatcher.authorization(topPrefix, interest, nil, func() {
+			accepted = true
+		}, func(code int) {})
+		if accepted != want {
+			t.Errorf("authorization of %s = %v, want %v", name, accepted, want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 16:45 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"github.com/sirupsen/logrus"
+	"minlib/common"
+	"minlib/component"
+	"minlib/mgmt"
+	"minlib/packet"
+	"mir-go/daemon/lf"
+	"mir-go/daemon/table"
+	"strconv"
+)
+
+// RibManager RIB 管理模块
+//
+// @Description:
+//  提供 register、unregister 两个控制命令和 list 数据集，路由写入 RIB 之后由 RIB 重新计算 FIB
+//
+type RibManager struct {
+	rib              *table.RIB        // RIB
+	prefixPropagator *PrefixPropagator // 前缀传播服务，不为空时把本地应用注册的前缀传播到上游路由器
+	logicFaceTable   *lf.LogicFaceTable
+}
+
+// CreateRibManager 创建 RIB 管理模块
+//
+// @Description:
+// @return *RibManager
+//
+func CreateRibManager() *RibManager {
+	return &RibManager{}
+}
+
+// Init 注册 RIB 管理模块的命令
+//
+// @Description:
+// @receiver r
+// @param dispatcher
+// @param logicFaceTable
+//
+func (r *RibManager) Init(dispatcher *Dispatcher, logicFaceTable *lf.LogicFaceTable) {
+	r.logicFaceTable = logicFaceTable
+	// /rib-mgmt/register => 添加或者更新一条路由
+	identifier, _ := component.CreateIdentifierByString("/rib-mgmt/register")
+	err := dispatcher.AddControlCommand(identifier, dispatcher.prefixAuthorization, func(parameters *component.ControlParameters) bool {
+		return parameters.ControlParameterPrefix.IsInitial()
+	}, r.Register)
+	if err != nil {
+		common.LogError("rib add register-command fail,the err is:", err)
+	}
+	// /rib-mgmt/unregister => 删除一条路由，和注册一样需要证书覆盖前缀的身份签名，防止删除别人的路由
+	identifier, _ = component.CreateIdentifierByString("/rib-mgmt/unregister")
+	err = dispatcher.AddControlCommand(identifier, dispatcher.prefixAuthorization, func(parameters *component.ControlParameters) bool {
+		return parameters.ControlParameterPrefix.IsInitial()
+	}, r.Unregister)
+	if err != nil {
+		common.LogError("rib add unregister-command fail,the err is:", err)
+	}
+	// /rib-mgmt/list => 展示所有路由
+	identifier, _ = component.CreateIdentifierByString("/rib-mgmt/list")
+	err = dispatcher.AddStatusDataset(identifier, dispatcher.authorization, func(parameters *component.ControlParameters) bool {
+		return true
+	}, r.ListEntries)
+	if err != nil {
+		common.LogError("rib add list-command fail,the err is:", err)
+	}
+}
+
+// Register 添加或者更新一条路由
+//
+// @Description:
+//  1. 没有指定 LogicFaceId 时使用收到命令的 LogicFace；
+//  2. 没有指定 Origin 时来源为 app，没有指定 Flags 时使用 ChildInherit；
+//  3. 指定了 ExpirationPeriod（毫秒）时，路由在到期之后自动删除；
+//  4. 通过 LocalhopTopPrefix 收到的命令来自下游路由器的前缀传播，只能以 prefix 来源注册到收到命令的 LogicFace 上。
+// @receiver r
+// @param topPrefix
+// @param interest
+// @param parameters
+// @return *mgmt.ControlResponse
+//
+func (r *RibManager) Register(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters) *mgmt.ControlResponse {
+	if r.rib == nil {
+		return MakeControlResponse(400, "RIB is not bound to RIB management module!", "")
+	}
+	prefix := parameters.ControlParameterPrefix.Prefix()
+	logicFaceId := r.getLogicFaceId(topPrefix, interest, parameters)
+	face := r.logicFaceTable.GetLogicFacePtrById(logicFaceId)
+	if face == nil {
+		common.LogDebugWithFields(logrus.Fields{
+			"prefix":      prefix.ToUri(),
+			"logicFaceId": strconv.FormatUint(logicFaceId, 10),
+		}, "the logicFace is not existed")
+		return MakeControlResponse(400, "the face is not found", "")
+	}
+
+	origin := r.getOrigin(topPrefix, parameters)
+	var cost uint64 = 0
+	if parameters.ControlParameterCost.IsInitial() {
+		cost = parameters.ControlParameterCost.Cost()
+	}
+	flags := table.RouteFlagChildInherit
+	if parameters.ControlParameterFlags.IsInitial() {
+		flags = parameters.ControlParameterFlags.Flags()
+	}
+	var expirationPeriod int64 = 0
+	if parameters.ControlParameterExpirationPeriod.IsInitial() {
+		expirationPeriod = int64(parameters.ControlParameterExpirationPeriod.ExpirationPeriod())
+	}
+
+	if err := r.rib.Register(prefix, face, origin, cost, flags, expirationPeriod); err != nil {
+		return MakeControlResponse(400, err.Error(), "")
+	}
+	common.LogInfo("Register route success:", prefix.ToUri(), "->", logicFaceId, ", origin =", origin)
+	if origin == table.RouteOriginApp && r.prefixPropagator != nil {
+		r.prefixPropagator.OnAppRegister(prefix, logicFaceId)
+	}
+	return MakeControlResponse(200, "register route success", "")
+}
+
+// Unregister 删除一条路由
+//
+// @Description:
+//  没有指定 LogicFaceId 时使用收到命令的 LogicFace，没有指定 Origin 时来源为 app，
+//  通过 LocalhopTopPrefix 收到的命令只能删除收到命令的 LogicFace 上 prefix 来源的路由
+// @receiver r
+// @param topPrefix
+// @param interest
+// @param parameters
+// @return *mgmt.ControlResponse
+//
+func (r *RibManager) Unregister(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters) *mgmt.ControlResponse {
+	if r.rib == nil {
+		return MakeControlResponse(400, "RIB is not bound to RIB management module!", "")
+	}
+	prefix := parameters.ControlParameterPrefix.Prefix()
+	logicFaceId := r.getLogicFaceId(topPrefix, interest, parameters)
+	origin := r.getOrigin(topPrefix, parameters)
+	if err := r.rib.Unregister(prefix, logicFaceId, origin); err != nil {
+		return MakeControlResponse(400, err.Error(), "")
+	}
+	common.LogInfo("Unregister route success:", prefix.ToUri(), "->", logicFaceId, ", origin =", origin)
+	if origin == table.RouteOriginApp && r.prefixPropagator != nil {
+		r.prefixPropagator.OnAppUnregister(prefix, logicFaceId)
+	}
+	return MakeControlResponse(200, "unregister route success", "")
+}
+
+// ListEntries 获取 RIB 中所有的路由
+//
+// @Description:
+// @receiver r
+// @param topPrefix
+// @param interest
+// @param parameters
+// @param context
+//
+func (r *RibManager) ListEntries(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters,
+	context *StatusDatasetContext) {
+	if r.rib == nil {
+		context.Reject(MakeControlResponse(400, "RIB is not bound to RIB management module!", ""))
+		return
+	}
+	for _, status := range r.rib.ListStatus() {
+		context.Append(status)
+	}
+	_ = context.Done(r.rib.GetVersion())
+}
+
+//
+// @Description: 获取命令中指定的 LogicFaceId，没有指定或者命令来自下游路由器时使用收到命令的 LogicFace
+// @receiver r
+// @param topPrefix
+// @param interest
+// @param parameters
+// @return uint64
+//
+func (r *RibManager) getLogicFaceId(topPrefix *component.Identifier, interest *packet.Interest,
+	parameters *component.ControlParameters) uint64 {
+	if parameters.ControlParameterLogicFaceId.IsInitial() && topPrefix.ToUri() != LocalhopTopPrefix {
+		return parameters.ControlParameterLogicFaceId.LogicFaceId()
+	}
+	return interest.IncomingLogicFaceId.GetIncomingLogicFaceId()
+}
+
+//
+// @Description: 获取命令中指定的路由来源，没有指定时为 app，命令来自下游路由器时固定为 prefix
+// @receiver r
+// @param topPrefix
+// @param parameters
+// @return uint64
+//
+func (r *RibManager) getOrigin(topPrefix *component.Identifier, parameters *component.ControlParameters) uint64 {
+	if topPrefix.ToUri() == LocalhopTopPrefix {
+		return table.RouteOriginPrefix
+	}
+	if parameters.ControlParameterOrigin.IsInitial() {
+		return parameters.ControlParameterOrigin.Origin()
+	}
+	return table.RouteOriginApp
+}
@@ -0,0 +1,387 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 18:00 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"minlib/common"
+	"mir-go/daemon/lf"
+	"mir-go/daemon/table"
+	"mir-go/daemon/utils"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RouteSnapshotVersion 当前路由快照格式的版本号
+const RouteSnapshotVersion = 1
+
+// routeStateCheckInterval 检查 RIB 版本号和 LogicFace 事件的时间间隔，只有发生变化时才会重新生成路由快照
+const routeStateCheckInterval = time.Second
+
+// RouteSnapshot 路由快照，路由状态文件和 mirc fib export/import 使用的 JSON 格式
+//
+// @Description:
+//  只包含持久化的 LogicFace（Persistence 不为 0 的 TCP、UDP、以太网 LogicFace）以及带 Persistent 标志的路由，
+//  LogicFace 使用对端地址表示，所以快照可以在重启之后或者在另一台路由器上重新建立
+//
+type RouteSnapshot struct {
+	Version uint64          // 快照格式的版本号
+	Links   []*LinkSnapshot // 按 RemoteUri 排序
+}
+
+// LinkSnapshot 路由快照中的一个 LogicFace 以及以它为下一跳的路由
+//
+// @Description:
+//
+type LinkSnapshot struct {
+	RemoteUri   string                // 对端地址，eg: udp://192.168.3.7:13899 | tcp://192.168.3.7:13899 | ether://34:cf:f6:f8:6a:d8
+	LocalUri    string                // 以太网 LogicFace 使用的网卡名，其它类型为空
+	Persistence uint64                // LogicFace 的 Persistence 属性
+	Tags        map[string]string     // LogicFace 的标签
+	Routes      []*RouteSnapshotEntry // 按前缀和来源排序
+}
+
+// RouteSnapshotEntry 路由快照中的一条路由
+//
+// @Description:
+//
+type RouteSnapshotEntry struct {
+	Identifier string // 前缀
+	Cost       uint64 // 路由开销
+	Origin     uint64 // 路由的来源
+	Flags      uint64 // 路由的标志位
+}
+
+// BuildRouteSnapshot 根据 LogicFaceTable 和 RIB 生成路由快照
+//
+// @Description:
+// @param rib
+// @param logicFaceTable
+// @return *RouteSnapshot
+//
+func BuildRouteSnapshot(rib *table.RIB, logicFaceTable *lf.LogicFaceTable) *RouteSnapshot {
+	links := make(map[uint64]*LinkSnapshot)
+	getLink := func(logicFace *lf.LogicFace) *LinkSnapshot {
+		link, ok := links[logicFace.LogicFaceId]
+		if !ok {
+			link = &LinkSnapshot{
+				RemoteUri:   logicFace.GetRemoteUri(),
+				Persistence: logicFace.Persistence,
+				Tags:        logicFace.GetTags(),
+				Routes:      []*RouteSnapshotEntry{},
+			}
+			if logicFace.GetLogicFaceType() == lf.LogicFaceTypeEther {
+				link.LocalUri = etherInterfaceName(logicFace.GetLocalUri())
+			}
+			links[logicFace.LogicFaceId] = link
+		}
+		return link
+	}
+
+	logicFaceTable.Range(func(logicFaceId uint64, logicFace *lf.LogicFace) bool {
+		if logicFace.Persistence != 0 && isSnapshotLogicFace(logicFace) {
+			getLink(logicFace)
+		}
+		return true
+	})
+	for _, entry := range rib.ListStatus() {
+		for _, route := range entry.Routes {
+			if route.Flags&table.RouteFlagPersistent == 0 {
+				continue
+			}
+			logicFace := logicFaceTable.GetLogicFacePtrById(route.LogicFaceId)
+			if logicFace == nil || !isSnapshotLogicFace(logicFace) {
+				continue
+			}
+			link := getLink(logicFace)
+			link.Routes = append(link.Routes, &RouteSnapshotEntry{
+				Identifier: entry.Prefix,
+				Cost:       route.Cost,
+				Origin:     route.Origin,
+				Flags:      route.Flags,
+			})
+		}
+	}
+
+	snapshot := &RouteSnapshot{Version: RouteSnapshotVersion, Links: make([]*LinkSnapshot, 0, len(links))}
+	for _, link := range links {
+		sort.Slice(link.Routes, func(i, j int) bool {
+			if link.Routes[i].Identifier != link.Routes[j].Identifier {
+				return link.Routes[i].Identifier < link.Routes[j].Identifier
+			}
+			return link.Routes[i].Origin < link.Routes[j].Origin
+		})
+		snapshot.Links = append(snapshot.Links, link)
+	}
+	sort.Slice(snapshot.Links, func(i, j int) bool {
+		return snapshot.Links[i].RemoteUri < snapshot.Links[j].RemoteUri
+	})
+	return snapshot
+}
+
+// ParseRouteSnapshot 从 JSON 中解析路由快照
+//
+// @Description:
+// @param buf
+// @return *RouteSnapshot
+// @return error
+//
+func ParseRouteSnapshot(buf []byte) (*RouteSnapshot, error) {
+	snapshot := new(RouteSnapshot)
+	if err := json.Unmarshal(buf, snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Version != RouteSnapshotVersion {
+		return nil, RouteSnapshotError{msg: fmt.Sprintf("unsupported snapshot version %d", snapshot.Version)}
+	}
+	for _, link := range snapshot.Links {
+		faceUri, err := lf.ParseFaceUri(link.RemoteUri)
+		if err != nil {
+			return nil, RouteSnapshotError{msg: "remote uri is wrong, " + err.Error()}
+		}
+		// 恢复时按照规范形式匹配已经存在的 LogicFace
+		link.RemoteUri = faceUri.String()
+	}
+	return snapshot, nil
+}
+
+// LoadRouteSnapshot 从文件中读取路由快照
+//
+// @Description:
+// @param path
+// @return *RouteSnapshot
+// @return error
+//
+func LoadRouteSnapshot(path string) (*RouteSnapshot, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRouteSnapshot(buf)
+}
+
+// SaveRouteSnapshot 把路由快照写入文件，先写入临时文件再重命名，避免写到一半时退出导致文件损坏
+//
+// @Description:
+// @param path
+// @param snapshot
+// @return error
+//
+func SaveRouteSnapshot(path string, snapshot *RouteSnapshot) error {
+	buf, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf)
+}
+
+// RouteStateStore 路由状态文件
+//
+// @Description:
+//  RIB 的版本号变化或者收到 LogicFace 事件之后重新生成路由快照，和上次写入的内容不同时写入路由状态文件，
+//  启动时由 MIRStarter 读取并重新建立。LogicFace 事件要等到下一次检查时才处理，这样创建者在创建之后设置的
+//  Persistence 和标签也能被写入快照
+//
+type RouteStateStore struct {
+	path           string
+	rib            *table.RIB
+	logicFaceTable *lf.LogicFaceTable
+	lastSaved      []byte // 上次写入文件的内容
+	savedVersion   uint64 // 上次生成快照时 RIB 的版本号
+	faceChanged    uint32 // 上次生成快照之后是否收到过 LogicFace 事件，原子操作
+	stopChan       chan struct{}
+}
+
+// CreateRouteStateStore 创建路由状态文件
+//
+// @Description:
+// @param path
+// @param rib
+// @param logicFaceTable
+// @return *RouteStateStore
+//
+func CreateRouteStateStore(path string, rib *table.RIB, logicFaceTable *lf.LogicFaceTable) *RouteStateStore {
+	r := &RouteStateStore{
+		path:           path,
+		rib:            rib,
+		logicFaceTable: logicFaceTable,
+		stopChan:       make(chan struct{}),
+	}
+	logicFaceTable.AddEventListener(func(event *lf.FaceEvent) {
+		atomic.StoreUint32(&r.faceChanged, 1)
+	})
+	return r
+}
+
+// Load 读取上次保存的路由状态，文件不存在时返回 nil
+//
+// @Description:
+// @receiver r
+// @return *RouteSnapshot
+// @return error
+//
+func (r *RouteStateStore) Load() (*RouteSnapshot, error) {
+	buf, err := ioutil.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.lastSaved = buf
+	return ParseRouteSnapshot(buf)
+}
+
+// Start 启动保存路由状态的协程，需要在路由状态恢复之后调用，否则恢复之前的空状态会覆盖路由状态文件
+//
+// @Description:
+// @receiver r
+//
+func (r *RouteStateStore) Start() {
+	utils.GoroutineNoPanic(func() {
+		ticker := time.NewTicker(routeStateCheckInterval)
+		defer ticker.Stop()
+		r.save()
+		for {
+			select {
+			case <-r.stopChan:
+				return
+			case <-ticker.C:
+				r.saveIfChanged()
+			}
+		}
+	})
+}
+
+// Stop 停止保存路由状态
+//
+// @Description:
+// @receiver r
+//
+func (r *RouteStateStore) Stop() {
+	close(r.stopChan)
+}
+
+//
+// @Description: RIB 的版本号发生变化或者收到过 LogicFace 事件时重新保存路由状态，否则不需要重新生成路由快照
+// @receiver r
+//
+func (r *RouteStateStore) saveIfChanged() {
+	if r.rib.GetVersion() == r.savedVersion && atomic.LoadUint32(&r.faceChanged) == 0 {
+		return
+	}
+	r.save()
+}
+
+//
+// @Description: 生成路由快照，和上次写入的内容不同时写入路由状态文件。先记录版本号和清除事件标志再生成快照，
+//	生成快照期间发生的变化会在下一次检查时处理
+// @receiver r
+//
+func (r *RouteStateStore) save() {
+	r.savedVersion = r.rib.GetVersion()
+	atomic.StoreUint32(&r.faceChanged, 0)
+	buf, err := json.MarshalIndent(BuildRouteSnapshot(r.rib, r.logicFaceTable), "", "  ")
+	if err != nil {
+		common.LogError("marshal route state failed: ", err)
+		return
+	}
+	if bytes.Equal(buf, r.lastSaved) {
+		return
+	}
+	if err := writeFileAtomic(r.path, buf); err != nil {
+		common.LogError("save route state to ", r.path, " failed: ", err)
+		// 下一次检查时重试
+		atomic.StoreUint32(&r.faceChanged, 1)
+		return
+	}
+	r.lastSaved = buf
+	common.LogDebug("save route state to ", r.path)
+}
+
+//
+// @Description: 判断 LogicFace 能否写入路由快照，只有主动连接到其它路由器的 TCP、TLS、WebSocket、UDP、以太网单播 LogicFace 才能重新建立
+// @param logicFace
+// @return bool
+//
+func isSnapshotLogicFace(logicFace *lf.LogicFace) bool {
+	if !logicFace.GetState() || logicFace.IsMulticast() {
+		return false
+	}
+	switch logicFace.GetLogicFaceType() {
+	case lf.LogicFaceTypeTCP, lf.LogicFaceTypeTLS, lf.LogicFaceTypeWebSocket, lf.LogicFaceTypeUDP, lf.LogicFaceTypeEther:
+		return !strings.HasSuffix(logicFace.GetRemoteUri(), "://nil")
+	}
+	return false
+}
+
+//
+// @Description: 根据以太网 LogicFace 的本地地址（ether://<MAC>）找到对应的网卡名，找不到时返回空字符串
+// @param localUri
+// @return string
+//
+func etherInterfaceName(localUri string) string {
+	mac := strings.TrimPrefix(localUri, "ether://")
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+	for _, ifi := range interfaces {
+		if strings.EqualFold(ifi.HardwareAddr.String(), mac) {
+			return ifi.Name
+		}
+	}
+	return ""
+}
+
+//
+// @Description: 先写入临时文件再重命名，保证文件内容要么是旧的要么是新的
+// @param path
+// @param buf
+// @return error
+//
+func writeFileAtomic(path string, buf []byte) error {
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type RouteSnapshotError struct {
+	msg string
+}
+
+func (r RouteSnapshotError) Error() string {
+	return fmt.Sprintf("RouteSnapshotError: %s", r.msg)
+}
@@ -0,0 +1,146 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package mgmt
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 18:10 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package mgmt
+
+import (
+	"io/ioutil"
+	"minlib/component"
+	"mir-go/daemon/lf"
+	"mir-go/daemon/table"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRouteSnapshotSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "route-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := &RouteSnapshot{
+		Version: RouteSnapshotVersion,
+		Links: []*LinkSnapshot{
+			{
+				RemoteUri:   "ether://34:cf:f6:f8:6a:d8",
+				LocalUri:    "eth0",
+				Persistence: 1,
+				Routes: []*RouteSnapshotEntry{
+					{Identifier: "/edu/pkusz", Cost: 10, Origin: 255, Flags: 5},
+				},
+			},
+			{
+				RemoteUri:   "tcp://192.168.3.7:13899",
+				Persistence: 1,
+			},
+		},
+	}
+	path := filepath.Join(dir, "routeState.json")
+	if err := SaveRouteSnapshot(path, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadRouteSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snapshot, loaded) {
+		t.Fatalf("loaded snapshot %+v, want %+v", loaded, snapshot)
+	}
+	// 临时文件在重命名之后不应该残留
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatal("temporary file is left behind")
+	}
+}
+
+func TestParseRouteSnapshot(t *testing.T) {
+	if _, err := ParseRouteSnapshot([]byte(`{"Version":2,"Links":[]}`)); err == nil {
+		t.Fatal("unsupported version should be rejected")
+	}
+	if _, err := ParseRouteSnapshot([]byte(`{"Version":1,"Links":[{"RemoteUri":"192.168.3.7:13899"}]}`)); err == nil {
+		t.Fatal("remote uri without scheme should be rejected")
+	}
+	if _, err := ParseRouteSnapshot([]byte(`{"Version":1,"Links":[{"RemoteUri":"udp://192.168.3.7:0"}]}`)); err == nil {
+		t.Fatal("remote uri with invalid port should be rejected")
+	}
+	if _, err := ParseRouteSnapshot([]byte(`{"Version":1,"Links":[{"RemoteUri":"foo://192.168.3.7:13899"}]}`)); err == nil {
+		t.Fatal("remote uri with unknown scheme should be rejected")
+	}
+	snapshot, err := ParseRouteSnapshot([]byte(`{"Version":1,"Links":[{"RemoteUri":"UDP://192.168.3.7:13899"},` +
+		`{"RemoteUri":"ether://34:CF:F6:F8:6A:D8"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Links[0].RemoteUri != "udp://192.168.3.7:13899" || snapshot.Links[1].RemoteUri != "ether://34:cf:f6:f8:6a:d8" {
+		t.Fatalf("remote uri should be canonical, got %s %s", snapshot.Links[0].RemoteUri, snapshot.Links[1].RemoteUri)
+	}
+}
+
+// 只有 RIB 的版本号变化或者收到 LogicFace 事件之后才重新生成路由快照
+func TestRouteStateStore_SaveIfChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routeState.json")
+	rib := table.CreateRIB(table.CreateFIB())
+	logicFaceTable := new(lf.LogicFaceTable)
+	logicFaceTable.Init()
+	store := CreateRouteStateStore(path, rib, logicFaceTable)
+
+	// 清除上次写入的内容，能否重新生成文件说明有没有重新生成快照
+	saved := func() bool {
+		_, err := os.Stat(path)
+		if err == nil {
+			_ = os.Remove(path)
+			store.lastSaved = nil
+		}
+		return err == nil
+	}
+	store.save()
+	if !saved() {
+		t.Fatal("route state should be saved on start")
+	}
+	store.saveIfChanged()
+	if saved() {
+		t.Fatal("route state should not be rebuilt without any change")
+	}
+
+	identifier, err := component.CreateIdentifierByString("/min/pku")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rib.Register(identifier, &lf.LogicFace{LogicFaceId: 1}, table.RouteOriginStatic, 0, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	store.saveIfChanged()
+	if !saved() {
+		t.Fatal("route state should be rebuilt after RIB changed")
+	}
+
+	logicFaceTable.AddLogicFace(new(lf.LogicFace))
+	store.saveIfChanged()
+	if !saved() {
+		t.Fatal("route state should be rebuilt after face event")
+	}
+	store.saveIfChanged()
+	if saved() {
+		t.Fatal("route state should not be rebuilt twice for one change")
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package cmd
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 10:40 上午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/desertbit/grumble"
+	"github.com/olekukonko/tablewriter"
+	"minlib/common"
+	"minlib/component"
+	mgmtlib "minlib/mgmt"
+	"mir-go/daemon/mgmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CS 管理模块名以及支持的行为
+const (
+	csManagementModule                 = "cs-mgmt"
+	csManagementActionList             = "list"
+	csManagementActionUnsolicitedSet   = "unsolicited-set"
+	csManagementActionUnsolicitedUnset = "unsolicited-unset"
+	csManagementActionUnsolicitedList  = "unsolicited-list"
+)
+
+// CreateCsCommands 创建一个 CsCommands
+//
+// @Description:
+// @param controller
+// @return *grumble.Command
+//
+func CreateCsCommands(controller *mgmtlib.MIRController) *grumble.Command {
+	cc := new(grumble.Command)
+	cc.Name = "cs"
+	cc.Help = "Content Store Management"
+
+	// info
+	cc.AddCommand(&grumble.Command{
+		Name: "info",
+		Help: "Show content store status of every partition",
+		Run: func(c *grumble.Context) error {
+			return ShowCsInfo(c, controller)
+		},
+	})
+
+	// unsolicited
+	uc := &grumble.Command{
+		Name: "unsolicited",
+		Help: "Unsolicited data admission policy management",
+	}
+	uc.AddCommand(&grumble.Command{
+		Name: "set",
+		Help: "Set unsolicited data policy (drop-all | admit-local | admit-network | admit-all) for specific prefix",
+		Args: func(a *grumble.Args) {
+			a.String("prefix", "Target identifier prefix")
+			a.String("policy", "drop-all | admit-local | admit-network | admit-all")
+		},
+		Run: func(c *grumble.Context) error {
+			return SetUnsolicitedDataPolicy(c, controller)
+		},
+	})
+	uc.AddCommand(&grumble.Command{
+		Name: "unset",
+		Help: "Unset unsolicited data policy of specific prefix",
+		Args: func(a *grumble.Args) {
+			a.String("prefix", "Target identifier prefix")
+		},
+		Run: func(c *grumble.Context) error {
+			return UnsetUnsolicitedDataPolicy(c, controller)
+		},
+	})
+	uc.AddCommand(&grumble.Command{
+		Name: "list",
+		Help: "Show default and per-prefix unsolicited data policies",
+		Run: func(c *grumble.Context) error {
+			return ListUnsolicitedDataPolicies(c, controller)
+		},
+	})
+	cc.AddCommand(uc)
+
+	return cc
+}
+
+// ShowCsInfo 显示CS的状态信息，包括每个分区的容量、已缓存数量和命中统计
+//
+// @Description:
+// @param c
+// @return error
+//
+func ShowCsInfo(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	// 构造一个命令执行器
+	commandExecutor, err := controller.PrepareCommandExecutor(newControlCommand(csManagementModule, csManagementActionList, nil))
+	if err != nil {
+		return err
+	}
+	commandExecutor.SetAutoShutdown(true)
+
+	// 执行命令
+	response, err := commandExecutor.Start()
+	if err != nil {
+		return err
+	}
+
+	// 反序列化，输出结果
+	var csInfoList []mgmt.CSInfo
+	err = json.Unmarshal(response.GetBytes(), &csInfoList)
+	if err != nil {
+		return err
+	}
+	if len(csInfoList) == 0 {
+		return fmt.Errorf("empty cs info")
+	}
+	csInfo := csInfoList[0]
+
+	// 使用表格美化输出
+	table := tablewriter.NewWriter(os.Stdout)
+	for _, partition := range csInfo.Partitions {
+		table.Append([]string{
+			partition.Name,
+			strings.Join(partition.Prefixes, ","),
+			partition.ReplaceStrategy,
+			strconv.Itoa(partition.Size) + "/" + strconv.Itoa(partition.Capacity),
+			strconv.FormatUint(partition.Hits, 10),
+			strconv.FormatUint(partition.Misses, 10),
+			strconv.FormatUint(partition.Swept, 10),
+		})
+	}
+	table.SetHeader([]string{"Partition", "Prefixes", "Policy", "Size/Capacity", "Hits", "Misses", "Swept"})
+	table.SetHeaderColor(
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold})
+	table.SetCaption(true, fmt.Sprintf("CS Info (total size = %d)", csInfo.Size))
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.Render()
+	return nil
+}
+
+// SetUnsolicitedDataPolicy 给前缀绑定未请求数据的接纳策略
+//
+// @Description:
+// @param c
+// @param controller
+// @return error
+//
+func SetUnsolicitedDataPolicy(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	// 解析命令行参数
+	prefix := c.Args.String("prefix")
+	policy := c.Args.String("policy")
+
+	parameters := &component.ControlParameters{}
+	identifier, err := component.CreateIdentifierByString(prefix)
+	if err != nil {
+		return err
+	}
+	parameters.SetPrefix(identifier)
+	parameters.SetCommonString(policy)
+
+	response, err := executeCsControlCommand(controller, csManagementActionUnsolicitedSet, parameters)
+	if err != nil {
+		return err
+	}
+	if response.Code == mgmtlib.ControlResponseCodeSuccess {
+		common.LogInfo(fmt.Sprintf("Set unsolicited data policy %s => %s success!", prefix, policy))
+	} else {
+		common.LogError(fmt.Sprintf("Set unsolicited data policy %s => %s failed! errMsg: %s", prefix, policy, response.Msg))
+	}
+	return nil
+}
+
+// UnsetUnsolicitedDataPolicy 删除前缀绑定的未请求数据接纳策略
+//
+// @Description:
+// @param c
+// @param controller
+// @return error
+//
+func UnsetUnsolicitedDataPolicy(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	// 解析命令行参数
+	prefix := c.Args.String("prefix")
+
+	parameters := &component.ControlParameters{}
+	identifier, err := component.CreateIdentifierByString(prefix)
+	if err != nil {
+		return err
+	}
+	parameters.SetPrefix(identifier)
+
+	response, err := executeCsControlCommand(controller, csManagementActionUnsolicitedUnset, parameters)
+	if err != nil {
+		return err
+	}
+	if response.Code == mgmtlib.ControlResponseCodeSuccess {
+		common.LogInfo(fmt.Sprintf("Unset unsolicited data policy of %s success!", prefix))
+	} else {
+		common.LogError(fmt.Sprintf("Unset unsolicited data policy of %s failed! errMsg: %s", prefix, response.Msg))
+	}
+	return nil
+}
+
+// ListUnsolicitedDataPolicies 显示默认策略以及所有前缀绑定的未请求数据接纳策略
+//
+// @Description:
+// @param c
+// @param controller
+// @return error
+//
+func ListUnsolicitedDataPolicies(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	response, err := executeCsControlCommand(controller, csManagementActionUnsolicitedList, nil)
+	if err != nil {
+		return err
+	}
+
+	// 反序列化，输出结果
+	var policyInfoList []mgmt.UnsolicitedDataPolicyInfo
+	err = json.Unmarshal(response.GetBytes(), &policyInfoList)
+	if err != nil {
+		return err
+	}
+	if len(policyInfoList) == 0 {
+		return fmt.Errorf("empty unsolicited data policy info")
+	}
+	policyInfo := policyInfoList[0]
+
+	// 使用表格美化输出
+	table := tablewriter.NewWriter(os.Stdout)
+	for _, entry := range policyInfo.Policies {
+		table.Append([]string{entry.Prefix, entry.Policy})
+	}
+	table.SetHeader([]string{"Prefix", "Policy"})
+	table.SetHeaderColor(
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold})
+	table.SetCaption(true, fmt.Sprintf("Unsolicited Data Policies (default = %s)", policyInfo.DefaultPolicy))
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.Render()
+	return nil
+}
+
+//
+// @Description: 执行一个 CS 管理命令并返回响应
+// @param controller
+// @param action
+// @param parameters
+// @return *mgmtlib.ControlResponse
+// @return error
+//
+func executeCsControlCommand(controller *mgmtlib.MIRController, action string,
+	parameters *component.ControlParameters) (*mgmtlib.ControlResponse, error) {
+	// 构造一个命令执行器
+	commandExecutor, err := controller.PrepareCommandExecutor(newControlCommand(csManagementModule, action, parameters))
+	if err != nil {
+		return nil, err
+	}
+	commandExecutor.SetAutoShutdown(true)
+
+	// 执行命令
+	return commandExecutor.Start()
+}
@@ -30,11 +30,19 @@ import (
 	"minlib/common"
 	"minlib/component"
 	mgmtlib "minlib/mgmt"
+	"mir-go/daemon/lf"
 	"mir-go/daemon/mgmt"
 	"os"
 	"sort"
 	"strconv"
-	"strings"
+	"time"
+)
+
+// LogicFace 管理模块名以及 minlib 中没有预置命令构造函数的行为
+const (
+	faceManagementModule       = "face-mgmt"
+	faceManagementActionStatus = "status"
+	faceManagementActionEvents = "events"
 )
 
 // CreateLogicFaceCommands 创建一个 LogicFaceCommands 命令
@@ -52,21 +60,54 @@ func CreateLogicFaceCommands(controller *mgmtlib.MIRController) *grumble.Command
 	lfc.AddCommand(&grumble.Command{
 		Name: "list",
 		Help: "Show all LogicFace",
+		Flags: func(f *grumble.Flags) {
+			f.Bool("v", "verbose", false, "Show state, queues and counters of each LogicFace")
+		},
 		Run: func(c *grumble.Context) error {
+			if c.Flags.Bool("verbose") {
+				return ListLogicFaceStatus(c, controller)
+			}
 			return ListLogicFace(c, controller)
 		},
 	})
 
+	// show
+	lfc.AddCommand(&grumble.Command{
+		Name: "show",
+		Help: "Show state, queues and counters of a LogicFace",
+		Args: func(a *grumble.Args) {
+			a.Uint64("id", "The LogicFaceId you need to show")
+		},
+		Run: func(c *grumble.Context) error {
+			return ShowLogicFace(c, controller)
+		},
+	})
+
+	// events
+	lfc.AddCommand(&grumble.Command{
+		Name: "events",
+		Help: "Show LogicFace lifecycle events, or subscribe to them with --follow",
+		Flags: func(f *grumble.Flags) {
+			f.Uint64("s", "since", 0, "Only show events whose sequence number is greater than this")
+			f.Bool("f", "follow", false, "Keep polling and print new events until interrupted")
+			f.Duration("i", "interval", time.Second, "Polling interval when following")
+		},
+		Run: func(c *grumble.Context) error {
+			return ShowLogicFaceEvents(c, controller)
+		},
+	})
+
 	// add
 	lfc.AddCommand(&grumble.Command{
 		Name: "add",
 		Help: "Create new LogicFace",
 		Args: func(a *grumble.Args) {
-			a.String("remote", "Remote Uri to connect")
+			a.String("remote", "Remote Uri to connect, eg: tcp://192.168.3.7:13899 | udp6://[2001:db8::1]:13899")
 			a.String("local", "Local Uri", grumble.Default(""))
 		},
 		Flags: func(f *grumble.Flags) {
-			f.String("p", "persistence", "persist", "Persistence of LogicFace, persist/on-demand")
+			f.String("p", "persistence", "persistent", "Persistence of LogicFace, on-demand/persistent/permanent")
+			f.String("t", "tags", "", "Tags of LogicFace, eg: role=wan,site=bj")
 		},
 		Run: func(c *grumble.Context) error {
 			return AddLogicFace(c, controller)
@@ -120,13 +161,15 @@ func ListLogicFace(c *grumble.Context, controller *mgmtlib.MIRController) error
 		return faceInfoList[i].LogicFaceId < faceInfoList[j].LogicFaceId
 	})
 	for _, v := range faceInfoList {
-		table.Append([]string{strconv.FormatUint(v.LogicFaceId, 10), v.LocalUri, v.RemoteUri, strconv.FormatUint(v.Mtu, 10)})
+		table.Append([]string{strconv.FormatUint(v.LogicFaceId, 10), v.LocalUri, v.RemoteUri, strconv.FormatUint(v.Mtu, 10),
+			lf.FormatTags(v.Tags)})
 	}
-	table.SetHeader([]string{"LogicFaceId", "LocalUri", "RemoteUri", "Mtu"})
+	table.SetHeader([]string{"LogicFaceId", "LocalUri", "RemoteUri", "Mtu", "Tags"})
 	table.SetHeaderColor(
 		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
 		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
 		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
 		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold})
 	table.SetCaption(true, "LogicFace Table Info")
 	table.SetAlignment(tablewriter.ALIGN_CENTER)
@@ -134,6 +177,266 @@ func ListLogicFace(c *grumble.Context, controller *mgmtlib.MIRController) error
 	return nil
 }
 
+//
+// @Description: 拉取 LogicFace 的详细状态
+// @param controller
+// @param parameters	带有 LogicFaceId 时只拉取该 LogicFace 的状态
+// @return []mgmt.FaceStatus
+// @return error
+//
+func fetchLogicFaceStatus(controller *mgmtlib.MIRController, parameters *component.ControlParameters) ([]mgmt.FaceStatus, error) {
+	commandExecutor, err := controller.PrepareCommandExecutor(newControlCommand(faceManagementModule,
+		faceManagementActionStatus, parameters))
+	if err != nil {
+		return nil, err
+	}
+	commandExecutor.SetAutoShutdown(true)
+
+	// 执行命令拉取结果
+	response, err := commandExecutor.Start()
+	if err != nil {
+		return nil, err
+	}
+	if response.Code != mgmtlib.ControlResponseCodeSuccess {
+		return nil, FaceManagerCliError{msg: response.Msg}
+	}
+
+	// 反序列化
+	var faceStatusList []mgmt.FaceStatus
+	if err := json.Unmarshal(response.GetBytes(), &faceStatusList); err != nil {
+		return nil, err
+	}
+	sort.Slice(faceStatusList, func(i, j int) bool {
+		return faceStatusList[i].LogicFaceId < faceStatusList[j].LogicFaceId
+	})
+	return faceStatusList, nil
+}
+
+// ListLogicFaceStatus 获取所有 LogicFace 的状态、队列深度和统计信息并展示
+//
+// @Description:
+// @param c
+// @return error
+//
+func ListLogicFaceStatus(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	faceStatusList, err := fetchLogicFaceStatus(controller, &component.ControlParameters{})
+	if err != nil {
+		return err
+	}
+
+	// 使用表格美化输出，包和字节数都按 收/发/丢弃 的顺序展示
+	now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	table := tablewriter.NewWriter(os.Stdout)
+	for _, v := range faceStatusList {
+		counters := v.Counters
+		table.Append([]string{
+			strconv.FormatUint(v.LogicFaceId, 10),
+			v.LogicFaceType,
+			v.LocalUri,
+			v.RemoteUri,
+			v.State,
+			formatPersistency(v.Persistency),
+			formatFaceExpire(v, now),
+			fmt.Sprintf("%d/%d", v.SendQueueLen, v.SendQueueCap),
+			fmt.Sprintf("%d/%d/%d", counters.InInterestN, counters.OutInterestN, counters.DropInterestN),
+			fmt.Sprintf("%d/%d/%d", counters.InDataN, counters.OutDataN, counters.DropDataN),
+			fmt.Sprintf("%d/%d/%d", counters.InNackN, counters.OutNackN, counters.DropNackN),
+			fmt.Sprintf("%d/%d/%d", counters.InGPPktN, counters.OutGPPktN, counters.DropGPPktN),
+			fmt.Sprintf("%d/%d", counters.InBytesN, counters.OutBytesN),
+		})
+	}
+	header := []string{"LogicFaceId", "Type", "LocalUri", "RemoteUri", "State", "Persistency", "Expires", "SendQue",
+		"Interest(in/out/drop)", "Data(in/out/drop)", "Nack(in/out/drop)", "GPPkt(in/out/drop)", "Bytes(in/out)"}
+	headerColors := make([]tablewriter.Colors, len(header))
+	for i := range headerColors {
+		headerColors[i] = tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold}
+	}
+	table.SetHeader(header)
+	table.SetHeaderColor(headerColors...)
+	table.SetCaption(true, fmt.Sprintf("LogicFace Status (%d)", len(faceStatusList)))
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.Render()
+	return nil
+}
+
+// ShowLogicFace 获取一个 LogicFace 的状态、队列深度和所有统计信息并展示
+//
+// @Description:
+// @param c
+// @return error
+//
+func ShowLogicFace(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	parameters := new(component.ControlParameters)
+	parameters.SetLogicFaceId(c.Args.Uint64("id"))
+	faceStatusList, err := fetchLogicFaceStatus(controller, parameters)
+	if err != nil {
+		return err
+	}
+	if len(faceStatusList) == 0 {
+		return FaceManagerCliError{msg: "The logicFace is not existed"}
+	}
+	v := faceStatusList[0]
+	counters := v.Counters
+	now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	scope := "non-local"
+	if v.Local {
+		scope = "local"
+	}
+
+	// 使用两列的表格展示，每行一个属性
+	table := tablewriter.NewWriter(os.Stdout)
+	table.AppendBulk([][]string{
+		{"LogicFaceId", strconv.FormatUint(v.LogicFaceId, 10)},
+		{"Type", v.LogicFaceType},
+		{"LocalUri", v.LocalUri},
+		{"RemoteUri", v.RemoteUri},
+		{"State", v.State},
+		{"Persistency", formatPersistency(v.Persistency)},
+		{"Scope", scope},
+		{"Mtu", strconv.FormatUint(v.Mtu, 10)},
+		{"Tags", lf.FormatTags(v.Tags)},
+		{"Expires", formatFaceExpire(v, now)},
+		{"SendQueue", fmt.Sprintf("%d/%d", v.SendQueueLen, v.SendQueueCap)},
+		{"RecvQueue", fmt.Sprintf("%d/%d", v.RecvQueueLen, v.RecvQueueCap)},
+		{"LpReliability", v.LpReliability},
+		{"InInterest", strconv.FormatUint(counters.InInterestN, 10)},
+		{"OutInterest", strconv.FormatUint(counters.OutInterestN, 10)},
+		{"OutDropInterest", strconv.FormatUint(counters.OutDropInterestN, 10)},
+		{"DropInterest", strconv.FormatUint(counters.DropInterestN, 10)},
+		{"InData", strconv.FormatUint(counters.InDataN, 10)},
+		{"OutData", strconv.FormatUint(counters.OutDataN, 10)},
+		{"OutDropData", strconv.FormatUint(counters.OutDropDataN, 10)},
+		{"DropData", strconv.FormatUint(counters.DropDataN, 10)},
+		{"DropUnsolicitedData", strconv.FormatUint(counters.DropUnsolicitedDataN, 10)},
+		{"InNack", strconv.FormatUint(counters.InNackN, 10)},
+		{"OutNack", strconv.FormatUint(counters.OutNackN, 10)},
+		{"OutDropNack", strconv.FormatUint(counters.OutDropNackN, 10)},
+		{"DropNack", strconv.FormatUint(counters.DropNackN, 10)},
+		{"InGPPkt", strconv.FormatUint(counters.InGPPktN, 10)},
+		{"OutGPPkt", strconv.FormatUint(counters.OutGPPktN, 10)},
+		{"OutDropGPPkt", strconv.FormatUint(counters.OutDropGPPktN, 10)},
+		{"DropGPPkt", strconv.FormatUint(counters.DropGPPktN, 10)},
+		{"InBytes", strconv.FormatUint(counters.InBytesN, 10)},
+		{"OutBytes", strconv.FormatUint(counters.OutBytesN, 10)},
+		{"ScopeViolation", strconv.FormatUint(counters.ScopeViolationN, 10)},
+		{"LpRetransmit", strconv.FormatUint(counters.LpRetransmitN, 10)},
+		{"LpLoss", strconv.FormatUint(counters.LpLossN, 10)},
+	})
+	// 发送队列每个调度类别的统计信息，按 排队/入队/出队/丢弃 的顺序展示
+	for _, class := range v.SendQueueClasses {
+		weight := "strict"
+		if class.Weight > 0 {
+			weight = "weight " + strconv.Itoa(class.Weight)
+		}
+		table.Append([]string{fmt.Sprintf("SendQueue(%s)", class.Class),
+			fmt.Sprintf("%d/%d/%d/%d (%s)", class.Len, class.EnqueueN, class.DequeueN, class.DropN, weight)})
+	}
+	table.SetHeader([]string{"Attribute", "Value"})
+	table.SetHeaderColor(
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold})
+	table.SetCaption(true, fmt.Sprintf("LogicFace %d", v.LogicFaceId))
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+	return nil
+}
+
+//
+// @Description: 格式化 LogicFace 的持久性
+// @param persistency
+// @return string
+//
+func formatPersistency(persistency uint64) string {
+	return lf.LogicFacePersistency(persistency).String()
+}
+
+//
+// @Description: 格式化 LogicFace 的剩余存活时间，有持久性的 LogicFace 不会过期
+// @param faceStatus
+// @param now
+// @return string
+//
+func formatFaceExpire(faceStatus mgmt.FaceStatus, now uint64) string {
+	if faceStatus.Persistency != 0 || faceStatus.ExpireTime <= 0 {
+		return "never"
+	}
+	return formatRemaining(uint64(faceStatus.ExpireTime), now)
+}
+
+//
+// @Description: 拉取序列号大于 since 的 LogicFace 事件
+// @param controller
+// @param since
+// @return *mgmt.FaceEventBatch
+// @return error
+//
+func fetchLogicFaceEvents(controller *mgmtlib.MIRController, since uint64) (*mgmt.FaceEventBatch, error) {
+	parameters := new(component.ControlParameters)
+	parameters.SetCount(since)
+	commandExecutor, err := controller.PrepareCommandExecutor(newControlCommand(faceManagementModule,
+		faceManagementActionEvents, parameters))
+	if err != nil {
+		return nil, err
+	}
+	commandExecutor.SetAutoShutdown(true)
+
+	// 执行命令拉取结果
+	response, err := commandExecutor.Start()
+	if err != nil {
+		return nil, err
+	}
+	if response.Code != mgmtlib.ControlResponseCodeSuccess {
+		return nil, FaceManagerCliError{msg: response.Msg}
+	}
+
+	// 反序列化
+	var batchList []mgmt.FaceEventBatch
+	if err := json.Unmarshal(response.GetBytes(), &batchList); err != nil {
+		return nil, err
+	}
+	if len(batchList) == 0 {
+		return nil, FaceManagerCliError{msg: "empty face event batch"}
+	}
+	return &batchList[0], nil
+}
+
+// ShowLogicFaceEvents 展示 LogicFace 生命周期事件，指定 --follow 时持续拉取新事件，相当于订阅事件流
+//
+// @Description: 根据序列号检测遗漏的事件和路由器重启，并打印提示
+// @param c
+// @return error
+//
+func ShowLogicFaceEvents(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	since := c.Flags.Uint64("since")
+	follow := c.Flags.Bool("follow")
+	interval := c.Flags.Duration("interval")
+	for {
+		batch, err := fetchLogicFaceEvents(controller, since)
+		if err != nil {
+			return err
+		}
+		if batch.LastSeq < since {
+			// 路由器重启之后序列号会从 1 开始重新计数
+			fmt.Printf("# router restarted, event sequence reset from %d to %d\n", since, batch.LastSeq)
+			since = 0
+			continue
+		}
+		if since > 0 && batch.FirstSeq > since+1 {
+			fmt.Printf("# missed %d events (seq %d - %d)\n", batch.FirstSeq-since-1, since+1, batch.FirstSeq-1)
+		}
+		for _, event := range batch.Events {
+			fmt.Printf("%d\t%s\t%-11s\tface=%d type=%s local=%s remote=%s mtu=%d\n", event.Seq,
+				time.Unix(0, event.Timestamp*int64(time.Millisecond)).Format("15:04:05.000"), event.Kind,
+				event.LogicFaceId, event.LogicFaceType, event.LocalUri, event.RemoteUri, event.Mtu)
+			since = event.Seq
+		}
+		if !follow {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+}
+
 // AddLogicFace 创建一个新的 LogicFace 连接到另一个路由器
 //
 // @Description:
@@ -144,19 +447,27 @@ func AddLogicFace(c *grumble.Context, controller *mgmtlib.MIRController) error {
 	// 从命令行解析参数
 	remoteUri := c.Args.String("remote")
 	localUri := c.Args.String("local")
-	persistency := c.Flags.String("persistence")
+	persistency, err := lf.ParseLogicFacePersistency(c.Flags.String("persistence"))
+	if err != nil {
+		return FaceManagerCliError{msg: err.Error()}
+	}
+	tags, err := lf.ParseTags(c.Flags.String("tags"))
+	if err != nil {
+		return FaceManagerCliError{msg: err.Error()}
+	}
 
-	remoteUriItems := strings.Split(remoteUri, "://")
-	if len(remoteUriItems) != 2 {
-		return FaceManagerCliError{msg: fmt.Sprintf("Remote uri is wrong, expect one '://' item, %s", remoteUri)}
+	faceUri, err := lf.ParseFaceUri(remoteUri)
+	if err != nil {
+		return FaceManagerCliError{msg: fmt.Sprintf("Remote uri is wrong, %s", err.Error())}
 	}
 	parameters := new(component.ControlParameters)
-	parameters.SetUri(remoteUri)
-	parameters.SetUriScheme(uint64(component.GetUriSchemeByString(remoteUriItems[0])))
+	// 标签以查询串的形式附在对端地址后面
+	parameters.SetUri(lf.AppendUriTags(faceUri.String(), tags))
+	parameters.SetUriScheme(uriSchemeOf(faceUri.Protocol()))
 	if localUri != "" {
 		parameters.SetLocalUri(localUri)
 	}
-	parameters.SetPersistency(uint64(component.GetPersistencyByString(persistency)))
+	parameters.SetPersistency(uint64(persistency))
 
 	// 发起一个请求命令得到结果
 	commandExecutor, err := controller.PrepareCommandExecutor(mgmtlib.CreateLogicFaceAddCommand(topPrefix, parameters))
@@ -29,11 +29,20 @@ import (
 	"minlib/common"
 	"minlib/component"
 	mgmtlib "minlib/mgmt"
+	"mir-go/daemon/lf"
 	"mir-go/daemon/mgmt"
+	"mir-go/daemon/table"
 	"os"
+	"sort"
 	"strconv"
 )
 
+// FIB 管理模块名以及导出路由使用的行为
+const (
+	fibManagementModule       = "fib-mgmt"
+	fibManagementActionExport = "export"
+)
+
 // CreateFibCommands 创建一个 FibCommands
 //
 // @Description:
@@ -54,6 +63,7 @@ func CreateFibCommands(controller *mgmtlib.MIRController) *grumble.Command {
 		},
 		Flags: func(f *grumble.Flags) {
 			f.Uint64("c", "cost", 0, "Link cost")
+			f.Bool("e", "ecmp", false, "Mark the next hop as an equal-cost multipath member")
 		},
 		Run: func(c *grumble.Context) error {
 			return AddFib(c, controller)
@@ -82,6 +92,30 @@ func CreateFibCommands(controller *mgmtlib.MIRController) *grumble.Command {
 		},
 	})
 
+	// export
+	fc.AddCommand(&grumble.Command{
+		Name: "export",
+		Help: "Export persistent logic faces and routes as a JSON route snapshot",
+		Args: func(a *grumble.Args) {
+			a.String("file", "Output file, print to stdout if not set", grumble.Default(""))
+		},
+		Run: func(c *grumble.Context) error {
+			return ExportFib(c, controller)
+		},
+	})
+
+	// import
+	fc.AddCommand(&grumble.Command{
+		Name: "import",
+		Help: "Create logic faces and register routes from a JSON route snapshot",
+		Args: func(a *grumble.Args) {
+			a.String("file", "Route snapshot file generated by 'fib export'")
+		},
+		Run: func(c *grumble.Context) error {
+			return ImportFib(c, controller)
+		},
+	})
+
 	return fc
 }
 
@@ -105,6 +139,9 @@ func AddFib(c *grumble.Context, controller *mgmtlib.MIRController) error {
 	parameters.SetPrefix(identifier)
 	parameters.SetLogicFaceId(logicFaceId)
 	parameters.SetCost(cost)
+	if c.Flags.Bool("ecmp") {
+		parameters.SetFlags(table.RouteFlagECMP)
+	}
 
 	// 构造一个命令执行器
 	commandExecutor, err := controller.PrepareCommandExecutor(mgmtlib.CreateFibAddCommand(topPrefix, parameters))
@@ -198,22 +235,177 @@ func ListFib(c *grumble.Context, controller *mgmtlib.MIRController) error {
 		return err
 	}
 
-	// 使用表格美化输出
-	table := tablewriter.NewWriter(os.Stdout)
-
+	// 使用表格美化输出，下一跳已经按 Priority、Cost、LogicFaceId 排好序
+	sort.Slice(fibInfoList, func(i, j int) bool {
+		return fibInfoList[i].Identifier < fibInfoList[j].Identifier
+	})
+	tw := tablewriter.NewWriter(os.Stdout)
 	for _, fibInfo := range fibInfoList {
 		for _, nextHopInfo := range fibInfo.NextHopsInfo {
-			table.Append([]string{fibInfo.Identifier, strconv.FormatUint(nextHopInfo.LogicFaceId, 10), strconv.FormatUint(nextHopInfo.Cost, 10)})
+			ecmp := "-"
+			if nextHopInfo.ECMP {
+				ecmp = "yes"
+			}
+			tw.Append([]string{
+				fibInfo.Identifier,
+				strconv.FormatUint(nextHopInfo.LogicFaceId, 10),
+				strconv.FormatUint(nextHopInfo.Priority, 10),
+				strconv.FormatUint(nextHopInfo.Cost, 10),
+				ecmp,
+			})
 		}
 	}
-	table.SetHeader([]string{"Prefix", "LogicFaceId", "Cost"})
-	table.SetHeaderColor(
+	tw.SetHeader([]string{"Prefix", "LogicFaceId", "Priority", "Cost", "ECMP"})
+	tw.SetHeaderColor(
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
 		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
 		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
 		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold})
-	table.SetCaption(true, "Fib Table Info")
-	table.SetAlignment(tablewriter.ALIGN_CENTER)
-	table.SetAutoMergeCellsByColumnIndex([]int{0})
-	table.Render()
+	tw.SetCaption(true, "Fib Table Info")
+	tw.SetAlignment(tablewriter.ALIGN_CENTER)
+	tw.SetAutoMergeCellsByColumnIndex([]int{0})
+	tw.Render()
 	return nil
 }
+
+// ExportFib 导出持久化的 LogicFace 和路由，输出格式见 mgmt.RouteSnapshot
+//
+// @Description:
+// @param c
+// @return error
+//
+func ExportFib(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	// 构造一个命令执行器
+	commandExecutor, err := controller.PrepareCommandExecutor(newControlCommand(fibManagementModule, fibManagementActionExport, nil))
+	if err != nil {
+		return err
+	}
+	commandExecutor.SetAutoShutdown(true)
+
+	// 执行命令
+	response, err := commandExecutor.Start()
+	if err != nil {
+		return err
+	}
+	if response.Code != mgmtlib.ControlResponseCodeSuccess {
+		common.LogError("Export fib failed! errMsg: ", response.Msg)
+		return nil
+	}
+
+	// 反序列化，数据集中只有一个快照
+	var snapshots []mgmt.RouteSnapshot
+	if err := json.Unmarshal(response.GetBytes(), &snapshots); err != nil {
+		return err
+	}
+	if len(snapshots) != 1 {
+		return FibManagerCliError{msg: fmt.Sprintf("expect one route snapshot, got %d", len(snapshots))}
+	}
+
+	file := c.Args.String("file")
+	if file == "" {
+		buf, err := json.MarshalIndent(&snapshots[0], "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(buf))
+		return nil
+	}
+	if err := mgmt.SaveRouteSnapshot(file, &snapshots[0]); err != nil {
+		return err
+	}
+	common.LogInfo(fmt.Sprintf("Export %d links to %s success!", len(snapshots[0].Links), file))
+	return nil
+}
+
+// ImportFib 根据路由快照创建 LogicFace，并以快照中记录的来源、开销和标志位注册路由
+//
+// @Description:
+// @param c
+// @return error
+//
+func ImportFib(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	file := c.Args.String("file")
+	snapshot, err := mgmt.LoadRouteSnapshot(file)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range snapshot.Links {
+		logicFaceId, err := importLogicFace(controller, link)
+		if err != nil {
+			common.LogError(fmt.Sprintf("Import logic face %s failed! errMsg: %s", link.RemoteUri, err))
+			continue
+		}
+		for _, route := range link.Routes {
+			parameters := &component.ControlParameters{}
+			identifier, err := component.CreateIdentifierByString(route.Identifier)
+			if err != nil {
+				return err
+			}
+			parameters.SetPrefix(identifier)
+			parameters.SetLogicFaceId(logicFaceId)
+			parameters.SetCost(route.Cost)
+			parameters.SetOrigin(route.Origin)
+			parameters.SetFlags(route.Flags)
+			if err := executeRibCommand(controller, ribManagementActionRegister, parameters,
+				fmt.Sprintf("Import route %s => %d", route.Identifier, logicFaceId)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//
+// @Description: 通过 face-mgmt 创建快照中的 LogicFace，返回 LogicFaceId
+// @param controller
+// @param link
+// @return uint64
+// @return error
+//
+func importLogicFace(controller *mgmtlib.MIRController, link *mgmt.LinkSnapshot) (uint64, error) {
+	faceUri, err := lf.ParseFaceUri(link.RemoteUri)
+	if err != nil {
+		return 0, FibManagerCliError{msg: fmt.Sprintf("Remote uri is wrong, %s", err.Error())}
+	}
+	parameters := new(component.ControlParameters)
+	parameters.SetUri(lf.AppendUriTags(faceUri.String(), link.Tags))
+	parameters.SetUriScheme(uriSchemeOf(faceUri.Protocol()))
+	if link.LocalUri != "" {
+		parameters.SetLocalUri(link.LocalUri)
+	}
+	parameters.SetPersistency(link.Persistence)
+
+	commandExecutor, err := controller.PrepareCommandExecutor(mgmtlib.CreateLogicFaceAddCommand(topPrefix, parameters))
+	if err != nil {
+		return 0, err
+	}
+	commandExecutor.SetAutoShutdown(true)
+
+	response, err := commandExecutor.Start()
+	if err != nil {
+		return 0, err
+	}
+	if response.Code != mgmtlib.ControlResponseCodeSuccess {
+		return 0, FibManagerCliError{msg: response.Msg}
+	}
+	logicFaceId, err := strconv.ParseUint(response.GetString(), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	common.LogInfo(fmt.Sprintf("Import logic face %s success, id = %d", link.RemoteUri, logicFaceId))
+	return logicFaceId, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type FibManagerCliError struct {
+	msg string
+}
+
+func (f FibManagerCliError) Error() string {
+	return fmt.Sprintf("FibManagerCliError: %s", f.msg)
+}
@@ -0,0 +1,129 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package cmd
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/desertbit/grumble"
+	"github.com/olekukonko/tablewriter"
+	"minlib/component"
+	mgmtlib "minlib/mgmt"
+	"mir-go/daemon/routing"
+	"os"
+	"strconv"
+	"time"
+)
+
+// 邻居管理模块名以及支持的行为
+const (
+	neighborManagementModule     = "neighbor-mgmt"
+	neighborManagementActionList = "list"
+)
+
+// CreateNeighborCommands 创建一个 NeighborCommands
+//
+// @Description:
+// @param controller
+// @return *grumble.Command
+//
+func CreateNeighborCommands(controller *mgmtlib.MIRController) *grumble.Command {
+	nc := new(grumble.Command)
+	nc.Name = "neighbor"
+	nc.Help = "Neighbor Discovery Inspection"
+
+	// list
+	nc.AddCommand(&grumble.Command{
+		Name: "list",
+		Help: "Show neighbors found by neighbor discovery",
+		Run: func(c *grumble.Context) error {
+			return ListNeighbors(c, controller)
+		},
+	})
+
+	return nc
+}
+
+// ListNeighbors 显示邻居表，包括每个邻居的发现方式、LogicFace 和存活时间
+//
+// @Description:
+// @param c
+// @return error
+//
+func ListNeighbors(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	// 构造一个命令执行器
+	commandExecutor, err := controller.PrepareCommandExecutor(newControlCommand(neighborManagementModule,
+		neighborManagementActionList, &component.ControlParameters{}))
+	if err != nil {
+		return err
+	}
+	commandExecutor.SetAutoShutdown(true)
+
+	// 执行命令
+	response, err := commandExecutor.Start()
+	if err != nil {
+		return err
+	}
+	var neighbors []routing.Neighbor
+	if err := json.Unmarshal(response.GetBytes(), &neighbors); err != nil {
+		return err
+	}
+
+	// 使用表格美化输出
+	now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	table := tablewriter.NewWriter(os.Stdout)
+	for _, neighbor := range neighbors {
+		table.Append([]string{
+			neighbor.Router,
+			neighbor.Via,
+			neighbor.Uri,
+			strconv.FormatUint(neighbor.LogicFaceId, 10),
+			formatElapsed(neighbor.LastSeen, now),
+			formatRemaining(neighbor.ExpireTime, now),
+		})
+	}
+	table.SetHeader([]string{"Router", "Via", "Uri", "LogicFaceId", "LastSeen", "Expires"})
+	table.SetHeaderColor(
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold})
+	table.SetCaption(true, fmt.Sprintf("Neighbors (%d)", len(neighbors)))
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.Render()
+	return nil
+}
+
+//
+// @Description: 格式化距离某个时间点已经过去的时间
+// @param since
+// @param now
+// @return string
+//
+func formatElapsed(since uint64, now uint64) string {
+	if since >= now {
+		return "0ms ago"
+	}
+	return strconv.FormatUint(now-since, 10) + "ms ago"
+}
@@ -0,0 +1,273 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package cmd
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 15:55 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/desertbit/grumble"
+	"github.com/olekukonko/tablewriter"
+	"minlib/component"
+	mgmtlib "minlib/mgmt"
+	"mir-go/daemon/mgmt"
+	"mir-go/daemon/table"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PIT 管理模块名以及支持的行为
+const (
+	pitManagementModule      = "pit-mgmt"
+	pitManagementActionList  = "list"
+	pitManagementActionCount = "count"
+)
+
+// CreatePitCommands 创建一个 PitCommands
+//
+// @Description:
+// @param controller
+// @return *grumble.Command
+//
+func CreatePitCommands(controller *mgmtlib.MIRController) *grumble.Command {
+	pc := new(grumble.Command)
+	pc.Name = "pit"
+	pc.Help = "Pending Interest Table Inspection"
+
+	// list
+	pc.AddCommand(&grumble.Command{
+		Name: "list",
+		Help: "Show pit entries under specific prefix",
+		Args: func(a *grumble.Args) {
+			a.String("prefix", "Target identifier prefix", grumble.Default(""))
+		},
+		Flags: func(f *grumble.Flags) {
+			f.Uint64("n", "limit", 0, "Max number of entries to show, 0 means using the default limit of router")
+		},
+		Run: func(c *grumble.Context) error {
+			return ListPit(c, controller)
+		},
+	})
+
+	// count
+	pc.AddCommand(&grumble.Command{
+		Name: "count",
+		Help: "Show summary of pit entries under specific prefix",
+		Args: func(a *grumble.Args) {
+			a.String("prefix", "Target identifier prefix", grumble.Default(""))
+		},
+		Run: func(c *grumble.Context) error {
+			return CountPit(c, controller)
+		},
+	})
+
+	return pc
+}
+
+// ListPit 显示 PIT 表项，包括每个表项的流入、流出记录
+//
+// @Description:
+// @param c
+// @return error
+//
+func ListPit(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	parameters, err := buildPitParameters(c.Args.String("prefix"))
+	if err != nil {
+		return err
+	}
+	if limit := c.Flags.Uint64("limit"); limit > 0 {
+		parameters.SetCount(limit)
+	}
+
+	var pitInfoList []mgmt.PITInfo
+	if err := fetchPitDataset(controller, pitManagementActionList, parameters, &pitInfoList); err != nil {
+		return err
+	}
+	if len(pitInfoList) == 0 {
+		return fmt.Errorf("empty pit info")
+	}
+	pitInfo := pitInfoList[0]
+
+	// 使用表格美化输出
+	now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	table := tablewriter.NewWriter(os.Stdout)
+	for _, entry := range pitInfo.Entries {
+		table.Append([]string{
+			entry.Name,
+			formatInRecords(entry.InRecords, now),
+			formatOutRecords(entry.OutRecords, now),
+			strconv.FormatBool(entry.Satisfied),
+			strconv.FormatBool(entry.Deleted),
+		})
+	}
+	table.SetHeader([]string{"Name", "In-Records", "Out-Records", "Satisfied", "Deleted"})
+	table.SetHeaderColor(
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold})
+	table.SetCaption(true, fmt.Sprintf("PIT Entries (%d of %d)", len(pitInfo.Entries), pitInfo.Total))
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+	return nil
+}
+
+// CountPit 显示 PIT 的统计信息
+//
+// @Description:
+// @param c
+// @return error
+//
+func CountPit(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	prefix := c.Args.String("prefix")
+	parameters, err := buildPitParameters(prefix)
+	if err != nil {
+		return err
+	}
+
+	var summaryList []table.PITSummary
+	if err := fetchPitDataset(controller, pitManagementActionCount, parameters, &summaryList); err != nil {
+		return err
+	}
+	if len(summaryList) == 0 {
+		return fmt.Errorf("empty pit summary")
+	}
+	summary := summaryList[0]
+
+	if prefix == "" {
+		prefix = "/"
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Append([]string{
+		prefix,
+		strconv.FormatUint(summary.Entries, 10),
+		strconv.FormatUint(summary.Satisfied, 10),
+		strconv.FormatUint(summary.InRecords, 10),
+		strconv.FormatUint(summary.OutRecords, 10),
+		strconv.FormatUint(summary.Nacked, 10),
+	})
+	table.SetHeader([]string{"Prefix", "Entries", "Satisfied", "In-Records", "Out-Records", "Nacked"})
+	table.SetHeaderColor(
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold})
+	table.SetCaption(true, "PIT Summary")
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.Render()
+	return nil
+}
+
+//
+// @Description: 构造 PIT 数据集请求的参数，prefix 为空时不过滤
+// @param prefix
+// @return *component.ControlParameters
+// @return error
+//
+func buildPitParameters(prefix string) (*component.ControlParameters, error) {
+	parameters := &component.ControlParameters{}
+	if prefix != "" {
+		identifier, err := component.CreateIdentifierByString(prefix)
+		if err != nil {
+			return nil, err
+		}
+		parameters.SetPrefix(identifier)
+	}
+	return parameters, nil
+}
+
+//
+// @Description: 请求 PIT 管理模块的数据集，并把结果反序列化到 result 中
+// @param controller
+// @param action
+// @param parameters
+// @param result
+// @return error
+//
+func fetchPitDataset(controller *mgmtlib.MIRController, action string, parameters *component.ControlParameters,
+	result interface{}) error {
+	// 构造一个命令执行器
+	commandExecutor, err := controller.PrepareCommandExecutor(newControlCommand(pitManagementModule, action, parameters))
+	if err != nil {
+		return err
+	}
+	commandExecutor.SetAutoShutdown(true)
+
+	// 执行命令
+	response, err := commandExecutor.Start()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(response.GetBytes(), result)
+}
+
+//
+// @Description: 格式化流入记录，每条记录一行：face=<id> nonce=<nonce> expires=<剩余时间>
+// @param inRecords
+// @param now
+// @return string
+//
+func formatInRecords(inRecords []*table.InRecordStatus, now uint64) string {
+	lines := make([]string, 0, len(inRecords))
+	for _, inRecord := range inRecords {
+		lines = append(lines, fmt.Sprintf("face=%d nonce=%d expires=%s",
+			inRecord.LogicFaceId, inRecord.Nonce, formatRemaining(inRecord.ExpireTime, now)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+//
+// @Description: 格式化流出记录，每条记录一行，收到 Nack 的记录附带 Nack 原因
+// @param outRecords
+// @param now
+// @return string
+//
+func formatOutRecords(outRecords []*table.OutRecordStatus, now uint64) string {
+	lines := make([]string, 0, len(outRecords))
+	for _, outRecord := range outRecords {
+		line := fmt.Sprintf("face=%d nonce=%d expires=%s",
+			outRecord.LogicFaceId, outRecord.Nonce, formatRemaining(outRecord.ExpireTime, now))
+		if outRecord.Nacked {
+			line += fmt.Sprintf(" nack=%d", outRecord.NackReason)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+//
+// @Description: 格式化距离超时的剩余时间
+// @param expireTime
+// @param now
+// @return string
+//
+func formatRemaining(expireTime uint64, now uint64) string {
+	if expireTime <= now {
+		return "expired"
+	}
+	return strconv.FormatUint(expireTime-now, 10) + "ms"
+}
@@ -0,0 +1,291 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package cmd
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:00 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/desertbit/grumble"
+	"github.com/olekukonko/tablewriter"
+	"minlib/common"
+	"minlib/component"
+	mgmtlib "minlib/mgmt"
+	"mir-go/daemon/table"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RIB 管理模块名以及支持的行为
+const (
+	ribManagementModule           = "rib-mgmt"
+	ribManagementActionRegister   = "register"
+	ribManagementActionUnregister = "unregister"
+	ribManagementActionList       = "list"
+)
+
+// CreateRibCommands 创建一个 RibCommands
+//
+// @Description:
+// @param controller
+// @return *grumble.Command
+//
+func CreateRibCommands(controller *mgmtlib.MIRController) *grumble.Command {
+	rc := new(grumble.Command)
+	rc.Name = "rib"
+	rc.Help = "Rib Management"
+
+	// register
+	rc.AddCommand(&grumble.Command{
+		Name: "register",
+		Help: "Register a route to RIB",
+		Args: func(a *grumble.Args) {
+			a.String("prefix", "Target identifier prefix")
+			a.Uint64("id", "Next hop logic face id")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.Uint64("c", "cost", 0, "Route cost")
+			f.Uint64("o", "origin", table.RouteOriginStatic, "Route origin, eg: 0(app) 128(routing) 255(static)")
+			f.Int64("e", "expires", 0, "Expiration period in milliseconds, 0 means never expire")
+			f.Bool("n", "no-inherit", false, "Do not let longer prefixes inherit this route")
+			f.Bool("p", "capture", false, "Do not let this prefix inherit routes of shorter prefixes")
+			f.Bool("s", "persistent", false, "Save this route to the route state file and restore it on restart")
+			f.Bool("m", "ecmp", false, "Mark the next hop as an equal-cost multipath member")
+		},
+		Run: func(c *grumble.Context) error {
+			return RegisterRoute(c, controller)
+		},
+	})
+
+	// unregister
+	rc.AddCommand(&grumble.Command{
+		Name: "unregister",
+		Help: "Unregister a route from RIB",
+		Args: func(a *grumble.Args) {
+			a.String("prefix", "Target identifier prefix")
+			a.Uint64("id", "Next hop logic face id")
+		},
+		Flags: func(f *grumble.Flags) {
+			f.Uint64("o", "origin", table.RouteOriginStatic, "Route origin")
+		},
+		Run: func(c *grumble.Context) error {
+			return UnregisterRoute(c, controller)
+		},
+	})
+
+	// list
+	rc.AddCommand(&grumble.Command{
+		Name: "list",
+		Help: "Show all routes in RIB",
+		Run: func(c *grumble.Context) error {
+			return ListRib(c, controller)
+		},
+	})
+
+	return rc
+}
+
+// RegisterRoute 在 RIB 中添加或者更新一条路由
+//
+// @Description:
+// @param c
+// @return error
+//
+func RegisterRoute(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	// 解析命令行参数
+	prefix := c.Args.String("prefix")
+	logicFaceId := c.Args.Uint64("id")
+	flags := table.RouteFlagChildInherit
+	if c.Flags.Bool("no-inherit") {
+		flags = 0
+	}
+	if c.Flags.Bool("capture") {
+		flags |= table.RouteFlagCapture
+	}
+	if c.Flags.Bool("persistent") {
+		flags |= table.RouteFlagPersistent
+	}
+	if c.Flags.Bool("ecmp") {
+		flags |= table.RouteFlagECMP
+	}
+
+	parameters := &component.ControlParameters{}
+	identifier, err := component.CreateIdentifierByString(prefix)
+	if err != nil {
+		return err
+	}
+	parameters.SetPrefix(identifier)
+	parameters.SetLogicFaceId(logicFaceId)
+	parameters.SetCost(c.Flags.Uint64("cost"))
+	parameters.SetOrigin(c.Flags.Uint64("origin"))
+	parameters.SetFlags(flags)
+	if expires := c.Flags.Int64("expires"); expires > 0 {
+		parameters.SetExpirationPeriod(uint64(expires))
+	}
+
+	return executeRibCommand(controller, ribManagementActionRegister, parameters,
+		fmt.Sprintf("Register route %s => %d", prefix, logicFaceId))
+}
+
+// UnregisterRoute 从 RIB 中删除一条路由
+//
+// @Description:
+// @param c
+// @return error
+//
+func UnregisterRoute(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	// 解析命令行参数
+	prefix := c.Args.String("prefix")
+	logicFaceId := c.Args.Uint64("id")
+
+	parameters := &component.ControlParameters{}
+	identifier, err := component.CreateIdentifierByString(prefix)
+	if err != nil {
+		return err
+	}
+	parameters.SetPrefix(identifier)
+	parameters.SetLogicFaceId(logicFaceId)
+	parameters.SetOrigin(c.Flags.Uint64("origin"))
+
+	return executeRibCommand(controller, ribManagementActionUnregister, parameters,
+		fmt.Sprintf("Unregister route %s => %d", prefix, logicFaceId))
+}
+
+// ListRib 显示 RIB 中的所有路由
+//
+// @Description:
+// @param c
+// @return error
+//
+func ListRib(c *grumble.Context, controller *mgmtlib.MIRController) error {
+	// 构造一个命令执行器
+	commandExecutor, err := controller.PrepareCommandExecutor(newControlCommand(ribManagementModule, ribManagementActionList, nil))
+	if err != nil {
+		return err
+	}
+	commandExecutor.SetAutoShutdown(true)
+
+	// 执行命令
+	response, err := commandExecutor.Start()
+	if err != nil {
+		return err
+	}
+
+	// 反序列化，输出结果
+	var ribEntryList []table.RIBEntryStatus
+	err = json.Unmarshal(response.GetBytes(), &ribEntryList)
+	if err != nil {
+		return err
+	}
+
+	// 使用表格美化输出
+	now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	tw := tablewriter.NewWriter(os.Stdout)
+	for _, ribEntry := range ribEntryList {
+		for _, route := range ribEntry.Routes {
+			expires := "never"
+			if route.ExpirationTime > 0 {
+				expires = formatRemaining(route.ExpirationTime, now)
+			}
+			tw.Append([]string{
+				ribEntry.Prefix,
+				strconv.FormatUint(route.LogicFaceId, 10),
+				strconv.FormatUint(route.Origin, 10),
+				strconv.FormatUint(route.Cost, 10),
+				formatRouteFlags(route.Flags),
+				expires,
+			})
+		}
+	}
+	tw.SetHeader([]string{"Prefix", "LogicFaceId", "Origin", "Cost", "Flags", "Expires"})
+	tw.SetHeaderColor(
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold},
+		tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold})
+	tw.SetCaption(true, "Rib Table Info")
+	tw.SetAlignment(tablewriter.ALIGN_CENTER)
+	tw.SetAutoMergeCellsByColumnIndex([]int{0})
+	tw.Render()
+	return nil
+}
+
+//
+// @Description: 执行 RIB 管理模块的控制命令，并输出执行结果
+// @param controller
+// @param action
+// @param parameters
+// @param desc	输出结果时使用的命令描述
+// @return error
+//
+func executeRibCommand(controller *mgmtlib.MIRController, action string, parameters *component.ControlParameters,
+	desc string) error {
+	// 构造一个命令执行器
+	commandExecutor, err := controller.PrepareCommandExecutor(newControlCommand(ribManagementModule, action, parameters))
+	if err != nil {
+		return err
+	}
+	commandExecutor.SetAutoShutdown(true)
+
+	// 执行命令
+	response, err := commandExecutor.Start()
+	if err != nil {
+		return err
+	}
+
+	// 如果请求成功，则输出结果
+	if response.Code == mgmtlib.ControlResponseCodeSuccess {
+		common.LogInfo(desc + " success!")
+	} else {
+		// 请求失败，则输出错误信息
+		common.LogError(fmt.Sprintf("%s failed! errMsg: %s", desc, response.Msg))
+	}
+	return nil
+}
+
+//
+// @Description: 把路由的标志位格式化成可读的字符串
+// @param flags
+// @return string
+//
+func formatRouteFlags(flags uint64) string {
+	var names []string
+	if flags&table.RouteFlagChildInherit != 0 {
+		names = append(names, "child-inherit")
+	}
+	if flags&table.RouteFlagCapture != 0 {
+		names = append(names, "capture")
+	}
+	if flags&table.RouteFlagPersistent != 0 {
+		names = append(names, "persistent")
+	}
+	if flags&table.RouteFlagECMP != 0 {
+		names = append(names, "ecmp")
+	}
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, "|")
+}
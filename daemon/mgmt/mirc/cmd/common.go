@@ -56,6 +56,36 @@ func buildPrefix(moduleName string, action string) string {
 	return topPrefix + "/" + moduleName + "/" + action
 }
 
+// uriSchemeOf 获取对端地址协议对应的 Uri scheme
+//
+// @Description:
+//  TLS 和 WebSocket 运行在 TCP 之上，ControlParameters 中没有单独的 Uri scheme，使用 TCP 的 Uri scheme，
+//  路由器根据对端地址的前缀 tls:// 和 ws:// 区分
+// @param scheme	对端地址去掉 IP 版本后缀之后的协议，见 lf.FaceUri.Protocol，eg: tcp | tls | ws | udp | ether | unix
+// @return uint64
+//
+func uriSchemeOf(scheme string) uint64 {
+	if scheme == "tls" || scheme == "ws" {
+		scheme = "tcp"
+	}
+	return uint64(component.GetUriSchemeByString(scheme))
+}
+
+// newControlCommand 构造一个访问指定管理模块的命令，用于 minlib 中没有预置命令构造函数的管理模块
+//
+// @Description:
+// @param moduleName	管理模块名，eg: cs-mgmt
+// @param action		行为，eg: list
+// @param parameters	命令参数，可以为空
+// @return *mgmtlib.ControlCommand
+//
+func newControlCommand(moduleName string, action string, parameters *component.ControlParameters) *mgmtlib.ControlCommand {
+	if parameters == nil {
+		parameters = new(component.ControlParameters)
+	}
+	return mgmtlib.CreateControlCommand(topPrefix, moduleName, action, parameters)
+}
+
 func newCommandInterest(moduleName string, action string) *packet.Interest {
 	interest := &packet.Interest{}
 	identifier, _ := component.CreateIdentifierByString(buildPrefix(moduleName, action))
@@ -120,6 +120,14 @@ func mirc(mirConfig *common2.MIRConfig) {
 	app.AddCommand(cmd.CreateFibCommands(controller))
 	// 添加 Identity 管理命令
 	app.AddCommand(cmd.CreateIdentityCommands(controller))
+	// 添加 CS 管理命令
+	app.AddCommand(cmd.CreateCsCommands(controller))
+	// 添加 PIT 管理命令
+	app.AddCommand(cmd.CreatePitCommands(controller))
+	// 添加 RIB 管理命令
+	app.AddCommand(cmd.CreateRibCommands(controller))
+	// 添加 Neighbor 管理命令
+	app.AddCommand(cmd.CreateNeighborCommands(controller))
 
 	grumble.Main(app)
 }
@@ -32,9 +32,9 @@ import (
 	"mir-go/daemon/lf"
 	"mir-go/daemon/mgmt"
 	"mir-go/daemon/plugin"
+	"mir-go/daemon/routing"
 	"mir-go/daemon/table"
 	utils2 "mir-go/daemon/utils"
-	"net"
 	"strconv"
 	"time"
 )
@@ -46,12 +46,15 @@ const defaultConfigFilePath = "/usr/local/etc/mir/mirconf.ini"
 // @Description:
 //
 type MIRStarter struct {
-	plugin.GlobalPluginManager                     // 全局插件管理器
-	keyChain                   security.KeyChain   // 秘钥链
-	mirConfig                  *common.MIRConfig   // MIR 配置文件
-	forwarder                  *fw.Forwarder       //转发器
-	logicFaceSystem            *lf.LogicFaceSystem // 管理LogicFace
-	dispatcher                 *mgmt.Dispatcher    // 管理命令分发器
+	plugin.GlobalPluginManager                            // 全局插件管理器
+	keyChain                   security.KeyChain          // 秘钥链
+	mirConfig                  *common.MIRConfig          // MIR 配置文件
+	forwarder                  *fw.Forwarder              //转发器
+	logicFaceSystem            *lf.LogicFaceSystem        // 管理LogicFace
+	dispatcher                 *mgmt.Dispatcher           // 管理命令分发器
+	linkStateRouting           *routing.LinkStateRouting  // 链路状态路由组件，没有开启路由时为 nil
+	neighborDiscovery          *routing.NeighborDiscovery // 邻居发现模块，没有开启邻居发现时为 nil
+	prefixPropagator           *mgmt.PrefixPropagator     // 前缀传播服务，没有开启前缀传播时为 nil
 }
 
 // NewMIRStarter 新建一个 MIR 启动器
@@ -92,21 +95,82 @@ func (m *MIRStarter) Init(mirConfig *common.MIRConfig) {
 	// LogicFaceSystem
 	m.logicFaceSystem = new(lf.LogicFaceSystem)
 	m.logicFaceSystem.Init(packetValidator, m.mirConfig)
+	// TLS 对端证书中的 MIR 身份必须存在于 KeyChain 中（本地创建或者导入了证书），并且证书由该身份绑定
+	m.logicFaceSystem.SetTlsIdentityVerifier(lf.NewKeyChainTlsIdentityVerifier(&m.keyChain))
 
 	// 管理模块
 	faceServer, faceClient := lf.CreateInnerLogicFacePair()
 	mgmtSystem := mgmt.CreateMgmtSystem()
 	mgmtSystem.SetFIB(m.forwarder.GetFIB())
+	mgmtSystem.SetCS(m.forwarder.GetCS())
+	mgmtSystem.SetUnsolicitedDataPolicyTable(m.forwarder.GetUnsolicitedDataPolicyTable())
+	mgmtSystem.SetPIT(m.forwarder.GetPIT())
+	mgmtSystem.SetRIB(m.forwarder.GetRIB())
 	mgmtSystem.BindFibCleaner(m.logicFaceSystem.LogicFaceTable())
 	m.dispatcher = mgmt.CreateDispatcher(m.mirConfig, &m.keyChain)
 	m.dispatcher.FaceClient = faceClient
+	// 注册前缀的命令需要由证书覆盖该前缀的身份签名
+	if m.mirConfig.AuthorizationConfig.EnableAuthorization {
+		m.dispatcher.SetPrefixAuthorizer(mgmt.CreatePrefixAuthorizer(m.mirConfig, &m.keyChain,
+			m.logicFaceSystem.LogicFaceTable()))
+	}
 	topPrefix, _ := component.CreateIdentifierByString("/min-mir/mgmt/localhost")
 	m.dispatcher.AddTopPrefix(topPrefix, m.forwarder.GetFIB(), faceServer)
+	// 接受下游路由器传播过来的前缀
+	if m.mirConfig.PropagationConfig.AcceptRemoteRegistration {
+		localhopPrefix, _ := component.CreateIdentifierByString(mgmt.LocalhopTopPrefix)
+		m.dispatcher.AddTopPrefix(localhopPrefix, m.forwarder.GetFIB(), faceServer)
+	}
 	mgmtSystem.Init(m.dispatcher, m.logicFaceSystem.LogicFaceTable())
 
-	// 加载静态路由配置
+	// 前缀传播，作为插件在上游 LogicFace 上收发注册命令
+	if m.mirConfig.PropagationConfig.EnablePropagation {
+		prefixPropagator, err := mgmt.CreatePrefixPropagator(m.mirConfig, m.forwarder.GetFIB(), &m.keyChain)
+		if err != nil {
+			common2.LogFatal(err)
+		}
+		m.prefixPropagator = prefixPropagator
+		m.RegisterPlugin(prefixPropagator)
+		mgmtSystem.SetPrefixPropagator(prefixPropagator)
+	}
+
+	// 链路状态路由，作为插件拦截路由协议报文
+	if m.mirConfig.RoutingConfig.EnableRouting {
+		linkStateRouting, err := routing.CreateLinkStateRouting(m.mirConfig, m.logicFaceSystem.LogicFaceTable(),
+			m.forwarder.GetFIB(), m.forwarder.GetRIB(), &m.keyChain)
+		if err != nil {
+			common2.LogFatal(err)
+		}
+		m.linkStateRouting = linkStateRouting
+		m.RegisterPlugin(linkStateRouting)
+	}
+
+	// 邻居发现，作为插件拦截以太网上的邻居发现报文
+	if m.mirConfig.DiscoveryConfig.EnableDiscovery {
+		neighborDiscovery, err := routing.CreateNeighborDiscovery(m.mirConfig, m.logicFaceSystem, &m.keyChain)
+		if err != nil {
+			common2.LogFatal(err)
+		}
+		m.neighborDiscovery = neighborDiscovery
+		m.RegisterPlugin(neighborDiscovery)
+		mgmtSystem.SetNeighborDiscovery(neighborDiscovery)
+	}
+
+	// 加载静态路由配置，然后恢复上次保存的路由状态，恢复完成之后才开始保存路由状态
 	utils2.GoroutineNoPanic(func() {
-		SetUpDefaultRoute(m.mirConfig.DefaultRouteConfigPath, m.mirConfig.DefaultRouteRetryCount, m.forwarder.GetFIB())
+		SetUpDefaultRoute(m.mirConfig.DefaultRouteConfigPath, m.mirConfig.DefaultRouteRetryCount, m.forwarder.GetRIB())
+		if m.mirConfig.RouteStatePath == "" {
+			return
+		}
+		routeStateStore := mgmt.CreateRouteStateStore(m.mirConfig.RouteStatePath, m.forwarder.GetRIB(),
+			m.logicFaceSystem.LogicFaceTable())
+		if snapshot, err := routeStateStore.Load(); err != nil {
+			common2.LogError("load route state error: ", err, ", ", m.mirConfig.RouteStatePath)
+		} else if snapshot != nil {
+			RestoreRouteSnapshot(snapshot, m.mirConfig.DefaultRouteRetryCount, m.logicFaceSystem.LogicFaceTable(),
+				m.forwarder.GetRIB())
+		}
+		routeStateStore.Start()
 	})
 }
 
@@ -124,6 +188,23 @@ func (m *MIRStarter) Start(pwd string) (string, error) {
 	// 启动 LogicFaceSystem
 	m.logicFaceSystem.Start()
 
+	// 启动链路状态路由
+	if m.linkStateRouting != nil {
+		m.linkStateRouting.Start()
+	}
+
+	// 启动邻居发现
+	if m.neighborDiscovery != nil {
+		if err := m.neighborDiscovery.Start(); err != nil {
+			return "", err
+		}
+	}
+
+	// 启动前缀传播
+	if m.prefixPropagator != nil {
+		m.prefixPropagator.Start()
+	}
+
 	// 启动命令分发程序
 	m.dispatcher.Start()
 	// 启动转发处理流程（死循环阻塞）
@@ -170,9 +251,9 @@ func (m *MIRStarter) initKeyChain(passwd string) error {
 // SetUpDefaultRoute
 // @Description: 加载静态路由配置文件
 // @param defaultRouteConfigPath	静态路由配置文件的文件路径
-// @param fib	FIB表指针
+// @param rib	RIB指针，静态路由以 static 为来源写入 RIB
 //
-func SetUpDefaultRoute(defaultRouteConfigPath string, retryCount int, fib *table.FIB) {
+func SetUpDefaultRoute(defaultRouteConfigPath string, retryCount int, rib *table.RIB) {
 	time.Sleep(time.Second * 2)
 	defaultRouteConfig, err := common.ParseDefaultConfig(defaultRouteConfigPath)
 	if err != nil {
@@ -181,48 +262,127 @@ func SetUpDefaultRoute(defaultRouteConfigPath string, retryCount int, fib *table
 	}
 	for i := 0; i < len(defaultRouteConfig.Link); i++ {
 		remoteUri := defaultRouteConfig.Link[i].RemoteUri
-		var logicFace *lf.LogicFace
 		if len(remoteUri) <= 0 {
 			common2.LogError("remote uri error: ", remoteUri)
 			continue
 		}
-		var retryTimewait uint = 1
-		for cnt := 0; cnt < retryCount; cnt++ {
-			if remoteUri[:3] == "udp" {
-				logicFace, err = lf.CreateUdpLogicFace(remoteUri[6:])
-			} else if remoteUri[:3] == "tcp" {
-				logicFace, err = lf.CreateTcpLogicFace(remoteUri[6:], 1)
-			} else if remoteUri[:3] == "eth" {
-				remoteAddr, err := net.ParseMAC(remoteUri[8:])
-				if err != nil {
-					common2.LogError("parse mac addr error: ", err)
-					break
-				}
-				logicFace, err = lf.CreateEtherLogicFace(defaultRouteConfig.Link[i].LocalUri, remoteAddr)
-			}
-			// 创建成功的情况下，或者最后一次也没有创建成功就退出这个循环
-			if (logicFace != nil && err == nil) || (cnt == retryCount) {
-				break
-			} else {
-				time.Sleep(time.Second * time.Duration(retryTimewait))
-				retryTimewait = retryTimewait * 2 // 每次等待的时间是上一次的2倍
-				common2.LogWarn("default route add retry:" + strconv.FormatInt(int64(cnt+1), 10) + " remoteUri-> " + remoteUri)
-			}
+		tags, err := lf.ParseTags(defaultRouteConfig.Link[i].Tags)
+		if err != nil {
+			common2.LogError("parse logic face tags error: ", err, ", ", remoteUri)
+			continue
 		}
+		logicFace, err := createLogicFaceWithRetry(remoteUri, defaultRouteConfig.Link[i].LocalUri, retryCount)
 		if logicFace == nil || err != nil {
 			common2.LogError("create static logic face error: ", err)
 			continue
 		}
 		common2.LogInfo("create default face: ", logicFace.GetLocalUri(), "->", logicFace.GetRemoteUri(), ", face id = ", logicFace.LogicFaceId)
 		logicFace.SetPersistence(uint64(defaultRouteConfig.Link[i].Persistence))
+		logicFace.SetTags(tags)
 		for j := 0; j < len(defaultRouteConfig.Link[i].Routes.Route); j++ {
 			identifier, err := component.CreateIdentifierByString(defaultRouteConfig.Link[i].Routes.Route[j].Identifier)
 			if err != nil {
 				common2.LogError("create identifier from string error: ", err)
 				continue
 			}
-			fib.AddOrUpdate(identifier, logicFace, uint64(defaultRouteConfig.Link[i].Routes.Route[j].Cost))
+			// Persistence 不为 0 的路由会被写入路由状态文件
+			flags := table.RouteFlagChildInherit
+			if defaultRouteConfig.Link[i].Routes.Route[j].Persistence != 0 {
+				flags |= table.RouteFlagPersistent
+			}
+			if err := rib.Register(identifier, logicFace, table.RouteOriginStatic,
+				uint64(defaultRouteConfig.Link[i].Routes.Route[j].Cost), flags, 0); err != nil {
+				common2.LogError("add route error: ", err)
+				continue
+			}
 			common2.LogInfo("add route prefix=", identifier.ToUri(), " -> logic face id = ", logicFace.LogicFaceId)
 		}
 	}
 }
+
+// RestoreRouteSnapshot
+// @Description: 重新建立路由快照中的 LogicFace 和路由，已经存在的 LogicFace 直接复用，创建 LogicFace 的重试行为和静态路由相同
+// @param snapshot	路由快照
+// @param retryCount	创建 LogicFace 的重试次数
+// @param logicFaceTable
+// @param rib
+//
+func RestoreRouteSnapshot(snapshot *mgmt.RouteSnapshot, retryCount int, logicFaceTable *lf.LogicFaceTable, rib *table.RIB) {
+	for _, link := range snapshot.Links {
+		logicFace := findLogicFaceByRemoteUri(logicFaceTable, link.RemoteUri)
+		if logicFace == nil {
+			var err error
+			if logicFace, err = createLogicFaceWithRetry(link.RemoteUri, link.LocalUri, retryCount); err != nil || logicFace == nil {
+				common2.LogError("restore logic face error: ", err, ", ", link.RemoteUri)
+				continue
+			}
+			common2.LogInfo("restore logic face: ", logicFace.GetLocalUri(), "->", logicFace.GetRemoteUri(), ", face id = ", logicFace.LogicFaceId)
+		}
+		if link.Persistence > logicFace.Persistence {
+			logicFace.SetPersistence(link.Persistence)
+		}
+		logicFace.SetTags(link.Tags)
+		for _, route := range link.Routes {
+			identifier, err := component.CreateIdentifierByString(route.Identifier)
+			if err != nil {
+				common2.LogError("create identifier from string error: ", err)
+				continue
+			}
+			if err := rib.Register(identifier, logicFace, route.Origin, route.Cost, route.Flags, 0); err != nil {
+				common2.LogError("restore route error: ", err)
+				continue
+			}
+			common2.LogInfo("restore route prefix=", identifier.ToUri(), " -> logic face id = ", logicFace.LogicFaceId)
+		}
+	}
+}
+
+//
+// @Description: 根据对端地址创建 LogicFace，失败时重试，每次等待的时间是上一次的 2 倍
+// @param remoteUri	对端地址，eg: udp://192.168.3.7:13899 | tcp6://[2001:db8::1]:13899 | tls://r2.example.com:13900 | ws://192.168.3.7:13901/mir | ether://34:cf:f6:f8:6a:d8
+// @param localUri	以太网 LogicFace 使用的网卡名
+// @param retryCount	重试次数
+// @return *lf.LogicFace
+// @return error
+//
+func createLogicFaceWithRetry(remoteUri string, localUri string, retryCount int) (*lf.LogicFace, error) {
+	faceUri, err := lf.ParseFaceUri(remoteUri)
+	if err != nil {
+		return nil, err
+	}
+	var logicFace *lf.LogicFace
+	var retryTimewait uint = 1
+	for cnt := 0; cnt < retryCount; cnt++ {
+		logicFace, err = lf.CreateLogicFaceByUri(faceUri, localUri, 1)
+		// 创建成功的情况下，或者最后一次也没有创建成功就退出这个循环
+		if (logicFace != nil && err == nil) || (cnt == retryCount-1) {
+			break
+		} else {
+			time.Sleep(time.Second * time.Duration(retryTimewait))
+			retryTimewait = retryTimewait * 2 // 每次等待的时间是上一次的2倍
+			common2.LogWarn("default route add retry:" + strconv.FormatInt(int64(cnt+1), 10) + " remoteUri-> " + remoteUri)
+		}
+	}
+	if err == nil && logicFace == nil {
+		err = errors.New("create logic face fail " + remoteUri)
+	}
+	return logicFace, err
+}
+
+//
+// @Description: 在 LogicFaceTable 中查找对端地址为 remoteUri 的 LogicFace
+// @param logicFaceTable
+// @param remoteUri
+// @return *lf.LogicFace
+//
+func findLogicFaceByRemoteUri(logicFaceTable *lf.LogicFaceTable, remoteUri string) *lf.LogicFace {
+	var found *lf.LogicFace
+	logicFaceTable.Range(func(logicFaceId uint64, logicFace *lf.LogicFace) bool {
+		if logicFace.GetState() && logicFace.GetRemoteUri() == remoteUri {
+			found = logicFace
+			return false
+		}
+		return true
+	})
+	return found
+}
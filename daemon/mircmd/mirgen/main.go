@@ -23,6 +23,9 @@
 package main
 
 import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"flag"
 	"fmt"
@@ -32,9 +35,11 @@ import (
 	"minlib/security"
 	utils2 "minlib/utils"
 	"mir-go/daemon/common"
+	"mir-go/daemon/lf"
 	"mir-go/daemon/utils"
 	"os"
 	"strings"
+	"time"
 )
 
 const (
@@ -46,11 +51,19 @@ var (
 	// 参数
 	resetPasswd     = false
 	oldPasswdNoHash = false
+	issueTls        = false
+	tlsCaCertFile   = ""
+	tlsCaKeyFile    = ""
+	tlsValidDays    = 365
 )
 
 func init() {
 	flag.BoolVar(&resetPasswd, "rp", false, "reset passwd")
 	flag.BoolVar(&oldPasswdNoHash, "oldPasswdNoHash", false, "Force old passwd do not use SM3 hash")
+	flag.BoolVar(&issueTls, "issueTLS", false, "issue TLS certificate bound to the network identity, written to TLSCertFile and TLSKeyFile")
+	flag.StringVar(&tlsCaCertFile, "caCert", "", "CA certificate used to issue the TLS certificate")
+	flag.StringVar(&tlsCaKeyFile, "caKey", "", "private key of the CA used to issue the TLS certificate")
+	flag.IntVar(&tlsValidDays, "days", 365, "valid days of the TLS certificate")
 }
 
 func main() {
@@ -120,6 +133,52 @@ func main() {
 		}
 	}
 	common2.LogInfo("Save passwd file success~")
+
+	if issueTls {
+		if err := issueTlsCertificate(keyChain, mirConfig); err != nil {
+			common2.LogFatal(err)
+		}
+		common2.LogInfo("Issue TLS certificate success~")
+	}
+}
+
+// issueTlsCertificate 为网络身份签发 TLSClientAuth = identity 时使用的 TLS 证书
+//
+// @Description:
+//	证书由 -caCert 和 -caKey 指定的 CA 签发，写入配置文件中的 TLSCertFile 和 TLSKeyFile，
+//	网络身份必须已经是 KeyChain 的当前身份
+// @param keyChain
+// @param mirConfig
+// @return error
+//
+func issueTlsCertificate(keyChain *security.KeyChain, mirConfig *common.MIRConfig) error {
+	if tlsCaCertFile == "" || tlsCaKeyFile == "" {
+		return errors.New("-caCert and -caKey must be provided to issue TLS certificate")
+	}
+	if mirConfig.LogicFaceConfig.TLSCertFile == "" || mirConfig.LogicFaceConfig.TLSKeyFile == "" {
+		return errors.New("TLSCertFile and TLSKeyFile must be configured to issue TLS certificate")
+	}
+	ca, err := tls.LoadX509KeyPair(tlsCaCertFile, tlsCaKeyFile)
+	if err != nil {
+		return err
+	}
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return err
+	}
+	caKey, ok := ca.PrivateKey.(crypto.Signer)
+	if !ok {
+		return errors.New("unsupported CA private key")
+	}
+	certPem, keyPem, err := lf.IssueTlsIdentityCertificate(keyChain, mirConfig.GeneralConfig.DefaultId, caCert, caKey,
+		time.Duration(tlsValidDays)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(mirConfig.LogicFaceConfig.TLSCertFile, certPem, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(mirConfig.LogicFaceConfig.TLSKeyFile, keyPem, 0600)
 }
 
 // checkPasswd 检查密码是否有效
@@ -0,0 +1,329 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routing
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package routing
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+)
+
+// LSDBEntry 链路状态数据库中的一个表项，保存某个路由器最新的 LSA
+//
+// @Description:
+//
+type LSDBEntry struct {
+	lsa        *LSA      // 解析后的 LSA
+	raw        []byte    // 签名后的原始报文，泛洪时原样转发
+	expireTime time.Time // 过期时间
+}
+
+// LSDB 链路状态数据库，保存路由域中每个路由器最新的 LSA
+//
+// @Description:
+//	LSDB 本身不加锁，由 Router 持有锁之后访问
+//
+type LSDB struct {
+	entries map[string]*LSDBEntry
+}
+
+// CreateLSDB 创建一个空的链路状态数据库
+//
+// @Description:
+// @return *LSDB
+//
+func CreateLSDB() *LSDB {
+	return &LSDB{entries: make(map[string]*LSDBEntry)}
+}
+
+// Install 尝试把一个 LSA 安装到 LSDB 中
+//
+// @Description:
+// @receiver l
+// @param lsa
+// @param raw	签名后的原始报文
+// @param now
+// @return int	1 => 比已有的更新，已安装
+//				0 => 和已有的相同
+//			   -1 => 比已有的旧，没有安装
+//
+func (l *LSDB) Install(lsa *LSA, raw []byte, now time.Time) int {
+	if entry, ok := l.entries[lsa.Origin]; ok {
+		if lsa.SeqNo == entry.lsa.SeqNo {
+			return 0
+		} else if lsa.SeqNo < entry.lsa.SeqNo {
+			return -1
+		}
+	}
+	l.entries[lsa.Origin] = &LSDBEntry{
+		lsa:        lsa,
+		raw:        raw,
+		expireTime: now.Add(time.Duration(lsa.Lifetime) * time.Millisecond),
+	}
+	return 1
+}
+
+// Get 获取某个路由器的 LSA
+//
+// @Description:
+// @receiver l
+// @param origin
+// @return *LSA
+// @return []byte
+//
+func (l *LSDB) Get(origin string) (*LSA, []byte) {
+	if entry, ok := l.entries[origin]; ok {
+		return entry.lsa, entry.raw
+	}
+	return nil, nil
+}
+
+// Expire 删除所有已过期的 LSA
+//
+// @Description:
+// @receiver l
+// @param now
+// @return bool	是否有 LSA 被删除
+//
+func (l *LSDB) Expire(now time.Time) bool {
+	expired := false
+	for origin, entry := range l.entries {
+		if !now.Before(entry.expireTime) {
+			delete(l.entries, origin)
+			expired = true
+		}
+	}
+	return expired
+}
+
+// RawLSAs 返回 LSDB 中所有 LSA 签名后的原始报文，用于和新邻居同步 LSDB
+//
+// @Description:
+// @receiver l
+// @return [][]byte
+//
+func (l *LSDB) RawLSAs() [][]byte {
+	raws := make([][]byte, 0, len(l.entries))
+	for _, entry := range l.entries {
+		raws = append(raws, entry.raw)
+	}
+	return raws
+}
+
+// Size 返回 LSDB 中 LSA 的个数
+//
+// @Description:
+// @receiver l
+// @return int
+//
+func (l *LSDB) Size() int {
+	return len(l.entries)
+}
+
+//
+// @Description: 根据 LSDB 构造拓扑图，只保留两端的 LSA 都包含对方的双向邻接关系
+// @receiver l
+// @return map[string]map[string]uint64	路由器名 => 邻居路由器名 => 开销
+//
+func (l *LSDB) buildGraph() map[string]map[string]uint64 {
+	graph := make(map[string]map[string]uint64, len(l.entries))
+	for origin, entry := range l.entries {
+		for _, adjacency := range entry.lsa.Adjacencies {
+			if !l.hasAdjacency(adjacency.Neighbor, origin) {
+				continue
+			}
+			if graph[origin] == nil {
+				graph[origin] = make(map[string]uint64)
+			}
+			graph[origin][adjacency.Neighbor] = adjacency.Cost
+		}
+	}
+	return graph
+}
+
+//
+// @Description: 判断 from 的 LSA 中是否包含到 to 的邻接关系
+// @receiver l
+// @param from
+// @param to
+// @return bool
+//
+func (l *LSDB) hasAdjacency(from string, to string) bool {
+	entry, ok := l.entries[from]
+	if !ok {
+		return false
+	}
+	for _, adjacency := range entry.lsa.Adjacencies {
+		if adjacency.Neighbor == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NextHop 路由计算得到的一个下一跳
+//
+// @Description:
+//
+type NextHop struct {
+	LinkId uint64 // 下一跳链路，对应 LogicFaceId
+	Cost   uint64 // 经过这条链路到达目的前缀的总开销
+}
+
+// localLink 路由计算时使用的一条本地链路
+type localLink struct {
+	linkId   uint64
+	neighbor string
+	cost     uint64
+}
+
+//
+// @Description: 计算到每个前缀的下一跳
+//	对每条本地链路，以链路对端的邻居为源点、在去掉本路由器的拓扑上运行 Dijkstra，得到经过这条链路到达每个路由器的开销。
+//	不开启多路径时每个前缀只保留开销最小的一个下一跳，开启多路径时按开销从小到大保留最多 maxPaths 个下一跳，
+//	开销相同时链路号小的优先。本路由器自己通告的前缀不计算路由。
+// @receiver l
+// @param self		本路由器名
+// @param links		本地可用的链路
+// @param multipath
+// @param maxPaths
+// @return map[string][]NextHop	前缀 => 按开销排好序的下一跳
+//
+func (l *LSDB) computeRoutes(self string, links []localLink, multipath bool, maxPaths int) map[string][]NextHop {
+	graph := l.buildGraph()
+	// 同一个邻居的最短路径只计算一次
+	distances := make(map[string]map[string]uint64)
+	for _, link := range links {
+		if _, ok := distances[link.neighbor]; !ok {
+			distances[link.neighbor] = dijkstra(graph, link.neighbor, self)
+		}
+	}
+
+	localPrefixes := make(map[string]bool)
+	if lsa, _ := l.Get(self); lsa != nil {
+		for _, prefix := range lsa.Prefixes {
+			localPrefixes[prefix] = true
+		}
+	}
+
+	// 前缀 => 链路号 => 经过这条链路的最小开销，多个路由器通告同一个前缀时取最小值
+	prefixCosts := make(map[string]map[uint64]uint64)
+	for origin, entry := range l.entries {
+		if origin == self {
+			continue
+		}
+		for _, link := range links {
+			distance, ok := distances[link.neighbor][origin]
+			if !ok {
+				continue
+			}
+			cost := link.cost + distance
+			for _, prefix := range entry.lsa.Prefixes {
+				if localPrefixes[prefix] {
+					continue
+				}
+				if prefixCosts[prefix] == nil {
+					prefixCosts[prefix] = make(map[uint64]uint64)
+				}
+				if oldCost, ok := prefixCosts[prefix][link.linkId]; !ok || cost < oldCost {
+					prefixCosts[prefix][link.linkId] = cost
+				}
+			}
+		}
+	}
+
+	routes := make(map[string][]NextHop, len(prefixCosts))
+	for prefix, costs := range prefixCosts {
+		nextHops := make([]NextHop, 0, len(costs))
+		for linkId, cost := range costs {
+			nextHops = append(nextHops, NextHop{LinkId: linkId, Cost: cost})
+		}
+		sort.Slice(nextHops, func(i, j int) bool {
+			if nextHops[i].Cost != nextHops[j].Cost {
+				return nextHops[i].Cost < nextHops[j].Cost
+			}
+			return nextHops[i].LinkId < nextHops[j].LinkId
+		})
+		limit := 1
+		if multipath && maxPaths > 1 {
+			limit = maxPaths
+		}
+		if len(nextHops) > limit {
+			nextHops = nextHops[:limit]
+		}
+		routes[prefix] = nextHops
+	}
+	return routes
+}
+
+// dijkstraItem 优先队列中的一个元素
+type dijkstraItem struct {
+	router   string
+	distance uint64
+}
+
+// dijkstraQueue 按距离排序的小顶堆
+type dijkstraQueue []dijkstraItem
+
+func (q dijkstraQueue) Len() int { return len(q) }
+func (q dijkstraQueue) Less(i, j int) bool {
+	return q[i].distance < q[j].distance
+}
+func (q dijkstraQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *dijkstraQueue) Push(x interface{}) { *q = append(*q, x.(dijkstraItem)) }
+func (q *dijkstraQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+//
+// @Description: 在 graph 上以 source 为源点运行 Dijkstra，计算时跳过 excluded 节点
+// @param graph
+// @param source
+// @param excluded
+// @return map[string]uint64	路由器名 => 最短距离，不可达的路由器不在结果中
+//
+func dijkstra(graph map[string]map[string]uint64, source string, excluded string) map[string]uint64 {
+	distances := map[string]uint64{source: 0}
+	visited := make(map[string]bool)
+	queue := &dijkstraQueue{{router: source, distance: 0}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(dijkstraItem)
+		if visited[item.router] {
+			continue
+		}
+		visited[item.router] = true
+		for neighbor, cost := range graph[item.router] {
+			if neighbor == excluded || visited[neighbor] {
+				continue
+			}
+			distance := item.distance + cost
+			if oldDistance, ok := distances[neighbor]; !ok || distance < oldDistance {
+				distances[neighbor] = distance
+				heap.Push(queue, dijkstraItem{router: neighbor, distance: distance})
+			}
+		}
+	}
+	return distances
+}
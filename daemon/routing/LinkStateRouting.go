@@ -0,0 +1,333 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routing
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package routing
+
+import (
+	common2 "minlib/common"
+	"minlib/component"
+	"minlib/packet"
+	"minlib/security"
+	"mir-go/daemon/common"
+	"mir-go/daemon/lf"
+	"mir-go/daemon/plugin"
+	"mir-go/daemon/table"
+	"mir-go/daemon/utils"
+	"strings"
+	"time"
+)
+
+// RoutingPrefix 路由协议报文使用的标识前缀，只在相邻的路由器之间传递，不会被转发
+const RoutingPrefix = "/localhop/mir-routing"
+
+// LinkStateRouting 运行在 MIR 中的链路状态路由组件
+//
+// @Description:
+//	1. 作为插件注册到 Forwarder，在 Incoming GPPkt 管道中拦截目的标识以 RoutingPrefix 开头的 GPPkt，交给 Router 处理；
+//	2. 周期性地扫描 LogicFaceTable，在所有 TCP、UDP 和以太网 LogicFace 上运行路由协议；
+//	3. 路由协议报文封装在 GPPkt 中，直接从对应的 LogicFace 发出；
+//	4. 计算出的路由以 routing 来源写入 RIB，没有 RIB 时直接写入 FIB。
+//
+type LinkStateRouting struct {
+	plugin.BasePlugin
+	router         *Router
+	logicFaceTable *lf.LogicFaceTable
+	linkCost       uint64
+	scanInterval   time.Duration
+	srcIdentifier  *component.Identifier // 发出的 GPPkt 的源标识，即本路由器名
+	dstIdentifier  *component.Identifier // 发出的 GPPkt 的目的标识
+	stopChan       chan struct{}
+}
+
+// CreateLinkStateRouting 根据配置创建链路状态路由组件
+//
+// @Description:
+// @param config
+// @param logicFaceTable
+// @param fib
+// @param rib		不为 nil 时路由写入 RIB，否则直接写入 FIB
+// @param keyChain	使用当前身份对 Hello 和 LSA 签名，使用其中的证书验证其它路由器的签名
+// @return *LinkStateRouting
+// @return error
+//
+func CreateLinkStateRouting(config *common.MIRConfig, logicFaceTable *lf.LogicFaceTable, fib *table.FIB,
+	rib *table.RIB, keyChain *security.KeyChain) (*LinkStateRouting, error) {
+	routingConfig := config.RoutingConfig
+	routerName, err := getSignedRouterName(config)
+	if err != nil {
+		return nil, err
+	}
+	srcIdentifier, err := component.CreateIdentifierByString(routerName)
+	if err != nil {
+		return nil, err
+	}
+	dstIdentifier, err := component.CreateIdentifierByString(RoutingPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &LinkStateRouting{
+		logicFaceTable: logicFaceTable,
+		linkCost:       uint64(routingConfig.LinkCost),
+		scanInterval:   time.Duration(routingConfig.HelloInterval) * time.Millisecond,
+		srcIdentifier:  srcIdentifier,
+		dstIdentifier:  dstIdentifier,
+		stopChan:       make(chan struct{}),
+	}
+	var installer IRouteInstaller
+	if rib != nil {
+		installer = &ribRouteInstaller{rib: rib, logicFaceTable: logicFaceTable, ecmp: routingConfig.Multipath}
+	} else {
+		installer = &fibRouteInstaller{fib: fib, logicFaceTable: logicFaceTable}
+	}
+	l.router = CreateRouter(RouterConfig{
+		RouterName:         routerName,
+		Prefixes:           routingConfig.AdvertisePrefixes,
+		HelloInterval:      time.Duration(routingConfig.HelloInterval) * time.Millisecond,
+		DeadInterval:       time.Duration(routingConfig.DeadInterval) * time.Millisecond,
+		LSARefreshInterval: time.Duration(routingConfig.LSARefreshInterval) * time.Millisecond,
+		LSALifetime:        time.Duration(routingConfig.LSALifetime) * time.Millisecond,
+		Multipath:          routingConfig.Multipath,
+		MaxPaths:           routingConfig.MaxPaths,
+	}, NewKeyChainSigner(keyChain), l, installer)
+	return l, nil
+}
+
+// GetRouter 获取链路状态路由器
+//
+// @Description:
+// @receiver l
+// @return *Router
+//
+func (l *LinkStateRouting) GetRouter() *Router {
+	return l.router
+}
+
+// Start 启动路由器和扫描 LogicFaceTable 的协程
+//
+// @Description:
+// @receiver l
+//
+func (l *LinkStateRouting) Start() {
+	l.router.Start()
+	utils.GoroutineNoPanic(func() {
+		ticker := time.NewTicker(l.scanInterval)
+		defer ticker.Stop()
+		for {
+			l.scanLogicFaces()
+			select {
+			case <-l.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Stop 停止路由组件
+//
+// @Description:
+// @receiver l
+//
+func (l *LinkStateRouting) Stop() {
+	close(l.stopChan)
+	l.router.Stop()
+}
+
+// OnIncomingGPPkt 拦截路由协议报文
+//
+// @Description:
+// @receiver l
+// @param ingress
+// @param gPPkt
+// @return int
+//
+func (l *LinkStateRouting) OnIncomingGPPkt(ingress *lf.LogicFace, gPPkt *packet.GPPkt) int {
+	if !uriHasPrefix(gPPkt.DstIdentifier().ToUri(), RoutingPrefix) {
+		return 0
+	}
+	l.router.OnMessage(ingress.LogicFaceId, gPPkt.Payload.GetValue())
+	// 路由协议报文由本组件消费，不再转发
+	return -1
+}
+
+// Send 把路由协议报文封装成 GPPkt，从对应的 LogicFace 发出
+//
+// @Description:
+// @receiver l
+// @param linkId
+// @param buf
+//
+func (l *LinkStateRouting) Send(linkId uint64, buf []byte) {
+	logicFace := l.logicFaceTable.GetLogicFacePtrById(linkId)
+	if logicFace == nil || !logicFace.IsUp() {
+		return
+	}
+	logicFace.SendGPPkt(newLocalhopGPPkt(l.srcIdentifier, l.dstIdentifier, buf))
+}
+
+//
+// @Description: 扫描 LogicFaceTable，在新出现的 TCP、TLS、WebSocket、UDP 和以太网 LogicFace 上运行路由协议，删除已经关闭的 LogicFace 对应的链路
+// @receiver l
+//
+func (l *LinkStateRouting) scanLogicFaces() {
+	alive := make(map[uint64]bool)
+	l.logicFaceTable.Range(func(logicFaceId uint64, logicFace *lf.LogicFace) bool {
+		// 组播 LogicFace 连接的是多个路由器，不能作为点到点的链路，永久性的 LogicFace 重连期间也当作链路已经断开
+		if !logicFace.IsUp() || logicFace.IsMulticast() {
+			return true
+		}
+		switch logicFace.GetLogicFaceType() {
+		case lf.LogicFaceTypeTCP, lf.LogicFaceTypeTLS, lf.LogicFaceTypeWebSocket, lf.LogicFaceTypeUDP, lf.LogicFaceTypeEther:
+			alive[logicFaceId] = true
+			if !l.router.HasLink(logicFaceId) {
+				common2.LogInfo("start routing on logic face ", logicFaceId, " ", logicFace.GetRemoteUri())
+				l.router.AddLink(logicFaceId, l.linkCost)
+			}
+		}
+		return true
+	})
+	for _, linkId := range l.router.LinkIds() {
+		if !alive[linkId] {
+			common2.LogInfo("stop routing on logic face ", linkId)
+			l.router.RemoveLink(linkId)
+		}
+	}
+}
+
+//
+// @Description: 获取本路由器名，没有配置 RouterName 时使用 DefaultId
+// @param config
+// @return string
+//
+func getRouterName(config *common.MIRConfig) string {
+	if config.RoutingConfig.RouterName != "" {
+		return config.RoutingConfig.RouterName
+	}
+	return config.GeneralConfig.DefaultId
+}
+
+//
+// @Description: 获取路由器名，路由器使用 DefaultId 身份签名，所以路由器名必须是 DefaultId 本身或者在 DefaultId 之下
+// @param config
+// @return string
+// @return error
+//
+func getSignedRouterName(config *common.MIRConfig) (string, error) {
+	routerName := getRouterName(config)
+	if !uriHasPrefix(routerName, config.GeneralConfig.DefaultId) {
+		return "", RoutingError{msg: "RouterName " + routerName + " is not under the signing identity " +
+			config.GeneralConfig.DefaultId}
+	}
+	return routerName, nil
+}
+
+//
+// @Description: 判断 uri 是否等于 prefix 或者以 prefix 为前缀
+// @param uri
+// @param prefix
+// @return bool
+//
+func uriHasPrefix(uri string, prefix string) bool {
+	return uri == prefix || strings.HasPrefix(uri, prefix+"/")
+}
+
+//
+// @Description: 构造一个只发往相邻路由器的 GPPkt，TTL 为 1，不会被继续转发
+// @param src
+// @param dst
+// @param payload
+// @return *packet.GPPkt
+//
+func newLocalhopGPPkt(src *component.Identifier, dst *component.Identifier, payload []byte) *packet.GPPkt {
+	gPPkt := new(packet.GPPkt)
+	gPPkt.SetSrcIdentifier(src)
+	gPPkt.SetDstIdentifier(dst)
+	gPPkt.SetTTL(1)
+	gPPkt.Payload.SetValue(payload)
+	return gPPkt
+}
+
+// ribRouteInstaller 把路由以 routing 来源写入 RIB
+type ribRouteInstaller struct {
+	rib            *table.RIB
+	logicFaceTable *lf.LogicFaceTable
+	ecmp           bool // 开启多路径时把下一跳标记为等价多路径成员
+}
+
+func (r *ribRouteInstaller) AddRoute(prefix string, linkId uint64, cost uint64) error {
+	identifier, err := component.CreateIdentifierByString(prefix)
+	if err != nil {
+		return err
+	}
+	logicFace := r.logicFaceTable.GetLogicFacePtrById(linkId)
+	if logicFace == nil {
+		return RoutingError{msg: "logic face is not found"}
+	}
+	flags := table.RouteFlagChildInherit
+	if r.ecmp {
+		flags |= table.RouteFlagECMP
+	}
+	return r.rib.Register(identifier, logicFace, table.RouteOriginRouting, cost, flags, 0)
+}
+
+func (r *ribRouteInstaller) RemoveRoute(prefix string, linkId uint64) error {
+	identifier, err := component.CreateIdentifierByString(prefix)
+	if err != nil {
+		return err
+	}
+	return r.rib.Unregister(identifier, linkId, table.RouteOriginRouting)
+}
+
+// fibRouteInstaller 没有 RIB 时直接把路由写入 FIB
+type fibRouteInstaller struct {
+	fib            *table.FIB
+	logicFaceTable *lf.LogicFaceTable
+}
+
+func (f *fibRouteInstaller) AddRoute(prefix string, linkId uint64, cost uint64) error {
+	identifier, err := component.CreateIdentifierByString(prefix)
+	if err != nil {
+		return err
+	}
+	logicFace := f.logicFaceTable.GetLogicFacePtrById(linkId)
+	if logicFace == nil {
+		return RoutingError{msg: "logic face is not found"}
+	}
+	f.fib.AddOrUpdate(identifier, logicFace, cost)
+	return nil
+}
+
+func (f *fibRouteInstaller) RemoveRoute(prefix string, linkId uint64) error {
+	identifier, err := component.CreateIdentifierByString(prefix)
+	if err != nil {
+		return err
+	}
+	fibEntry := f.fib.FindExactMatch(identifier)
+	if fibEntry == nil {
+		return RoutingError{msg: "FIB entry " + prefix + " is not found"}
+	}
+	fibEntry.RemoveNextHop(&lf.LogicFace{LogicFaceId: linkId})
+	if !fibEntry.HasNextHops() {
+		return f.fib.EraseByFIBEntry(fibEntry)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routing
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package routing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"minlib/component"
+	"minlib/encoding"
+	"minlib/packet"
+	"minlib/security"
+)
+
+// 路由协议报文的类型
+const (
+	MessageTypeHello = "hello"
+	MessageTypeLSA   = "lsa"
+)
+
+// Hello 邻居之间周期性交换的 Hello 报文，用于发现邻居和检测链路是否可用
+//
+// @Description:
+//	Neighbors 中包含发送者在这条链路上已经收到过 Hello 的邻居，收到的一方在其中发现了自己，
+//	则认为这条链路是双向可达的，可以用于路由计算
+//
+type Hello struct {
+	Router    string   `json:"router"`    // 发送者的路由器名
+	Neighbors []string `json:"neighbors"` // 发送者在这条链路上已经收到过 Hello 的邻居
+}
+
+// Adjacency LSA 中描述的一条邻接关系
+//
+// @Description:
+//
+type Adjacency struct {
+	Neighbor string `json:"neighbor"` // 邻居路由器名
+	Cost     uint64 `json:"cost"`     // 到邻居的链路开销
+}
+
+// LSA 链路状态通告，由每个路由器生成并泛洪到整个路由域
+//
+// @Description:
+//
+type LSA struct {
+	Origin      string      `json:"origin"`      // 生成这个 LSA 的路由器名
+	SeqNo       uint64      `json:"seqNo"`       // 序列号，序列号越大的 LSA 越新
+	Lifetime    int64       `json:"lifetime"`    // 生存时间（单位为毫秒）
+	Adjacencies []Adjacency `json:"adjacencies"` // 生成者当前可用的邻接关系
+	Prefixes    []string    `json:"prefixes"`    // 生成者通告的前缀
+}
+
+// Message 路由协议报文的封装，所有的 Hello 和 LSA 在发送之前都需要签名
+//
+// @Description:
+//	Signer 使用自己在 KeyChain 中的身份签名，LSA 在泛洪时原样转发签名后的报文，所以每个路由器都可以用 Signer 的证书
+//	验证 LSA 确实是由 Signer 生成的
+//
+type Message struct {
+	Type      string `json:"type"`      // 报文类型
+	Signer    string `json:"signer"`    // 签名者的路由器名
+	Body      []byte `json:"body"`      // Hello 或者 LSA 序列化之后的内容
+	Signature []byte `json:"signature"` // 签名
+}
+
+// ISigner 路由协议报文的签名器
+//
+// @Description:
+//
+type ISigner interface {
+	// Sign
+	// 使用 signer 的身份对 content 签名
+	//
+	// @Description:
+	// @param signer
+	// @param content
+	// @return []byte
+	// @return error
+	//
+	Sign(signer string, content []byte) ([]byte, error)
+
+	// Verify
+	// 验证 signature 是否是 signer 对 content 的签名
+	//
+	// @Description:
+	// @param signer
+	// @param content
+	// @param signature
+	// @return error	验证失败时返回错误
+	//
+	Verify(signer string, content []byte, signature []byte) error
+}
+
+// KeyChainSigner 使用 KeyChain 中各个路由器自己的身份对路由协议报文签名
+//
+// @Description:
+//	1. 签名时把签名内容作为 Payload 放到以 signer 命名的 Data 中，使用 KeyChain 的当前身份签名，编码后的 Data 即为签名；
+//	2. 验证时要求 Data 的名字和 Payload 与报文一致，签名身份是 signer 本身或者 signer 的前缀，并且签名身份的证书存在于
+//	   KeyChain 中（本地创建或者通过 identity-mgmt/importCert 导入了证书），再用证书验证签名。
+//	每个路由器只能使用自己的私钥签名，所以持有其它路由器证书的路由器也无法伪造它们的 Hello 和 LSA。
+//
+type KeyChainSigner struct {
+	keyChain *security.KeyChain
+}
+
+// NewKeyChainSigner 使用 KeyChain 创建一个 KeyChainSigner
+//
+// @Description:
+// @param keyChain
+// @return *KeyChainSigner
+//
+func NewKeyChainSigner(keyChain *security.KeyChain) *KeyChainSigner {
+	return &KeyChainSigner{keyChain: keyChain}
+}
+
+func (k *KeyChainSigner) Sign(signer string, content []byte) ([]byte, error) {
+	identifier, err := component.CreateIdentifierByString(signer)
+	if err != nil {
+		return nil, err
+	}
+	data := packet.NewDataByName(identifier)
+	data.Payload.SetValue(content)
+	if err := k.keyChain.SignData(data); err != nil {
+		return nil, err
+	}
+	var encoder encoding.Encoder
+	if err := encoder.EncoderReset(encoding.MaxPacketSize, 0); err != nil {
+		return nil, err
+	}
+	bufLen, err := data.WireEncode(&encoder)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := encoder.GetBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf[:bufLen], nil
+}
+
+func (k *KeyChainSigner) Verify(signer string, content []byte, signature []byte) error {
+	block, err := encoding.CreateBlockByBuffer(signature, true)
+	if err != nil {
+		return err
+	}
+	var minPacket packet.MINPacket
+	if err := minPacket.WireDecode(block); err != nil {
+		return err
+	}
+	data, err := packet.NewDataByMINPacket(&minPacket)
+	if err != nil {
+		return err
+	}
+	if data.GetName().ToUri() != signer || !bytes.Equal(data.Payload.GetValue(), content) {
+		return RoutingError{msg: "signature does not match the message of " + signer}
+	}
+	sig, err := data.GetSignature(0)
+	if err != nil || sig == nil || sig.SigInfo == nil || sig.SigInfo.KeyLocator == nil {
+		return RoutingError{msg: "message of " + signer + " is not signed"}
+	}
+	identityName := sig.SigInfo.KeyLocator.ToUri()
+	if !uriHasPrefix(signer, identityName) {
+		return RoutingError{msg: "identity " + identityName + " can not sign for " + signer}
+	}
+	if k.keyChain.GetIdentityByName(identityName) == nil {
+		return RoutingError{msg: "the certificate of " + identityName + " is not in KeyChain"}
+	}
+	if err := k.keyChain.Verify(&minPacket); err != nil {
+		return RoutingError{msg: "verify signature of " + signer + " failed: " + err.Error()}
+	}
+	return nil
+}
+
+//
+// @Description: 签名时覆盖的内容，包括报文类型和报文内容
+// @param messageType
+// @param body
+// @return []byte
+//
+func signedContent(messageType string, body []byte) []byte {
+	content := make([]byte, 0, len(messageType)+1+len(body))
+	content = append(content, messageType...)
+	content = append(content, 0)
+	return append(content, body...)
+}
+
+//
+// @Description: 序列化 Hello 或 LSA 并签名，返回可以直接发送的报文
+// @param signer
+// @param signerName
+// @param messageType
+// @param body
+// @return []byte
+// @return error
+//
+func encodeMessage(signer ISigner, signerName string, messageType string, body interface{}) ([]byte, error) {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(signerName, signedContent(messageType, bodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&Message{
+		Type:      messageType,
+		Signer:    signerName,
+		Body:      bodyBytes,
+		Signature: signature,
+	})
+}
+
+//
+// @Description: 反序列化一个报文并验证签名
+// @param signer
+// @param buf
+// @return *Message
+// @return error
+//
+func decodeMessage(signer ISigner, buf []byte) (*Message, error) {
+	message := new(Message)
+	if err := json.Unmarshal(buf, message); err != nil {
+		return nil, err
+	}
+	if err := signer.Verify(message.Signer, signedContent(message.Type, message.Body), message.Signature); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type RoutingError struct {
+	msg string
+}
+
+func (r RoutingError) Error() string {
+	return fmt.Sprintf("RoutingError: %s", r.msg)
+}
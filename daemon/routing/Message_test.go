@@ -0,0 +1,92 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routing
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 22:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package routing
+
+import (
+	"minlib/security"
+	"path/filepath"
+	"testing"
+)
+
+// 创建一个 KeyChain，并创建 identityName 身份作为当前身份
+func newTestKeyChain(t *testing.T, identityName string) *security.KeyChain {
+	keyChain := new(security.KeyChain)
+	if err := keyChain.InitialKeyChainByPath(filepath.Join(t.TempDir(), "identity.db")); err != nil {
+		t.Fatal(err)
+	}
+	identity, err := keyChain.CreateIdentityByName(identityName, "mir-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keyChain.SetCurrentIdentity(identity, "mir-test"); err != nil {
+		t.Fatal(err)
+	}
+	return keyChain
+}
+
+func TestKeyChainSigner(t *testing.T) {
+	r1KeyChain := newTestKeyChain(t, "/r1")
+	r2KeyChain := newTestKeyChain(t, "/r2")
+	r3KeyChain := newTestKeyChain(t, "/r3")
+
+	// r2 导入 r1 的证书，r3 没有导入
+	cert, err := r1KeyChain.IdentityManager.DumpCert("/r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r2KeyChain.IdentityManager.ImportCert([]byte(cert)); err != nil {
+		t.Fatal(err)
+	}
+
+	content := signedContent(MessageTypeHello, []byte(`{"Router":"/r1"}`))
+	signature, err := NewKeyChainSigner(r1KeyChain).Sign("/r1", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := NewKeyChainSigner(r2KeyChain)
+	if err := verifier.Verify("/r1", content, signature); err != nil {
+		t.Fatalf("message signed by /r1 should be verified with its imported certificate: %v", err)
+	}
+
+	// 报文内容或者签名者被篡改
+	tampered := signedContent(MessageTypeHello, []byte(`{"Router":"/r4"}`))
+	if err := verifier.Verify("/r1", tampered, signature); err == nil {
+		t.Error("tampered message should be rejected")
+	}
+	if err := verifier.Verify("/r1/x", content, signature); err == nil {
+		t.Error("signature of another signer should be rejected")
+	}
+
+	// 没有导入证书的 KeyChain 无法验证
+	if err := NewKeyChainSigner(r3KeyChain).Verify("/r1", content, signature); err == nil {
+		t.Error("signer whose certificate is not in KeyChain should be rejected")
+	}
+
+	// r2 用自己的身份冒充 r1 签名
+	forged, err := NewKeyChainSigner(r2KeyChain).Sign("/r1", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.Verify("/r1", content, forged); err == nil {
+		t.Error("message signed by another identity should be rejected")
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routing
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:30 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package routing
+
+import (
+	"encoding/json"
+	common2 "minlib/common"
+	"minlib/component"
+	"minlib/packet"
+	"minlib/security"
+	"mir-go/daemon/common"
+	"mir-go/daemon/lf"
+	"mir-go/daemon/plugin"
+	"mir-go/daemon/utils"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DiscoveryPrefix 邻居发现报文使用的标识前缀，只在相邻的路由器之间传递，不会被转发
+const DiscoveryPrefix = "/localhop/mir-discovery"
+
+// MessageTypeDiscovery 邻居发现 Hello 的报文类型
+const MessageTypeDiscovery = "discovery"
+
+// DiscoveryHello 邻居发现使用的 Hello 报文，周期性地发送到以太网组播 LogicFace 和 UDP 组播组
+//
+// @Description:
+//
+type DiscoveryHello struct {
+	Router  string `json:"router"`  // 发送者的路由器名
+	UdpPort int    `json:"udpPort"` // 发送者接收单播 UDP 的端口号，0 表示不支持 UDP
+}
+
+// NeighborDiscovery 基于 Hello 的邻居发现
+//
+// @Description:
+//	1. 周期性地在每个网卡的以太网组播 LogicFace 上发送 Hello（封装在 GPPkt 中），同时发送到 UDP 组播组；
+//	2. 以太网 Hello 的接收方由 InterfaceListener 根据源 MAC 地址自动创建单播的以太网 LogicFace，本模块作为插件从
+//	   Incoming GPPkt 管道中拦截 Hello，把入口 LogicFace 记录为到邻居的单播 LogicFace；
+//	3. UDP Hello 由本模块直接从组播 socket 中读取，根据源 IP 地址和 Hello 中宣告的端口创建单播的 UDP LogicFace；
+//	4. 超过 NeighborDeadInterval 没有收到 Hello 的邻居被认为已失效，如果到它的 LogicFace 是本模块创建的则关闭该 LogicFace；
+//	   以太网 LogicFace 由 InterfaceListener 维护，已经存在的 UDP LogicFace（eg: defaultRoute.xml 或者 mirc lf add 创建的）
+//	   属于创建它的模块，都不会被关闭。
+//
+//	和链路状态路由一样，Hello 使用本路由器在 KeyChain 中的身份签名，签名验证失败的 Hello 不会创建 LogicFace。
+//
+type NeighborDiscovery struct {
+	plugin.BasePlugin
+	routerName      string
+	signer          ISigner
+	logicFaceSystem *lf.LogicFaceSystem
+	neighborTable   *NeighborTable
+	interval        time.Duration
+	enableEther     bool
+	udpPort         int          // 本路由器接收单播 UDP 的端口号，不支持 UDP 时为 0
+	udpGroup        *net.UDPAddr // UDP 组播地址，没有开启 UDP 发现时为 nil
+	udpRecvConn     *net.UDPConn // 加入组播组用于接收 Hello 的 socket
+	udpSendConn     *net.UDPConn // 用于发送 Hello 的 socket
+	srcIdentifier   *component.Identifier
+	dstIdentifier   *component.Identifier
+	stopChan        chan struct{}
+	faceLock        sync.Mutex          // 保护 createdFaces
+	createdFaces    map[uint64]struct{} // 本模块创建的 LogicFace，邻居失效时只关闭这些 LogicFace
+}
+
+// CreateNeighborDiscovery 根据配置创建邻居发现模块
+//
+// @Description:
+// @param config
+// @param logicFaceSystem
+// @param keyChain	使用当前身份对 Hello 签名，使用其中的证书验证邻居的签名
+// @return *NeighborDiscovery
+// @return error
+//
+func CreateNeighborDiscovery(config *common.MIRConfig, logicFaceSystem *lf.LogicFaceSystem,
+	keyChain *security.KeyChain) (*NeighborDiscovery, error) {
+	routerName, err := getSignedRouterName(config)
+	if err != nil {
+		return nil, err
+	}
+	srcIdentifier, err := component.CreateIdentifierByString(routerName)
+	if err != nil {
+		return nil, err
+	}
+	dstIdentifier, err := component.CreateIdentifierByString(DiscoveryPrefix)
+	if err != nil {
+		return nil, err
+	}
+	n := &NeighborDiscovery{
+		routerName:      routerName,
+		signer:          NewKeyChainSigner(keyChain),
+		logicFaceSystem: logicFaceSystem,
+		neighborTable:   CreateNeighborTable(uint64(config.DiscoveryConfig.NeighborDeadInterval)),
+		interval:        time.Duration(config.DiscoveryConfig.DiscoveryInterval) * time.Millisecond,
+		enableEther:     config.DiscoveryConfig.EnableEtherDiscovery,
+		srcIdentifier:   srcIdentifier,
+		dstIdentifier:   dstIdentifier,
+		stopChan:        make(chan struct{}),
+		createdFaces:    make(map[uint64]struct{}),
+	}
+	if config.DiscoveryConfig.EnableUdpDiscovery && config.LogicFaceConfig.SupportUDP {
+		if n.udpGroup, err = net.ResolveUDPAddr("udp4", config.DiscoveryConfig.UdpMulticastGroup); err != nil {
+			return nil, err
+		}
+		if !n.udpGroup.IP.IsMulticast() {
+			return nil, RoutingError{msg: config.DiscoveryConfig.UdpMulticastGroup + " is not a multicast address"}
+		}
+		n.udpPort = config.LogicFaceConfig.UDPPort
+	}
+	logicFaceSystem.LogicFaceTable().AddEventListener(n.onFaceEvent)
+	return n, nil
+}
+
+// GetNeighborTable 获取邻居表
+//
+// @Description:
+// @receiver n
+// @return *NeighborTable
+//
+func (n *NeighborDiscovery) GetNeighborTable() *NeighborTable {
+	return n.neighborTable
+}
+
+// Start 打开 UDP 组播 socket，启动周期性发送 Hello 和检测邻居失效的协程
+//
+// @Description:
+// @receiver n
+// @return error
+//
+func (n *NeighborDiscovery) Start() error {
+	if n.udpGroup != nil {
+		var err error
+		if n.udpRecvConn, err = net.ListenMulticastUDP("udp4", nil, n.udpGroup); err != nil {
+			return err
+		}
+		if n.udpSendConn, err = net.ListenUDP("udp4", nil); err != nil {
+			_ = n.udpRecvConn.Close()
+			return err
+		}
+		utils.GoroutineNoPanic(n.receiveUdpHello)
+	}
+	utils.GoroutineNoPanic(func() {
+		ticker := time.NewTicker(n.interval)
+		defer ticker.Stop()
+		for {
+			n.sendHello()
+			n.expireNeighbors()
+			select {
+			case <-n.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	return nil
+}
+
+// Stop 停止邻居发现
+//
+// @Description:
+// @receiver n
+//
+func (n *NeighborDiscovery) Stop() {
+	close(n.stopChan)
+	if n.udpRecvConn != nil {
+		_ = n.udpRecvConn.Close()
+		_ = n.udpSendConn.Close()
+	}
+}
+
+// OnIncomingGPPkt 拦截从以太网收到的邻居发现 Hello
+//
+// @Description:
+// @receiver n
+// @param ingress
+// @param gPPkt
+// @return int
+//
+func (n *NeighborDiscovery) OnIncomingGPPkt(ingress *lf.LogicFace, gPPkt *packet.GPPkt) int {
+	if !uriHasPrefix(gPPkt.DstIdentifier().ToUri(), DiscoveryPrefix) {
+		return 0
+	}
+	hello, err := n.decodeHello(gPPkt.Payload.GetValue())
+	if err != nil {
+		common2.LogDebug("drop discovery hello from logic face ", ingress.LogicFaceId, ": ", err)
+		return -1
+	}
+	// InterfaceListener 已经为邻居的源 MAC 地址创建了单播 LogicFace，直接把入口 LogicFace 作为到邻居的 LogicFace
+	if hello.Router != n.routerName && ingress.GetLogicFaceType() == lf.LogicFaceTypeEther && !ingress.IsMulticast() {
+		// 只提高 Persistence，不能把 permanent 的 LogicFace 降级
+		if ingress.Persistence < 1 {
+			ingress.SetPersistence(1)
+		}
+		n.onNeighborHello(hello.Router, NeighborViaEther, ingress)
+	}
+	// 邻居发现报文由本模块消费，不再转发
+	return -1
+}
+
+//
+// @Description: 在所有以太网组播 LogicFace 和 UDP 组播组中发送 Hello
+// @receiver n
+//
+func (n *NeighborDiscovery) sendHello() {
+	buf, err := n.encodeHello()
+	if err != nil {
+		common2.LogError("encode discovery hello failed: ", err)
+		return
+	}
+	if n.enableEther {
+		for _, logicFace := range n.logicFaceSystem.EtherMulticastLogicFaces() {
+			logicFace.SendGPPkt(newLocalhopGPPkt(n.srcIdentifier, n.dstIdentifier, buf))
+		}
+	}
+	if n.udpSendConn != nil {
+		if _, err := n.udpSendConn.WriteToUDP(buf, n.udpGroup); err != nil {
+			common2.LogWarn("send discovery hello to ", n.udpGroup.String(), " failed: ", err)
+		}
+	}
+}
+
+//
+// @Description: 构造并签名本路由器的 Hello
+// @receiver n
+// @return []byte
+// @return error
+//
+func (n *NeighborDiscovery) encodeHello() ([]byte, error) {
+	return encodeMessage(n.signer, n.routerName, MessageTypeDiscovery, &DiscoveryHello{
+		Router:  n.routerName,
+		UdpPort: n.udpPort,
+	})
+}
+
+//
+// @Description: 从 UDP 组播 socket 中读取 Hello
+// @receiver n
+//
+func (n *NeighborDiscovery) receiveUdpHello() {
+	buf := make([]byte, 9000)
+	for {
+		length, remoteAddr, err := n.udpRecvConn.ReadFromUDP(buf)
+		if err != nil {
+			common2.LogInfo("udp discovery socket closed: ", err)
+			return
+		}
+		n.onUdpHello(buf[:length], remoteAddr)
+	}
+}
+
+//
+// @Description: 处理一个 UDP Hello，复用到邻居的 UDP LogicFace，不存在时创建一个并记录为本模块创建的 LogicFace
+// @receiver n
+// @param buf
+// @param remoteAddr	Hello 的源地址
+//
+func (n *NeighborDiscovery) onUdpHello(buf []byte, remoteAddr *net.UDPAddr) {
+	hello, err := n.decodeHello(buf)
+	if err != nil {
+		common2.LogDebug("drop discovery hello from ", remoteAddr.String(), ": ", err)
+		return
+	}
+	// 组播会把自己发出的 Hello 回环回来
+	if hello.Router == n.routerName || hello.UdpPort <= 0 {
+		return
+	}
+	remoteUri := net.JoinHostPort(remoteAddr.IP.String(), strconv.Itoa(hello.UdpPort))
+	logicFace := lf.GetUdpLogicFace(remoteUri)
+	if logicFace == nil {
+		if logicFace, err = lf.CreateUdpLogicFace(remoteUri); err != nil || logicFace == nil {
+			common2.LogWarn("create udp logic face to neighbor ", hello.Router, " ", remoteUri, " failed: ", err)
+			return
+		}
+		if logicFace.Persistence < 1 {
+			logicFace.SetPersistence(1)
+		}
+		n.faceLock.Lock()
+		n.createdFaces[logicFace.LogicFaceId] = struct{}{}
+		n.faceLock.Unlock()
+	}
+	n.onNeighborHello(hello.Router, NeighborViaUdp, logicFace)
+}
+
+//
+// @Description: 反序列化一个 Hello 并验证签名
+// @receiver n
+// @param buf
+// @return *DiscoveryHello
+// @return error
+//
+func (n *NeighborDiscovery) decodeHello(buf []byte) (*DiscoveryHello, error) {
+	message, err := decodeMessage(n.signer, buf)
+	if err != nil {
+		return nil, err
+	}
+	if message.Type != MessageTypeDiscovery {
+		return nil, RoutingError{msg: "unexpected message type " + message.Type}
+	}
+	hello := new(DiscoveryHello)
+	if err := json.Unmarshal(message.Body, hello); err != nil {
+		return nil, err
+	}
+	if hello.Router != message.Signer {
+		return nil, RoutingError{msg: "hello of " + hello.Router + " is signed by " + message.Signer}
+	}
+	return hello, nil
+}
+
+//
+// @Description: 收到邻居的 Hello 之后刷新邻居表
+// @receiver n
+// @param router
+// @param via
+// @param logicFace
+//
+func (n *NeighborDiscovery) onNeighborHello(router string, via string, logicFace *lf.LogicFace) {
+	if n.neighborTable.Refresh(router, via, logicFace.GetRemoteUri(), logicFace.LogicFaceId, common.GetCurrentTime()) {
+		common2.LogInfo("discover neighbor ", router, " via ", via, " ", logicFace.GetRemoteUri(),
+			", logic face id = ", logicFace.LogicFaceId)
+	}
+}
+
+//
+// @Description: 到邻居的 LogicFace 被关闭或者移出 LogicFaceTable 之后（eg: 空闲清理、transport 出错）立即删除对应的邻居，
+//	否则邻居表中会留下失效的表项，重新创建的 LogicFace 还会产生重复的表项
+// @receiver n
+// @param event
+//
+func (n *NeighborDiscovery) onFaceEvent(event *lf.FaceEvent) {
+	if event.Kind != lf.FaceEventDown && event.Kind != lf.FaceEventDestroyed {
+		return
+	}
+	if event.Kind == lf.FaceEventDestroyed {
+		n.faceLock.Lock()
+		delete(n.createdFaces, event.LogicFaceId)
+		n.faceLock.Unlock()
+	}
+	if n.neighborTable.Remove(event.LogicFaceId) {
+		common2.LogInfo("logic face ", event.LogicFaceId, " to neighbor is ", event.Kind.String())
+	}
+}
+
+//
+// @Description: 删除已失效的邻居，并关闭本模块为它们创建的 LogicFace
+// @receiver n
+//
+func (n *NeighborDiscovery) expireNeighbors() {
+	for _, neighbor := range n.neighborTable.Expire(common.GetCurrentTime()) {
+		common2.LogInfo("neighbor ", neighbor.Router, " via ", neighbor.Via, " ", neighbor.Uri, " is dead")
+		n.faceLock.Lock()
+		_, created := n.createdFaces[neighbor.LogicFaceId]
+		delete(n.createdFaces, neighbor.LogicFaceId)
+		n.faceLock.Unlock()
+		if !created {
+			continue
+		}
+		if logicFace := n.logicFaceSystem.LogicFaceTable().GetLogicFacePtrById(neighbor.LogicFaceId); logicFace != nil {
+			logicFace.Shutdown()
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routing
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:35 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package routing
+
+import (
+	"mir-go/daemon/common"
+	"mir-go/daemon/lf"
+	"net"
+	"testing"
+	"time"
+)
+
+// 丢弃所有收到的包的 IPacketValidator
+type discardPacketValidator struct{}
+
+func (discardPacketValidator) ReceiveMINPacket(data *lf.IncomingPacketData) {}
+
+func (discardPacketValidator) ReportLinkLoss(report *lf.LinkLossReport) {}
+
+func newTestLogicFaceSystem() *lf.LogicFaceSystem {
+	config := &common.MIRConfig{}
+	config.Init()
+	faceSystem := new(lf.LogicFaceSystem)
+	faceSystem.Init(discardPacketValidator{}, config)
+	// 没有管理模块清理下一跳
+	faceSystem.LogicFaceTable().OnEvicted = func(logicFaceId uint64) {}
+	return faceSystem
+}
+
+// 创建一个只处理 UDP Hello 的邻居发现模块，不打开组播 socket
+func newTestNeighborDiscovery(routerName string, udpPort int, keys map[string][]byte, faceSystem *lf.LogicFaceSystem,
+	deadInterval uint64) *NeighborDiscovery {
+	n := &NeighborDiscovery{
+		routerName:      routerName,
+		signer:          &testSigner{self: routerName, keys: keys},
+		logicFaceSystem: faceSystem,
+		neighborTable:   CreateNeighborTable(deadInterval),
+		udpPort:         udpPort,
+		stopChan:        make(chan struct{}),
+		createdFaces:    make(map[uint64]struct{}),
+	}
+	faceSystem.LogicFaceTable().AddEventListener(n.onFaceEvent)
+	return n
+}
+
+func TestNeighborDiscovery_UdpHello(t *testing.T) {
+	faceSystem := newTestLogicFaceSystem()
+	keys := newTestKeys(3)
+	r1 := newTestNeighborDiscovery("/r1", 13901, keys, faceSystem, 50)
+	r2 := newTestNeighborDiscovery("/r2", 13902, keys, faceSystem, 50)
+	r3 := newTestNeighborDiscovery("/r3", 13903, keys, faceSystem, 50)
+	source := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 40000}
+
+	// r1 的 Hello 到达 r2，r2 创建到 r1 的 UDP LogicFace，重复的 Hello 复用同一个 LogicFace
+	hello, err := r1.encodeHello()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2.onUdpHello(hello, source)
+	r2.onUdpHello(hello, source)
+	neighbors := r2.neighborTable.List()
+	if len(neighbors) != 1 || neighbors[0].Router != "/r1" || neighbors[0].Via != NeighborViaUdp {
+		t.Fatalf("unexpected neighbors %+v", neighbors)
+	}
+	createdFace := faceSystem.LogicFaceTable().GetLogicFacePtrById(neighbors[0].LogicFaceId)
+	if createdFace == nil || createdFace != lf.GetUdpLogicFace("127.0.0.1:13901") || createdFace.Persistence != 1 {
+		t.Fatal("hello should create a persistent udp logic face to the neighbor")
+	}
+
+	// 签名不对的 Hello 被丢弃，不会创建 LogicFace
+	forged, err := forgeMessage(keys["/r3"], "/r1", MessageTypeDiscovery, &DiscoveryHello{Router: "/r1", UdpPort: 13904})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2.onUdpHello(forged, source)
+	if r2.neighborTable.Size() != 1 || lf.GetUdpLogicFace("127.0.0.1:13904") != nil {
+		t.Fatal("hello with bad signature should be dropped")
+	}
+
+	// 已经存在的 LogicFace（eg: 静态配置的）被复用，但是不属于邻居发现
+	staticFace, err := lf.CreateUdpLogicFace("127.0.0.1:13903")
+	if err != nil {
+		t.Fatal(err)
+	}
+	staticFace.SetPersistence(1)
+	defer staticFace.Shutdown()
+	if hello, err = r3.encodeHello(); err != nil {
+		t.Fatal(err)
+	}
+	r2.onUdpHello(hello, source)
+	if r2.neighborTable.Size() != 2 {
+		t.Fatalf("unexpected neighbors %+v", r2.neighborTable.List())
+	}
+
+	// 邻居失效之后只关闭邻居发现创建的 LogicFace
+	time.Sleep(100 * time.Millisecond)
+	r2.expireNeighbors()
+	if r2.neighborTable.Size() != 0 {
+		t.Fatalf("dead neighbors should be removed, got %+v", r2.neighborTable.List())
+	}
+	if createdFace.GetState() {
+		t.Error("logic face created by discovery should be shut down")
+	}
+	if !staticFace.GetState() || faceSystem.LogicFaceTable().GetLogicFacePtrById(staticFace.LogicFaceId) == nil {
+		t.Error("logic face not created by discovery should be kept")
+	}
+}
+
+// 到邻居的 LogicFace 被其它模块关闭之后立即删除邻居，不会留下失效的表项
+func TestNeighborDiscovery_FaceClosed(t *testing.T) {
+	faceSystem := newTestLogicFaceSystem()
+	keys := newTestKeys(2)
+	r1 := newTestNeighborDiscovery("/r1", 13911, keys, faceSystem, 60000)
+	r2 := newTestNeighborDiscovery("/r2", 13912, keys, faceSystem, 60000)
+	hello, err := r1.encodeHello()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r2.onUdpHello(hello, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 40000})
+	neighbors := r2.neighborTable.List()
+	if len(neighbors) != 1 {
+		t.Fatalf("unexpected neighbors %+v", neighbors)
+	}
+	faceSystem.LogicFaceTable().GetLogicFacePtrById(neighbors[0].LogicFaceId).Shutdown()
+	if r2.neighborTable.Size() != 0 {
+		t.Fatalf("neighbor should be removed with its logic face, got %+v", r2.neighborTable.List())
+	}
+	faceSystem.LogicFaceTable().RemoveByLogicFaceId(neighbors[0].LogicFaceId)
+	if len(r2.createdFaces) != 0 {
+		t.Fatal("destroyed logic face should be forgotten")
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routing
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:30 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package routing
+
+import (
+	"sort"
+	"sync"
+)
+
+// 邻居是通过哪种组播发现的
+const (
+	NeighborViaEther = "ether"
+	NeighborViaUdp   = "udp"
+)
+
+// Neighbor 邻居表中的一个邻居，每个到邻居的单播 LogicFace 对应一个表项
+//
+// @Description:
+//
+type Neighbor struct {
+	Router      string // 邻居在 Hello 中宣告的路由器名
+	Via         string // 发现方式 ether | udp
+	Uri         string // 到邻居的单播 LogicFace 的对端地址
+	LogicFaceId uint64 // 到邻居的单播 LogicFace
+	FirstSeen   uint64 // 第一次收到 Hello 的时间（ms）
+	LastSeen    uint64 // 最后一次收到 Hello 的时间（ms）
+	ExpireTime  uint64 // 邻居失效的时间（ms）
+}
+
+// NeighborTable 邻居表，记录通过邻居发现找到的邻居以及它们的存活状态
+//
+// @Description:
+//
+type NeighborTable struct {
+	lock         sync.RWMutex
+	neighbors    map[uint64]*Neighbor // LogicFaceId => 邻居
+	deadInterval uint64               // 超过这么长时间（ms）没有收到 Hello 则认为邻居失效
+}
+
+// CreateNeighborTable 创建一个邻居表
+//
+// @Description:
+// @param deadInterval	邻居失效时间（ms）
+// @return *NeighborTable
+//
+func CreateNeighborTable(deadInterval uint64) *NeighborTable {
+	return &NeighborTable{
+		neighbors:    make(map[uint64]*Neighbor),
+		deadInterval: deadInterval,
+	}
+}
+
+// Refresh 收到邻居的 Hello 之后，添加或者刷新邻居表项
+//
+// @Description:
+// @receiver n
+// @param router
+// @param via
+// @param uri
+// @param logicFaceId
+// @param now	当前时间（ms）
+// @return bool	是否是新发现的邻居
+//
+func (n *NeighborTable) Refresh(router string, via string, uri string, logicFaceId uint64, now uint64) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	neighbor, ok := n.neighbors[logicFaceId]
+	if !ok || neighbor.Router != router {
+		// 新邻居，或者同一个 LogicFace 对端的路由器变了
+		neighbor = &Neighbor{Router: router, Via: via, Uri: uri, LogicFaceId: logicFaceId, FirstSeen: now}
+		n.neighbors[logicFaceId] = neighbor
+		ok = false
+	}
+	neighbor.LastSeen = now
+	neighbor.ExpireTime = now + n.deadInterval
+	return !ok
+}
+
+// Remove 删除一个 LogicFace 对应的邻居
+//
+// @Description:
+// @receiver n
+// @param logicFaceId
+// @return bool	邻居是否存在
+//
+func (n *NeighborTable) Remove(logicFaceId uint64) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	_, ok := n.neighbors[logicFaceId]
+	delete(n.neighbors, logicFaceId)
+	return ok
+}
+
+// Expire 删除并返回所有已失效的邻居
+//
+// @Description:
+// @receiver n
+// @param now	当前时间（ms）
+// @return []*Neighbor
+//
+func (n *NeighborTable) Expire(now uint64) []*Neighbor {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	var expired []*Neighbor
+	for logicFaceId, neighbor := range n.neighbors {
+		if neighbor.ExpireTime <= now {
+			expired = append(expired, neighbor)
+			delete(n.neighbors, logicFaceId)
+		}
+	}
+	return expired
+}
+
+// List 返回所有邻居的拷贝，按路由器名和 LogicFaceId 排序
+//
+// @Description:
+// @receiver n
+// @return []Neighbor
+//
+func (n *NeighborTable) List() []Neighbor {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	neighbors := make([]Neighbor, 0, len(n.neighbors))
+	for _, neighbor := range n.neighbors {
+		neighbors = append(neighbors, *neighbor)
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Router != neighbors[j].Router {
+			return neighbors[i].Router < neighbors[j].Router
+		}
+		return neighbors[i].LogicFaceId < neighbors[j].LogicFaceId
+	})
+	return neighbors
+}
+
+// Size 返回邻居个数
+//
+// @Description:
+// @receiver n
+// @return int
+//
+func (n *NeighborTable) Size() int {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	return len(n.neighbors)
+}
@@ -0,0 +1,70 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routing
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:30 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package routing
+
+import "testing"
+
+func TestNeighborTable_RefreshAndExpire(t *testing.T) {
+	n := CreateNeighborTable(1000)
+	if !n.Refresh("/r2", NeighborViaEther, "aa:bb:cc:dd:ee:ff", 7, 100) {
+		t.Fatal("first hello should discover a new neighbor")
+	}
+	if n.Refresh("/r2", NeighborViaEther, "aa:bb:cc:dd:ee:ff", 7, 600) {
+		t.Fatal("second hello should only refresh the neighbor")
+	}
+	if !n.Refresh("/r3", NeighborViaUdp, "192.168.1.3:13899", 8, 600) {
+		t.Fatal("hello from another logic face should discover a new neighbor")
+	}
+
+	// /r2 刷新之后在 1600 失效，/r3 也在 1600 失效
+	if expired := n.Expire(1500); len(expired) != 0 {
+		t.Fatalf("no neighbor should expire at 1500, got %d", len(expired))
+	}
+	neighbors := n.List()
+	if len(neighbors) != 2 || neighbors[0].Router != "/r2" || neighbors[0].FirstSeen != 100 || neighbors[0].LastSeen != 600 {
+		t.Fatalf("unexpected neighbors %+v", neighbors)
+	}
+
+	n.Refresh("/r3", NeighborViaUdp, "192.168.1.3:13899", 8, 1500)
+	expired := n.Expire(1600)
+	if len(expired) != 1 || expired[0].LogicFaceId != 7 {
+		t.Fatalf("only /r2 should expire at 1600, got %+v", expired)
+	}
+	if n.Size() != 1 {
+		t.Fatalf("expect 1 neighbor left, got %d", n.Size())
+	}
+}
+
+func TestNeighborTable_RouterChanged(t *testing.T) {
+	n := CreateNeighborTable(1000)
+	n.Refresh("/r2", NeighborViaEther, "aa:bb:cc:dd:ee:ff", 7, 100)
+	// 同一个 LogicFace 对端换成了另一个路由器，作为新邻居处理
+	if !n.Refresh("/r4", NeighborViaEther, "aa:bb:cc:dd:ee:ff", 7, 200) {
+		t.Fatal("router change on the same logic face should be reported as a new neighbor")
+	}
+	if neighbors := n.List(); len(neighbors) != 1 || neighbors[0].Router != "/r4" || neighbors[0].FirstSeen != 200 {
+		t.Fatalf("unexpected neighbors %+v", neighbors)
+	}
+	if !n.Remove(7) || n.Remove(7) {
+		t.Fatal("remove should report whether the neighbor existed")
+	}
+}
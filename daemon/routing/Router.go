@@ -0,0 +1,585 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routing
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package routing
+
+import (
+	"encoding/json"
+	common2 "minlib/common"
+	"mir-go/daemon/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RouterConfig 链路状态路由器的配置
+//
+// @Description:
+//
+type RouterConfig struct {
+	RouterName         string        // 路由器名
+	Prefixes           []string      // 本路由器通告的前缀
+	HelloInterval      time.Duration // 发送 Hello 的时间间隔
+	DeadInterval       time.Duration // 超过这么长时间没有收到邻居的 Hello 则认为邻居失效
+	LSARefreshInterval time.Duration // 重新生成本路由器 LSA 的时间间隔
+	LSALifetime        time.Duration // LSA 的生存时间
+	Multipath          bool          // 是否为每个前缀计算多条路径
+	MaxPaths           int           // 开启多路径时每个前缀最多安装的下一跳个数
+}
+
+// ILinkSender 路由协议报文的发送接口
+//
+// @Description:
+//
+type ILinkSender interface {
+	// Send
+	// 把一个路由协议报文从指定链路发送出去，发送需要是非阻塞的
+	//
+	// @Description:
+	// @param linkId
+	// @param buf
+	//
+	Send(linkId uint64, buf []byte)
+}
+
+// IRouteInstaller 路由安装接口，Router 计算出路由之后通过本接口写入 RIB 或者 FIB
+//
+// @Description:
+//
+type IRouteInstaller interface {
+	// AddRoute
+	// 添加或者更新一条路由
+	//
+	// @Description:
+	// @param prefix
+	// @param linkId
+	// @param cost
+	// @return error
+	//
+	AddRoute(prefix string, linkId uint64, cost uint64) error
+
+	// RemoveRoute
+	// 删除一条路由
+	//
+	// @Description:
+	// @param prefix
+	// @param linkId
+	// @return error
+	//
+	RemoveRoute(prefix string, linkId uint64) error
+}
+
+// link 运行路由协议的一条本地链路
+type link struct {
+	id        uint64    // 链路号，对应 LogicFaceId
+	cost      uint64    // 链路开销
+	neighbor  string    // 链路对端的邻居路由器名，还没有收到 Hello 时为空
+	twoWay    bool      // 对端的 Hello 中是否包含本路由器，双向可达的链路才用于路由计算
+	lastHello time.Time // 最后一次收到 Hello 的时间
+}
+
+// outgoingMessage 等待发送的路由协议报文
+type outgoingMessage struct {
+	linkId uint64
+	buf    []byte
+}
+
+// NeighborStatus 邻居状态
+//
+// @Description:
+//
+type NeighborStatus struct {
+	LinkId   uint64 // 链路号
+	Neighbor string // 邻居路由器名
+	Cost     uint64 // 链路开销
+	TwoWay   bool   // 是否双向可达
+}
+
+// Router 链路状态路由器
+//
+// @Description:
+//	1. 周期性地在每条链路上发送 Hello，维护邻居状态；
+//	2. 邻居状态变化时重新生成本路由器的 LSA，并泛洪到所有邻居；
+//	3. 收到更新的 LSA 时安装到 LSDB 并继续泛洪，LSDB 变化之后重新计算路由，把变化的部分通过 IRouteInstaller 写入路由表。
+//
+//	发送报文在释放锁之后进行，安装路由在持有锁时进行，所以 IRouteInstaller 不能回调 Router
+//
+type Router struct {
+	lock      sync.Mutex
+	config    RouterConfig
+	signer    ISigner
+	sender    ILinkSender
+	installer IRouteInstaller
+
+	links         map[uint64]*link
+	lsdb          *LSDB
+	seqNo         uint64                       // 本路由器 LSA 的序列号
+	lastOriginate time.Time                    // 最后一次生成本路由器 LSA 的时间
+	installed     map[string]map[uint64]uint64 // 已经安装的路由，前缀 => 链路号 => 开销
+	outgoing      []outgoingMessage            // 持有锁期间产生的待发送报文
+	stopChan      chan struct{}
+}
+
+// CreateRouter 创建一个链路状态路由器
+//
+// @Description:
+// @param config
+// @param signer
+// @param sender
+// @param installer
+// @return *Router
+//
+func CreateRouter(config RouterConfig, signer ISigner, sender ILinkSender, installer IRouteInstaller) *Router {
+	return &Router{
+		config:    config,
+		signer:    signer,
+		sender:    sender,
+		installer: installer,
+		links:     make(map[uint64]*link),
+		lsdb:      CreateLSDB(),
+		// 使用当前时间初始化序列号，保证路由器重启之后生成的 LSA 比重启之前的新
+		seqNo:     uint64(time.Now().UnixNano() / int64(time.Microsecond)),
+		installed: make(map[string]map[uint64]uint64),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start 启动路由器，生成本路由器的 LSA，并启动周期性发送 Hello 的协程
+//
+// @Description:
+// @receiver r
+//
+func (r *Router) Start() {
+	r.lock.Lock()
+	r.originateLSA(time.Now())
+	r.lock.Unlock()
+	r.flush()
+
+	utils.GoroutineNoPanic(func() {
+		ticker := time.NewTicker(r.config.HelloInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stopChan:
+				return
+			case now := <-ticker.C:
+				r.onTick(now)
+			}
+		}
+	})
+}
+
+// Stop 停止路由器
+//
+// @Description:
+// @receiver r
+//
+func (r *Router) Stop() {
+	close(r.stopChan)
+}
+
+// AddLink 添加一条运行路由协议的链路，链路已存在时更新开销
+//
+// @Description:
+// @receiver r
+// @param linkId
+// @param cost
+//
+func (r *Router) AddLink(linkId uint64, cost uint64) {
+	r.lock.Lock()
+	if l, ok := r.links[linkId]; ok {
+		if l.cost != cost {
+			l.cost = cost
+			if l.twoWay {
+				now := time.Now()
+				r.originateLSA(now)
+				r.recompute()
+			}
+		}
+	} else {
+		r.links[linkId] = &link{id: linkId, cost: cost}
+		// 立刻发送一个 Hello，加快邻居发现
+		r.sendHello(r.links[linkId])
+	}
+	r.lock.Unlock()
+	r.flush()
+}
+
+// RemoveLink 删除一条链路，通常在 LogicFace 被销毁时调用
+//
+// @Description:
+// @receiver r
+// @param linkId
+//
+func (r *Router) RemoveLink(linkId uint64) {
+	r.lock.Lock()
+	if l, ok := r.links[linkId]; ok {
+		delete(r.links, linkId)
+		if l.twoWay {
+			r.originateLSA(time.Now())
+		}
+		r.recompute()
+	}
+	r.lock.Unlock()
+	r.flush()
+}
+
+// HasLink 判断链路是否在运行路由协议
+//
+// @Description:
+// @receiver r
+// @param linkId
+// @return bool
+//
+func (r *Router) HasLink(linkId uint64) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	_, ok := r.links[linkId]
+	return ok
+}
+
+// LinkIds 返回所有运行路由协议的链路号
+//
+// @Description:
+// @receiver r
+// @return []uint64
+//
+func (r *Router) LinkIds() []uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	linkIds := make([]uint64, 0, len(r.links))
+	for linkId := range r.links {
+		linkIds = append(linkIds, linkId)
+	}
+	return linkIds
+}
+
+// GetNeighbors 返回所有链路上的邻居状态，按链路号排序
+//
+// @Description:
+// @receiver r
+// @return []NeighborStatus
+//
+func (r *Router) GetNeighbors() []NeighborStatus {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	neighbors := make([]NeighborStatus, 0, len(r.links))
+	for _, l := range r.links {
+		neighbors = append(neighbors, NeighborStatus{LinkId: l.id, Neighbor: l.neighbor, Cost: l.cost, TwoWay: l.twoWay})
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		return neighbors[i].LinkId < neighbors[j].LinkId
+	})
+	return neighbors
+}
+
+// OnMessage 处理从某条链路收到的路由协议报文
+//
+// @Description:
+// @receiver r
+// @param linkId
+// @param buf
+//
+func (r *Router) OnMessage(linkId uint64, buf []byte) {
+	message, err := decodeMessage(r.signer, buf)
+	if err != nil {
+		common2.LogWarn("drop routing message from link ", linkId, ": ", err)
+		return
+	}
+
+	r.lock.Lock()
+	l, ok := r.links[linkId]
+	if ok {
+		switch message.Type {
+		case MessageTypeHello:
+			var hello Hello
+			if err := json.Unmarshal(message.Body, &hello); err != nil || hello.Router != message.Signer {
+				common2.LogWarn("drop invalid hello from link ", linkId)
+				break
+			}
+			r.onHello(l, &hello)
+		case MessageTypeLSA:
+			lsa := new(LSA)
+			if err := json.Unmarshal(message.Body, lsa); err != nil || lsa.Origin != message.Signer {
+				common2.LogWarn("drop invalid LSA from link ", linkId)
+				break
+			}
+			r.onLSA(l, lsa, buf)
+		default:
+			common2.LogWarn("drop routing message with unknown type ", message.Type)
+		}
+	}
+	r.lock.Unlock()
+	r.flush()
+}
+
+//
+// @Description: 处理收到的 Hello，更新邻居状态，需要持有锁
+// @receiver r
+// @param l
+// @param hello
+//
+func (r *Router) onHello(l *link, hello *Hello) {
+	now := time.Now()
+	wasTwoWay := l.twoWay
+	// 链路对端的路由器变了，马上回复一个 Hello，让对端尽快确认双向可达
+	replyHello := l.neighbor != hello.Router
+	l.neighbor = hello.Router
+	l.lastHello = now
+	l.twoWay = false
+	for _, neighbor := range hello.Neighbors {
+		if neighbor == r.config.RouterName {
+			l.twoWay = true
+			break
+		}
+	}
+	if replyHello {
+		r.sendHello(l)
+	}
+
+	if l.twoWay != wasTwoWay {
+		common2.LogInfo("routing adjacency with ", l.neighbor, " on link ", l.id, " two-way = ", l.twoWay)
+		if l.twoWay {
+			// 邻接关系建立，和邻居同步 LSDB
+			for _, raw := range r.lsdb.RawLSAs() {
+				r.outgoing = append(r.outgoing, outgoingMessage{linkId: l.id, buf: raw})
+			}
+		}
+		r.originateLSA(now)
+		r.recompute()
+	}
+}
+
+//
+// @Description: 处理收到的 LSA，需要持有锁
+// @receiver r
+// @param l
+// @param lsa
+// @param raw
+//
+func (r *Router) onLSA(l *link, lsa *LSA, raw []byte) {
+	now := time.Now()
+	if lsa.Origin == r.config.RouterName {
+		// 收到了自己在重启之前生成的 LSA，使用更大的序列号重新生成
+		if lsa.SeqNo >= r.seqNo {
+			r.seqNo = lsa.SeqNo
+			r.originateLSA(now)
+		}
+		return
+	}
+	switch r.lsdb.Install(lsa, raw, now) {
+	case 1:
+		// 更新的 LSA，泛洪到其它邻居，然后重新计算路由
+		r.flood(raw, l.id)
+		r.recompute()
+	case -1:
+		// 对端的 LSA 比较旧，把自己的版本发回去
+		if _, newer := r.lsdb.Get(lsa.Origin); newer != nil {
+			r.outgoing = append(r.outgoing, outgoingMessage{linkId: l.id, buf: newer})
+		}
+	}
+}
+
+//
+// @Description: 周期性的处理，发送 Hello、检测邻居失效、删除过期的 LSA 和刷新本路由器的 LSA
+// @receiver r
+// @param now
+//
+func (r *Router) onTick(now time.Time) {
+	r.lock.Lock()
+	changed := false
+	for _, l := range r.links {
+		if l.neighbor != "" && now.Sub(l.lastHello) > r.config.DeadInterval {
+			common2.LogInfo("routing neighbor ", l.neighbor, " on link ", l.id, " is dead")
+			if l.twoWay {
+				changed = true
+			}
+			l.neighbor = ""
+			l.twoWay = false
+		}
+		r.sendHello(l)
+	}
+	if changed || now.Sub(r.lastOriginate) >= r.config.LSARefreshInterval {
+		r.originateLSA(now)
+		changed = true
+	}
+	if r.lsdb.Expire(now) {
+		changed = true
+	}
+	if changed {
+		r.recompute()
+	}
+	r.lock.Unlock()
+	r.flush()
+}
+
+//
+// @Description: 在一条链路上发送 Hello，需要持有锁
+// @receiver r
+// @param l
+//
+func (r *Router) sendHello(l *link) {
+	hello := &Hello{Router: r.config.RouterName, Neighbors: []string{}}
+	if l.neighbor != "" {
+		hello.Neighbors = append(hello.Neighbors, l.neighbor)
+	}
+	buf, err := encodeMessage(r.signer, r.config.RouterName, MessageTypeHello, hello)
+	if err != nil {
+		common2.LogError("encode hello failed: ", err)
+		return
+	}
+	r.outgoing = append(r.outgoing, outgoingMessage{linkId: l.id, buf: buf})
+}
+
+//
+// @Description: 生成本路由器新的 LSA，安装到 LSDB 并泛洪到所有邻居，需要持有锁
+// @receiver r
+// @param now
+//
+func (r *Router) originateLSA(now time.Time) {
+	// 同一个邻居有多条链路时取最小开销
+	costs := make(map[string]uint64)
+	for _, l := range r.links {
+		if !l.twoWay {
+			continue
+		}
+		if cost, ok := costs[l.neighbor]; !ok || l.cost < cost {
+			costs[l.neighbor] = l.cost
+		}
+	}
+	adjacencies := make([]Adjacency, 0, len(costs))
+	for neighbor, cost := range costs {
+		adjacencies = append(adjacencies, Adjacency{Neighbor: neighbor, Cost: cost})
+	}
+	sort.Slice(adjacencies, func(i, j int) bool {
+		return adjacencies[i].Neighbor < adjacencies[j].Neighbor
+	})
+	prefixes := append([]string{}, r.config.Prefixes...)
+	sort.Strings(prefixes)
+
+	r.seqNo++
+	lsa := &LSA{
+		Origin:      r.config.RouterName,
+		SeqNo:       r.seqNo,
+		Lifetime:    int64(r.config.LSALifetime / time.Millisecond),
+		Adjacencies: adjacencies,
+		Prefixes:    prefixes,
+	}
+	raw, err := encodeMessage(r.signer, r.config.RouterName, MessageTypeLSA, lsa)
+	if err != nil {
+		common2.LogError("encode LSA failed: ", err)
+		return
+	}
+	r.lastOriginate = now
+	r.lsdb.Install(lsa, raw, now)
+	r.flood(raw, 0)
+}
+
+//
+// @Description: 把一个 LSA 泛洪到除了 exceptLinkId 之外的所有双向可达的链路，需要持有锁
+// @receiver r
+// @param raw
+// @param exceptLinkId
+//
+func (r *Router) flood(raw []byte, exceptLinkId uint64) {
+	for _, l := range r.links {
+		if l.twoWay && l.id != exceptLinkId {
+			r.outgoing = append(r.outgoing, outgoingMessage{linkId: l.id, buf: raw})
+		}
+	}
+}
+
+//
+// @Description: 重新计算路由，并把和已安装的路由之间的差异写入路由表，需要持有锁
+// @receiver r
+//
+func (r *Router) recompute() {
+	links := make([]localLink, 0, len(r.links))
+	for _, l := range r.links {
+		if l.twoWay {
+			links = append(links, localLink{linkId: l.id, neighbor: l.neighbor, cost: l.cost})
+		}
+	}
+	routes := r.lsdb.computeRoutes(r.config.RouterName, links, r.config.Multipath, r.config.MaxPaths)
+
+	// 先删除不再需要的路由
+	for prefix, installedNextHops := range r.installed {
+		for linkId := range installedNextHops {
+			if !containsLink(routes[prefix], linkId) {
+				if err := r.installer.RemoveRoute(prefix, linkId); err != nil {
+					common2.LogWarn("remove route ", prefix, " => ", linkId, " failed: ", err)
+				}
+				delete(installedNextHops, linkId)
+			}
+		}
+		if len(installedNextHops) == 0 {
+			delete(r.installed, prefix)
+		}
+	}
+	// 再添加新的路由或者更新开销变化的路由
+	for prefix, nextHops := range routes {
+		if r.installed[prefix] == nil {
+			r.installed[prefix] = make(map[uint64]uint64)
+		}
+		for _, nextHop := range nextHops {
+			if cost, ok := r.installed[prefix][nextHop.LinkId]; ok && cost == nextHop.Cost {
+				continue
+			}
+			if err := r.installer.AddRoute(prefix, nextHop.LinkId, nextHop.Cost); err != nil {
+				common2.LogWarn("add route ", prefix, " => ", nextHop.LinkId, " failed: ", err)
+				continue
+			}
+			r.installed[prefix][nextHop.LinkId] = nextHop.Cost
+		}
+		if len(r.installed[prefix]) == 0 {
+			delete(r.installed, prefix)
+		}
+	}
+}
+
+//
+// @Description: 在释放锁之后发送所有待发送的报文
+// @receiver r
+//
+func (r *Router) flush() {
+	r.lock.Lock()
+	outgoing := r.outgoing
+	r.outgoing = nil
+	r.lock.Unlock()
+	for _, message := range outgoing {
+		r.sender.Send(message.linkId, message.buf)
+	}
+}
+
+//
+// @Description: 判断下一跳列表中是否包含指定链路
+// @param nextHops
+// @param linkId
+// @return bool
+//
+func containsLink(nextHops []NextHop, linkId uint64) bool {
+	for _, nextHop := range nextHops {
+		if nextHop.LinkId == linkId {
+			return true
+		}
+	}
+	return false
+}
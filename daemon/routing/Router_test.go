@@ -0,0 +1,317 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routing
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package routing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testSigner 模拟 KeyChainSigner：每个路由器只能使用自己的秘钥签名，验证时使用签名者的秘钥（相当于签名者的证书）
+type testSigner struct {
+	self string
+	keys map[string][]byte
+}
+
+func (s *testSigner) Sign(signer string, content []byte) ([]byte, error) {
+	if signer != s.self {
+		return nil, RoutingError{msg: s.self + " can not sign for " + signer}
+	}
+	mac := hmac.New(sha256.New, s.keys[signer])
+	mac.Write(content)
+	return mac.Sum(nil), nil
+}
+
+func (s *testSigner) Verify(signer string, content []byte, signature []byte) error {
+	key, ok := s.keys[signer]
+	if !ok {
+		return RoutingError{msg: "unknown signer " + signer}
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return RoutingError{msg: "invalid signature of " + signer}
+	}
+	return nil
+}
+
+func newTestKeys(count int) map[string][]byte {
+	keys := make(map[string][]byte)
+	for i := 1; i <= count; i++ {
+		keys[fmt.Sprintf("/r%d", i)] = []byte(fmt.Sprintf("key-of-r%d", i))
+	}
+	return keys
+}
+
+// memoryLink 连接两个路由器的内存链路
+type memoryLink struct {
+	down int32 // 非 0 表示链路断开，两个方向的报文都会被丢弃
+}
+
+// memoryPort 内存链路的一端
+type memoryPort struct {
+	link       *memoryLink
+	peer       *memoryNode
+	peerLinkId uint64
+}
+
+// inboundMessage 路由器收到的一个报文
+type inboundMessage struct {
+	linkId uint64
+	buf    []byte
+}
+
+// memoryNode 内存网络中的一个路由器，同时实现 ILinkSender 和 IRouteInstaller
+type memoryNode struct {
+	router *Router
+	ports  map[uint64]*memoryPort
+	inbox  chan inboundMessage
+	lock   sync.Mutex
+	routes map[string]map[uint64]uint64
+}
+
+func (n *memoryNode) Send(linkId uint64, buf []byte) {
+	port, ok := n.ports[linkId]
+	if !ok || atomic.LoadInt32(&port.link.down) != 0 {
+		return
+	}
+	select {
+	case port.peer.inbox <- inboundMessage{linkId: port.peerLinkId, buf: buf}:
+	default:
+	}
+}
+
+func (n *memoryNode) AddRoute(prefix string, linkId uint64, cost uint64) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.routes[prefix] == nil {
+		n.routes[prefix] = make(map[uint64]uint64)
+	}
+	n.routes[prefix][linkId] = cost
+	return nil
+}
+
+func (n *memoryNode) RemoveRoute(prefix string, linkId uint64) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if _, ok := n.routes[prefix][linkId]; !ok {
+		return fmt.Errorf("route %s => %d not found", prefix, linkId)
+	}
+	delete(n.routes[prefix], linkId)
+	if len(n.routes[prefix]) == 0 {
+		delete(n.routes, prefix)
+	}
+	return nil
+}
+
+// getRoutes 返回前缀当前的路由，链路号 => 开销
+func (n *memoryNode) getRoutes(prefix string) map[uint64]uint64 {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	routes := make(map[uint64]uint64)
+	for linkId, cost := range n.routes[prefix] {
+		routes[linkId] = cost
+	}
+	return routes
+}
+
+// memoryNetwork 由若干个路由器和内存链路组成的网络
+type memoryNetwork struct {
+	nodes []*memoryNode
+	stop  chan struct{}
+}
+
+func newMemoryNetwork(count int, multipath bool) *memoryNetwork {
+	network := &memoryNetwork{stop: make(chan struct{})}
+	keys := newTestKeys(count)
+	for i := 1; i <= count; i++ {
+		node := &memoryNode{
+			ports:  make(map[uint64]*memoryPort),
+			inbox:  make(chan inboundMessage, 4096),
+			routes: make(map[string]map[uint64]uint64),
+		}
+		node.router = CreateRouter(RouterConfig{
+			RouterName:         fmt.Sprintf("/r%d", i),
+			Prefixes:           []string{fmt.Sprintf("/r%d/prefix", i)},
+			HelloInterval:      20 * time.Millisecond,
+			DeadInterval:       100 * time.Millisecond,
+			LSARefreshInterval: 10 * time.Second,
+			LSALifetime:        30 * time.Second,
+			Multipath:          multipath,
+			MaxPaths:           2,
+		}, &testSigner{self: fmt.Sprintf("/r%d", i), keys: keys}, node, node)
+		network.nodes = append(network.nodes, node)
+	}
+	return network
+}
+
+// connect 用一条内存链路连接第 a 个和第 b 个路由器（从 1 开始），链路号为 a*10+b 和 b*10+a
+func (n *memoryNetwork) connect(a, b int, cost uint64) *memoryLink {
+	link := new(memoryLink)
+	nodeA, nodeB := n.nodes[a-1], n.nodes[b-1]
+	linkIdA, linkIdB := uint64(a*10+b), uint64(b*10+a)
+	nodeA.ports[linkIdA] = &memoryPort{link: link, peer: nodeB, peerLinkId: linkIdB}
+	nodeB.ports[linkIdB] = &memoryPort{link: link, peer: nodeA, peerLinkId: linkIdA}
+	nodeA.router.AddLink(linkIdA, cost)
+	nodeB.router.AddLink(linkIdB, cost)
+	return link
+}
+
+func (n *memoryNetwork) start() {
+	for _, node := range n.nodes {
+		node := node
+		go func() {
+			for {
+				select {
+				case <-n.stop:
+					return
+				case message := <-node.inbox:
+					node.router.OnMessage(message.linkId, message.buf)
+				}
+			}
+		}()
+		node.router.Start()
+	}
+}
+
+func (n *memoryNetwork) close() {
+	close(n.stop)
+	for _, node := range n.nodes {
+		node.router.Stop()
+	}
+}
+
+// waitRoutes 等待第 index 个路由器上前缀的路由收敛到 expected
+func waitRoutes(t *testing.T, network *memoryNetwork, index int, prefix string, expected map[uint64]uint64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	var routes map[uint64]uint64
+	for time.Now().Before(deadline) {
+		routes = network.nodes[index-1].getRoutes(prefix)
+		if fmt.Sprint(routes) == fmt.Sprint(expected) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("routes of %s on /r%d did not converge, expected %v, got %v", prefix, index, expected, routes)
+}
+
+// 拓扑为一个环：r1 - r2 - r3 - r4 - r1，所有链路开销为 10
+func TestRouter_ConvergeAfterLinkFailure(t *testing.T) {
+	network := newMemoryNetwork(4, false)
+	link12 := network.connect(1, 2, 10)
+	network.connect(2, 3, 10)
+	network.connect(3, 4, 10)
+	network.connect(4, 1, 10)
+	network.start()
+	defer network.close()
+
+	// r1 直连 r2，r2 直连 r1
+	waitRoutes(t, network, 1, "/r2/prefix", map[uint64]uint64{12: 10})
+	waitRoutes(t, network, 2, "/r1/prefix", map[uint64]uint64{21: 10})
+	// 不开启多路径时，等价路径中选择链路号小的
+	waitRoutes(t, network, 1, "/r3/prefix", map[uint64]uint64{12: 20})
+	waitRoutes(t, network, 1, "/r1/prefix", map[uint64]uint64{})
+
+	// r1 - r2 断开之后，通过邻居失效检测重新收敛到绕环的另一侧
+	atomic.StoreInt32(&link12.down, 1)
+	waitRoutes(t, network, 1, "/r2/prefix", map[uint64]uint64{14: 30})
+	waitRoutes(t, network, 2, "/r1/prefix", map[uint64]uint64{23: 30})
+	waitRoutes(t, network, 1, "/r3/prefix", map[uint64]uint64{14: 20})
+
+	// 链路恢复之后回到最短路径
+	atomic.StoreInt32(&link12.down, 0)
+	waitRoutes(t, network, 1, "/r2/prefix", map[uint64]uint64{12: 10})
+	waitRoutes(t, network, 2, "/r1/prefix", map[uint64]uint64{21: 10})
+}
+
+func TestRouter_Multipath(t *testing.T) {
+	network := newMemoryNetwork(4, true)
+	link12 := network.connect(1, 2, 10)
+	network.connect(2, 3, 10)
+	network.connect(3, 4, 10)
+	network.connect(4, 1, 10)
+	network.start()
+	defer network.close()
+
+	// 开启多路径时，同时安装经过两个邻居的路径
+	waitRoutes(t, network, 1, "/r3/prefix", map[uint64]uint64{12: 20, 14: 20})
+	waitRoutes(t, network, 1, "/r2/prefix", map[uint64]uint64{12: 10, 14: 30})
+
+	atomic.StoreInt32(&link12.down, 1)
+	waitRoutes(t, network, 1, "/r3/prefix", map[uint64]uint64{14: 20})
+	waitRoutes(t, network, 1, "/r2/prefix", map[uint64]uint64{14: 30})
+}
+
+func TestRouter_RejectBadSignature(t *testing.T) {
+	keys := newTestKeys(3)
+	installed := &memoryNode{ports: make(map[uint64]*memoryPort), routes: make(map[string]map[uint64]uint64)}
+	router := CreateRouter(RouterConfig{RouterName: "/r1"}, &testSigner{self: "/r1", keys: keys}, installed, installed)
+	router.AddLink(1, 10)
+
+	// 其它路由器不能以 /r2 的身份签名
+	hello := &Hello{Router: "/r2", Neighbors: []string{"/r1"}}
+	if _, err := encodeMessage(&testSigner{self: "/r3", keys: keys}, "/r2", MessageTypeHello, hello); err == nil {
+		t.Fatal("r3 should not be able to sign for r2")
+	}
+
+	// 使用 /r3 的秘钥伪造的 /r2 的 Hello 不会建立邻居关系
+	buf, err := forgeMessage(keys["/r3"], "/r2", MessageTypeHello, hello)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.OnMessage(1, buf)
+	if neighbors := router.GetNeighbors(); neighbors[0].Neighbor != "" {
+		t.Errorf("hello with bad signature should be dropped, got neighbor %s", neighbors[0].Neighbor)
+	}
+
+	// /r3 签名但是声称来自 /r2 的 Hello 同样被丢弃
+	buf, _ = encodeMessage(&testSigner{self: "/r3", keys: keys}, "/r3", MessageTypeHello, hello)
+	router.OnMessage(1, buf)
+	if neighbors := router.GetNeighbors(); neighbors[0].Neighbor != "" {
+		t.Errorf("hello of r2 signed by r3 should be dropped, got neighbor %s", neighbors[0].Neighbor)
+	}
+
+	// /r2 自己签名的 Hello 建立双向邻居关系
+	buf, _ = encodeMessage(&testSigner{self: "/r2", keys: keys}, "/r2", MessageTypeHello, hello)
+	router.OnMessage(1, buf)
+	if neighbors := router.GetNeighbors(); neighbors[0].Neighbor != "/r2" || !neighbors[0].TwoWay {
+		t.Errorf("unexpected neighbor state %+v", neighbors[0])
+	}
+}
+
+// 使用 key 计算签名，把报文伪造成由 signerName 签名
+func forgeMessage(key []byte, signerName string, messageType string, body interface{}) ([]byte, error) {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(signedContent(messageType, bodyBytes))
+	return json.Marshal(&Message{Type: messageType, Signer: signerName, Body: bodyBytes, Signature: mac.Sum(nil)})
+}
@@ -0,0 +1,205 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 10:12 上午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	"minlib/component"
+	"minlib/packet"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultCSPartitionName 默认分区的名字，所有没有匹配到任何分区前缀的数据包都缓存在默认分区当中
+const DefaultCSPartitionName = "default"
+
+// CSPartition CS 的一个分区
+//
+// @Description:
+//  每个分区绑定一个或多个前缀，拥有独立的容量和缓存替换策略，分区之间互不影响，
+//  这样某个繁忙前缀下的数据包只会踢出本分区中的缓存，而不会挤占其它分区的缓存空间
+//
+type CSPartition struct {
+	name            string                  // 分区名
+	prefixes        []*component.Identifier // 分区绑定的前缀
+	capacity        int                     // 分区容量，包为单位
+	replaceStrategy string                  // 缓存替换策略
+	policy          ICSPolicy               // 分区使用的缓存替换策略实例
+	hits            uint64                  // 命中缓存次数
+	misses          uint64                  // 没有命中缓存次数
+	swept           uint64                  // 被后台清理删除的表项数
+	sweepEntries    []*CSEntry              // 本轮清理还没有检查的表项
+	sweepLock       sync.Mutex              // 保护 sweepEntries
+}
+
+// CSPartitionStatus 分区的状态信息，用于在管理模块中展示
+//
+// @Description:
+//
+type CSPartitionStatus struct {
+	Name            string   // 分区名
+	Prefixes        []string // 分区绑定的前缀
+	Capacity        int      // 分区容量
+	Size            int      // 分区中已缓存的数据包数量
+	ReplaceStrategy string   // 缓存替换策略
+	Hits            uint64   // 命中缓存次数
+	Misses          uint64   // 没有命中缓存次数
+	Swept           uint64   // 被后台清理删除的表项数
+}
+
+// NewCSPartition 新建一个 CS 分区
+//
+// @Description:
+// @param name
+// @param prefixes
+// @param capacity
+// @param replaceStrategy
+// @return *CSPartition
+// @return error
+//
+func NewCSPartition(name string, prefixes []*component.Identifier, capacity int, replaceStrategy string) (*CSPartition, error) {
+	policy, err := NewUniversalCSPolicy(capacity, replaceStrategy)
+	if err != nil {
+		return nil, err
+	}
+	return &CSPartition{
+		name:            name,
+		prefixes:        prefixes,
+		capacity:        capacity,
+		replaceStrategy: replaceStrategy,
+		policy:          policy,
+	}, nil
+}
+
+// GetName 获取分区名
+//
+// @Description:
+// @receiver p
+// @return string
+//
+func (p *CSPartition) GetName() string {
+	return p.name
+}
+
+// Find 在本分区中查找与兴趣包匹配的缓存
+//
+// @Description:
+// @receiver p
+// @param interest
+// @return *CSEntry
+// @return error
+//
+func (p *CSPartition) Find(interest *packet.Interest) (*CSEntry, error) {
+	csEntry, err := p.policy.Find(interest)
+	if err != nil {
+		atomic.AddUint64(&p.misses, 1)
+		return nil, err
+	}
+	atomic.AddUint64(&p.hits, 1)
+	return csEntry, nil
+}
+
+// Insert 将数据包缓存到本分区当中
+//
+// @Description:
+// @receiver p
+// @param data
+// @return *CSEntry
+// @return error
+//
+func (p *CSPartition) Insert(data *packet.Data) (*CSEntry, error) {
+	return p.policy.Insert(data)
+}
+
+// Sweep 检查本分区中最多 batchSize 个表项，删除变旧之后超过最大保留时间的表项
+//
+// @Description:
+//  每一轮清理开始时先对分区中的所有表项做一次快照，之后每次调用只检查快照中的一批表项，
+//  快照中的表项都检查完之后再开始下一轮，这样每次调用的耗时都是有上限的，不会长时间占用缓存的锁
+// @receiver p
+// @param batchSize	最多检查的表项数
+// @param now	当前时间，单位为秒
+// @param getMaxAge	获取表项最大保留时间（单位为秒）的函数，返回值小于等于 0 表示该表项不需要清理
+// @return int	本次检查的表项数
+// @return int	本次删除的表项数
+//
+func (p *CSPartition) Sweep(batchSize int, now int64, getMaxAge func(identifier *component.Identifier) int64) (int, int) {
+	p.sweepLock.Lock()
+	defer p.sweepLock.Unlock()
+	if len(p.sweepEntries) == 0 {
+		p.sweepEntries = p.policy.Entries()
+	}
+	batch := p.sweepEntries
+	if len(batch) > batchSize {
+		batch = batch[:batchSize]
+	}
+	p.sweepEntries = p.sweepEntries[len(batch):]
+
+	expired := func(csEntry *CSEntry) bool {
+		maxAge := getMaxAge(csEntry.GetIdentifier())
+		return maxAge > 0 && now-csEntry.GetStaleTime() > maxAge
+	}
+	removed := 0
+	for _, csEntry := range batch {
+		if !expired(csEntry) {
+			continue
+		}
+		// 快照中的表项可能已经被同名的新数据包替换或者被刷新，删除时再检查一次缓存中的表项是否还是它并且仍然过期
+		if p.policy.Remove(csEntry, expired) {
+			removed++
+		}
+	}
+	atomic.AddUint64(&p.swept, uint64(removed))
+	return len(batch), removed
+}
+
+// Size 返回本分区已缓存的数据包的数量
+//
+// @Description:
+// @receiver p
+// @return int
+//
+func (p *CSPartition) Size() int {
+	return p.policy.Size()
+}
+
+// Status 获取本分区的状态信息
+//
+// @Description:
+// @receiver p
+// @return *CSPartitionStatus
+//
+func (p *CSPartition) Status() *CSPartitionStatus {
+	prefixes := make([]string, 0, len(p.prefixes))
+	for _, prefix := range p.prefixes {
+		prefixes = append(prefixes, prefix.ToUri())
+	}
+	return &CSPartitionStatus{
+		Name:            p.name,
+		Prefixes:        prefixes,
+		Capacity:        p.capacity,
+		Size:            p.Size(),
+		ReplaceStrategy: p.replaceStrategy,
+		Hits:            atomic.LoadUint64(&p.hits),
+		Misses:          atomic.LoadUint64(&p.misses),
+		Swept:           atomic.LoadUint64(&p.swept),
+	}
+}
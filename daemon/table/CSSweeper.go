@@ -0,0 +1,98 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 11:40 上午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	common2 "minlib/common"
+	"mir-go/daemon/utils"
+	"sync"
+	"time"
+)
+
+// CSSweeper CS 后台清理器
+//
+// @Description:
+//  变旧的缓存只有在缓存替换策略把它踢出去的时候才会被删除，在此之前会一直占用 CS 的容量。
+//  CSSweeper 在后台周期性地调用 ICS.Sweep，每次最多检查 batchSize 个表项，删除变旧之后超过最大保留时间的表项，
+//  删除的表项数会累加到各个分区的 Swept 计数当中
+//
+type CSSweeper struct {
+	cs        ICS           // 需要清理的 CS
+	interval  time.Duration // 清理的时间间隔
+	batchSize int           // 每一轮最多检查的表项数
+	stopChan  chan struct{} // 用来通知清理协程退出
+	stopOnce  sync.Once     // 保证 stopChan 只关闭一次
+}
+
+// NewCSSweeper 新建一个 CSSweeper
+//
+// @Description:
+// @param cs
+// @param interval
+// @param batchSize
+// @return *CSSweeper
+//
+func NewCSSweeper(cs ICS, interval time.Duration, batchSize int) *CSSweeper {
+	return &CSSweeper{
+		cs:        cs,
+		interval:  interval,
+		batchSize: batchSize,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start 启动后台清理协程，时间间隔或者批大小不大于 0 时不启动
+//
+// @Description:
+// @receiver c
+//
+func (c *CSSweeper) Start() {
+	if c.interval <= 0 || c.batchSize <= 0 {
+		common2.LogInfo("CS sweeper is disabled")
+		return
+	}
+	utils.GoroutineNoPanic(func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stopChan:
+				return
+			case <-ticker.C:
+				if removed := c.cs.Sweep(c.batchSize); removed > 0 {
+					common2.LogDebug("CS sweeper removed ", removed, " entries")
+				}
+			}
+		}
+	})
+}
+
+// Stop 停止后台清理协程
+//
+// @Description:
+// @receiver c
+//
+func (c *CSSweeper) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+	})
+}
@@ -23,21 +23,20 @@
 package table
 
 import (
-	"github.com/sirupsen/logrus"
-	"minlib/common"
+	"fmt"
 	"minlib/component"
 	"mir-go/daemon/lf"
 	"sync"
 )
 
 // FIB
-// 储存FIBEntry的前缀树
+// 储存FIBEntry的转发表，表项存储在名字树的节点中，名字树可以和PIT、StrategyTable等表共用
 //
 // @Description:
 //
 type FIB struct {
-	lpm      *LpmMatcher //最长前缀匹配器
-	version  uint64      //版本号
+	nameTree *NameTree //名字树
+	version  uint64    //版本号
 	rwLocker sync.RWMutex
 }
 
@@ -49,25 +48,37 @@ type FIB struct {
 //
 func CreateFIB() *FIB {
 	var f = new(FIB)
-	f.lpm = new(LpmMatcher) //初始化
-	f.lpm.Create()          //初始化锁
-	f.version = 0           // 初始化版本号为0
+	f.Init()
 	return f
 }
 
 // Init
-// 初始化创建好的FIB表
+// 初始化创建好的FIB表，使用一棵独立的名字树
 //
 // @Description:
 //
 func (f *FIB) Init() {
-	f.lpm = new(LpmMatcher) //初始化
-	f.lpm.Create()          //初始化锁
+	f.InitWithNameTree(CreateNameTree())
+}
+
+// InitWithNameTree
+// 使用指定的名字树初始化FIB表，用于和其它表共用同一棵名字树
+//
+// @Description:
+// @param nameTree
+//
+func (f *FIB) InitWithNameTree(nameTree *NameTree) {
+	f.nameTree = nameTree
 	f.version = 0
 }
 
+// hasFIBEntry 名字树节点过滤函数，只匹配挂有FIB表项的节点
+func hasFIBEntry(entry *NameTreeEntry) bool {
+	return entry.fibEntry != nil
+}
+
 // FindLongestPrefixMatch
-// 通过标识在前缀树中最长前缀匹配查找对应的FIBEntry 最长前缀匹配的意思是有尽量多个Component可以匹配到结果
+// 通过标识在名字树中最长前缀匹配查找对应的FIBEntry 最长前缀匹配的意思是有尽量多个Component可以匹配到结果
 //
 // @Description:
 // @param *component.Identifier	需要进行查找的标识
@@ -76,19 +87,15 @@ func (f *FIB) Init() {
 func (f *FIB) FindLongestPrefixMatch(identifier *component.Identifier) *FIBEntry {
 	f.rwLocker.RLock()
 	defer f.rwLocker.RUnlock()
-	var PrefixList []string
-	for _, v := range identifier.GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
-	}
-	if table, ok := f.lpm.FindLongestPrefixMatch(PrefixList); ok {
-		return table.(*FIBEntry)
+	if entry := f.nameTree.FindLongestPrefixMatch(NewHashedName(identifier), hasFIBEntry); entry != nil {
+		return entry.fibEntry
 	}
 	// 匹配失败返回空
 	return nil
 }
 
 // FindExactMatch
-// 通过标识在前缀树中准确匹配查找对应的FIBEntry
+// 通过标识在名字树中准确匹配查找对应的FIBEntry
 //
 // @Description:
 // @param *component.Identifier	需要进行查找的标识
@@ -97,19 +104,15 @@ func (f *FIB) FindLongestPrefixMatch(identifier *component.Identifier) *FIBEntry
 func (f *FIB) FindExactMatch(identifier *component.Identifier) *FIBEntry {
 	f.rwLocker.RLock()
 	defer f.rwLocker.RUnlock()
-	var PrefixList []string
-	for _, v := range identifier.GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
-	}
-	if table, ok := f.lpm.FindExactMatch(PrefixList); ok {
-		return table.(*FIBEntry)
+	if entry := f.nameTree.FindExactMatch(NewHashedName(identifier), hasFIBEntry); entry != nil {
+		return entry.fibEntry
 	}
 	// 匹配失败返回空
 	return nil
 }
 
 // AddOrUpdate
-// 通过标识在前缀树中添加或更新FIBEntry 包含NextHop信息
+// 通过标识在名字树中添加或更新FIBEntry 包含NextHop信息
 //
 // @Description:
 // @param *component.Identifier	需要进行查找的标识 logicFaceId  cost 用来创建NextHop的参数
@@ -118,28 +121,58 @@ func (f *FIB) FindExactMatch(identifier *component.Identifier) *FIBEntry {
 func (f *FIB) AddOrUpdate(identifier *component.Identifier, logicFace *lf.LogicFace, cost uint64) *FIBEntry {
 	f.rwLocker.Lock()
 	defer f.rwLocker.Unlock()
-	var PrefixList []string
-	for _, v := range identifier.GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
-	}
-	//两种模式 模式一 val == nil func not nil 用处理函数设置val 模式二 val not nil func == nil 直接设置val
-	val := f.lpm.AddOrUpdate(PrefixList, nil, func(val interface{}) interface{} {
-		// not ok 那么val == nil 存入标识
-		if _, ok := (val).(*FIBEntry); !ok {
-			fibEntry := CreateFIBEntry()
-			val = fibEntry
+	var fibEntry *FIBEntry
+	f.nameTree.FindOrInsert(NewHashedName(identifier), func(entry *NameTreeEntry) {
+		if entry.fibEntry == nil {
+			entry.fibEntry = CreateFIBEntry()
 		}
-		entry := (val).(*FIBEntry)
-		entry.SetIdentifier(identifier)
-		entry.NextHopList[logicFace.LogicFaceId] = &NextHop{LogicFace: logicFace, Cost: cost}
-		return entry
+		fibEntry = entry.fibEntry
+		fibEntry.SetIdentifier(identifier)
+		fibEntry.NextHopList[logicFace.LogicFaceId] = &NextHop{LogicFace: logicFace, Cost: cost}
 	})
 	f.version++
-	return val.(*FIBEntry)
+	return fibEntry
+}
+
+// ReplaceNextHops
+// 用 nextHops 替换标识对应的FIBEntry中的全部下一跳，nextHops 为空时删除FIBEntry，供 RIB 重新计算路由时使用
+//
+// @Description:
+// @param identifier
+// @param nextHops
+// @return error	标识对应的FIBEntry是只读的时候返回错误
+//
+func (f *FIB) ReplaceNextHops(identifier *component.Identifier, nextHops []*NextHop) error {
+	f.rwLocker.Lock()
+	defer f.rwLocker.Unlock()
+	hashedName := NewHashedName(identifier)
+	if entry := f.nameTree.FindExactMatch(hashedName, hasFIBEntry); entry != nil && !entry.fibEntry.IsChanged() {
+		return FIBError{msg: "FIB entry " + identifier.ToUri() + " is read only"}
+	}
+	f.version++
+	if len(nextHops) == 0 {
+		// 原本就没有对应的FIBEntry时忽略删除失败
+		_ = f.erase(identifier)
+		return nil
+	}
+	nextHopList := make(map[uint64]*NextHop, len(nextHops))
+	for _, nextHop := range nextHops {
+		nextHopList[nextHop.LogicFace.LogicFaceId] = nextHop
+	}
+	f.nameTree.FindOrInsert(hashedName, func(entry *NameTreeEntry) {
+		if entry.fibEntry == nil {
+			entry.fibEntry = CreateFIBEntry()
+		}
+		entry.fibEntry.SetIdentifier(identifier)
+		entry.fibEntry.RWlock.Lock()
+		entry.fibEntry.NextHopList = nextHopList
+		entry.fibEntry.RWlock.Unlock()
+	})
+	return nil
 }
 
 // EraseByIdentifier
-// 通过标识在前缀树中删除FIBEntry
+// 通过标识在名字树中删除FIBEntry
 //
 // @Description:
 // @param *component.Identifier	需要删除的标识
@@ -148,16 +181,12 @@ func (f *FIB) AddOrUpdate(identifier *component.Identifier, logicFace *lf.LogicF
 func (f *FIB) EraseByIdentifier(identifier *component.Identifier) error {
 	f.rwLocker.Lock()
 	defer f.rwLocker.Unlock()
-	var PrefixList []string
-	for _, v := range identifier.GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
-	}
 	f.version++
-	return f.lpm.Delete(PrefixList)
+	return f.erase(identifier)
 }
 
 // EraseByFIBEntry
-// 通过FIBEntry在前缀树中删除FIBEntry
+// 通过FIBEntry在名字树中删除FIBEntry
 //
 // @Description:
 // @param *FIBEntry	需要删除的FIBEntry
@@ -166,12 +195,29 @@ func (f *FIB) EraseByIdentifier(identifier *component.Identifier) error {
 func (f *FIB) EraseByFIBEntry(fibEntry *FIBEntry) error {
 	f.rwLocker.Lock()
 	defer f.rwLocker.Unlock()
-	var PrefixList []string
-	for _, v := range fibEntry.GetIdentifier().GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
-	}
 	f.version++
-	return f.lpm.Delete(PrefixList)
+	return f.erase(fibEntry.GetIdentifier())
+}
+
+//
+// 从名字树中删除标识对应的FIBEntry，调用者需要持有写锁
+//
+// @Description:
+// @param identifier
+// @return error
+//
+func (f *FIB) erase(identifier *component.Identifier) error {
+	erased := false
+	f.nameTree.Modify(NewHashedName(identifier), func(entry *NameTreeEntry) {
+		if entry.fibEntry != nil {
+			entry.fibEntry = nil
+			erased = true
+		}
+	})
+	if !erased {
+		return createNodeErrorByType(DataNotExistedError)
+	}
+	return nil
 }
 
 // RemoveNextHopByFace
@@ -185,23 +231,17 @@ func (f *FIB) RemoveNextHopByFace(logicFace *lf.LogicFace) uint64 {
 	f.rwLocker.Lock()
 	defer f.rwLocker.Unlock()
 	f.version++
-	return f.lpm.TraverseFunc(func(val interface{}) uint64 {
-		if v, ok := val.(*FIBEntry); ok {
-			if v.HasNextHop(logicFace) {
-				v.RemoveNextHop(logicFace)
-				return 1
-			}
-		} else {
-			common.LogErrorWithFields(logrus.Fields{
-				"value": val,
-			}, "FIBEntry transform fail")
+	return f.nameTree.TraverseFunc(func(entry *NameTreeEntry) uint64 {
+		if entry.fibEntry != nil && entry.fibEntry.HasNextHop(logicFace) {
+			entry.fibEntry.RemoveNextHop(logicFace)
+			return 1
 		}
 		return 0
 	})
 }
 
 // Size
-// 返回前缀树里存有FIBEntry的节点数
+// 返回名字树里存有FIBEntry的节点数
 //
 // @Description:
 // @return uint64
@@ -209,29 +249,33 @@ func (f *FIB) RemoveNextHopByFace(logicFace *lf.LogicFace) uint64 {
 func (f *FIB) Size() uint64 {
 	f.rwLocker.RLock()
 	defer f.rwLocker.RUnlock()
-	return f.lpm.TraverseFunc(func(val interface{}) uint64 {
-		if _, ok := val.(*FIBEntry); ok {
-			return 1
-		} else {
-			common.LogErrorWithFields(logrus.Fields{
-				"value": val,
-			}, "FIBEntry transform fail")
+	var size uint64 = 0
+	f.nameTree.Range(func(entry *NameTreeEntry) bool {
+		if entry.fibEntry != nil {
+			size++
 		}
-		return 0
+		return true
 	})
+	return size
 }
 
 // GetDepth
-// 返回前缀树当前的深度
+// 返回FIB表中最长前缀的组件个数
 //
-// @Description: 返回前缀树当前的深度
+// @Description: 返回FIB表中最长前缀的组件个数，与原先前缀树的深度（不含根节点）一致
 // @return int
 //
 func (f *FIB) GetDepth() int {
 	f.rwLocker.RLock()
 	defer f.rwLocker.RUnlock()
-	// 根节点不存储数据
-	return f.lpm.GetDepth() - 1
+	depth := 0
+	f.nameTree.Range(func(entry *NameTreeEntry) bool {
+		if entry.fibEntry != nil && len(entry.components) > depth {
+			depth = len(entry.components)
+		}
+		return true
+	})
+	return depth
 }
 
 // GetAllEntry
@@ -244,16 +288,11 @@ func (f *FIB) GetAllEntry() []*FIBEntry {
 	f.rwLocker.RLock()
 	defer f.rwLocker.RUnlock()
 	var fibEntries []*FIBEntry
-	f.lpm.TraverseFunc(func(val interface{}) uint64 {
-		if fibEntry, ok := val.(*FIBEntry); ok {
-			fibEntries = append(fibEntries, fibEntry)
-			return 1
-		} else {
-			common.LogErrorWithFields(logrus.Fields{
-				"value": val,
-			}, "FIBEntry transform fail")
+	f.nameTree.Range(func(entry *NameTreeEntry) bool {
+		if entry.fibEntry != nil {
+			fibEntries = append(fibEntries, entry.fibEntry)
 		}
-		return 0
+		return true
 	})
 	return fibEntries
 }
@@ -267,3 +306,15 @@ func (f *FIB) GetAllEntry() []*FIBEntry {
 func (f *FIB) GetVersion() uint64 {
 	return f.version
 }
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type FIBError struct {
+	msg string
+}
+
+func (f FIBError) Error() string {
+	return fmt.Sprintf("FIBError: %s", f.msg)
+}
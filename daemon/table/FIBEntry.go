@@ -23,6 +23,7 @@
 package table
 
 import (
+	"hash/fnv"
 	"minlib/component"
 	"mir-go/daemon/lf"
 	"sort"
@@ -33,10 +34,87 @@ import (
 // 下一跳结构体
 //
 // @Description:下一跳结构体 用于存储下一跳信息
+//	1.Priority 是管理优先级，和 Cost 相互独立，数值越小越优先，比较下一跳时先比较 Priority 再比较 Cost
+//	2.Priority 和 Cost 都相同时按 LogicFaceId 从小到大排序，保证选出的下一跳是确定的
+//	3.ECMP 为 true 表示该下一跳是等价多路径的成员，转发策略会在 Priority 和 Cost 都最优的 ECMP 成员之间分担流量
 //
 type NextHop struct {
 	LogicFace *lf.LogicFace //逻辑接口号
 	Cost      uint64        //路由开销
+	Priority  uint64        //管理优先级，数值越小越优先
+	ECMP      bool          //是否是等价多路径成员
+}
+
+// Less
+// 判断下一跳 n 是否排在 other 之前，依次比较 Priority、Cost 和 LogicFaceId
+//
+// @Description:
+// @receiver n
+// @param other
+// @return bool
+//
+func (n *NextHop) Less(other *NextHop) bool {
+	if n.Priority != other.Priority {
+		return n.Priority < other.Priority
+	}
+	if n.Cost != other.Cost {
+		return n.Cost < other.Cost
+	}
+	return n.LogicFace.LogicFaceId < other.LogicFace.LogicFaceId
+}
+
+// IsEquivalent
+// 判断两个下一跳的 Priority 和 Cost 是否都相同
+//
+// @Description:
+// @receiver n
+// @param other
+// @return bool
+//
+func (n *NextHop) IsEquivalent(other *NextHop) bool {
+	return n.Priority == other.Priority && n.Cost == other.Cost
+}
+
+// SelectNextHop
+// 从按 Less 排好序的下一跳列表中选出一个下一跳，排除 excludeLogicFaceId 对应的逻辑接口
+//
+// @Description:
+//	1.最优的下一跳不是 ECMP 成员时，直接返回最优的下一跳；
+//	2.最优的下一跳是 ECMP 成员时，在所有和它等价的 ECMP 成员中根据 flowKey 的哈希值选择一个，同一个 flowKey 总是选到同一个下一跳
+// @param nextHops				按 Less 排好序的下一跳列表
+// @param excludeLogicFaceId	需要排除的逻辑接口号，一般是包到来的逻辑接口
+// @param flowKey				用于分担流量的键，一般是包的标识
+// @return *NextHop				没有可用的下一跳时返回 nil
+//
+func SelectNextHop(nextHops []*NextHop, excludeLogicFaceId uint64, flowKey string) *NextHop {
+	var members []*NextHop
+	for _, nextHop := range nextHops {
+		if nextHop.LogicFace.LogicFaceId == excludeLogicFaceId {
+			continue
+		}
+		if len(members) == 0 {
+			if !nextHop.ECMP {
+				return nextHop
+			}
+			members = append(members, nextHop)
+			continue
+		}
+		if !nextHop.IsEquivalent(members[0]) {
+			break
+		}
+		if nextHop.ECMP {
+			members = append(members, nextHop)
+		}
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	if len(members) == 1 {
+		return members[0]
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(flowKey))
+	return members[hash.Sum32()%uint32(len(members))]
 }
 
 // FIBEntry
@@ -79,7 +157,7 @@ func (f *FIBEntry) GetIdentifier() *component.Identifier { return f.identifier }
 func (f *FIBEntry) SetIdentifier(identifier *component.Identifier) { f.identifier = identifier }
 
 // GetNextHops
-// 返回FIBEntry中的下一跳列表 列表应该按cost从小到大排序
+// 返回FIBEntry中的下一跳列表 列表按 Priority、Cost、LogicFaceId 从小到大排序
 //
 // @Description:
 // @return []NextHop
@@ -91,9 +169,9 @@ func (f *FIBEntry) GetNextHops() []*NextHop {
 		NextHopList = append(NextHopList, nextHop)
 	}
 	f.RWlock.RUnlock()
-	// 内置函数 按照cost从小到大排序
+	// 按照 Priority、Cost、LogicFaceId 从小到大排序，避免 map 遍历顺序影响选路结果
 	sort.Slice(NextHopList, func(i, j int) bool {
-		return NextHopList[i].Cost < NextHopList[j].Cost
+		return NextHopList[i].Less(NextHopList[j])
 	})
 	return NextHopList
 }
@@ -55,4 +55,19 @@ type ICS interface {
 	// @return int
 	//
 	Size() int
+
+	// GetPartitionStatus 返回CS各个分区的状态信息，用于在管理模块中展示
+	//
+	// @Description:
+	// @return []*CSPartitionStatus
+	//
+	GetPartitionStatus() []*CSPartitionStatus
+
+	// Sweep 检查最多 batchSize 个表项，删除变旧之后超过最大保留时间的表项，由 CSSweeper 在后台周期性调用
+	//
+	// @Description:
+	// @param batchSize
+	// @return int 删除的表项数
+	//
+	Sweep(batchSize int) int
 }
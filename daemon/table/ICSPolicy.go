@@ -51,4 +51,23 @@ type ICSPolicy interface {
 	// @return int
 	//
 	Size() int
+
+	// Entries 返回当前缓存的所有CS条目的快照
+	//
+	// @Description:
+	//  获取快照不能影响缓存替换策略的统计信息（例如 LRU 的访问顺序和 LFU 的访问次数）
+	// @return []*CSEntry
+	//
+	Entries() []*CSEntry
+
+	// Remove 从缓存中删除一个CS条目
+	//
+	// @Description:
+	//  只有缓存中同名的条目就是 csEntry 本身，并且 canRemove 为 nil 或者返回 true 时才删除，两个条件的检查和删除是原子的，
+	//  这样根据旧快照删除时不会误删同名的新数据包
+	// @param csEntry
+	// @param canRemove	删除前对条目做的检查，为 nil 表示不检查
+	// @return bool 删除成功返回 true，条目不存在、已经被替换或者检查不通过返回 false
+	//
+	Remove(csEntry *CSEntry, canRemove func(csEntry *CSEntry) bool) bool
 }
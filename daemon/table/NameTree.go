@@ -0,0 +1,466 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 13:10 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	"minlib/component"
+	"sync"
+	"time"
+)
+
+// 名字树使用 FNV-1a 计算前缀哈希
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// nameTreeSeed 名字树使用的哈希种子，进程启动时随机生成，使得同一个名字在不同进程中的哈希值不同
+var nameTreeSeed = fnvOffset64 ^ uint64(time.Now().UnixNano())
+
+// HashedName 预先计算好所有前缀哈希值的标识
+//
+// @Description:
+//  对于一个有 n 个组件的标识，HashedName 一次性计算出它的 n+1 个前缀（包括根前缀 "/"）的哈希值并缓存下来，
+//  之后的精确匹配和最长前缀匹配都直接使用缓存的哈希值探测哈希表，不需要再重新拼接字符串数组和逐层遍历前缀树。
+//  同一个 HashedName 可以在多个表之间复用（例如 PITEntry 会保存插入时的 HashedName，删除时直接使用）
+//
+type HashedName struct {
+	identifier *component.Identifier // 原始标识
+	components []string              // 标识的每个组件的字符串表示
+	hashes     []uint64              // hashes[i] 为前 i 个组件构成的前缀的哈希值，hashes[0] 为根前缀的哈希值
+}
+
+// NewHashedName 根据标识新建一个 HashedName
+//
+// @Description:
+//  前缀哈希是增量计算的：依次把每个组件的长度和内容混入哈希值，每混入一个组件就记录一次当前的哈希值，
+//  混入长度是为了区分 /a/bc 和 /ab/c 这类拼接后相同的名字
+// @param identifier
+// @return *HashedName
+//
+func NewHashedName(identifier *component.Identifier) *HashedName {
+	components := identifier.GetComponents()
+	h := &HashedName{
+		identifier: identifier,
+		components: make([]string, len(components)),
+		hashes:     make([]uint64, len(components)+1),
+	}
+	hash := nameTreeSeed
+	h.hashes[0] = hash
+	for i, v := range components {
+		str := v.ToString()
+		h.components[i] = str
+		hash = (hash ^ uint64(len(str))) * fnvPrime64
+		for j := 0; j < len(str); j++ {
+			hash = (hash ^ uint64(str[j])) * fnvPrime64
+		}
+		h.hashes[i+1] = hash
+	}
+	return h
+}
+
+// GetIdentifier 获取原始标识
+//
+// @Description:
+// @receiver h
+// @return *component.Identifier
+//
+func (h *HashedName) GetIdentifier() *component.Identifier {
+	return h.identifier
+}
+
+// Len 返回标识的组件个数
+//
+// @Description:
+// @receiver h
+// @return int
+//
+func (h *HashedName) Len() int {
+	return len(h.components)
+}
+
+// hasPrefix 判断标识是否以 prefix 开头
+//
+// @Description:
+// @receiver h
+// @param prefix
+// @return bool
+//
+func (h *HashedName) hasPrefix(prefix *HashedName) bool {
+	return componentsHavePrefix(h.components, prefix.components)
+}
+
+// NameTreeEntry 名字树的一个节点，对应一个前缀
+//
+// @Description:
+//  PIT、FIB 和 StrategyTable 共用一棵名字树，同一个前缀的各个表项挂在同一个节点上，
+//  所有表项都为空时节点会被删除
+//
+type NameTreeEntry struct {
+	identifier    *component.Identifier // 节点对应的前缀
+	components    []string              // 前缀的每个组件的字符串表示，用于哈希冲突时比较
+	hash          uint64                // 前缀的哈希值
+	pitEntry      *PITEntry             // PIT 表项
+	fibEntry      *FIBEntry             // FIB 表项
+	strategyEntry *StrategyTableEntry   // 策略表表项
+}
+
+// GetIdentifier 获取节点对应的前缀
+//
+// @Description:
+// @receiver e
+// @return *component.Identifier
+//
+func (e *NameTreeEntry) GetIdentifier() *component.Identifier {
+	return e.identifier
+}
+
+// isEmpty 判断节点上是否还挂有表项
+//
+// @Description:
+// @receiver e
+// @return bool
+//
+func (e *NameTreeEntry) isEmpty() bool {
+	return e.pitEntry == nil && e.fibEntry == nil && e.strategyEntry == nil
+}
+
+// matches 判断节点是否对应 name 的前 n 个组件构成的前缀
+//
+// @Description:
+// @receiver e
+// @param name
+// @param n
+// @return bool
+//
+func (e *NameTreeEntry) matches(name *HashedName, n int) bool {
+	if e.hash != name.hashes[n] || len(e.components) != n {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if e.components[i] != name.components[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasPrefix 判断节点对应的前缀是否以 prefix 开头
+//
+// @Description:
+// @receiver e
+// @param prefix
+// @return bool
+//
+func (e *NameTreeEntry) hasPrefix(prefix *HashedName) bool {
+	return componentsHavePrefix(e.components, prefix.components)
+}
+
+//
+// @Description: 判断组件列表 components 是否以 prefix 开头
+// @param components
+// @param prefix
+// @return bool
+//
+func componentsHavePrefix(components []string, prefix []string) bool {
+	if len(components) < len(prefix) {
+		return false
+	}
+	for i, str := range prefix {
+		if components[i] != str {
+			return false
+		}
+	}
+	return true
+}
+
+// NameTree 基于哈希表实现的名字树
+//
+// @Description:
+//  1. 每个前缀对应一个 NameTreeEntry，以前缀的哈希值为 key 存放在哈希表中，哈希冲突的节点用切片串起来；
+//  2. 精确匹配只需要探测一次哈希表，是 O(1) 的；
+//  3. 最长前缀匹配从不超过当前最大深度的最长前缀开始，依次用更短前缀的哈希值探测哈希表，每次探测都是 O(1) 的；
+//  4. 只有挂有表项的前缀才会在哈希表中占用节点，中间前缀不需要额外的节点
+//
+type NameTree struct {
+	lock       sync.RWMutex                // 保护整棵名字树的读写锁
+	buckets    map[uint64][]*NameTreeEntry // 前缀哈希值 => 节点列表
+	depthCount []int                       // depthCount[i] 为组件个数为 i 的节点数量，用于确定最长前缀匹配的起点
+	size       int                         // 节点数量
+}
+
+// CreateNameTree 创建并初始化一棵名字树
+//
+// @Description:
+// @return *NameTree
+//
+func CreateNameTree() *NameTree {
+	n := new(NameTree)
+	n.Init()
+	return n
+}
+
+// Init 初始化名字树
+//
+// @Description:
+// @receiver n
+//
+func (n *NameTree) Init() {
+	n.buckets = make(map[uint64][]*NameTreeEntry)
+	n.depthCount = make([]int, 1)
+	n.size = 0
+}
+
+// Size 返回名字树中的节点数量
+//
+// @Description:
+// @receiver n
+// @return int
+//
+func (n *NameTree) Size() int {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	return n.size
+}
+
+// lookup 查找 name 的前 depth 个组件构成的前缀对应的节点，调用者需要持有锁
+//
+// @Description:
+// @receiver n
+// @param name
+// @param depth
+// @return *NameTreeEntry
+//
+func (n *NameTree) lookup(name *HashedName, depth int) *NameTreeEntry {
+	for _, entry := range n.buckets[name.hashes[depth]] {
+		if entry.matches(name, depth) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// maxDepth 返回当前名字树中节点的最大组件个数，调用者需要持有锁
+//
+// @Description:
+// @receiver n
+// @return int
+//
+func (n *NameTree) maxDepth() int {
+	return len(n.depthCount) - 1
+}
+
+// insert 插入 name 对应的节点，调用者需要持有写锁
+//
+// @Description:
+// @receiver n
+// @param name
+// @return *NameTreeEntry
+//
+func (n *NameTree) insert(name *HashedName) *NameTreeEntry {
+	depth := name.Len()
+	entry := &NameTreeEntry{
+		identifier: name.identifier,
+		components: name.components,
+		hash:       name.hashes[depth],
+	}
+	n.buckets[entry.hash] = append(n.buckets[entry.hash], entry)
+	for len(n.depthCount) <= depth {
+		n.depthCount = append(n.depthCount, 0)
+	}
+	n.depthCount[depth]++
+	n.size++
+	return entry
+}
+
+// remove 删除一个节点，调用者需要持有写锁
+//
+// @Description:
+// @receiver n
+// @param entry
+//
+func (n *NameTree) remove(entry *NameTreeEntry) {
+	bucket := n.buckets[entry.hash]
+	for i, v := range bucket {
+		if v != entry {
+			continue
+		}
+		if len(bucket) == 1 {
+			delete(n.buckets, entry.hash)
+		} else {
+			bucket[i] = bucket[len(bucket)-1]
+			bucket[len(bucket)-1] = nil
+			n.buckets[entry.hash] = bucket[:len(bucket)-1]
+		}
+		depth := len(entry.components)
+		n.depthCount[depth]--
+		// 收缩 depthCount，使得最长前缀匹配总是从当前的最大深度开始探测
+		for len(n.depthCount) > 1 && n.depthCount[len(n.depthCount)-1] == 0 {
+			n.depthCount = n.depthCount[:len(n.depthCount)-1]
+		}
+		n.size--
+		return
+	}
+}
+
+// FindExactMatch 精确匹配，返回 name 对应的节点，并且该节点需要满足 predicate
+//
+// @Description:
+// @receiver n
+// @param name
+// @param predicate	节点的过滤函数，例如只匹配挂有 FIB 表项的节点，为 nil 表示不过滤
+// @return *NameTreeEntry
+//
+func (n *NameTree) FindExactMatch(name *HashedName, predicate func(entry *NameTreeEntry) bool) *NameTreeEntry {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	if name.Len() > n.maxDepth() {
+		return nil
+	}
+	if entry := n.lookup(name, name.Len()); entry != nil && (predicate == nil || predicate(entry)) {
+		return entry
+	}
+	return nil
+}
+
+// FindLongestPrefixMatch 最长前缀匹配，返回 name 的满足 predicate 的最长前缀对应的节点
+//
+// @Description:
+// @receiver n
+// @param name
+// @param predicate	节点的过滤函数，例如只匹配挂有 FIB 表项的节点，为 nil 表示不过滤
+// @return *NameTreeEntry
+//
+func (n *NameTree) FindLongestPrefixMatch(name *HashedName, predicate func(entry *NameTreeEntry) bool) *NameTreeEntry {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	depth := name.Len()
+	if maxDepth := n.maxDepth(); depth > maxDepth {
+		depth = maxDepth
+	}
+	for ; depth >= 0; depth-- {
+		if n.depthCount[depth] == 0 {
+			continue
+		}
+		if entry := n.lookup(name, depth); entry != nil && (predicate == nil || predicate(entry)) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// FindOrInsert 查找 name 对应的节点，不存在则插入一个新节点，然后在写锁的保护下调用 f 更新节点上的表项
+//
+// @Description:
+//  f 执行完之后如果节点上没有任何表项，节点会被删除
+// @receiver n
+// @param name
+// @param f
+// @return *NameTreeEntry
+//
+func (n *NameTree) FindOrInsert(name *HashedName, f func(entry *NameTreeEntry)) *NameTreeEntry {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	entry := n.lookup(name, name.Len())
+	if entry == nil {
+		entry = n.insert(name)
+	}
+	if f != nil {
+		f(entry)
+	}
+	if entry.isEmpty() {
+		n.remove(entry)
+	}
+	return entry
+}
+
+// Modify 查找 name 对应的节点，存在则在写锁的保护下调用 f 更新节点上的表项
+//
+// @Description:
+//  f 执行完之后如果节点上没有任何表项，节点会被删除
+// @receiver n
+// @param name
+// @param f
+// @return bool	节点是否存在
+//
+func (n *NameTree) Modify(name *HashedName, f func(entry *NameTreeEntry)) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if name.Len() > n.maxDepth() {
+		return false
+	}
+	entry := n.lookup(name, name.Len())
+	if entry == nil {
+		return false
+	}
+	f(entry)
+	if entry.isEmpty() {
+		n.remove(entry)
+	}
+	return true
+}
+
+// Range 在读锁的保护下遍历所有节点，f 返回 false 时停止遍历
+//
+// @Description:
+//  f 中不能修改节点上的表项，也不能再调用名字树的其它方法，需要修改表项时使用 TraverseFunc
+// @receiver n
+// @param f
+//
+func (n *NameTree) Range(f func(entry *NameTreeEntry) bool) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	for _, bucket := range n.buckets {
+		for _, entry := range bucket {
+			if !f(entry) {
+				return
+			}
+		}
+	}
+}
+
+// TraverseFunc 在写锁的保护下遍历所有节点，返回 f 返回值的累加和
+//
+// @Description:
+//  f 中可以修改节点上的表项，遍历完之后没有任何表项的节点会被删除；f 中不能再调用名字树的其它方法
+// @receiver n
+// @param f
+// @return uint64
+//
+func (n *NameTree) TraverseFunc(f func(entry *NameTreeEntry) uint64) uint64 {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	var count uint64 = 0
+	var emptyEntries []*NameTreeEntry
+	for _, bucket := range n.buckets {
+		for _, entry := range bucket {
+			count += f(entry)
+			if entry.isEmpty() {
+				emptyEntries = append(emptyEntries, entry)
+			}
+		}
+	}
+	for _, entry := range emptyEntries {
+		n.remove(entry)
+	}
+	return count
+}
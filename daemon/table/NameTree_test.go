@@ -0,0 +1,218 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 14:05 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	"minlib/component"
+	"mir-go/daemon/lf"
+	"mir-go/daemon/utils"
+	"strconv"
+	"testing"
+)
+
+func mustCreateIdentifier(tb testing.TB, name string) *component.Identifier {
+	identifier, err := component.CreateIdentifierByString(name)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return identifier
+}
+
+func TestNameTree_SharedEntries(t *testing.T) {
+	nameTree := CreateNameTree()
+	fib := new(FIB)
+	fib.InitWithNameTree(nameTree)
+	pit := new(PIT)
+	pit.InitWithNameTree(nameTree)
+
+	fib.AddOrUpdate(mustCreateIdentifier(t, "/min"), &lf.LogicFace{LogicFaceId: 1}, 1)
+	fib.AddOrUpdate(mustCreateIdentifier(t, "/min/pku/edu"), &lf.LogicFace{LogicFaceId: 2}, 1)
+	interest := newTestInterest("/min/pku/edu")
+	pitEntry := pit.Insert(interest)
+	if nameTree.Size() != 2 {
+		t.Fatalf("FIB and PIT entries of /min/pku/edu should share one node, size = %d", nameTree.Size())
+	}
+
+	// 最长前缀匹配只匹配挂有 FIB 表项的节点
+	if fibEntry := fib.FindLongestPrefixMatch(mustCreateIdentifier(t, "/min/pku/cn")); fibEntry == nil ||
+		fibEntry.GetIdentifier().ToUri() != "/min" {
+		t.Errorf("/min/pku/cn should match /min, got %v", fibEntry)
+	}
+	if fibEntry := fib.FindLongestPrefixMatch(mustCreateIdentifier(t, "/min/pku/edu/a/b")); fibEntry == nil ||
+		fibEntry.GetIdentifier().ToUri() != "/min/pku/edu" {
+		t.Errorf("/min/pku/edu/a/b should match /min/pku/edu, got %v", fibEntry)
+	}
+	if fibEntry := fib.FindExactMatch(mustCreateIdentifier(t, "/min/pku")); fibEntry != nil {
+		t.Errorf("/min/pku should not exact match any FIB entry")
+	}
+
+	// 删除 PIT 表项之后，节点上还有 FIB 表项，不能被删除
+	if err := pit.EraseByPITEntry(pitEntry); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pit.Find(interest); err == nil {
+		t.Errorf("PIT entry should be erased")
+	}
+	if nameTree.Size() != 2 || fib.FindExactMatch(mustCreateIdentifier(t, "/min/pku/edu")) == nil {
+		t.Errorf("FIB entry of /min/pku/edu should be kept")
+	}
+
+	// 节点上的表项都删除之后，节点也被删除
+	if err := fib.EraseByIdentifier(mustCreateIdentifier(t, "/min/pku/edu")); err != nil {
+		t.Fatal(err)
+	}
+	if nameTree.Size() != 1 || fib.GetDepth() != 1 {
+		t.Errorf("unexpected name tree size %d or FIB depth %d", nameTree.Size(), fib.GetDepth())
+	}
+	if err := fib.EraseByIdentifier(mustCreateIdentifier(t, "/min/pku/edu")); err == nil {
+		t.Errorf("erase a not existed FIB entry should fail")
+	}
+}
+
+func TestHashedName_DistinctPrefixes(t *testing.T) {
+	a := NewHashedName(mustCreateIdentifier(t, "/a/bc"))
+	b := NewHashedName(mustCreateIdentifier(t, "/ab/c"))
+	if a.hashes[2] == b.hashes[2] {
+		t.Errorf("/a/bc and /ab/c should have different hashes")
+	}
+	root := NewHashedName(mustCreateIdentifier(t, "/"))
+	if root.Len() != 0 || root.hashes[0] != a.hashes[0] {
+		t.Errorf("root prefix hash should be shared by all names")
+	}
+}
+
+// 基准测试，对比名字树和 Lpm.go 中的前缀树
+// go test -run=^$ -bench='NameTree|LpmMatcher' -benchmem
+
+const nameTreeBenchmarkSize = 100000
+
+// loadNameTreeBenchmarkNames 生成 nameTreeBenchmarkSize 个前缀，以及在每个前缀后面随机追加若干组件得到的查询名字
+func loadNameTreeBenchmarkNames(b *testing.B) ([]*component.Identifier, []*component.Identifier) {
+	prefixes := make([]*component.Identifier, nameTreeBenchmarkSize)
+	queries := make([]*component.Identifier, nameTreeBenchmarkSize)
+	for i := 0; i < nameTreeBenchmarkSize; i++ {
+		prefix := "/bench/" + strconv.Itoa(i%100) + utils.RandomMINName(1, 4, 8, int64(i+1))
+		prefixes[i] = mustCreateIdentifier(b, prefix)
+		queries[i] = mustCreateIdentifier(b, prefix+utils.RandomMINName(1, 4, 8, int64(-i-1)))
+		// 缓存 ToUri 和 GetPrefix
+		prefixes[i].ToUri()
+		queries[i].ToUri()
+	}
+	return prefixes, queries
+}
+
+func loadLpmMatcher(prefixes []*component.Identifier) *LpmMatcher {
+	lpm := new(LpmMatcher)
+	lpm.Create()
+	for _, prefix := range prefixes {
+		lpm.AddOrUpdate(identifierToPrefixList(prefix), prefix, nil)
+	}
+	return lpm
+}
+
+func loadNameTree(prefixes []*component.Identifier) *NameTree {
+	nameTree := CreateNameTree()
+	for _, prefix := range prefixes {
+		fibEntry := CreateFIBEntry()
+		fibEntry.SetIdentifier(prefix)
+		nameTree.FindOrInsert(NewHashedName(prefix), func(entry *NameTreeEntry) {
+			entry.fibEntry = fibEntry
+		})
+	}
+	return nameTree
+}
+
+func BenchmarkLpmMatcher_FindExactMatch(b *testing.B) {
+	prefixes, _ := loadNameTreeBenchmarkNames(b)
+	lpm := loadLpmMatcher(prefixes)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := lpm.FindExactMatch(identifierToPrefixList(prefixes[i%nameTreeBenchmarkSize])); !ok {
+			b.Fatal("lpm find exact match failed")
+		}
+	}
+}
+
+func BenchmarkNameTree_FindExactMatch(b *testing.B) {
+	prefixes, _ := loadNameTreeBenchmarkNames(b)
+	nameTree := loadNameTree(prefixes)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if nameTree.FindExactMatch(NewHashedName(prefixes[i%nameTreeBenchmarkSize]), hasFIBEntry) == nil {
+			b.Fatal("name tree find exact match failed")
+		}
+	}
+}
+
+func BenchmarkLpmMatcher_FindLongestPrefixMatch(b *testing.B) {
+	prefixes, queries := loadNameTreeBenchmarkNames(b)
+	lpm := loadLpmMatcher(prefixes)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := lpm.FindLongestPrefixMatch(identifierToPrefixList(queries[i%nameTreeBenchmarkSize])); !ok {
+			b.Fatal("lpm find longest prefix match failed")
+		}
+	}
+}
+
+func BenchmarkNameTree_FindLongestPrefixMatch(b *testing.B) {
+	prefixes, queries := loadNameTreeBenchmarkNames(b)
+	nameTree := loadNameTree(prefixes)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if nameTree.FindLongestPrefixMatch(NewHashedName(queries[i%nameTreeBenchmarkSize]), hasFIBEntry) == nil {
+			b.Fatal("name tree find longest prefix match failed")
+		}
+	}
+}
+
+func BenchmarkNameTree_FindLongestPrefixMatch_Parallel(b *testing.B) {
+	prefixes, queries := loadNameTreeBenchmarkNames(b)
+	nameTree := loadNameTree(prefixes)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			nameTree.FindLongestPrefixMatch(NewHashedName(queries[i%nameTreeBenchmarkSize]), hasFIBEntry)
+			i++
+		}
+	})
+}
+
+func BenchmarkLpmMatcher_FindLongestPrefixMatch_Parallel(b *testing.B) {
+	prefixes, queries := loadNameTreeBenchmarkNames(b)
+	lpm := loadLpmMatcher(prefixes)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			lpm.FindLongestPrefixMatch(identifierToPrefixList(queries[i%nameTreeBenchmarkSize]))
+			i++
+		}
+	})
+}
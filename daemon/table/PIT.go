@@ -23,19 +23,19 @@ package table
 
 import (
 	"fmt"
-	"github.com/sirupsen/logrus"
-	common2 "minlib/common"
+	"minlib/component"
 	"minlib/packet"
 	"mir-go/daemon/lf"
+	"sort"
 )
 
 // PIT
 // PIT表结构体
 //
-// @Description:PIT表结构体,用前缀树存储表项
+// @Description:PIT表结构体,表项存储在名字树的节点中，名字树可以和FIB、StrategyTable等表共用
 //
 type PIT struct {
-	lpm *LpmMatcher //最长前缀匹配器
+	nameTree *NameTree // 名字树
 }
 
 // CreatePIT
@@ -46,19 +46,27 @@ type PIT struct {
 //
 func CreatePIT() *PIT {
 	var p = &PIT{}
-	p.lpm = &LpmMatcher{} //初始化
-	p.lpm.Create()        //初始化锁
+	p.Init()
 	return p
 }
 
 // Init
-// 初始化创建好的PIT表
+// 初始化创建好的PIT表，使用一棵独立的名字树
 //
 // @Description:
 //
 func (p *PIT) Init() {
-	p.lpm = new(LpmMatcher) //初始化
-	p.lpm.Create()          //初始化锁
+	p.InitWithNameTree(CreateNameTree())
+}
+
+// InitWithNameTree
+// 使用指定的名字树初始化PIT表，用于和其它表共用同一棵名字树
+//
+// @Description:
+// @param nameTree
+//
+func (p *PIT) InitWithNameTree(nameTree *NameTree) {
+	p.nameTree = nameTree
 }
 
 // Size
@@ -68,34 +76,29 @@ func (p *PIT) Init() {
 // @return uint64
 //
 func (p *PIT) Size() uint64 {
-	return p.lpm.TraverseFunc(func(val interface{}) uint64 {
-		if _, ok := val.(*PITEntry); ok {
-			return 1
-		} else {
-			common2.LogErrorWithFields(logrus.Fields{
-				"value": val,
-			}, "PITEntry transform fail")
+	var size uint64 = 0
+	p.nameTree.Range(func(entry *NameTreeEntry) bool {
+		if entry.pitEntry != nil {
+			size++
 		}
-		return 0
+		return true
 	})
+	return size
 }
 
 // Find
-// 通过兴趣包在前缀树中精准匹配查找对应的PITEntry
+// 通过兴趣包在名字树中精准匹配查找对应的PITEntry
 //
 // @Description:
 // @param *packet.Interest	需要进行查找的兴趣包
 // @return *PITEntry error
 //
 func (p *PIT) Find(interest *packet.Interest) (*PITEntry, error) {
-	var PrefixList []string
-	for _, v := range interest.GetName().GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
-	}
-	if v, ok := p.lpm.FindExactMatch(PrefixList); ok {
-		if pitEntry, ok := v.(*PITEntry); ok {
-			return pitEntry, nil
-		}
+	entry := p.nameTree.FindExactMatch(NewHashedName(interest.GetName()), func(entry *NameTreeEntry) bool {
+		return entry.pitEntry != nil
+	})
+	if entry != nil {
+		return entry.pitEntry, nil
 	}
 	return nil, createPITErrorByType(PITEntryNotExistedError)
 }
@@ -108,22 +111,17 @@ func (p *PIT) Find(interest *packet.Interest) (*PITEntry, error) {
 // @return *PITEntry
 //
 func (p *PIT) Insert(interest *packet.Interest) *PITEntry {
-	var PrefixList []string
-	for _, v := range interest.GetName().GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
-	}
-	val := p.lpm.AddOrUpdate(PrefixList, nil, func(val interface{}) interface{} {
-		// not ok 那么val == nil 存入标识
-		if _, ok := (val).(*PITEntry); !ok {
-			// 存入的表项 不是 *PITEntry类型 或者 为nil
-			pitEntry := CreatePITEntry()
-			val = pitEntry
+	hashedName := NewHashedName(interest.GetName())
+	var pitEntry *PITEntry
+	p.nameTree.FindOrInsert(hashedName, func(entry *NameTreeEntry) {
+		if entry.pitEntry == nil {
+			entry.pitEntry = CreatePITEntry()
+			entry.pitEntry.hashedName = hashedName
 		}
-		entry := (val).(*PITEntry)
-		entry.Identifier = interest.GetName()
-		return entry
+		pitEntry = entry.pitEntry
+		pitEntry.Identifier = interest.GetName()
 	})
-	return val.(*PITEntry)
+	return pitEntry
 }
 
 // FindDataMatches
@@ -134,30 +132,38 @@ func (p *PIT) Insert(interest *packet.Interest) *PITEntry {
 // @return *PITEntry
 //
 func (p *PIT) FindDataMatches(data *packet.Data) *PITEntry {
-	var PrefixList []string
-	for _, v := range data.GetName().GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
-	}
-	if v, ok := p.lpm.FindExactMatch(PrefixList); ok {
-		p.lpm.Delete(PrefixList)
-		return v.(*PITEntry)
-	}
-	return nil
+	var pitEntry *PITEntry
+	p.nameTree.Modify(NewHashedName(data.GetName()), func(entry *NameTreeEntry) {
+		pitEntry = entry.pitEntry
+		entry.pitEntry = nil
+	})
+	return pitEntry
 }
 
 // EraseByPITEntry
 // 根据PITEntry删除PIT表中的PITEntry
 //
 // @Description:
+//  同名的表项可能已经被满足并重新插入，只有表中的表项就是 pitEntry 本身时才删除
 // @param *PITEntry
 // @return error
 //
 func (p *PIT) EraseByPITEntry(pitEntry *PITEntry) error {
-	var PrefixList []string
-	for _, v := range pitEntry.Identifier.GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
+	hashedName := pitEntry.hashedName
+	if hashedName == nil {
+		hashedName = NewHashedName(pitEntry.Identifier)
+	}
+	erased := false
+	p.nameTree.Modify(hashedName, func(entry *NameTreeEntry) {
+		if entry.pitEntry != nil && entry.pitEntry == pitEntry {
+			entry.pitEntry = nil
+			erased = true
+		}
+	})
+	if !erased {
+		return createPITErrorByType(PITEntryNotExistedError)
 	}
-	return p.lpm.Delete(PrefixList)
+	return nil
 }
 
 // EraseByLogicFace
@@ -168,27 +174,110 @@ func (p *PIT) EraseByPITEntry(pitEntry *PITEntry) error {
 // @return uint64
 //
 func (p *PIT) EraseByLogicFace(logicFace *lf.LogicFace) uint64 {
-	return p.lpm.TraverseFunc(func(val interface{}) uint64 {
-		if pitEntry, ok := val.(*PITEntry); ok {
-			var ok1, ok2 bool
-			if _, ok1 = pitEntry.InRecordList[logicFace.LogicFaceId]; ok1 {
-				delete(pitEntry.InRecordList, logicFace.LogicFaceId)
-			}
-			if _, ok2 = pitEntry.OutRecordList[logicFace.LogicFaceId]; ok2 {
-				delete(pitEntry.OutRecordList, logicFace.LogicFaceId)
-			}
-			if ok1 || ok2 {
-				return 1
-			}
+	return p.nameTree.TraverseFunc(func(entry *NameTreeEntry) uint64 {
+		pitEntry := entry.pitEntry
+		if pitEntry == nil {
 			return 0
 		}
-		common2.LogErrorWithFields(logrus.Fields{
-			"value": val,
-		}, "PITEntry transform fail")
+		var ok1, ok2 bool
+		pitEntry.RWlock.Lock()
+		defer pitEntry.RWlock.Unlock()
+		if _, ok1 = pitEntry.InRecordList[logicFace.LogicFaceId]; ok1 {
+			delete(pitEntry.InRecordList, logicFace.LogicFaceId)
+		}
+		if _, ok2 = pitEntry.OutRecordList[logicFace.LogicFaceId]; ok2 {
+			delete(pitEntry.OutRecordList, logicFace.LogicFaceId)
+		}
+		if ok1 || ok2 {
+			return 1
+		}
 		return 0
 	})
 }
 
+// PITSummary PIT 表的统计信息，用于在管理模块中展示
+//
+// @Description:
+//
+type PITSummary struct {
+	Entries    uint64 // 表项数
+	Satisfied  uint64 // 已被满足但是还没有移除的表项数
+	InRecords  uint64 // 流入记录总数
+	OutRecords uint64 // 流出记录总数
+	Nacked     uint64 // 收到了 Nack 的流出记录数
+}
+
+// ListStatus
+// 获取以 prefix 开头的 PIT 表项的状态信息，按标识排序
+//
+// @Description:
+// @param prefix	过滤使用的前缀，为 nil 时返回所有表项
+// @param limit	最多返回的表项数，小于等于 0 时不限制
+// @return []*PITEntryStatus	表项的状态信息
+// @return uint64	匹配 prefix 的表项总数，可能大于返回的表项数
+//
+func (p *PIT) ListStatus(prefix *component.Identifier, limit int) ([]*PITEntryStatus, uint64) {
+	pitEntries := p.collect(prefix)
+	sort.Slice(pitEntries, func(i, j int) bool {
+		return pitEntries[i].Identifier.ToUri() < pitEntries[j].Identifier.ToUri()
+	})
+	total := uint64(len(pitEntries))
+	if limit > 0 && len(pitEntries) > limit {
+		pitEntries = pitEntries[:limit]
+	}
+	statusList := make([]*PITEntryStatus, 0, len(pitEntries))
+	for _, pitEntry := range pitEntries {
+		statusList = append(statusList, pitEntry.Status())
+	}
+	return statusList, total
+}
+
+// GetSummary
+// 统计以 prefix 开头的 PIT 表项
+//
+// @Description:
+// @param prefix	过滤使用的前缀，为 nil 时统计所有表项
+// @return *PITSummary
+//
+func (p *PIT) GetSummary(prefix *component.Identifier) *PITSummary {
+	summary := new(PITSummary)
+	for _, pitEntry := range p.collect(prefix) {
+		status := pitEntry.Status()
+		summary.Entries++
+		if status.Satisfied {
+			summary.Satisfied++
+		}
+		summary.InRecords += uint64(len(status.InRecords))
+		summary.OutRecords += uint64(len(status.OutRecords))
+		for _, outRecord := range status.OutRecords {
+			if outRecord.Nacked {
+				summary.Nacked++
+			}
+		}
+	}
+	return summary
+}
+
+//
+// @Description: 在名字树的读锁保护下收集以 prefix 开头的 PIT 表项，读取表项内容放在锁外进行
+// @param prefix
+// @return []*PITEntry
+//
+func (p *PIT) collect(prefix *component.Identifier) []*PITEntry {
+	var hashedPrefix *HashedName
+	if prefix != nil {
+		hashedPrefix = NewHashedName(prefix)
+	}
+	var pitEntries []*PITEntry
+	p.nameTree.Range(func(entry *NameTreeEntry) bool {
+		if entry.pitEntry != nil && (hashedPrefix == nil || entry.hasPrefix(hashedPrefix)) {
+			pitEntries = append(pitEntries, entry.pitEntry)
+		}
+		return true
+	})
+	return pitEntries
+}
+
 /////////////////////////////////////////////////////////////////////////////////////////////////////////
 ///// 错误处理
 /////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -27,6 +27,9 @@ import (
 	"minlib/component"
 	"minlib/packet"
 	"mir-go/daemon/lf"
+	"mir-go/daemon/utils"
+	"sort"
+	"sync"
 )
 
 // InRecord
@@ -64,6 +67,9 @@ type PITEntry struct {
 	OutRecordList map[uint64]*OutRecord //流出记录表
 	isSatisfied   bool                  // 是否已被满足
 	isDeleted     bool                  // 是否已经从 PIT 表中移除
+	hashedName    *HashedName           // 插入时计算好的前缀哈希，删除表项时直接复用
+	expiryTimer   *utils.TimerHandle    // 超时定时任务的句柄，重新设置超时时间时用来取消之前的定时任务
+	RWlock        *sync.RWMutex         // 保护流入、流出记录表的读写锁，管理模块会在其它协程中读取记录表
 	//ExpireTime    time.Duration         //超时时间 底层设置 过期删除
 	//InRWlock               *sync.RWMutex         //流入读写锁
 	//OutRWlock              *sync.RWMutex         //流出读写锁
@@ -81,6 +87,7 @@ func CreatePITEntry() *PITEntry {
 	var p = &PITEntry{}
 	p.InRecordList = make(map[uint64]*InRecord)
 	p.OutRecordList = make(map[uint64]*OutRecord)
+	p.RWlock = new(sync.RWMutex)
 	//p.InRWlock = new(sync.RWMutex)
 	//p.OutRWlock = new(sync.RWMutex)
 	//p.ch = make(chan int)
@@ -133,6 +140,28 @@ func (p *PITEntry) SetDeleted(isDeleted bool) {
 	p.isDeleted = isDeleted
 }
 
+// GetExpiryTimer
+// 返回当前 PITEntry 的超时定时任务句柄
+//
+// @Description:
+// @receiver p
+// @return *utils.TimerHandle
+//
+func (p *PITEntry) GetExpiryTimer() *utils.TimerHandle {
+	return p.expiryTimer
+}
+
+// SetExpiryTimer
+// 设置当前 PITEntry 的超时定时任务句柄
+//
+// @Description:
+// @receiver p
+// @param expiryTimer
+//
+func (p *PITEntry) SetExpiryTimer(expiryTimer *utils.TimerHandle) {
+	p.expiryTimer = expiryTimer
+}
+
 //// SetExpiryTimer
 //// 设置超时定时器 经过duration时间段 自动调用函数f 并且可以在中途调用CancelTimer取消
 ////
@@ -187,8 +216,8 @@ func (p *PITEntry) SetDeleted(isDeleted bool) {
 // @return *packet.Interest, bool
 //
 func (p *PITEntry) GetInterest() (*packet.Interest, bool) {
-	//p.InRWlock.RLock()
-	//defer p.InRWlock.RUnlock()
+	p.RWlock.RLock()
+	defer p.RWlock.RUnlock()
 	for _, inRecord := range p.InRecordList {
 		return inRecord.Interest, true
 	}
@@ -212,8 +241,8 @@ func (p *PITEntry) GetIdentifier() *component.Identifier {
 // @return bool, error
 //
 func (p *PITEntry) CanMatch(interest *packet.Interest) (bool, error) {
-	//p.InRWlock.RLock()
-	//defer p.InRWlock.RUnlock()
+	p.RWlock.RLock()
+	defer p.RWlock.RUnlock()
 	for _, inRecord := range p.InRecordList {
 		return inRecord.Interest.MatchesInterest(interest), nil
 	}
@@ -228,11 +257,11 @@ func (p *PITEntry) CanMatch(interest *packet.Interest) (bool, error) {
 //
 func (p *PITEntry) GetInRecords() []*InRecord {
 	InRecordList := make([]*InRecord, 0)
-	//p.InRWlock.RLock()
+	p.RWlock.RLock()
 	for _, inRecord := range p.InRecordList {
 		InRecordList = append(InRecordList, inRecord)
 	}
-	//p.InRWlock.RUnlock()
+	p.RWlock.RUnlock()
 	return InRecordList
 }
 
@@ -243,8 +272,8 @@ func (p *PITEntry) GetInRecords() []*InRecord {
 // @return bool
 //
 func (p *PITEntry) HasInRecords() bool {
-	//p.InRWlock.RLock()
-	//defer p.InRWlock.RUnlock()
+	p.RWlock.RLock()
+	defer p.RWlock.RUnlock()
 	return len(p.InRecordList) != 0
 }
 
@@ -255,8 +284,8 @@ func (p *PITEntry) HasInRecords() bool {
 // @return InRecord, error
 //
 func (p *PITEntry) GetInRecord(logicFace *lf.LogicFace) (*InRecord, error) {
-	//p.InRWlock.RLock()
-	//defer p.InRWlock.RUnlock()
+	p.RWlock.RLock()
+	defer p.RWlock.RUnlock()
 	if inRecord, ok := p.InRecordList[logicFace.LogicFaceId]; ok {
 		return inRecord, nil
 	}
@@ -277,11 +306,11 @@ func (p *PITEntry) InsertOrUpdateInRecord(logicFace *lf.LogicFace, interest *pac
 	//	p.RWlock.Unlock()
 	//	return &InRecord{}
 	//}
-	//p.InRWlock.Lock()
+	p.RWlock.Lock()
 	delete(p.InRecordList, logicFace.LogicFaceId)
 	inRecord := &InRecord{LogicFace: logicFace, Interest: interest, LastNonce: interest.Nonce}
 	p.InRecordList[logicFace.LogicFaceId] = inRecord
-	//p.InRWlock.Unlock()
+	p.RWlock.Unlock()
 	// 返回引用 对返回值修改就是对原值修改
 	return inRecord
 }
@@ -294,8 +323,8 @@ func (p *PITEntry) InsertOrUpdateInRecord(logicFace *lf.LogicFace, interest *pac
 // @return error
 //
 func (p *PITEntry) DeleteInRecord(logicFace *lf.LogicFace) error {
-	//p.InRWlock.Lock()
-	//defer p.InRWlock.Unlock()
+	p.RWlock.Lock()
+	defer p.RWlock.Unlock()
 	if _, ok := p.InRecordList[logicFace.LogicFaceId]; ok {
 		delete(p.InRecordList, logicFace.LogicFaceId)
 		return nil
@@ -309,8 +338,8 @@ func (p *PITEntry) DeleteInRecord(logicFace *lf.LogicFace) error {
 // @Description:
 //
 func (p *PITEntry) ClearInRecords() {
-	//p.InRWlock.Lock()
-	//defer p.InRWlock.Unlock()
+	p.RWlock.Lock()
+	defer p.RWlock.Unlock()
 	p.InRecordList = make(map[uint64]*InRecord)
 }
 
@@ -322,11 +351,11 @@ func (p *PITEntry) ClearInRecords() {
 //
 func (p *PITEntry) GetOutRecords() []*OutRecord {
 	OutRecordList := make([]*OutRecord, 0)
-	//p.OutRWlock.RLock()
+	p.RWlock.RLock()
 	for _, outRecord := range p.OutRecordList {
 		OutRecordList = append(OutRecordList, outRecord)
 	}
-	//p.OutRWlock.RUnlock()
+	p.RWlock.RUnlock()
 	return OutRecordList
 }
 
@@ -337,8 +366,8 @@ func (p *PITEntry) GetOutRecords() []*OutRecord {
 // @return bool
 //
 func (p *PITEntry) HasOutRecords() bool {
-	//p.OutRWlock.RLock()
-	//defer p.OutRWlock.RUnlock()
+	p.RWlock.RLock()
+	defer p.RWlock.RUnlock()
 	return len(p.OutRecordList) != 0
 }
 
@@ -350,8 +379,8 @@ func (p *PITEntry) HasOutRecords() bool {
 // @return OutRecord, error
 //
 func (p *PITEntry) GetOutRecord(logicFace *lf.LogicFace) (*OutRecord, error) {
-	//p.OutRWlock.RLock()
-	//defer p.OutRWlock.RUnlock()
+	p.RWlock.RLock()
+	defer p.RWlock.RUnlock()
 	if outRecord, ok := p.OutRecordList[logicFace.LogicFaceId]; ok {
 		return outRecord, nil
 	}
@@ -369,11 +398,11 @@ func (p *PITEntry) InsertOrUpdateOutRecord(logicFace *lf.LogicFace, interest *pa
 	//if p.OutRecordList == nil {
 	//	p.OutRecordList = make(map[uint64]OutRecord)
 	//}
-	//p.OutRWlock.Lock()
+	p.RWlock.Lock()
 	delete(p.OutRecordList, logicFace.LogicFaceId)
 	outRecord := &OutRecord{LogicFace: logicFace, LastNonce: interest.Nonce}
 	p.OutRecordList[logicFace.LogicFaceId] = outRecord
-	//p.OutRWlock.Unlock()
+	p.RWlock.Unlock()
 	return outRecord
 }
 
@@ -385,8 +414,8 @@ func (p *PITEntry) InsertOrUpdateOutRecord(logicFace *lf.LogicFace, interest *pa
 // @return error
 //
 func (p *PITEntry) DeleteOutRecord(logicFace *lf.LogicFace) error {
-	//p.OutRWlock.Lock()
-	//defer p.OutRWlock.Unlock()
+	p.RWlock.Lock()
+	defer p.RWlock.Unlock()
 	if _, ok := p.OutRecordList[logicFace.LogicFaceId]; ok {
 		delete(p.OutRecordList, logicFace.LogicFaceId)
 		return nil
@@ -400,11 +429,91 @@ func (p *PITEntry) DeleteOutRecord(logicFace *lf.LogicFace) error {
 // @Description:
 //
 func (p *PITEntry) ClearOutRecords() {
-	//p.OutRWlock.Lock()
-	//defer p.OutRWlock.Unlock()
+	p.RWlock.Lock()
+	defer p.RWlock.Unlock()
 	p.OutRecordList = make(map[uint64]*OutRecord)
 }
 
+// InRecordStatus 流入记录的状态信息，用于在管理模块中展示
+//
+// @Description:
+//
+type InRecordStatus struct {
+	LogicFaceId uint64 // 流入 LogicFace 的 ID
+	Nonce       uint64 // 最后一个兴趣包的 nonce
+	ExpireTime  uint64 // 超时时间（毫秒时间戳）
+}
+
+// OutRecordStatus 流出记录的状态信息，用于在管理模块中展示
+//
+// @Description:
+//
+type OutRecordStatus struct {
+	LogicFaceId uint64 // 流出 LogicFace 的 ID
+	Nonce       uint64 // 最后一个兴趣包的 nonce
+	ExpireTime  uint64 // 超时时间（毫秒时间戳）
+	Nacked      bool   // 是否收到了 Nack
+	NackReason  int    // 收到的 Nack 原因，Nacked 为 false 时无意义
+}
+
+// PITEntryStatus PIT 表项的状态信息，用于在管理模块中展示
+//
+// @Description:
+//
+type PITEntryStatus struct {
+	Name       string             // 表项对应的标识
+	InRecords  []*InRecordStatus  // 流入记录
+	OutRecords []*OutRecordStatus // 流出记录
+	Satisfied  bool               // 是否已被满足
+	Deleted    bool               // 是否已经从 PIT 表中移除
+}
+
+// Status
+// 获取表项的状态信息快照
+//
+// @Description:
+//  记录表在读锁的保护下复制一份，可以在转发协程以外的协程中调用
+// @receiver p
+// @return *PITEntryStatus
+//
+func (p *PITEntry) Status() *PITEntryStatus {
+	status := &PITEntryStatus{
+		Name:      p.Identifier.ToUri(),
+		Satisfied: p.isSatisfied,
+		Deleted:   p.isDeleted,
+	}
+	p.RWlock.RLock()
+	defer p.RWlock.RUnlock()
+	status.InRecords = make([]*InRecordStatus, 0, len(p.InRecordList))
+	for logicFaceId, inRecord := range p.InRecordList {
+		status.InRecords = append(status.InRecords, &InRecordStatus{
+			LogicFaceId: logicFaceId,
+			Nonce:       uint64(inRecord.LastNonce.GetNonce()),
+			ExpireTime:  inRecord.ExpireTime,
+		})
+	}
+	status.OutRecords = make([]*OutRecordStatus, 0, len(p.OutRecordList))
+	for logicFaceId, outRecord := range p.OutRecordList {
+		outRecordStatus := &OutRecordStatus{
+			LogicFaceId: logicFaceId,
+			Nonce:       uint64(outRecord.LastNonce.GetNonce()),
+			ExpireTime:  outRecord.ExpireTime,
+		}
+		if outRecord.NackHeader != nil {
+			outRecordStatus.Nacked = true
+			outRecordStatus.NackReason = int(outRecord.NackHeader.GetNackReason())
+		}
+		status.OutRecords = append(status.OutRecords, outRecordStatus)
+	}
+	sort.Slice(status.InRecords, func(i, j int) bool {
+		return status.InRecords[i].LogicFaceId < status.InRecords[j].LogicFaceId
+	})
+	sort.Slice(status.OutRecords, func(i, j int) bool {
+		return status.OutRecords[i].LogicFaceId < status.OutRecords[j].LogicFaceId
+	})
+	return status
+}
+
 /////////////////////////////////////////////////////////////////////////////////////////////////////////
 ///// 错误处理
 /////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -46,7 +46,7 @@ func TestInsert(t *testing.T) {
 		PrefixList = append(PrefixList, v.ToString())
 	}
 	fmt.Println(PrefixList)
-	fmt.Println(pit.lpm.table)
+	fmt.Println(pit.Size())
 
 	//测试 正常插入
 	interest.SetName(identifier)
@@ -54,7 +54,7 @@ func TestInsert(t *testing.T) {
 		PrefixList = append(PrefixList, v.ToString())
 	}
 	fmt.Println(PrefixList)
-	fmt.Println(pit.lpm.table)
+	fmt.Println(pit.Size())
 
 }
 
@@ -185,6 +185,36 @@ func TestEraseByPITEntry(t *testing.T) {
 	fmt.Println(pit.Size())
 }
 
+// 过期的旧表项被删除时，不能删掉同名的新表项
+func TestEraseByPITEntry_Replaced(t *testing.T) {
+	pit := CreatePIT()
+	identifier, err := component.CreateIdentifierByString("/min/pku")
+	if err != nil {
+		t.Fatal(err)
+	}
+	interest := &packet.Interest{}
+	interest.SetName(identifier)
+	oldEntry := pit.Insert(interest)
+	data := &packet.Data{}
+	data.SetName(identifier)
+	if pit.FindDataMatches(data) != oldEntry {
+		t.Fatal("data should match the old entry")
+	}
+	newEntry := pit.Insert(interest)
+	if newEntry == oldEntry {
+		t.Fatal("a new entry should be inserted")
+	}
+	if err := pit.EraseByPITEntry(oldEntry); err == nil {
+		t.Error("erase a replaced entry should fail")
+	}
+	if pitEntry, err := pit.Find(interest); err != nil || pitEntry != newEntry {
+		t.Errorf("new entry should be kept, err = %v", err)
+	}
+	if err := pit.EraseByPITEntry(newEntry); err != nil || pit.Size() != 0 {
+		t.Errorf("erase new entry failed, err = %v, size = %d", err, pit.Size())
+	}
+}
+
 func TestEraseByLogicFace(t *testing.T) {
 	pit := CreatePIT()
 	identifier, err := component.CreateIdentifierByString("/min")
@@ -398,3 +428,82 @@ func BenchmarkInsert(b *testing.B) {
 	}
 }
 */
+
+// 创建一个包含 /min/a/1、/min/a/2、/min/b/1、/other 四个表项的 PIT，
+// /min/a/1 有一条流入记录和一条收到了 Nack 的流出记录，/min/a/2 有一条流入记录和一条流出记录，/min/b/1 已被满足
+func newPopulatedPIT(t *testing.T) (*PIT, []*component.Identifier) {
+	pit := CreatePIT()
+	var identifiers []*component.Identifier
+	for _, name := range []string{"/other", "/min/b/1", "/min/a/2", "/min/a/1"} {
+		identifier, err := component.CreateIdentifierByString(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		identifiers = append(identifiers, identifier)
+		interest := &packet.Interest{}
+		interest.SetName(identifier)
+		interest.SetNonce(1234)
+		pitEntry := pit.Insert(interest)
+		switch name {
+		case "/min/a/1":
+			pitEntry.InsertOrUpdateInRecord(&lf.LogicFace{LogicFaceId: 1}, interest)
+			outRecord := pitEntry.InsertOrUpdateOutRecord(&lf.LogicFace{LogicFaceId: 2}, interest)
+			outRecord.NackHeader = &component.NackHeader{}
+		case "/min/a/2":
+			pitEntry.InsertOrUpdateInRecord(&lf.LogicFace{LogicFaceId: 3}, interest)
+			pitEntry.InsertOrUpdateOutRecord(&lf.LogicFace{LogicFaceId: 2}, interest)
+		case "/min/b/1":
+			pitEntry.SetSatisfied(true)
+		}
+	}
+	// 按标识排序：/min/a/1、/min/a/2、/min/b/1、/other
+	return pit, []*component.Identifier{identifiers[3], identifiers[2], identifiers[1], identifiers[0]}
+}
+
+func TestPIT_ListStatus(t *testing.T) {
+	pit, identifiers := newPopulatedPIT(t)
+
+	statusList, total := pit.ListStatus(nil, 0)
+	if total != 4 || len(statusList) != 4 {
+		t.Fatalf("unexpected list result %d/%d", len(statusList), total)
+	}
+	for i, status := range statusList {
+		if status.Name != identifiers[i].ToUri() {
+			t.Errorf("entry %d should be %s, got %s", i, identifiers[i].ToUri(), status.Name)
+		}
+	}
+	if status := statusList[0]; len(status.InRecords) != 1 || status.InRecords[0].LogicFaceId != 1 ||
+		len(status.OutRecords) != 1 || !status.OutRecords[0].Nacked {
+		t.Errorf("unexpected status of %s: %+v", status.Name, status)
+	}
+	if !statusList[2].Satisfied {
+		t.Errorf("%s should be satisfied", statusList[2].Name)
+	}
+
+	// 按前缀过滤并限制返回的数量，Total 仍然是匹配前缀的表项总数
+	prefix, _ := component.CreateIdentifierByString("/min")
+	statusList, total = pit.ListStatus(prefix, 2)
+	if total != 3 || len(statusList) != 2 || statusList[0].Name != identifiers[0].ToUri() ||
+		statusList[1].Name != identifiers[1].ToUri() {
+		t.Errorf("unexpected filtered list result %d/%d", len(statusList), total)
+	}
+	prefix, _ = component.CreateIdentifierByString("/min/c")
+	if statusList, total = pit.ListStatus(prefix, 0); total != 0 || len(statusList) != 0 {
+		t.Errorf("no entry should match /min/c, got %d/%d", len(statusList), total)
+	}
+}
+
+func TestPIT_GetSummary(t *testing.T) {
+	pit, _ := newPopulatedPIT(t)
+
+	summary := pit.GetSummary(nil)
+	if summary.Entries != 4 || summary.Satisfied != 1 || summary.InRecords != 2 || summary.OutRecords != 2 ||
+		summary.Nacked != 1 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	prefix, _ := component.CreateIdentifierByString("/min/a")
+	summary = pit.GetSummary(prefix)
+	if summary.Entries != 2 || summary.Satisfied != 0 || summary.InRecords != 2 || summary.Nacked != 1 {
+		t.Errorf("unexpected summary of /min/a %+v", summary)
+	}
+}
@@ -0,0 +1,619 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 16:20 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	"fmt"
+	common2 "minlib/common"
+	"minlib/component"
+	"mir-go/daemon/common"
+	"mir-go/daemon/lf"
+	"mir-go/daemon/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 路由的来源，同一个前缀上同一个 LogicFace 可以同时存在多个不同来源的路由
+const (
+	RouteOriginApp      uint64 = 0   // 本地应用通过前缀注册添加的路由
+	RouteOriginAutoreg  uint64 = 64  // 自动注册的路由
+	RouteOriginClient   uint64 = 65  // 客户端添加的路由
+	RouteOriginAutoconf uint64 = 66  // 自动配置的路由
+	RouteOriginRouting  uint64 = 128 // 路由协议计算得到的路由
+	RouteOriginPrefix   uint64 = 129 // 上游路由器传播过来的前缀
+	RouteOriginStatic   uint64 = 255 // 静态配置的路由（defaultRoute.xml 或者 mirc fib add）
+)
+
+// 路由的标志位
+const (
+	RouteFlagChildInherit uint64 = 1 // 更长的前缀会继承这条路由
+	RouteFlagCapture      uint64 = 2 // 更长的前缀不再继承更短前缀上的路由
+	RouteFlagPersistent   uint64 = 4 // 路由会被写入路由状态文件，重启之后恢复
+	RouteFlagECMP         uint64 = 8 // 路由对应的下一跳是等价多路径成员
+)
+
+// ribExpiryTick RIB 检查路由是否过期的时间间隔
+const ribExpiryTick = 100 * time.Millisecond
+
+// Route RIB 中的一条路由
+//
+// @Description:
+//  同一个前缀上，LogicFace 和 Origin 都相同的路由只会存在一条，重复注册会更新 Cost、Flags 和过期时间
+//
+type Route struct {
+	LogicFace      *lf.LogicFace      // 下一跳
+	Origin         uint64             // 路由的来源
+	Cost           uint64             // 路由开销
+	Flags          uint64             // 路由的标志位
+	ExpirationTime uint64             // 过期时间（毫秒时间戳），为 0 表示永不过期
+	expiryTimer    *utils.TimerHandle // 过期定时任务的句柄
+}
+
+// IsChildInherit 更长的前缀是否会继承这条路由
+//
+// @Description:
+// @receiver r
+// @return bool
+//
+func (r *Route) IsChildInherit() bool {
+	return r.Flags&RouteFlagChildInherit != 0
+}
+
+// IsCapture 更长的前缀是否不再继承更短前缀上的路由
+//
+// @Description:
+// @receiver r
+// @return bool
+//
+func (r *Route) IsCapture() bool {
+	return r.Flags&RouteFlagCapture != 0
+}
+
+// RIBEntry RIB 表项，保存一个前缀上的所有路由
+//
+// @Description:
+//
+type RIBEntry struct {
+	identifier *component.Identifier // 表项对应的前缀
+	hashedName *HashedName           // 前缀的哈希，用于判断前缀之间的包含关系
+	routes     []*Route              // 前缀上的所有路由
+}
+
+// GetIdentifier 获取表项对应的前缀
+//
+// @Description:
+// @receiver r
+// @return *component.Identifier
+//
+func (r *RIBEntry) GetIdentifier() *component.Identifier {
+	return r.identifier
+}
+
+//
+// @Description: 查找 LogicFace 和 Origin 都相同的路由的下标，找不到返回 -1
+// @receiver r
+// @param logicFaceId
+// @param origin
+// @return int
+//
+func (r *RIBEntry) findRoute(logicFaceId uint64, origin uint64) int {
+	for i, route := range r.routes {
+		if route.LogicFace.LogicFaceId == logicFaceId && route.Origin == origin {
+			return i
+		}
+	}
+	return -1
+}
+
+//
+// @Description: 表项上是否有带 Capture 标志的路由
+// @receiver r
+// @return bool
+//
+func (r *RIBEntry) hasCapture() bool {
+	for _, route := range r.routes {
+		if route.IsCapture() {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteStatus 路由的状态信息，用于在管理模块中展示
+//
+// @Description:
+//
+type RouteStatus struct {
+	LogicFaceId    uint64 // 下一跳 LogicFace 的 ID
+	Origin         uint64 // 路由的来源
+	Cost           uint64 // 路由开销
+	Flags          uint64 // 路由的标志位
+	ExpirationTime uint64 // 过期时间（毫秒时间戳），为 0 表示永不过期
+}
+
+// RIBEntryStatus RIB 表项的状态信息，用于在管理模块中展示
+//
+// @Description:
+//
+type RIBEntryStatus struct {
+	Prefix string         // 前缀
+	Routes []*RouteStatus // 前缀上的所有路由
+}
+
+// RIB 路由信息表
+//
+// @Description:
+//  1. 静态配置、应用注册、路由协议等不同来源的路由都先写入 RIB，再由 RIB 计算出 FIB，不同来源的路由不会互相覆盖；
+//  2. 计算一个前缀的 FIB 表项时，先取该前缀上所有路由（同一个 LogicFace 取优先级最高、开销最小的一条），再从近到远依次继承祖先前缀上
+//     带 ChildInherit 标志的路由（同一个 LogicFace 以更长前缀上的路由为准），遇到带 Capture 标志的前缀就停止继承；
+//  3. RIB 变化时只重新计算受影响的前缀，即发生变化的前缀以及 RIB 中所有以它开头的更长前缀，更长前缀通过 descendants 索引查找；
+//  4. 带过期时间的路由由时间轮管理，过期后自动删除；
+//  5. 下一跳的管理优先级由路由的来源决定，同一个 LogicFace 上有多条路由时取优先级最高、开销最小的一条。
+//
+type RIB struct {
+	lock           sync.Mutex
+	fib            *FIB                            // RIB 计算结果写入的 FIB
+	entries        map[string]*RIBEntry            // 所有 RIB 表项，key 为前缀
+	descendants    map[string]map[string]*RIBEntry // 前缀 => RIB 中所有以它开头的更长前缀的表项，前缀本身不一定有表项
+	timerWheel     *utils.TimerWheel               // 管理路由过期的时间轮
+	version        uint64                          // 版本号，RIB 每次变化都会加一
+	stopChan       chan struct{}                   // 停止后台检查过期路由的协程
+	originPriority map[uint64]uint64               // 路由来源 => 下一跳的管理优先级，没有配置的来源优先级为 0
+}
+
+// CreateRIB 创建一个 RIB，计算结果写入 fib
+//
+// @Description:
+// @param fib
+// @return *RIB
+//
+func CreateRIB(fib *FIB) *RIB {
+	return &RIB{
+		fib:            fib,
+		entries:        make(map[string]*RIBEntry),
+		descendants:    make(map[string]map[string]*RIBEntry),
+		timerWheel:     utils.NewTimerWheel(int64(ribExpiryTick / time.Millisecond)),
+		originPriority: make(map[uint64]uint64),
+	}
+}
+
+// SetOriginPriority 设置某个来源的路由对应的下一跳的管理优先级，数值越小越优先，并重新计算所有 FIB 表项
+//
+// @Description:
+// @receiver r
+// @param origin
+// @param priority
+//
+func (r *RIB) SetOriginPriority(origin uint64, priority uint64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.originPriority[origin] = priority
+	for _, entry := range r.entries {
+		r.replaceNextHops(entry.identifier, r.computeNextHops(entry))
+	}
+}
+
+// Start 启动后台协程，定期删除过期的路由
+//
+// @Description:
+// @receiver r
+//
+func (r *RIB) Start() {
+	r.lock.Lock()
+	if r.stopChan != nil {
+		r.lock.Unlock()
+		return
+	}
+	stopChan := make(chan struct{})
+	r.stopChan = stopChan
+	r.lock.Unlock()
+
+	utils.GoroutineNoPanic(func() {
+		ticker := time.NewTicker(ribExpiryTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.timerWheel.DealEvent()
+			case <-stopChan:
+				return
+			}
+		}
+	})
+}
+
+// Stop 停止后台协程
+//
+// @Description:
+// @receiver r
+//
+func (r *RIB) Stop() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.stopChan != nil {
+		close(r.stopChan)
+		r.stopChan = nil
+	}
+}
+
+// Register 在前缀上添加或者更新一条路由，并重新计算受影响的 FIB 表项
+//
+// @Description:
+//  expirationPeriod 大于 0 时，路由在 expirationPeriod 毫秒之后过期，重复注册会刷新过期时间
+// @receiver r
+// @param identifier	前缀
+// @param logicFace	下一跳
+// @param origin		路由的来源
+// @param cost		路由开销
+// @param flags		路由的标志位
+// @param expirationPeriod	有效期，单位为毫秒，小于等于 0 表示永不过期
+// @return error
+//
+func (r *RIB) Register(identifier *component.Identifier, logicFace *lf.LogicFace, origin uint64, cost uint64,
+	flags uint64, expirationPeriod int64) error {
+	if logicFace == nil {
+		return RIBError{msg: "LogicFace of route must not be nil"}
+	}
+	hashedName := NewHashedName(identifier)
+	key := ribKey(hashedName, hashedName.Len())
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = &RIBEntry{identifier: identifier, hashedName: hashedName}
+		r.addEntry(key, entry)
+	}
+	route := &Route{LogicFace: logicFace, Origin: origin, Cost: cost, Flags: flags}
+	if idx := entry.findRoute(logicFace.LogicFaceId, origin); idx >= 0 {
+		r.timerWheel.CancelEvent(entry.routes[idx].expiryTimer)
+		entry.routes[idx] = route
+	} else {
+		entry.routes = append(entry.routes, route)
+	}
+	if expirationPeriod > 0 {
+		route.ExpirationTime = common.GetCurrentTime() + uint64(expirationPeriod)
+		route.expiryTimer = r.timerWheel.AddTimeoutEvent(expirationPeriod, func() {
+			r.expire(key, route)
+		})
+	}
+	r.version++
+	r.updateFIB(hashedName)
+	return nil
+}
+
+// Unregister 删除前缀上指定 LogicFace 和来源的路由，并重新计算受影响的 FIB 表项
+//
+// @Description:
+// @receiver r
+// @param identifier
+// @param logicFaceId
+// @param origin
+// @return error	路由不存在时返回错误
+//
+func (r *RIB) Unregister(identifier *component.Identifier, logicFaceId uint64, origin uint64) error {
+	hashedName := NewHashedName(identifier)
+	key := ribKey(hashedName, hashedName.Len())
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, ok := r.entries[key]
+	if !ok {
+		return RIBError{msg: "RIB entry " + identifier.ToUri() + " is not existed"}
+	}
+	idx := entry.findRoute(logicFaceId, origin)
+	if idx < 0 {
+		return RIBError{msg: fmt.Sprintf("route of %s via %d with origin %d is not existed", identifier.ToUri(), logicFaceId, origin)}
+	}
+	r.removeRoute(key, entry, idx)
+	r.updateFIB(hashedName)
+	return nil
+}
+
+// RemoveRoutesByFace 删除所有以 logicFaceId 为下一跳的路由，返回删除的路由数，LogicFace 被销毁时调用
+//
+// @Description:
+// @receiver r
+// @param logicFaceId
+// @return uint64
+//
+func (r *RIB) RemoveRoutesByFace(logicFaceId uint64) uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var removed uint64 = 0
+	var changed []*HashedName
+	for key, entry := range r.entries {
+		// 同一个 LogicFace 上可能有多个来源的路由，全部删除
+		routes := entry.routes[:0]
+		for _, route := range entry.routes {
+			if route.LogicFace.LogicFaceId == logicFaceId {
+				r.timerWheel.CancelEvent(route.expiryTimer)
+				removed++
+			} else {
+				routes = append(routes, route)
+			}
+		}
+		if len(routes) == len(entry.routes) {
+			continue
+		}
+		entry.routes = routes
+		if len(routes) == 0 {
+			r.deleteEntry(key, entry)
+		}
+		changed = append(changed, entry.hashedName)
+	}
+	if removed > 0 {
+		r.version++
+	}
+	for _, hashedName := range changed {
+		r.updateFIB(hashedName)
+	}
+	return removed
+}
+
+// ListStatus 获取所有 RIB 表项的状态信息，按前缀排序
+//
+// @Description:
+// @receiver r
+// @return []*RIBEntryStatus
+//
+func (r *RIB) ListStatus() []*RIBEntryStatus {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	statusList := make([]*RIBEntryStatus, 0, len(r.entries))
+	for _, entry := range r.entries {
+		status := &RIBEntryStatus{Prefix: entry.identifier.ToUri()}
+		for _, route := range entry.routes {
+			status.Routes = append(status.Routes, &RouteStatus{
+				LogicFaceId:    route.LogicFace.LogicFaceId,
+				Origin:         route.Origin,
+				Cost:           route.Cost,
+				Flags:          route.Flags,
+				ExpirationTime: route.ExpirationTime,
+			})
+		}
+		statusList = append(statusList, status)
+	}
+	sort.Slice(statusList, func(i, j int) bool {
+		return statusList[i].Prefix < statusList[j].Prefix
+	})
+	return statusList
+}
+
+// Size 返回 RIB 表项数
+//
+// @Description:
+// @receiver r
+// @return int
+//
+func (r *RIB) Size() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.entries)
+}
+
+// GetVersion 获取 RIB 的版本号
+//
+// @Description:
+// @receiver r
+// @return uint64
+//
+func (r *RIB) GetVersion() uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.version
+}
+
+//
+// @Description: 路由过期的回调，路由已经被更新或者删除时不做任何处理
+// @receiver r
+// @param key
+// @param route
+//
+func (r *RIB) expire(key string, route *Route) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, ok := r.entries[key]
+	if !ok {
+		return
+	}
+	for idx, v := range entry.routes {
+		if v == route {
+			common2.LogInfo("route expired: ", entry.identifier.ToUri(), " -> ", route.LogicFace.LogicFaceId)
+			r.removeRoute(key, entry, idx)
+			r.updateFIB(entry.hashedName)
+			return
+		}
+	}
+}
+
+//
+// @Description: 删除表项中下标为 idx 的路由，表项中没有路由时删除表项，调用者需要持有锁
+// @receiver r
+// @param key
+// @param entry
+// @param idx
+//
+func (r *RIB) removeRoute(key string, entry *RIBEntry, idx int) {
+	r.timerWheel.CancelEvent(entry.routes[idx].expiryTimer)
+	entry.routes = append(entry.routes[:idx], entry.routes[idx+1:]...)
+	if len(entry.routes) == 0 {
+		r.deleteEntry(key, entry)
+	}
+	r.version++
+}
+
+//
+// @Description: 添加一个表项，并把它加入所有祖先前缀的 descendants 索引，调用者需要持有锁
+// @receiver r
+// @param key
+// @param entry
+//
+func (r *RIB) addEntry(key string, entry *RIBEntry) {
+	r.entries[key] = entry
+	for n := entry.hashedName.Len() - 1; n >= 0; n-- {
+		ancestorKey := ribKey(entry.hashedName, n)
+		descendants, ok := r.descendants[ancestorKey]
+		if !ok {
+			descendants = make(map[string]*RIBEntry)
+			r.descendants[ancestorKey] = descendants
+		}
+		descendants[key] = entry
+	}
+}
+
+//
+// @Description: 删除一个表项，并把它从所有祖先前缀的 descendants 索引中删除，调用者需要持有锁
+// @receiver r
+// @param key
+// @param entry
+//
+func (r *RIB) deleteEntry(key string, entry *RIBEntry) {
+	delete(r.entries, key)
+	for n := entry.hashedName.Len() - 1; n >= 0; n-- {
+		ancestorKey := ribKey(entry.hashedName, n)
+		descendants := r.descendants[ancestorKey]
+		delete(descendants, key)
+		if len(descendants) == 0 {
+			delete(r.descendants, ancestorKey)
+		}
+	}
+}
+
+//
+// @Description: 重新计算 changed 以及 RIB 中所有以 changed 开头的前缀对应的 FIB 表项，调用者需要持有锁
+// @receiver r
+// @param changed
+//
+func (r *RIB) updateFIB(changed *HashedName) {
+	key := ribKey(changed, changed.Len())
+	if entry, ok := r.entries[key]; ok {
+		r.replaceNextHops(entry.identifier, r.computeNextHops(entry))
+	} else {
+		// 表项已经被删除，删除对应的 FIB 表项
+		r.replaceNextHops(changed.GetIdentifier(), nil)
+	}
+	for _, entry := range r.descendants[key] {
+		r.replaceNextHops(entry.identifier, r.computeNextHops(entry))
+	}
+}
+
+//
+// @Description: 把计算结果写入 FIB，写入失败（例如对应的 FIB 表项是只读的）时只输出日志
+// @receiver r
+// @param identifier
+// @param nextHops
+//
+func (r *RIB) replaceNextHops(identifier *component.Identifier, nextHops []*NextHop) {
+	if err := r.fib.ReplaceNextHops(identifier, nextHops); err != nil {
+		common2.LogWarn("update FIB from RIB failed: ", err)
+	}
+}
+
+//
+// @Description: 计算一个 RIB 表项对应的 FIB 下一跳列表，调用者需要持有锁
+// @receiver r
+// @param entry
+// @return []*NextHop
+//
+func (r *RIB) computeNextHops(entry *RIBEntry) []*NextHop {
+	nextHopMap := make(map[uint64]*NextHop)
+	// 前缀自己的路由，同一个 LogicFace 取优先级最高、开销最小的一条
+	for _, route := range entry.routes {
+		candidate := r.toNextHop(route)
+		if nextHop, ok := nextHopMap[route.LogicFace.LogicFaceId]; !ok || candidate.Less(nextHop) {
+			nextHopMap[route.LogicFace.LogicFaceId] = candidate
+		}
+	}
+	// 从近到远继承祖先前缀上带 ChildInherit 标志的路由
+	if !entry.hasCapture() {
+		for n := entry.hashedName.Len() - 1; n >= 0; n-- {
+			ancestor, ok := r.entries[ribKey(entry.hashedName, n)]
+			if !ok {
+				continue
+			}
+			inherited := make(map[uint64]*NextHop)
+			for _, route := range ancestor.routes {
+				if !route.IsChildInherit() {
+					continue
+				}
+				if _, ok := nextHopMap[route.LogicFace.LogicFaceId]; ok {
+					continue
+				}
+				candidate := r.toNextHop(route)
+				if nextHop, ok := inherited[route.LogicFace.LogicFaceId]; !ok || candidate.Less(nextHop) {
+					inherited[route.LogicFace.LogicFaceId] = candidate
+				}
+			}
+			for logicFaceId, nextHop := range inherited {
+				nextHopMap[logicFaceId] = nextHop
+			}
+			if ancestor.hasCapture() {
+				break
+			}
+		}
+	}
+	nextHops := make([]*NextHop, 0, len(nextHopMap))
+	for _, nextHop := range nextHopMap {
+		nextHops = append(nextHops, nextHop)
+	}
+	return nextHops
+}
+
+//
+// @Description: 把一条路由转换成 FIB 下一跳，调用者需要持有锁
+// @receiver r
+// @param route
+// @return *NextHop
+//
+func (r *RIB) toNextHop(route *Route) *NextHop {
+	return &NextHop{
+		LogicFace: route.LogicFace,
+		Cost:      route.Cost,
+		Priority:  r.originPriority[route.Origin],
+		ECMP:      route.Flags&RouteFlagECMP != 0,
+	}
+}
+
+//
+// @Description: 计算 name 的前 n 个组件构成的前缀在 RIB 中的 key
+// @param name
+// @param n
+// @return string
+//
+func ribKey(name *HashedName, n int) string {
+	return "/" + strings.Join(name.components[:n], "/")
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type RIBError struct {
+	msg string
+}
+
+func (r RIBError) Error() string {
+	return fmt.Sprintf("RIBError: %s", r.msg)
+}
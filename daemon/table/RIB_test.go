@@ -0,0 +1,197 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 17:10 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	"mir-go/daemon/lf"
+	"testing"
+	"time"
+)
+
+// fibNextHops 返回前缀对应的 FIB 表项中 LogicFaceId 到 Cost 的映射，表项不存在时返回 nil
+func fibNextHops(t *testing.T, fib *FIB, prefix string) map[uint64]uint64 {
+	fibEntry := fib.FindExactMatch(mustCreateIdentifier(t, prefix))
+	if fibEntry == nil {
+		return nil
+	}
+	nextHops := make(map[uint64]uint64)
+	for _, nextHop := range fibEntry.GetNextHops() {
+		nextHops[nextHop.LogicFace.LogicFaceId] = nextHop.Cost
+	}
+	return nextHops
+}
+
+func TestRIB_OriginsAndInherit(t *testing.T) {
+	fib := CreateFIB()
+	rib := CreateRIB(fib)
+	face1, face2, face3 := &lf.LogicFace{LogicFaceId: 1}, &lf.LogicFace{LogicFaceId: 2}, &lf.LogicFace{LogicFaceId: 3}
+
+	// 不同来源的路由不会互相覆盖，同一个 LogicFace 取最小开销
+	_ = rib.Register(mustCreateIdentifier(t, "/min"), face1, RouteOriginStatic, 10, RouteFlagChildInherit, 0)
+	_ = rib.Register(mustCreateIdentifier(t, "/min"), face1, RouteOriginRouting, 5, RouteFlagChildInherit, 0)
+	_ = rib.Register(mustCreateIdentifier(t, "/min"), face2, RouteOriginApp, 0, 0, 0)
+	if nextHops := fibNextHops(t, fib, "/min"); len(nextHops) != 2 || nextHops[1] != 5 || nextHops[2] != 0 {
+		t.Fatalf("unexpected next hops of /min: %v", nextHops)
+	}
+
+	// 更长的前缀只继承带 ChildInherit 标志的路由，自己的路由优先
+	_ = rib.Register(mustCreateIdentifier(t, "/min/pku"), face3, RouteOriginApp, 1, RouteFlagChildInherit, 0)
+	if nextHops := fibNextHops(t, fib, "/min/pku"); len(nextHops) != 2 || nextHops[1] != 5 || nextHops[3] != 1 {
+		t.Fatalf("unexpected next hops of /min/pku: %v", nextHops)
+	}
+
+	// 删除一个来源的路由之后，另一个来源的路由仍然生效，并且增量更新到更长的前缀
+	if err := rib.Unregister(mustCreateIdentifier(t, "/min"), 1, RouteOriginRouting); err != nil {
+		t.Fatal(err)
+	}
+	if nextHops := fibNextHops(t, fib, "/min/pku"); nextHops[1] != 10 {
+		t.Errorf("/min/pku should inherit static route of /min, got %v", nextHops)
+	}
+	if err := rib.Unregister(mustCreateIdentifier(t, "/min"), 1, RouteOriginRouting); err == nil {
+		t.Errorf("unregister a not existed route should fail")
+	}
+
+	// Capture 阻止继承
+	_ = rib.Register(mustCreateIdentifier(t, "/min/pku"), face3, RouteOriginApp, 1, RouteFlagCapture, 0)
+	if nextHops := fibNextHops(t, fib, "/min/pku"); len(nextHops) != 1 || nextHops[3] != 1 {
+		t.Errorf("/min/pku with capture flag should not inherit, got %v", nextHops)
+	}
+
+	// LogicFace 被销毁时删除它的所有路由，只剩它的路由的前缀对应的 FIB 表项也被删除
+	if removed := rib.RemoveRoutesByFace(3); removed != 1 {
+		t.Errorf("should remove 1 route, removed %d", removed)
+	}
+	if nextHops := fibNextHops(t, fib, "/min/pku"); nextHops != nil {
+		t.Errorf("FIB entry of /min/pku should be erased, got %v", nextHops)
+	}
+	if rib.Size() != 1 {
+		t.Errorf("RIB should have 1 entry, size = %d", rib.Size())
+	}
+}
+
+func TestRIB_Expire(t *testing.T) {
+	fib := CreateFIB()
+	rib := CreateRIB(fib)
+	rib.Start()
+	defer rib.Stop()
+	face := &lf.LogicFace{LogicFaceId: 1}
+
+	_ = rib.Register(mustCreateIdentifier(t, "/min/expire"), face, RouteOriginApp, 0, RouteFlagChildInherit, 200)
+	_ = rib.Register(mustCreateIdentifier(t, "/min/keep"), face, RouteOriginApp, 0, RouteFlagChildInherit, 0)
+	if fibNextHops(t, fib, "/min/expire") == nil {
+		t.Fatalf("route of /min/expire should be installed")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for fibNextHops(t, fib, "/min/expire") != nil && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if fibNextHops(t, fib, "/min/expire") != nil {
+		t.Errorf("expired route should be removed")
+	}
+	if fibNextHops(t, fib, "/min/keep") == nil {
+		t.Errorf("route without expiration should be kept")
+	}
+}
+
+func TestRIB_PriorityAndECMP(t *testing.T) {
+	fib := CreateFIB()
+	rib := CreateRIB(fib)
+	face1, face2, face3, face4 := &lf.LogicFace{LogicFaceId: 1}, &lf.LogicFace{LogicFaceId: 2},
+		&lf.LogicFace{LogicFaceId: 3}, &lf.LogicFace{LogicFaceId: 4}
+
+	// 开销相同的下一跳按 LogicFaceId 排序，选择结果和 map 的遍历顺序无关
+	prefix := mustCreateIdentifier(t, "/min")
+	_ = rib.Register(prefix, face3, RouteOriginRouting, 10, RouteFlagChildInherit, 0)
+	_ = rib.Register(prefix, face2, RouteOriginRouting, 10, RouteFlagChildInherit, 0)
+	_ = rib.Register(prefix, face1, RouteOriginStatic, 20, RouteFlagChildInherit, 0)
+	for i := 0; i < 10; i++ {
+		nextHops := fib.FindExactMatch(prefix).GetNextHops()
+		if nextHops[0].LogicFace.LogicFaceId != 2 || nextHops[1].LogicFace.LogicFaceId != 3 || nextHops[2].LogicFace.LogicFaceId != 1 {
+			t.Fatalf("next hops should be sorted by cost and logic face id")
+		}
+		if selected := SelectNextHop(nextHops, 0, "/min/a"); selected.LogicFace.LogicFaceId != 2 {
+			t.Fatalf("tie should be broken by the smallest logic face id, got %d", selected.LogicFace.LogicFaceId)
+		}
+	}
+
+	// 管理优先级优先于开销
+	rib.SetOriginPriority(RouteOriginRouting, 10)
+	nextHops := fib.FindExactMatch(prefix).GetNextHops()
+	if nextHops[0].LogicFace.LogicFaceId != 1 || nextHops[0].Priority != 0 || nextHops[1].Priority != 10 {
+		t.Fatalf("static route with higher priority should come first")
+	}
+	if selected := SelectNextHop(nextHops, 1, "/min/a"); selected.LogicFace.LogicFaceId != 2 {
+		t.Fatalf("excluded logic face should be skipped, got %d", selected.LogicFace.LogicFaceId)
+	}
+
+	// 等价的 ECMP 成员之间按标识分担流量，同一个标识总是选到同一个下一跳
+	ecmpPrefix := mustCreateIdentifier(t, "/ecmp")
+	_ = rib.Register(ecmpPrefix, face1, RouteOriginRouting, 5, RouteFlagECMP, 0)
+	_ = rib.Register(ecmpPrefix, face2, RouteOriginRouting, 5, RouteFlagECMP, 0)
+	_ = rib.Register(ecmpPrefix, face3, RouteOriginRouting, 5, 0, 0)
+	_ = rib.Register(ecmpPrefix, face4, RouteOriginRouting, 6, RouteFlagECMP, 0)
+	nextHops = fib.FindExactMatch(ecmpPrefix).GetNextHops()
+	selected := make(map[uint64]int)
+	for i := 0; i < 64; i++ {
+		key := "/ecmp/" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		first := SelectNextHop(nextHops, 0, key)
+		if again := SelectNextHop(nextHops, 0, key); again != first {
+			t.Fatalf("the same flow key should select the same next hop")
+		}
+		selected[first.LogicFace.LogicFaceId]++
+	}
+	if len(selected) != 2 || selected[1] == 0 || selected[2] == 0 {
+		t.Fatalf("traffic should be shared by equal-cost ECMP members 1 and 2, got %v", selected)
+	}
+	if selected := SelectNextHop(nextHops, 1, "/ecmp/a"); selected.LogicFace.LogicFaceId != 2 {
+		t.Fatalf("remaining ECMP member should be selected when the other one is excluded, got %d", selected.LogicFace.LogicFaceId)
+	}
+}
+
+// 更长前缀通过 descendants 索引增量更新，中间前缀没有表项时也能找到，表项删除后索引被清理
+func TestRIB_Descendants(t *testing.T) {
+	fib := CreateFIB()
+	rib := CreateRIB(fib)
+	face1, face2 := &lf.LogicFace{LogicFaceId: 1}, &lf.LogicFace{LogicFaceId: 2}
+
+	_ = rib.Register(mustCreateIdentifier(t, "/min/pku/r1"), face2, RouteOriginApp, 0, 0, 0)
+	_ = rib.Register(mustCreateIdentifier(t, "/edu/pku"), face2, RouteOriginApp, 0, 0, 0)
+	_ = rib.Register(mustCreateIdentifier(t, "/min"), face1, RouteOriginStatic, 10, RouteFlagChildInherit, 0)
+	if nextHops := fibNextHops(t, fib, "/min/pku/r1"); len(nextHops) != 2 || nextHops[1] != 10 {
+		t.Fatalf("/min/pku/r1 should inherit route of /min, got %v", nextHops)
+	}
+	if nextHops := fibNextHops(t, fib, "/edu/pku"); len(nextHops) != 1 {
+		t.Fatalf("/edu/pku should not be affected by /min, got %v", nextHops)
+	}
+	if len(rib.descendants["/min/pku"]) != 1 || len(rib.descendants["/"]) != 3 {
+		t.Fatalf("unexpected descendants index %v", rib.descendants)
+	}
+
+	_ = rib.Unregister(mustCreateIdentifier(t, "/min"), 1, RouteOriginStatic)
+	if nextHops := fibNextHops(t, fib, "/min/pku/r1"); len(nextHops) != 1 || nextHops[2] != 0 {
+		t.Fatalf("/min/pku/r1 should lose the inherited route, got %v", nextHops)
+	}
+	rib.RemoveRoutesByFace(2)
+	if rib.Size() != 0 || len(rib.descendants) != 0 {
+		t.Fatalf("descendants index should be empty, got %v", rib.descendants)
+	}
+}
@@ -23,97 +23,87 @@
 package table
 
 import (
-	"github.com/sirupsen/logrus"
-	common2 "minlib/common"
 	"minlib/component"
 )
 
 type StrategyTable struct {
-	lpm *LpmMatcher //最长前缀匹配器
+	nameTree *NameTree // 名字树
 }
 
 func CreateStrategyTable() *StrategyTable {
 	var s = &StrategyTable{}
-	s.lpm = &LpmMatcher{} //初始化
-	s.lpm.Create()        //初始化锁
+	s.Init()
 	return s
 }
 
 func (s *StrategyTable) Init() {
-	s.lpm = &LpmMatcher{} //初始化
-	s.lpm.Create()        //初始化锁
+	s.InitWithNameTree(CreateNameTree())
+}
+
+// InitWithNameTree 使用指定的名字树初始化策略表，用于和其它表共用同一棵名字树
+func (s *StrategyTable) InitWithNameTree(nameTree *NameTree) {
+	s.nameTree = nameTree
 }
 
 // Size 获得StrategyTable的大小
 func (s *StrategyTable) Size() uint64 {
-	return s.lpm.TraverseFunc(func(val interface{}) uint64 {
-		if _, ok := val.(*StrategyTableEntry); ok {
-			return 1
-		} else {
-			common2.LogErrorWithFields(logrus.Fields{
-				"value": val,
-			}, "StrategyTableEntry transform fail")
+	var size uint64 = 0
+	s.nameTree.Range(func(entry *NameTreeEntry) bool {
+		if entry.strategyEntry != nil {
+			size++
 		}
-		return 0
+		return true
 	})
+	return size
 }
 
 // SetDefaultStrategy 为所有的前缀设置一个默认的策略
 func (s *StrategyTable) SetDefaultStrategy(strategyName string) {
-	s.lpm.TraverseFunc(func(val interface{}) uint64 {
-		if strategyTableEntry, ok := val.(*StrategyTableEntry); ok {
-			strategyTableEntry.StrategyName = strategyName
-			return 1
-		} else {
-			common2.LogErrorWithFields(logrus.Fields{
-				"value": val,
-			}, "StrategyTableEntry transform fail")
+	s.nameTree.Range(func(entry *NameTreeEntry) bool {
+		if entry.strategyEntry != nil {
+			entry.strategyEntry.SetStrategyName(strategyName)
 		}
-		return 0
+		return true
 	})
 }
 
 // Insert 往策略表中插入一个策略
 func (s *StrategyTable) Insert(identifier *component.Identifier, strategyName string, istrategy IStrategy) *StrategyTableEntry {
-	var PrefixList []string
-	for _, v := range identifier.GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
-	}
-	val := s.lpm.AddOrUpdate(PrefixList, nil, func(val interface{}) interface{} {
-		// not ok 那么val == nil 存入标识
-		if _, ok := (val).(*StrategyTableEntry); !ok {
-			// 存入的表项 不是 *StrategyTableEntry类型 或者 为nil
-			strategyTableEntry := CreateStrategyTableEntry()
-			val = strategyTableEntry
+	var strategyTableEntry *StrategyTableEntry
+	s.nameTree.FindOrInsert(NewHashedName(identifier), func(entry *NameTreeEntry) {
+		if entry.strategyEntry == nil {
+			entry.strategyEntry = CreateStrategyTableEntry()
 		}
-		entry := (val).(*StrategyTableEntry)
-		entry.StrategyName = strategyName
-		entry.IStrategy = istrategy
-		return entry
+		strategyTableEntry = entry.strategyEntry
+		strategyTableEntry.Identifier = identifier
+		strategyTableEntry.StrategyName = strategyName
+		strategyTableEntry.IStrategy = istrategy
 	})
-	return val.(*StrategyTableEntry)
-
+	return strategyTableEntry
 }
 
 // Erase 通过前缀删除策略表中策略
 func (s *StrategyTable) Erase(identifier *component.Identifier) error {
-	var PrefixList []string
-	for _, v := range identifier.GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
+	erased := false
+	s.nameTree.Modify(NewHashedName(identifier), func(entry *NameTreeEntry) {
+		if entry.strategyEntry != nil {
+			entry.strategyEntry = nil
+			erased = true
+		}
+	})
+	if !erased {
+		return createNodeErrorByType(DataNotExistedError)
 	}
-	return s.lpm.Delete(PrefixList)
+	return nil
 }
 
 // FindEffectiveStrategyEntry 查询和一个指定的名称前缀匹配的策略条目 最长前缀匹配
 func (s *StrategyTable) FindEffectiveStrategyEntry(identifier *component.Identifier) *StrategyTableEntry {
-	var PrefixList []string
-	for _, v := range identifier.GetComponents() {
-		PrefixList = append(PrefixList, v.ToString())
-	}
-	if v, ok := s.lpm.FindLongestPrefixMatch(PrefixList); ok {
-		if strategyTableEntry, ok := v.(*StrategyTableEntry); ok {
-			return strategyTableEntry
-		}
+	entry := s.nameTree.FindLongestPrefixMatch(NewHashedName(identifier), func(entry *NameTreeEntry) bool {
+		return entry.strategyEntry != nil
+	})
+	if entry != nil {
+		return entry.strategyEntry
 	}
 	return nil
 }
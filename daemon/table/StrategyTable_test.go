@@ -110,7 +110,7 @@ func TestStrategyTableInsert(t *testing.T) {
 		PrefixList = append(PrefixList, v.ToString())
 	}
 	fmt.Println(PrefixList)
-	fmt.Println(strategyTable.lpm.table)
+	fmt.Println(strategyTable.Size())
 
 	//测试 正常插入
 	strategyTable.Insert(identifier, strategyName, istrategy)
@@ -118,7 +118,7 @@ func TestStrategyTableInsert(t *testing.T) {
 		PrefixList = append(PrefixList, v.ToString())
 	}
 	fmt.Println(PrefixList)
-	fmt.Println(strategyTable.lpm.table)
+	fmt.Println(strategyTable.Size())
 }
 
 //这个只会写成功的测试用例，没测失败的情况
@@ -0,0 +1,139 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 18:50 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	"fmt"
+	"minlib/component"
+	"mir-go/daemon/lf"
+)
+
+// TagConstraintTable 下一跳标签约束表
+//
+// @Description:
+//	1.每个前缀可以绑定一个标签表达式，名字根据最长前缀匹配到对应的表达式，转发策略只会把包转发给标签满足表达式的下一跳
+//	2.例如 /internal 绑定 role!=wan 之后，/internal 下的包永远不会从打了 role=wan 标签的 LogicFace 转发出去
+//	3.约束只在启动时从配置文件加载，运行时只读；nil 的约束表表示没有任何约束
+//
+type TagConstraintTable struct {
+	matcher     *LpmMatcher                  // 前缀 => 标签表达式 的最长前缀匹配器
+	constraints map[string]*lf.TagExpression // 前缀 => 标签表达式
+}
+
+// CreateTagConstraintTable 新建一个下一跳标签约束表
+//
+// @Description:
+// @return *TagConstraintTable
+//
+func CreateTagConstraintTable() *TagConstraintTable {
+	t := &TagConstraintTable{
+		matcher:     new(LpmMatcher),
+		constraints: make(map[string]*lf.TagExpression),
+	}
+	t.matcher.Create()
+	return t
+}
+
+// SetConstraint 给前缀绑定一个标签表达式
+//
+// @Description:
+// @receiver t
+// @param prefix
+// @param expression
+// @return error
+//
+func (t *TagConstraintTable) SetConstraint(prefix string, expression string) error {
+	identifier, err := component.CreateIdentifierByString(prefix)
+	if err != nil {
+		return err
+	}
+	tagExpression, err := lf.ParseTagExpression(expression)
+	if err != nil {
+		return TagConstraintTableError{msg: "invalid tag constraint for " + prefix + ": " + err.Error()}
+	}
+	t.matcher.AddOrUpdate(identifierToPrefixList(identifier), tagExpression, nil)
+	t.constraints[identifier.ToUri()] = tagExpression
+	return nil
+}
+
+// FindConstraint 获取名字最长前缀匹配到的标签表达式
+//
+// @Description:
+// @receiver t
+// @param identifier
+// @return *lf.TagExpression	没有匹配到时返回 nil
+//
+func (t *TagConstraintTable) FindConstraint(identifier *component.Identifier) *lf.TagExpression {
+	if t == nil || len(t.constraints) == 0 {
+		return nil
+	}
+	if val, ok := t.matcher.FindLongestPrefixMatch(identifierToPrefixList(identifier)); ok {
+		if tagExpression, ok := val.(*lf.TagExpression); ok {
+			return tagExpression
+		}
+	}
+	return nil
+}
+
+// FilterNextHops 过滤掉标签不满足名字对应的标签表达式的下一跳，保持原有的顺序
+//
+// @Description:
+// @receiver t
+// @param identifier
+// @param nextHops
+// @return []*NextHop
+//
+func (t *TagConstraintTable) FilterNextHops(identifier *component.Identifier, nextHops []*NextHop) []*NextHop {
+	tagExpression := t.FindConstraint(identifier)
+	if tagExpression == nil {
+		return nextHops
+	}
+	filtered := make([]*NextHop, 0, len(nextHops))
+	for _, nextHop := range nextHops {
+		if nextHop.LogicFace.MatchTags(tagExpression) {
+			filtered = append(filtered, nextHop)
+		}
+	}
+	return filtered
+}
+
+// Size 获取绑定了标签表达式的前缀数
+//
+// @Description:
+// @receiver t
+// @return int
+//
+func (t *TagConstraintTable) Size() int {
+	return len(t.constraints)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type TagConstraintTableError struct {
+	msg string
+}
+
+func (t TagConstraintTableError) Error() string {
+	return fmt.Sprintf("TagConstraintTableError: %s", t.msg)
+}
@@ -0,0 +1,72 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 19:10 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	"mir-go/daemon/lf"
+	"testing"
+)
+
+func TestTagConstraintTable_FilterNextHops(t *testing.T) {
+	wanFace, lanFace, untaggedFace := &lf.LogicFace{LogicFaceId: 1}, &lf.LogicFace{LogicFaceId: 2}, &lf.LogicFace{LogicFaceId: 3}
+	wanFace.SetTags(map[string]string{"role": "wan"})
+	lanFace.SetTags(map[string]string{"role": "lan"})
+	nextHops := []*NextHop{{LogicFace: wanFace}, {LogicFace: lanFace}, {LogicFace: untaggedFace}}
+
+	constraints := CreateTagConstraintTable()
+	if err := constraints.SetConstraint("/internal", "role!=wan"); err != nil {
+		t.Fatal(err)
+	}
+	if err := constraints.SetConstraint("/internal/lab", "role=lan"); err != nil {
+		t.Fatal(err)
+	}
+	if err := constraints.SetConstraint("/bad", "role="); err == nil {
+		t.Fatal("invalid expression should be rejected")
+	}
+
+	filteredIds := func(prefix string) []uint64 {
+		var ids []uint64
+		for _, nextHop := range constraints.FilterNextHops(mustCreateIdentifier(t, prefix), nextHops) {
+			ids = append(ids, nextHop.LogicFace.LogicFaceId)
+		}
+		return ids
+	}
+
+	// 没有约束的前缀不过滤
+	if ids := filteredIds("/public/video"); len(ids) != 3 {
+		t.Fatalf("unexpected next hops of /public/video: %v", ids)
+	}
+	// /internal 下的包不能从 WAN 转发出去
+	if ids := filteredIds("/internal/doc"); len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+		t.Fatalf("unexpected next hops of /internal/doc: %v", ids)
+	}
+	// 最长前缀匹配，/internal/lab 只能从 LAN 转发出去
+	if ids := filteredIds("/internal/lab/data"); len(ids) != 1 || ids[0] != 2 {
+		t.Fatalf("unexpected next hops of /internal/lab/data: %v", ids)
+	}
+
+	// nil 的约束表不过滤
+	var empty *TagConstraintTable
+	if filtered := empty.FilterNextHops(mustCreateIdentifier(t, "/internal"), nextHops); len(filtered) != 3 {
+		t.Fatalf("nil constraint table should not filter next hops: %v", filtered)
+	}
+}
@@ -22,16 +22,28 @@
 package table
 
 import (
+	"fmt"
+	"minlib/component"
 	"minlib/packet"
 	"mir-go/daemon/common"
+	"time"
 )
 
 // UniversalCS 基于Hash表实现的 ContentStore
 //
 // @Description:
+//  UniversalCS 由一个默认分区和若干个命名分区组成，每个命名分区绑定一个或多个前缀，数据包根据名字最长前缀匹配到对应的分区，
+//  没有匹配到任何分区前缀的数据包使用默认分区
 //
 type UniversalCS struct {
-	csPolicy ICSPolicy
+	defaultPartition *CSPartition            // 默认分区
+	partitions       []*CSPartition          // 所有的命名分区
+	matcher          *LpmMatcher             // 前缀 => 分区 的最长前缀匹配器
+	boundPrefixes    map[string]*CSPartition // 已经绑定到分区的前缀
+	defaultMaxAge    int64                   // 默认的最大保留时间，单位为秒，0 表示不清理
+	maxAgeMatcher    *LpmMatcher             // 前缀 => 最大保留时间 的最长前缀匹配器
+	maxAgePrefixNum  int                     // 单独配置了最大保留时间的前缀数
+	sweepCursor      int                     // 下一次后台清理从哪个分区开始
 }
 
 // NewUniversalCS 新建一个 UniversalCS
@@ -53,14 +65,170 @@ func NewUniversalCS(config *common.MIRConfig) (*UniversalCS, error) {
 // @return error
 //
 func (h *UniversalCS) Init(config *common.MIRConfig) error {
-	if policy, err := NewUniversalCSPolicy(config.TableConfig.CSSize, config.TableConfig.CSReplaceStrategy); err != nil {
+	if partition, err := NewCSPartition(DefaultCSPartitionName, nil, config.TableConfig.CSSize,
+		config.TableConfig.CSReplaceStrategy); err != nil {
 		return err
 	} else {
-		h.csPolicy = policy
+		h.defaultPartition = partition
+	}
+
+	h.partitions = make([]*CSPartition, 0, len(config.TableConfig.CSPartitionConfigs))
+	h.matcher = new(LpmMatcher)
+	h.matcher.Create()
+	h.boundPrefixes = make(map[string]*CSPartition)
+	for _, partitionConfig := range config.TableConfig.CSPartitionConfigs {
+		if err := h.AddPartition(partitionConfig.Name, partitionConfig.Prefixes, partitionConfig.CSSize,
+			partitionConfig.CSReplaceStrategy); err != nil {
+			return err
+		}
+	}
+
+	// 加载最大保留时间配置
+	h.defaultMaxAge = int64(config.TableConfig.CSMaxAge)
+	h.maxAgeMatcher = new(LpmMatcher)
+	h.maxAgeMatcher.Create()
+	for prefix, maxAge := range config.TableConfig.CSMaxAgeConfigs {
+		if err := h.SetMaxAge(prefix, int64(maxAge)); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// SetMaxAge 设置某个前缀下的缓存变旧之后的最大保留时间
+//
+// @Description:
+//  名字根据最长前缀匹配到对应的最大保留时间，匹配不到则使用 [Table] 中配置的 CSMaxAge
+// @receiver h
+// @param prefix
+// @param maxAge	最大保留时间，单位为秒，0 表示该前缀下的缓存不清理
+// @return error
+//
+func (h *UniversalCS) SetMaxAge(prefix string, maxAge int64) error {
+	identifier, err := component.CreateIdentifierByString(prefix)
+	if err != nil {
+		return err
+	}
+	if maxAge < 0 {
+		return UniversalCSError{msg: "Max age of prefix " + identifier.ToUri() + " must not be negative"}
+	}
+	h.maxAgeMatcher.AddOrUpdate(identifierToPrefixList(identifier), maxAge, nil)
+	h.maxAgePrefixNum++
+	return nil
+}
+
+// getMaxAge 获取某个名字的缓存变旧之后的最大保留时间
+//
+// @Description:
+// @receiver h
+// @param identifier
+// @return int64
+//
+func (h *UniversalCS) getMaxAge(identifier *component.Identifier) int64 {
+	if h.maxAgePrefixNum == 0 {
+		return h.defaultMaxAge
+	}
+	if val, ok := h.maxAgeMatcher.FindLongestPrefixMatch(identifierToPrefixList(identifier)); ok {
+		if maxAge, ok := val.(int64); ok {
+			return maxAge
+		}
+	}
+	return h.defaultMaxAge
+}
+
+// Sweep 检查最多 batchSize 个表项，删除变旧之后超过最大保留时间的表项
+//
+// @Description:
+//  所有分区共享 batchSize 个检查额度，每次调用从不同的分区开始检查，避免某个大分区一直占用全部额度
+// @receiver h
+// @param batchSize
+// @return int	删除的表项数
+//
+func (h *UniversalCS) Sweep(batchSize int) int {
+	if h.defaultMaxAge <= 0 && h.maxAgePrefixNum == 0 {
+		// 没有配置任何最大保留时间，不需要清理
+		return 0
+	}
+	partitions := make([]*CSPartition, 0, len(h.partitions)+1)
+	partitions = append(partitions, h.defaultPartition)
+	partitions = append(partitions, h.partitions...)
+
+	now := time.Now().Unix()
+	removed := 0
+	for i := 0; i < len(partitions) && batchSize > 0; i++ {
+		partition := partitions[(h.sweepCursor+i)%len(partitions)]
+		checked, n := partition.Sweep(batchSize, now, h.getMaxAge)
+		batchSize -= checked
+		removed += n
+	}
+	h.sweepCursor = (h.sweepCursor + 1) % len(partitions)
+	return removed
+}
+
+// AddPartition 新增一个分区，并将分区绑定到指定的前缀上
+//
+// @Description:
+//  同一个前缀只能绑定到一个分区上，分区名也不能重复
+// @receiver h
+// @param name
+// @param prefixes
+// @param capacity
+// @param replaceStrategy
+// @return error
+//
+func (h *UniversalCS) AddPartition(name string, prefixes []string, capacity int, replaceStrategy string) error {
+	if name == DefaultCSPartitionName {
+		return UniversalCSError{msg: "Partition name " + name + " is reserved"}
+	}
+	for _, partition := range h.partitions {
+		if partition.name == name {
+			return UniversalCSError{msg: "Duplicate partition name: " + name}
+		}
+	}
+
+	identifiers := make([]*component.Identifier, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		identifier, err := component.CreateIdentifierByString(prefix)
+		if err != nil {
+			return err
+		}
+		if _, ok := h.boundPrefixes[identifier.ToUri()]; ok {
+			return UniversalCSError{msg: "Prefix " + identifier.ToUri() + " is already bound to another partition"}
+		}
+		identifiers = append(identifiers, identifier)
+	}
+
+	partition, err := NewCSPartition(name, identifiers, capacity, replaceStrategy)
+	if err != nil {
+		return err
+	}
+	for _, identifier := range identifiers {
+		h.matcher.AddOrUpdate(identifierToPrefixList(identifier), partition, nil)
+		h.boundPrefixes[identifier.ToUri()] = partition
+	}
+	h.partitions = append(h.partitions, partition)
+	return nil
+}
+
+// selectPartition 根据名字选择对应的分区，最长前缀匹配，匹配不到则返回默认分区
+//
+// @Description:
+// @receiver h
+// @param identifier
+// @return *CSPartition
+//
+func (h *UniversalCS) selectPartition(identifier *component.Identifier) *CSPartition {
+	if len(h.partitions) == 0 {
+		return h.defaultPartition
+	}
+	if val, ok := h.matcher.FindLongestPrefixMatch(identifierToPrefixList(identifier)); ok {
+		if partition, ok := val.(*CSPartition); ok {
+			return partition
+		}
+	}
+	return h.defaultPartition
+}
+
 // Size 返回已缓存的数据包的数量
 //
 // @Description:
@@ -68,7 +236,11 @@ func (h *UniversalCS) Init(config *common.MIRConfig) error {
 // @return int
 //
 func (h *UniversalCS) Size() int {
-	return h.csPolicy.Size()
+	size := h.defaultPartition.Size()
+	for _, partition := range h.partitions {
+		size += partition.Size()
+	}
+	return size
 }
 
 // Find 根据传入的 Interest 查询CS表中是否缓存有与之匹配的 data
@@ -82,7 +254,7 @@ func (h *UniversalCS) Size() int {
 // @return *CSEntry
 //
 func (h *UniversalCS) Find(interest *packet.Interest) (*CSEntry, error) {
-	return h.csPolicy.Find(interest)
+	return h.selectPartition(interest.GetName()).Find(interest)
 }
 
 // Insert 将传入的 data 缓存到CS当中
@@ -93,5 +265,46 @@ func (h *UniversalCS) Find(interest *packet.Interest) (*CSEntry, error) {
 // @return *CSEntry
 //
 func (h *UniversalCS) Insert(data *packet.Data) (*CSEntry, error) {
-	return h.csPolicy.Insert(data)
+	return h.selectPartition(data.GetName()).Insert(data)
+}
+
+// GetPartitionStatus 获取所有分区的状态信息，第一项为默认分区
+//
+// @Description:
+// @receiver h
+// @return []*CSPartitionStatus
+//
+func (h *UniversalCS) GetPartitionStatus() []*CSPartitionStatus {
+	statusList := make([]*CSPartitionStatus, 0, len(h.partitions)+1)
+	statusList = append(statusList, h.defaultPartition.Status())
+	for _, partition := range h.partitions {
+		statusList = append(statusList, partition.Status())
+	}
+	return statusList
+}
+
+// identifierToPrefixList 将标识转换成最长前缀匹配器使用的字符串数组
+//
+// @Description:
+// @param identifier
+// @return []string
+//
+func identifierToPrefixList(identifier *component.Identifier) []string {
+	var prefixList []string
+	for _, v := range identifier.GetComponents() {
+		prefixList = append(prefixList, v.ToString())
+	}
+	return prefixList
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type UniversalCSError struct {
+	msg string
+}
+
+func (u UniversalCSError) Error() string {
+	return fmt.Sprintf("UniversalCSError: %s", u.msg)
 }
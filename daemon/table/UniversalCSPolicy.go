@@ -26,6 +26,7 @@ import (
 	"github.com/bluele/gcache"
 	"minlib/packet"
 	"strings"
+	"sync"
 )
 
 // UniversalCSPolicy 统一的缓存策略实现，基于gcache实现了LFU, LRU and ARC缓存替换策略
@@ -34,6 +35,7 @@ import (
 //
 type UniversalCSPolicy struct {
 	cache gcache.Cache
+	lock  sync.Mutex // 保证插入和按条目删除时先查询再修改的操作是原子的
 }
 
 // NewUniversalCSPolicy 新建一个 UniversalCSPolicy
@@ -79,6 +81,8 @@ func (L *UniversalCSPolicy) Init(capacity int, cacheType string) error {
 //
 func (L *UniversalCSPolicy) Insert(data *packet.Data) (*CSEntry, error) {
 	key := data.GetName().ToUri()
+	L.lock.Lock()
+	defer L.lock.Unlock()
 	if item, err := L.cache.Get(key); err != nil {
 		// 不存在，则构建一个 CSEntry 插入
 		csEntry := NewCSEntry(data)
@@ -120,6 +124,46 @@ func (L *UniversalCSPolicy) Size() int {
 	return L.cache.Len(false)
 }
 
+// Entries 返回当前缓存的所有CS条目的快照
+//
+// @Description:
+//  使用 GetALL 获取快照，不会改变 LRU 的访问顺序和 LFU 的访问次数
+// @return []*CSEntry
+//
+func (L *UniversalCSPolicy) Entries() []*CSEntry {
+	items := L.cache.GetALL(false)
+	entries := make([]*CSEntry, 0, len(items))
+	for _, item := range items {
+		if csEntry, ok := item.(*CSEntry); ok {
+			entries = append(entries, csEntry)
+		}
+	}
+	return entries
+}
+
+// Remove 从缓存中删除一个CS条目
+//
+// @Description:
+//  缓存中同名的条目可能已经被替换成新的数据包，只有仍然是 csEntry 本身并且通过 canRemove 检查时才删除
+// @param csEntry
+// @param canRemove
+// @return bool
+//
+func (L *UniversalCSPolicy) Remove(csEntry *CSEntry, canRemove func(csEntry *CSEntry) bool) bool {
+	key := csEntry.GetIdentifier().ToUri()
+	L.lock.Lock()
+	defer L.lock.Unlock()
+	// 只在条目即将被删除时查询，所以对 LRU 访问顺序和 LFU 访问次数的影响可以忽略
+	item, err := L.cache.GetIFPresent(key)
+	if err != nil || item != csEntry {
+		return false
+	}
+	if canRemove != nil && !canRemove(csEntry) {
+		return false
+	}
+	return L.cache.Remove(key)
+}
+
 /////////////////////////////////////////////////////////////////////////////////////////////////////////
 ///// 错误处理
 /////////////////////////////////////////////////////////////////////////////////////////////////////////
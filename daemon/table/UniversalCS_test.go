@@ -0,0 +1,228 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 11:05 上午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	"minlib/component"
+	"minlib/packet"
+	"mir-go/daemon/common"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newPartitionTestConfig() *common.MIRConfig {
+	config := new(common.MIRConfig)
+	config.Init()
+	config.TableConfig.CSSize = 10
+	config.TableConfig.CSPartitionConfigs = []common.CSPartitionConfig{
+		{Name: "video", Prefixes: []string{"/video"}, CSSize: 5, CSReplaceStrategy: "lru"},
+		{Name: "critical", Prefixes: []string{"/critical", "/video/critical"}, CSSize: 5, CSReplaceStrategy: "lfu"},
+	}
+	return config
+}
+
+func newTestData(name string) *packet.Data {
+	identifier, _ := component.CreateIdentifierByString(name)
+	data := new(packet.Data)
+	data.SetName(identifier)
+	return data
+}
+
+func newTestInterest(name string) *packet.Interest {
+	identifier, _ := component.CreateIdentifierByString(name)
+	interest := new(packet.Interest)
+	interest.SetName(identifier)
+	return interest
+}
+
+func TestUniversalCS_PartitionIsolation(t *testing.T) {
+	cs, err := NewUniversalCS(newPartitionTestConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 先缓存关键前缀和默认分区的数据
+	for _, name := range []string{"/critical/a", "/video/critical/b", "/other/c"} {
+		if _, err := cs.Insert(newTestData(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 大量的视频数据只会踢出视频分区的缓存
+	for i := 0; i < 100; i++ {
+		if _, err := cs.Insert(newTestData("/video/" + strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"/critical/a", "/video/critical/b", "/other/c", "/video/99"} {
+		if _, err := cs.Find(newTestInterest(name)); err != nil {
+			t.Errorf("%s should be cached, err = %v", name, err)
+		}
+	}
+	if _, err := cs.Find(newTestInterest("/video/0")); err == nil {
+		t.Errorf("/video/0 should be evicted")
+	}
+
+	statusList := cs.GetPartitionStatus()
+	if len(statusList) != 3 || statusList[0].Name != DefaultCSPartitionName {
+		t.Fatalf("unexpected partition status: %v", statusList)
+	}
+	if statusList[1].Size != 5 || statusList[2].Size != 2 || statusList[0].Size != 1 {
+		t.Errorf("unexpected partition size: %d %d %d", statusList[0].Size, statusList[1].Size, statusList[2].Size)
+	}
+	if cs.Size() != 8 {
+		t.Errorf("cs size should be 8, got %d", cs.Size())
+	}
+}
+
+func TestUniversalCS_DuplicatePrefix(t *testing.T) {
+	config := newPartitionTestConfig()
+	config.TableConfig.CSPartitionConfigs = append(config.TableConfig.CSPartitionConfigs,
+		common.CSPartitionConfig{Name: "dup", Prefixes: []string{"/video"}, CSSize: 5, CSReplaceStrategy: "lru"})
+	if _, err := NewUniversalCS(config); err == nil {
+		t.Errorf("binding one prefix to two partitions should fail")
+	}
+}
+
+func TestUniversalCS_Sweep(t *testing.T) {
+	config := newPartitionTestConfig()
+	config.TableConfig.CSMaxAgeConfigs = map[string]int{"/video": 10}
+	cs, err := NewUniversalCS(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	staleTime := time.Now().Unix() - 100
+	for _, name := range []string{"/video/a", "/video/b", "/other/c"} {
+		csEntry, err := cs.Insert(newTestData(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != "/video/b" {
+			csEntry.UpdateStaleTime(staleTime)
+		}
+	}
+
+	// 只有 /video/a 变旧超过了 10 秒，/other/c 没有配置最大保留时间
+	if removed := cs.Sweep(100); removed != 1 {
+		t.Errorf("sweep should remove 1 entry, got %d", removed)
+	}
+	if _, err := cs.Find(newTestInterest("/video/a")); err == nil {
+		t.Errorf("/video/a should be swept")
+	}
+	for _, name := range []string{"/video/b", "/other/c"} {
+		if _, err := cs.Find(newTestInterest(name)); err != nil {
+			t.Errorf("%s should be cached, err = %v", name, err)
+		}
+	}
+	if status := cs.GetPartitionStatus()[1]; status.Swept != 1 {
+		t.Errorf("swept counter of partition %s should be 1, got %d", status.Name, status.Swept)
+	}
+}
+
+func TestUniversalCS_SweepBatch(t *testing.T) {
+	config := newPartitionTestConfig()
+	config.TableConfig.CSMaxAge = 1
+	cs, err := NewUniversalCS(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	staleTime := time.Now().Unix() - 100
+	for i := 0; i < 10; i++ {
+		csEntry, err := cs.Insert(newTestData("/other/" + strconv.Itoa(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		csEntry.UpdateStaleTime(staleTime)
+	}
+
+	// 每一轮最多检查 3 个表项
+	total := 0
+	for i := 0; i < 4; i++ {
+		removed := cs.Sweep(3)
+		if removed > 3 {
+			t.Fatalf("sweep should not remove more than 3 entries, got %d", removed)
+		}
+		total += removed
+	}
+	if total != 10 || cs.Size() != 0 {
+		t.Errorf("all entries should be swept, removed = %d, size = %d", total, cs.Size())
+	}
+}
+
+// 清理快照中的旧表项被同名的新数据包替换之后，不能误删新数据包
+func TestCSPartition_SweepReplacedEntry(t *testing.T) {
+	partition, err := NewCSPartition("video", nil, 10, "lru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	staleTime := time.Now().Unix() - 100
+	for _, name := range []string{"/video/a", "/video/b"} {
+		csEntry, err := partition.Insert(newTestData(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		csEntry.UpdateStaleTime(staleTime)
+	}
+	getMaxAge := func(identifier *component.Identifier) int64 { return 10 }
+
+	// 第一次清理对两个表项做快照，只检查其中一个
+	if checked, removed := partition.Sweep(1, time.Now().Unix(), getMaxAge); checked != 1 || removed != 1 {
+		t.Fatalf("unexpected sweep result %d, %d", checked, removed)
+	}
+	name := "/video/a"
+	if _, err := partition.Find(newTestInterest(name)); err == nil {
+		name = "/video/b"
+	}
+	// 快照中剩下的表项被删除之后又缓存了同名的新数据包
+	oldEntry, err := partition.Find(newTestInterest(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !partition.policy.Remove(oldEntry, nil) {
+		t.Fatal("old entry should be removed")
+	}
+	freshEntry, err := partition.Insert(newTestData(name))
+	if err != nil || freshEntry == oldEntry {
+		t.Fatalf("fresh entry should be inserted, err = %v", err)
+	}
+	freshEntry.UpdateStaleTime(time.Now().Unix() + 100)
+
+	if checked, removed := partition.Sweep(1, time.Now().Unix(), getMaxAge); checked != 1 || removed != 0 {
+		t.Fatalf("stale snapshot entry should not evict fresh data, got %d, %d", checked, removed)
+	}
+	if csEntry, err := partition.Find(newTestInterest(name)); err != nil || csEntry != freshEntry {
+		t.Fatalf("fresh data of %s should be cached, err = %v", name, err)
+	}
+
+	// 表项仍然是同一个但是已经被刷新时也不删除
+	freshEntry.UpdateStaleTime(staleTime)
+	if partition.policy.Remove(freshEntry, func(csEntry *CSEntry) bool { return !csEntry.IsStale() }) {
+		t.Fatal("entry should not be removed when the check fails")
+	}
+	if !partition.policy.Remove(freshEntry, func(csEntry *CSEntry) bool { return csEntry.IsStale() }) {
+		t.Fatal("stale entry should be removed")
+	}
+}
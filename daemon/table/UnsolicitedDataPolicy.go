@@ -0,0 +1,233 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 19:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	"fmt"
+	"minlib/component"
+	"mir-go/daemon/lf"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UnsolicitedDataPolicy 未请求数据（Unsolicited Data）的接纳策略
+type UnsolicitedDataPolicy int
+
+//
+// @Description: 未请求数据的接纳策略，被接纳的数据包会被缓存到 CS 中
+//
+const (
+	UnsolicitedDataPolicyDropAll      UnsolicitedDataPolicy = 0 // 丢弃所有未请求数据
+	UnsolicitedDataPolicyAdmitLocal   UnsolicitedDataPolicy = 1 // 只接纳从 local 作用域的 LogicFace（Unix、内部）收到的未请求数据
+	UnsolicitedDataPolicyAdmitNetwork UnsolicitedDataPolicy = 2 // 只接纳从 non-local 作用域的 LogicFace（TCP、UDP、以太网）收到的未请求数据
+	UnsolicitedDataPolicyAdmitAll     UnsolicitedDataPolicy = 3 // 接纳所有未请求数据
+)
+
+var unsolicitedDataPolicyNames = map[UnsolicitedDataPolicy]string{
+	UnsolicitedDataPolicyDropAll:      "drop-all",
+	UnsolicitedDataPolicyAdmitLocal:   "admit-local",
+	UnsolicitedDataPolicyAdmitNetwork: "admit-network",
+	UnsolicitedDataPolicyAdmitAll:     "admit-all",
+}
+
+// ParseUnsolicitedDataPolicy 根据策略名解析未请求数据的接纳策略
+//
+// @Description:
+// @param name	drop-all | admit-local | admit-network | admit-all
+// @return UnsolicitedDataPolicy
+// @return error
+//
+func ParseUnsolicitedDataPolicy(name string) (UnsolicitedDataPolicy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for policy, policyName := range unsolicitedDataPolicyNames {
+		if policyName == name {
+			return policy, nil
+		}
+	}
+	return UnsolicitedDataPolicyDropAll, UnsolicitedDataPolicyError{msg: "unknown unsolicited data policy: " + name}
+}
+
+// String 获取策略名
+//
+// @Description:
+// @receiver p
+// @return string
+//
+func (p UnsolicitedDataPolicy) String() string {
+	if name, ok := unsolicitedDataPolicyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(p))
+}
+
+// Admit 判断是否接纳从 ingress 收到的未请求数据
+//
+// @Description:
+// @receiver p
+// @param ingress
+// @return bool
+//
+func (p UnsolicitedDataPolicy) Admit(ingress *lf.LogicFace) bool {
+	switch p {
+	case UnsolicitedDataPolicyAdmitLocal:
+		return ingress.IsLocal()
+	case UnsolicitedDataPolicyAdmitNetwork:
+		return !ingress.IsLocal()
+	case UnsolicitedDataPolicyAdmitAll:
+		return true
+	}
+	return false
+}
+
+// UnsolicitedDataPolicyEntry 前缀绑定的未请求数据接纳策略
+//
+// @Description:
+//
+type UnsolicitedDataPolicyEntry struct {
+	Prefix string // 前缀
+	Policy string // 策略名
+}
+
+// UnsolicitedDataPolicyTable 未请求数据接纳策略表
+//
+// @Description:
+//	1.每个前缀可以绑定一个接纳策略，未请求数据根据名字最长前缀匹配到对应的策略，没有匹配到任何前缀时使用默认策略
+//	2.策略可以在运行时通过管理模块修改，所有操作都是线程安全的
+//
+type UnsolicitedDataPolicyTable struct {
+	defaultPolicy UnsolicitedDataPolicy            // 默认策略
+	matcher       *LpmMatcher                      // 前缀 => 策略 的最长前缀匹配器
+	policies      map[string]UnsolicitedDataPolicy // 前缀 => 策略
+	lock          sync.RWMutex
+}
+
+// CreateUnsolicitedDataPolicyTable 新建一个未请求数据接纳策略表
+//
+// @Description:
+// @param defaultPolicy	默认策略
+// @return *UnsolicitedDataPolicyTable
+//
+func CreateUnsolicitedDataPolicyTable(defaultPolicy UnsolicitedDataPolicy) *UnsolicitedDataPolicyTable {
+	t := &UnsolicitedDataPolicyTable{
+		defaultPolicy: defaultPolicy,
+		matcher:       new(LpmMatcher),
+		policies:      make(map[string]UnsolicitedDataPolicy),
+	}
+	t.matcher.Create()
+	return t
+}
+
+// GetDefaultPolicy 获取默认策略
+//
+// @Description:
+// @receiver t
+// @return UnsolicitedDataPolicy
+//
+func (t *UnsolicitedDataPolicyTable) GetDefaultPolicy() UnsolicitedDataPolicy {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.defaultPolicy
+}
+
+// SetPolicy 给前缀绑定一个接纳策略，已经绑定的策略会被覆盖
+//
+// @Description:
+// @receiver t
+// @param prefix
+// @param policy
+//
+func (t *UnsolicitedDataPolicyTable) SetPolicy(prefix *component.Identifier, policy UnsolicitedDataPolicy) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.matcher.AddOrUpdate(identifierToPrefixList(prefix), policy, nil)
+	t.policies[prefix.ToUri()] = policy
+}
+
+// UnsetPolicy 删除前缀绑定的接纳策略
+//
+// @Description:
+// @receiver t
+// @param prefix
+// @return error	前缀没有绑定策略时返回错误
+//
+func (t *UnsolicitedDataPolicyTable) UnsetPolicy(prefix *component.Identifier) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, ok := t.policies[prefix.ToUri()]; !ok {
+		return UnsolicitedDataPolicyError{msg: "no unsolicited data policy is set for " + prefix.ToUri()}
+	}
+	delete(t.policies, prefix.ToUri())
+	return t.matcher.Delete(identifierToPrefixList(prefix))
+}
+
+// FindPolicy 获取名字最长前缀匹配到的接纳策略，没有匹配到时返回默认策略
+//
+// @Description:
+// @receiver t
+// @param identifier
+// @return UnsolicitedDataPolicy
+//
+func (t *UnsolicitedDataPolicyTable) FindPolicy(identifier *component.Identifier) UnsolicitedDataPolicy {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	if len(t.policies) == 0 {
+		return t.defaultPolicy
+	}
+	if val, ok := t.matcher.FindLongestPrefixMatch(identifierToPrefixList(identifier)); ok {
+		if policy, ok := val.(UnsolicitedDataPolicy); ok {
+			return policy
+		}
+	}
+	return t.defaultPolicy
+}
+
+// List 获取所有前缀绑定的接纳策略，按前缀排序
+//
+// @Description:
+// @receiver t
+// @return []*UnsolicitedDataPolicyEntry
+//
+func (t *UnsolicitedDataPolicyTable) List() []*UnsolicitedDataPolicyEntry {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	entries := make([]*UnsolicitedDataPolicyEntry, 0, len(t.policies))
+	for prefix, policy := range t.policies {
+		entries = append(entries, &UnsolicitedDataPolicyEntry{Prefix: prefix, Policy: policy.String()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Prefix < entries[j].Prefix
+	})
+	return entries
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 错误处理
+/////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type UnsolicitedDataPolicyError struct {
+	msg string
+}
+
+func (u UnsolicitedDataPolicyError) Error() string {
+	return fmt.Sprintf("UnsolicitedDataPolicyError: %s", u.msg)
+}
@@ -0,0 +1,89 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package table
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 19:50 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package table
+
+import (
+	"mir-go/daemon/lf"
+	"testing"
+)
+
+func TestParseUnsolicitedDataPolicy(t *testing.T) {
+	for _, name := range []string{"drop-all", "admit-local", "admit-network", "admit-all"} {
+		policy, err := ParseUnsolicitedDataPolicy(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if policy.String() != name {
+			t.Fatalf("policy %s parsed as %s", name, policy.String())
+		}
+	}
+	if policy, err := ParseUnsolicitedDataPolicy(" Admit-All "); err != nil || policy != UnsolicitedDataPolicyAdmitAll {
+		t.Fatalf("policy name should be case-insensitive: %v %v", policy, err)
+	}
+	if _, err := ParseUnsolicitedDataPolicy("admit-some"); err == nil {
+		t.Fatal("unknown policy should be rejected")
+	}
+}
+
+func TestUnsolicitedDataPolicyTable(t *testing.T) {
+	// 零值的 LogicFace 是 non-local 的
+	networkFace := &lf.LogicFace{LogicFaceId: 1}
+
+	policies := CreateUnsolicitedDataPolicyTable(UnsolicitedDataPolicyDropAll)
+	policies.SetPolicy(mustCreateIdentifier(t, "/video"), UnsolicitedDataPolicyAdmitNetwork)
+	policies.SetPolicy(mustCreateIdentifier(t, "/video/private"), UnsolicitedDataPolicyAdmitLocal)
+
+	cases := []struct {
+		name   string
+		policy UnsolicitedDataPolicy
+		admit  bool
+	}{
+		{"/doc/a", UnsolicitedDataPolicyDropAll, false},
+		{"/video/movie", UnsolicitedDataPolicyAdmitNetwork, true},
+		{"/video/private/movie", UnsolicitedDataPolicyAdmitLocal, false},
+	}
+	for _, c := range cases {
+		policy := policies.FindPolicy(mustCreateIdentifier(t, c.name))
+		if policy != c.policy {
+			t.Errorf("FindPolicy(%s) = %s, want %s", c.name, policy, c.policy)
+		}
+		if policy.Admit(networkFace) != c.admit {
+			t.Errorf("%s.Admit(non-local) = %v, want %v", policy, !c.admit, c.admit)
+		}
+	}
+
+	entries := policies.List()
+	if len(entries) != 2 || entries[0].Prefix != "/video" || entries[1].Policy != "admit-local" {
+		t.Fatalf("unexpected policy list: %v", entries)
+	}
+
+	// 删除之后回退到更短前缀绑定的策略
+	if err := policies.UnsetPolicy(mustCreateIdentifier(t, "/video/private")); err != nil {
+		t.Fatal(err)
+	}
+	if policy := policies.FindPolicy(mustCreateIdentifier(t, "/video/private/movie")); policy != UnsolicitedDataPolicyAdmitNetwork {
+		t.Fatalf("unexpected policy after unset: %s", policy)
+	}
+	if err := policies.UnsetPolicy(mustCreateIdentifier(t, "/video/private")); err == nil {
+		t.Fatal("unset a prefix without policy should fail")
+	}
+}
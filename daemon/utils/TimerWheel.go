@@ -0,0 +1,331 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package utils
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 14:40 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	timerWheelBits   = 8                   // 每一层时间轮的槽数为 2^timerWheelBits
+	timerWheelSize   = 1 << timerWheelBits // 每一层时间轮的槽数
+	timerWheelMask   = timerWheelSize - 1  // 计算槽下标使用的掩码
+	timerWheelLevels = 4                   // 时间轮的层数，tick 为 1ms 时可以覆盖约 49 天
+)
+
+// timerWheelSpan 时间轮能覆盖的 tick 数
+const timerWheelSpan = int64(1) << (timerWheelBits * timerWheelLevels)
+
+// 定时任务的状态
+const (
+	timerStatePending   = iota // 等待触发
+	timerStateFired            // 已经触发
+	timerStateCancelled        // 已经取消
+)
+
+// TimerHandle 定时任务的句柄，用于取消定时任务
+//
+// @Description:
+//  每个定时任务对应一个独立的句柄，取消定时任务时直接使用句柄，不再像 HeapTimer 那样用字符串作为 key，
+//  因此同名的两个定时任务不会互相影响
+//
+type TimerHandle struct {
+	expireTick int64        // 到期的 tick
+	callback   func()       // 到期时执行的回调
+	state      int          // 定时任务的状态
+	bucket     *timerBucket // 定时任务当前所在的槽，为 nil 表示不在任何槽中
+	prev       *TimerHandle // 槽中的前一个定时任务
+	next       *TimerHandle // 槽中的后一个定时任务
+}
+
+// timerBucket 时间轮的一个槽，用双向链表存放定时任务，使得取消定时任务是 O(1) 的
+//
+// @Description:
+//
+type timerBucket struct {
+	head  TimerHandle // 哨兵节点
+	level int         // 槽所在的层，-1 表示已经到期的定时任务列表
+}
+
+//
+// @Description: 初始化槽
+// @receiver b
+//
+func (b *timerBucket) init() {
+	b.head.prev = &b.head
+	b.head.next = &b.head
+}
+
+//
+// @Description: 把定时任务加入到槽的末尾
+// @receiver b
+// @param handle
+//
+func (b *timerBucket) pushBack(handle *TimerHandle) {
+	handle.prev = b.head.prev
+	handle.next = &b.head
+	b.head.prev.next = handle
+	b.head.prev = handle
+	handle.bucket = b
+}
+
+//
+// @Description: 把定时任务从槽中移除
+// @receiver b
+// @param handle
+//
+func (b *timerBucket) remove(handle *TimerHandle) {
+	handle.prev.next = handle.next
+	handle.next.prev = handle.prev
+	handle.prev = nil
+	handle.next = nil
+	handle.bucket = nil
+}
+
+//
+// @Description: 取出槽中所有的定时任务追加到 handles 后面，并清空槽
+// @receiver b
+// @param handles
+// @return []*TimerHandle
+//
+func (b *timerBucket) takeAll(handles []*TimerHandle) []*TimerHandle {
+	for handle := b.head.next; handle != &b.head; {
+		next := handle.next
+		handle.prev = nil
+		handle.next = nil
+		handle.bucket = nil
+		handles = append(handles, handle)
+		handle = next
+	}
+	b.init()
+	return handles
+}
+
+// TimerWheel 分层时间轮
+//
+// @Description:
+//  1. 时间轮一共有 timerWheelLevels 层，每层 timerWheelSize 个槽，第 i 层的一个槽对应 timerWheelSize^i 个 tick；
+//  2. 添加定时任务时，根据到期时间距离当前时间的 tick 数选择所在的层和槽，添加和取消都是 O(1) 的；
+//  3. 时间每推进一个 tick，如果低层的时间轮转完了一圈，就把高层对应槽中的定时任务重新分配到低层（cascade），
+//     然后触发第 0 层当前槽中的所有定时任务；
+//  4. 回调在释放锁之后执行，所以回调中可以再添加或者取消定时任务；
+//  5. 定时任务触发之后再取消不会产生任何影响，同一个定时任务最多只会触发一次，已经取消的定时任务一定不会触发。
+//
+type TimerWheel struct {
+	lock        sync.Mutex
+	tickMs      int64                                         // 一个 tick 对应的毫秒数
+	currentTick int64                                         // 当前时间对应的 tick
+	buckets     [timerWheelLevels][timerWheelSize]timerBucket // 各层时间轮的槽
+	due         timerBucket                                   // 已经到期，等待下一次 DealEvent 触发的定时任务
+	count       int                                           // 等待触发的定时任务数
+	levelCount  [timerWheelLevels]int                         // 各层时间轮中的定时任务数，用来跳过空的层
+	now         func() int64                                  // 获取当前时间（毫秒）的函数，测试时可以替换
+}
+
+// NewTimerWheel 新建一个时间轮
+//
+// @Description:
+// @param tickMs	一个 tick 对应的毫秒数，小于等于 0 时使用 1ms
+// @return *TimerWheel
+//
+func NewTimerWheel(tickMs int64) *TimerWheel {
+	return newTimerWheelWithClock(tickMs, func() int64 {
+		return time.Now().UnixNano() / int64(time.Millisecond)
+	})
+}
+
+//
+// @Description: 使用指定的时钟新建一个时间轮
+// @param tickMs
+// @param now
+// @return *TimerWheel
+//
+func newTimerWheelWithClock(tickMs int64, now func() int64) *TimerWheel {
+	if tickMs <= 0 {
+		tickMs = 1
+	}
+	t := &TimerWheel{tickMs: tickMs, now: now}
+	for level := range t.buckets {
+		for slot := range t.buckets[level] {
+			t.buckets[level][slot].init()
+			t.buckets[level][slot].level = level
+		}
+	}
+	t.due.init()
+	t.due.level = -1
+	t.currentTick = now() / tickMs
+	return t
+}
+
+// Size 返回等待触发的定时任务数
+//
+// @Description:
+// @receiver t
+// @return int
+//
+func (t *TimerWheel) Size() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.count
+}
+
+// AddTimeoutEvent 添加一个定时任务，在 duration 毫秒之后执行 callback
+//
+// @Description:
+//  duration 小于等于 0 的定时任务会在下一次调用 DealEvent 时触发
+// @receiver t
+// @param duration	超时时间，单位为毫秒
+// @param callback	到期时执行的回调
+// @return *TimerHandle	定时任务的句柄，用于取消定时任务
+//
+func (t *TimerWheel) AddTimeoutEvent(duration int64, callback func()) *TimerHandle {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if duration < 0 {
+		duration = 0
+	}
+	handle := &TimerHandle{
+		// 向上取整，保证定时任务不会提前触发
+		expireTick: (t.now() + duration + t.tickMs - 1) / t.tickMs,
+		callback:   callback,
+		state:      timerStatePending,
+	}
+	t.place(handle)
+	t.count++
+	return handle
+}
+
+// CancelEvent 取消一个定时任务
+//
+// @Description:
+// @receiver t
+// @param handle
+// @return bool	定时任务还没有触发并且取消成功返回 true，已经触发过或者已经取消过返回 false
+//
+func (t *TimerWheel) CancelEvent(handle *TimerHandle) bool {
+	if handle == nil {
+		return false
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if handle.state != timerStatePending {
+		return false
+	}
+	handle.state = timerStateCancelled
+	if handle.bucket != nil {
+		if handle.bucket.level >= 0 {
+			t.levelCount[handle.bucket.level]--
+		}
+		handle.bucket.remove(handle)
+	}
+	t.count--
+	return true
+}
+
+// DealEvent 把时间轮推进到当前时间，并触发所有已经到期的定时任务
+//
+// @Description:
+// @receiver t
+//
+func (t *TimerWheel) DealEvent() {
+	t.advance(t.now() / t.tickMs)
+}
+
+//
+// @Description: 把定时任务放入合适的槽中，调用者需要持有锁
+// @receiver t
+// @param handle
+//
+func (t *TimerWheel) place(handle *TimerHandle) {
+	delta := handle.expireTick - t.currentTick
+	if delta <= 0 {
+		t.due.pushBack(handle)
+		return
+	}
+	expireTick := handle.expireTick
+	if delta >= timerWheelSpan {
+		// 超出时间轮的范围，先放到最高层最远的槽中，cascade 的时候会重新计算
+		expireTick = t.currentTick + timerWheelSpan - 1
+		delta = timerWheelSpan - 1
+	}
+	level := 0
+	for delta >= int64(1)<<(timerWheelBits*(level+1)) {
+		level++
+	}
+	slot := (expireTick >> (timerWheelBits * level)) & timerWheelMask
+	t.buckets[level][slot].pushBack(handle)
+	t.levelCount[level]++
+}
+
+//
+// @Description: 把时间轮推进到 targetTick，并触发所有已经到期的定时任务
+// @receiver t
+// @param targetTick
+//
+func (t *TimerWheel) advance(targetTick int64) {
+	t.lock.Lock()
+	fired := t.due.takeAll(nil)
+	for t.currentTick < targetTick {
+		// 找到下一个需要处理的 tick：如果低层的时间轮是空的，直接跳到高层下一次 cascade 的位置
+		nextTick := t.currentTick + 1
+		for level := 0; level < timerWheelLevels && t.levelCount[level] == 0; level++ {
+			step := int64(1) << (timerWheelBits * (level + 1))
+			nextTick = (t.currentTick/step + 1) * step
+		}
+		if nextTick > targetTick {
+			t.currentTick = targetTick
+			break
+		}
+		t.currentTick = nextTick
+		// 低层的时间轮转完了一圈，把高层对应槽中的定时任务重新分配到低层
+		for level := 1; level < timerWheelLevels; level++ {
+			if t.currentTick&(int64(1)<<(timerWheelBits*level)-1) != 0 {
+				break
+			}
+			slot := (t.currentTick >> (timerWheelBits * level)) & timerWheelMask
+			handles := t.buckets[level][slot].takeAll(nil)
+			t.levelCount[level] -= len(handles)
+			for _, handle := range handles {
+				t.place(handle)
+			}
+		}
+		before := len(fired)
+		fired = t.buckets[0][t.currentTick&timerWheelMask].takeAll(fired)
+		t.levelCount[0] -= len(fired) - before
+		fired = t.due.takeAll(fired)
+	}
+	t.lock.Unlock()
+
+	// 在锁外执行回调，执行之前检查定时任务是否已经被前面的回调取消了
+	for _, handle := range fired {
+		t.lock.Lock()
+		if handle.state != timerStatePending {
+			t.lock.Unlock()
+			continue
+		}
+		handle.state = timerStateFired
+		t.count--
+		t.lock.Unlock()
+		handle.callback()
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright [2022] [MIN-Group -- Peking University Shenzhen Graduate School Multi-Identifier Network Development Group]
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package utils
+// @Author: MIN-Group
+// @Description:
+// @Version: 1.0.0
+// @Date: 2026/10/19 15:10 下午
+// @Copyright: MIN-Group；国家重大科技基础设施——未来网络北大实验室；深圳市信息论与未来网络重点实验室
+//
+package utils
+
+import (
+	utils2 "minlib/utils"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeClock 测试使用的时钟，时间只会在调用 advance 时推进
+type fakeClock struct {
+	now int64
+}
+
+func (c *fakeClock) Now() int64 {
+	return atomic.LoadInt64(&c.now)
+}
+
+func (c *fakeClock) advance(ms int64) {
+	atomic.AddInt64(&c.now, ms)
+}
+
+func newTestTimerWheel() (*TimerWheel, *fakeClock) {
+	clock := &fakeClock{now: 1000000}
+	return newTimerWheelWithClock(1, clock.Now), clock
+}
+
+func TestTimerWheel_FireInOrder(t *testing.T) {
+	wheel, clock := newTestTimerWheel()
+	var fired []int64
+	// 覆盖第 0 层到第 3 层，以及超出时间轮范围的定时任务
+	durations := []int64{0, 1, 255, 256, 1000, 65535, 65536, 70000, 16777216, timerWheelSpan + 5}
+	for i := len(durations) - 1; i >= 0; i-- {
+		duration := durations[i]
+		wheel.AddTimeoutEvent(duration, func() {
+			fired = append(fired, duration)
+		})
+	}
+
+	start := clock.Now()
+	for i, duration := range durations {
+		// 到期前一毫秒不能触发
+		if duration > 0 {
+			clock.now = start + duration - 1
+			wheel.DealEvent()
+			if len(fired) != i {
+				t.Fatalf("timer of %d ms fired too early", duration)
+			}
+		}
+		clock.now = start + duration
+		wheel.DealEvent()
+		if len(fired) != i+1 || fired[i] != duration {
+			t.Fatalf("timer of %d ms should fire, fired = %v", duration, fired)
+		}
+	}
+	if wheel.Size() != 0 {
+		t.Errorf("wheel should be empty, size = %d", wheel.Size())
+	}
+}
+
+func TestTimerWheel_Cancel(t *testing.T) {
+	wheel, clock := newTestTimerWheel()
+	var fired int32
+	handle := wheel.AddTimeoutEvent(100, func() {
+		atomic.AddInt32(&fired, 1)
+	})
+	if !wheel.CancelEvent(handle) {
+		t.Fatalf("cancel a pending timer should succeed")
+	}
+	if wheel.CancelEvent(handle) {
+		t.Errorf("cancel a timer twice should fail")
+	}
+	clock.advance(200)
+	wheel.DealEvent()
+	if fired != 0 {
+		t.Errorf("cancelled timer should not fire")
+	}
+
+	// 定时任务已经触发之后再取消，不会产生任何影响
+	handle = wheel.AddTimeoutEvent(10, func() {
+		atomic.AddInt32(&fired, 1)
+	})
+	clock.advance(10)
+	wheel.DealEvent()
+	if wheel.CancelEvent(handle) {
+		t.Errorf("cancel a fired timer should fail")
+	}
+	clock.advance(10)
+	wheel.DealEvent()
+	if fired != 1 || wheel.Size() != 0 {
+		t.Errorf("timer should fire exactly once, fired = %d, size = %d", fired, wheel.Size())
+	}
+	if wheel.CancelEvent(nil) {
+		t.Errorf("cancel a nil handle should fail")
+	}
+}
+
+func TestTimerWheel_CancelInCallback(t *testing.T) {
+	wheel, clock := newTestTimerWheel()
+	var second *TimerHandle
+	secondFired := false
+	// 两个定时任务在同一个 tick 到期，第一个回调取消第二个
+	wheel.AddTimeoutEvent(5, func() {
+		wheel.CancelEvent(second)
+	})
+	second = wheel.AddTimeoutEvent(5, func() {
+		secondFired = true
+	})
+	clock.advance(5)
+	wheel.DealEvent()
+	if secondFired {
+		t.Errorf("timer cancelled by an earlier callback in the same tick should not fire")
+	}
+
+	// 回调中重新添加定时任务
+	count := 0
+	var rearm func()
+	rearm = func() {
+		count++
+		if count < 3 {
+			wheel.AddTimeoutEvent(0, rearm)
+		}
+	}
+	wheel.AddTimeoutEvent(0, rearm)
+	for i := 0; i < 3; i++ {
+		wheel.DealEvent()
+	}
+	if count != 3 {
+		t.Errorf("timer added in callback should fire, count = %d", count)
+	}
+}
+
+// 使用 go test -race 运行，检查并发添加、取消和触发定时任务时没有数据竞争
+func TestTimerWheel_Concurrent(t *testing.T) {
+	wheel, clock := newTestTimerWheel()
+	var fired, cancelled int64
+	var wg sync.WaitGroup
+	const workers, perWorker = 8, 1000
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				handle := wheel.AddTimeoutEvent(int64(i%300), func() {
+					atomic.AddInt64(&fired, 1)
+				})
+				if i%2 == w%2 && wheel.CancelEvent(handle) {
+					atomic.AddInt64(&cancelled, 1)
+				}
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				clock.advance(1)
+				wheel.DealEvent()
+			}
+		}
+	}()
+	wg.Wait()
+	close(done)
+	clock.advance(1000)
+	wheel.DealEvent()
+	if fired+cancelled != workers*perWorker || wheel.Size() != 0 {
+		t.Errorf("every timer should either fire or be cancelled, fired = %d, cancelled = %d, size = %d",
+			fired, cancelled, wheel.Size())
+	}
+}
+
+// 基准测试：模拟 PIT 条目的超时时间被不断刷新，对比时间轮和字符串为 key 的堆定时器
+
+func BenchmarkTimerWheel_AddCancel(b *testing.B) {
+	wheel := NewTimerWheel(1)
+	handles := make([]*TimerHandle, 1024)
+	callback := func() {}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx := i & 1023
+		wheel.CancelEvent(handles[idx])
+		handles[idx] = wheel.AddTimeoutEvent(int64(4000+i&1023), callback)
+		if i&63 == 0 {
+			wheel.DealEvent()
+		}
+	}
+}
+
+func BenchmarkHeapTimer_AddCancel(b *testing.B) {
+	heapTimer := utils2.NewHeapTimer()
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = "/min/pku/edu/" + strconv.Itoa(i)
+	}
+	callback := func() {}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx := i & 1023
+		heapTimer.CancelEvent(keys[idx])
+		heapTimer.AddTimeoutEvent(int64(4000+i&1023), keys[idx], callback)
+		if i&63 == 0 {
+			heapTimer.DealEvent()
+		}
+	}
+}
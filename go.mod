@@ -13,6 +13,7 @@ require (
 	github.com/sirupsen/logrus v1.8.1
 	github.com/smartystreets/goconvey v1.6.4 // indirect
 	github.com/urfave/cli/v2 v2.3.0
+	golang.org/x/net v0.0.0-20190620200207-3b0461eec859
 	gopkg.in/ini.v1 v1.62.0
 	minlib v0.0.0
 )